@@ -20,6 +20,8 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/ontio/ontology/cmd/utils"
 	"github.com/ontio/ontology/common"
@@ -38,7 +40,10 @@ func SetOntologyConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
 	setCommonConfig(ctx, cfg.Common)
 	setConsensusConfig(ctx, cfg.Consensus)
 	setP2PNodeConfig(ctx, cfg.P2PNode)
-	setRpcConfig(ctx, cfg.Rpc)
+	err = setRpcConfig(ctx, cfg.Rpc)
+	if err != nil {
+		return nil, fmt.Errorf("setRpcConfig error:%s", err)
+	}
 	setRestfulConfig(ctx, cfg.Restful)
 	setGraphQLConfig(ctx, cfg.GraphQL)
 	setWebSocketConfig(ctx, cfg.Ws)
@@ -119,6 +124,13 @@ func setGenesis(ctx *cli.Context, cfg *config.OntologyConfig) error {
 		if cfg.Genesis.DBFT.GenBlockTime <= 0 {
 			cfg.Genesis.DBFT.GenBlockTime = config.DEFAULT_GEN_BLOCK_TIME
 		}
+	case config.CONSENSUS_TYPE_SBFT:
+		if len(cfg.Genesis.SBFT.Bookkeepers) < config.SBFT_MIN_NODE_NUM {
+			return fmt.Errorf("SBFT consensus at least need %d bookkeepers in config", config.SBFT_MIN_NODE_NUM)
+		}
+		if cfg.Genesis.SBFT.GenBlockTime <= 0 {
+			cfg.Genesis.SBFT.GenBlockTime = config.DEFAULT_GEN_BLOCK_TIME
+		}
 	case config.CONSENSUS_TYPE_VBFT:
 		err = governance.CheckVBFTConfig(cfg.Genesis.VBFT)
 		if err != nil {
@@ -137,11 +149,16 @@ func setGenesis(ctx *cli.Context, cfg *config.OntologyConfig) error {
 func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.LogLevel = ctx.Uint(utils.GetFlagName(utils.LogLevelFlag))
 	cfg.EnableEventLog = !ctx.Bool(utils.GetFlagName(utils.DisableEventLogFlag))
+	cfg.EnableArchive = ctx.Bool(utils.GetFlagName(utils.EnableArchiveFlag))
 	cfg.MinGasLimit = ctx.Uint64(utils.GetFlagName(utils.GasLimitFlag))
 	cfg.GasPrice = ctx.Uint64(utils.GetFlagName(utils.GasPriceFlag))
 	cfg.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+	cfg.DBBackend = ctx.String(utils.GetFlagName(utils.DBBackendFlag))
 	//add new flag for ethgaslimit
 	cfg.ETHTxGasLimit = ctx.Uint64(utils.GetFlagName(utils.ETHTxGasLimitFlag))
+	cfg.TxPoolCapacity = ctx.Uint(utils.GetFlagName(utils.TxPoolCapacityFlag))
+	cfg.TxPoolPayerSlots = ctx.Uint(utils.GetFlagName(utils.TxPoolPayerSlotsFlag))
+	cfg.TxPoolPriceBump = ctx.Uint(utils.GetFlagName(utils.TxPoolPriceBumpFlag))
 }
 
 func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
@@ -160,6 +177,7 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 	cfg.MaxConnInBound = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundFlag))
 	cfg.MaxConnOutBound = ctx.Uint(utils.GetFlagName(utils.MaxConnOutBoundFlag))
 	cfg.MaxConnInBoundForSingleIP = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundForSingleIPFlag))
+	cfg.FastSync = ctx.Bool(utils.GetFlagName(utils.FastSyncFlag))
 
 	rsvfile := ctx.String(utils.GetFlagName(utils.ReservedPeersFileFlag))
 	if cfg.ReservedPeersOnly {
@@ -182,11 +200,44 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 
 }
 
-func setRpcConfig(ctx *cli.Context, cfg *config.RpcConfig) {
+func setRpcConfig(ctx *cli.Context, cfg *config.RpcConfig) error {
 	cfg.EnableHttpJsonRpc = !ctx.Bool(utils.GetFlagName(utils.RPCDisabledFlag))
 	cfg.HttpJsonPort = ctx.Uint(utils.GetFlagName(utils.RPCPortFlag))
 	cfg.HttpLocalPort = ctx.Uint(utils.GetFlagName(utils.RPCLocalProtFlag))
 	cfg.EthJsonPort = ctx.Uint(utils.GetFlagName(utils.ETHRPCPortFlag))
+	cfg.EnableEthDebug = ctx.Bool(utils.GetFlagName(utils.ETHRPCDebugFlag))
+	cfg.EthWsOrigins = parseOrigins(ctx.String(utils.GetFlagName(utils.ETHRPCWsOriginsFlag)))
+	cfg.MaxBatchSize = ctx.Uint(utils.GetFlagName(utils.RPCMaxBatchSizeFlag))
+	cfg.RateLimit = ctx.Uint(utils.GetFlagName(utils.RPCRateLimitFlag))
+	cfg.RateBurst = ctx.Uint(utils.GetFlagName(utils.RPCRateBurstFlag))
+	cfg.AdminToken = ctx.String(utils.GetFlagName(utils.RPCAdminTokenFlag))
+	methodRates, err := parseMethodRateLimit(ctx.String(utils.GetFlagName(utils.RPCMethodRateLimitFlag)))
+	if err != nil {
+		return err
+	}
+	cfg.MethodRateLimit = methodRates
+	return nil
+}
+
+//parseMethodRateLimit parses the method rate limit like "getblocktxsbyheight=5,getblock=50"
+func parseMethodRateLimit(value string) (map[string]uint, error) {
+	methodRates := make(map[string]uint)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.Split(item, "=")
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid method rate limit:%s", item)
+		}
+		rate, err := strconv.ParseUint(strings.TrimSpace(kv[1]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid method rate limit:%s, %s", item, err)
+		}
+		methodRates[strings.TrimSpace(kv[0])] = uint(rate)
+	}
+	return methodRates, nil
 }
 
 func setRestfulConfig(ctx *cli.Context, cfg *config.RestfulConfig) {
@@ -199,6 +250,18 @@ func setGraphQLConfig(ctx *cli.Context, cfg *config.GraphQLConfig) {
 	cfg.EnableGraphQL = ctx.Bool(utils.GetFlagName(utils.GraphQLEnableFlag))
 	cfg.GraphQLPort = ctx.Uint(utils.GetFlagName(utils.GraphQLPortFlag))
 	cfg.MaxConnections = ctx.Uint(utils.GetFlagName(utils.GraphQLMaxConnsFlag))
+	cfg.AllowedOrigins = parseOrigins(ctx.String(utils.GetFlagName(utils.GraphQLOriginsFlag)))
+}
+
+//parseOrigins parses the comma separated origins
+func parseOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
 }
 
 func setWebSocketConfig(ctx *cli.Context, cfg *config.WebSocketConfig) {
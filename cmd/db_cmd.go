@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/store/ledgerstore"
+	"github.com/urfave/cli"
+)
+
+var DBCommand = cli.Command{
+	Name:  "db",
+	Usage: "Manage the storage of block data",
+	Subcommands: []cli.Command{
+		{
+			Action:    migrateDB,
+			Name:      "migrate",
+			Usage:     "Copy the block data to another data dir with different storage backend",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.DataDirFlag,
+				utils.DBBackendFlag,
+				utils.TargetDataDirFlag,
+				utils.TargetDBBackendFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+			Description: "Note that the node should be stopped before migrate, and the target data dir should be empty",
+		},
+	},
+}
+
+func migrateDB(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+
+	_, err := SetOntologyConfig(ctx)
+	if err != nil {
+		PrintErrorMsg("SetOntologyConfig error:%s", err)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	targetDir := ctx.String(utils.GetFlagName(utils.TargetDataDirFlag))
+	targetBackend := ctx.String(utils.GetFlagName(utils.TargetDBBackendFlag))
+	if targetDir == "" || targetBackend == "" {
+		PrintErrorMsg("Missing %s or %s argument.", utils.TargetDataDirFlag.Name, utils.TargetDBBackendFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	networkName := config.DefConfig.P2PNode.NetworkName
+	srcDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, networkName)
+	dstDir := utils.GetStoreDirPath(targetDir, networkName)
+	backend := config.DefConfig.Common.DBBackend
+
+	PrintInfoMsg("Start migrate %s(%s) to %s(%s).", srcDir, backend, dstDir, targetBackend)
+	err = ledgerstore.MigrateDataDir(srcDir, backend, dstDir, targetBackend, func(db string, count uint64) {
+		log.Infof("migrate %s: %d records", db, count)
+	})
+	if err != nil {
+		return fmt.Errorf("MigrateDataDir error:%s", err)
+	}
+	PrintInfoMsg("Migrate successfully.")
+	PrintInfoMsg("Start the node with --%s=%s --%s=%s", utils.DataDirFlag.Name, targetDir,
+		utils.DBBackendFlag.Name, targetBackend)
+	return nil
+}
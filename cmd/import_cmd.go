@@ -45,9 +45,11 @@ var ImportCommand = cli.Command{
 		utils.ImportFileFlag,
 		utils.ImportEndHeightFlag,
 		utils.DataDirFlag,
+		utils.DBBackendFlag,
 		utils.ConfigFlag,
 		utils.NetworkIdFlag,
 		utils.DisableEventLogFlag,
+		utils.EnableArchiveFlag,
 	},
 	Description: "Note that import cmd doesn't support testmode",
 }
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gosuri/uiprogress"
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/store/ledgerstore"
+	"github.com/urfave/cli"
+)
+
+var ReindexEventCommand = cli.Command{
+	Name:      "reindexevent",
+	Usage:     "Rebuild the contract event index of DB",
+	ArgsUsage: "",
+	Action:    reindexEvents,
+	Flags: []cli.Flag{
+		utils.DataDirFlag,
+		utils.DBBackendFlag,
+		utils.ConfigFlag,
+		utils.NetworkIdFlag,
+	},
+	Description: "Note that the node should be stopped before reindex",
+}
+
+func reindexEvents(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+
+	_, err := SetOntologyConfig(ctx)
+	if err != nil {
+		PrintErrorMsg("SetOntologyConfig error:%s", err)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
+	eventStore, err := ledgerstore.NewEventStore(fmt.Sprintf("%s%s%s", dbDir, string(os.PathSeparator), ledgerstore.DBDirEvent))
+	if err != nil {
+		return fmt.Errorf("NewEventStore error:%s", err)
+	}
+	defer eventStore.Close()
+
+	_, endHeight, err := eventStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("GetCurrentBlock error:%s", err)
+	}
+
+	//progress bar
+	uiprogress.Start()
+	bar := uiprogress.AddBar(int(endHeight + 1)).
+		AppendCompleted().
+		AppendElapsed().
+		PrependFunc(func(b *uiprogress.Bar) string {
+			return fmt.Sprintf("Block(%d/%d)", b.Current(), int(endHeight))
+		})
+
+	PrintInfoMsg("Start reindex events.")
+	err = eventStore.ReindexEvents(endHeight, func(height uint32) {
+		bar.Incr()
+	})
+	uiprogress.Stop()
+	if err != nil {
+		return fmt.Errorf("ReindexEvents error:%s", err)
+	}
+	PrintInfoMsg("Reindex events successfully.")
+	PrintInfoMsg("EndBlockHeight:%d", endHeight)
+	return nil
+}
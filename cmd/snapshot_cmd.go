@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ontio/ontology/cmd/utils"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/store/ledgerstore"
+	"github.com/ontio/ontology/core/types"
+	"github.com/urfave/cli"
+)
+
+var SnapshotCommand = cli.Command{
+	Name:  "snapshot",
+	Usage: "Export or import the state snapshot for fast node bootstrap",
+	Subcommands: []cli.Command{
+		{
+			Action:    exportSnapshot,
+			Name:      "export",
+			Usage:     "Export the states at current block height, with the header chain to a file",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.DataDirFlag,
+				utils.DBBackendFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+			Description: "Note that the node should be stopped before export",
+		},
+		{
+			Action:    importSnapshot,
+			Name:      "import",
+			Usage:     "Restore an empty DB from a state snapshot file",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.SnapshotStateHashFlag,
+				utils.DataDirFlag,
+				utils.DBBackendFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+			Description: "The headers in snapshot are verified by their signatures, and the states must match the state hash " +
+				"got from trusted nodes. After import, the node syncs blocks from snapshot height + 1",
+		},
+	},
+}
+
+func openSnapshotLedger(ctx *cli.Context) (*ledgerstore.LedgerStoreImp, *types.Block, error) {
+	cfg, err := SetOntologyConfig(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SetOntologyConfig error:%s", err)
+	}
+	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
+	stateHashHeight := config.GetStateHashCheckHeight(cfg.P2PNode.NetworkId)
+	bookKeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, config.DefConfig.Genesis)
+	if err != nil {
+		return nil, nil, fmt.Errorf("BuildGenesisBlock error %s", err)
+	}
+	ledgerStore, err := ledgerstore.NewLedgerStore(dbDir, stateHashHeight)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NewLedgerStore error:%s", err)
+	}
+	return ledgerStore, genesisBlock, nil
+}
+
+func exportSnapshot(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+
+	ledgerStore, genesisBlock, err := openSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledgerStore.Close()
+	bookKeepers, _ := config.DefConfig.GetBookkeepers()
+	err = ledgerStore.InitLedgerStoreWithGenesisBlock(genesisBlock, bookKeepers)
+	if err != nil {
+		return fmt.Errorf("InitLedgerStoreWithGenesisBlock error:%s", err)
+	}
+
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	file, err := os.OpenFile(snapshotFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("OpenFile error:%s", err)
+	}
+	defer file.Close()
+
+	PrintInfoMsg("Start export snapshot.")
+	manifest, err := ledgerStore.ExportSnapshot(file)
+	if err != nil {
+		return fmt.Errorf("ExportSnapshot error:%s", err)
+	}
+	PrintInfoMsg("Export snapshot successfully.")
+	PrintInfoMsg("BlockHeight:%d", manifest.Height)
+	PrintInfoMsg("BlockHash:%s", manifest.BlockHash.ToHexString())
+	PrintInfoMsg("StateMerkleRoot:%s", manifest.StateMerkleRoot.ToHexString())
+	PrintInfoMsg("StateHash:%s", manifest.StateHash.ToHexString())
+	PrintInfoMsg("Export file:%s", snapshotFile)
+	return nil
+}
+
+func importSnapshot(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+
+	stateHash, err := common.Uint256FromHexString(ctx.String(utils.GetFlagName(utils.SnapshotStateHashFlag)))
+	if err != nil {
+		return fmt.Errorf("invalid snapshot state hash:%s", err)
+	}
+
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	file, err := os.OpenFile(snapshotFile, os.O_RDONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("OpenFile error:%s", err)
+	}
+	defer file.Close()
+
+	ledgerStore, genesisBlock, err := openSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledgerStore.Close()
+
+	PrintInfoMsg("Start import snapshot.")
+	manifest, err := ledgerStore.ImportSnapshot(file, genesisBlock, stateHash)
+	if err != nil {
+		return fmt.Errorf("ImportSnapshot error:%s", err)
+	}
+	PrintInfoMsg("Import snapshot successfully.")
+	PrintInfoMsg("BlockHeight:%d", manifest.Height)
+	PrintInfoMsg("BlockHash:%s", manifest.BlockHash.ToHexString())
+	PrintInfoMsg("StateMerkleRoot:%s", manifest.StateMerkleRoot.ToHexString())
+	return nil
+}
@@ -103,7 +103,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.LogDirFlag,
 			utils.DisableLogFileFlag,
 			utils.DisableEventLogFlag,
+			utils.EnableArchiveFlag,
 			utils.DataDirFlag,
+			utils.DBBackendFlag,
 			utils.ETHTxGasLimitFlag,
 			utils.WasmVerifyMethodFlag,
 		},
@@ -144,6 +146,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.GasPriceFlag,
 			utils.GasLimitFlag,
 			utils.TxpoolPreExecDisableFlag,
+			utils.TxPoolCapacityFlag,
+			utils.TxPoolPayerSlotsFlag,
+			utils.TxPoolPriceBumpFlag,
 			utils.DisableSyncVerifyTxFlag,
 			utils.DisableBroadcastNetTxFlag,
 		},
@@ -159,6 +164,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MaxConnInBoundFlag,
 			utils.MaxConnOutBoundFlag,
 			utils.MaxConnInBoundForSingleIPFlag,
+			utils.FastSyncFlag,
 		},
 	},
 	{
@@ -169,6 +175,13 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCLocalEnableFlag,
 			utils.RPCLocalProtFlag,
 			utils.ETHRPCPortFlag,
+			utils.ETHRPCDebugFlag,
+			utils.ETHRPCWsOriginsFlag,
+			utils.RPCMaxBatchSizeFlag,
+			utils.RPCRateLimitFlag,
+			utils.RPCRateBurstFlag,
+			utils.RPCMethodRateLimitFlag,
+			utils.RPCAdminTokenFlag,
 		},
 	},
 	{
@@ -185,6 +198,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.GraphQLEnableFlag,
 			utils.GraphQLPortFlag,
 			utils.GraphQLMaxConnsFlag,
+			utils.GraphQLOriginsFlag,
 		},
 	},
 	{
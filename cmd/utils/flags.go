@@ -29,6 +29,7 @@ import (
 
 const (
 	DEFAULT_EXPORT_FILE   = "./OntBlocks.dat"
+	DEFAULT_SNAPSHOT_FILE = "./OntSnapshot.dat"
 	DEFAULT_ABI_PATH      = "./abi"
 	DEFAULT_EXPORT_HEIGHT = 0
 	DEFAULT_WALLET_PATH   = "./wallet_data"
@@ -58,6 +59,10 @@ var (
 		Name:  "disable-event-log",
 		Usage: "Discard event log output by smart contract execution",
 	}
+	EnableArchiveFlag = cli.BoolFlag{
+		Name:  "enable-archive",
+		Usage: "Keep the history of ledger states to support state query at the block height after archive enabled",
+	}
 	WasmVerifyMethodFlag = cli.BoolFlag{
 		Name:  "enable-wasmjit-verifier",
 		Usage: "Enable wasmjit verifier to verify wasm contract",
@@ -82,6 +87,19 @@ var (
 		Usage: "Block data storage `<path>`",
 		Value: config.DEFAULT_DATA_DIR,
 	}
+	DBBackendFlag = cli.StringFlag{
+		Name:  "db-backend",
+		Usage: "Storage `<backend>` of block data (leveldb|pebble|memory), memory is only for test and ephemeral devnet",
+		Value: config.DEFAULT_DB_BACKEND,
+	}
+	TargetDataDirFlag = cli.StringFlag{
+		Name:  "target-data-dir",
+		Usage: "Block data storage `<path>` of migrate target",
+	}
+	TargetDBBackendFlag = cli.StringFlag{
+		Name:  "target-db-backend",
+		Usage: "Storage `<backend>` of migrate target (leveldb|pebble)",
+	}
 	ETHTxGasLimitFlag = cli.Uint64Flag{
 		Name:  "eth-tx-gaslimit",
 		Usage: "ETH block total gas limit",
@@ -158,6 +176,10 @@ var (
 		Usage: "Max connection `<number>` in bound for single ip",
 		Value: config.DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP,
 	}
+	FastSyncFlag = cli.BoolFlag{
+		Name:  "fast-sync",
+		Usage: "Sync block headers first, then download block ranges from the fastest peers in parallel",
+	}
 	// RPC settings
 	RPCDisabledFlag = cli.BoolFlag{
 		Name:  "disable-rpc",
@@ -173,6 +195,14 @@ var (
 		Usage: "Eth json rpc server listening port `<number>`",
 		Value: config.DEFAULT_ETH_RPC_PORT,
 	}
+	ETHRPCDebugFlag = cli.BoolFlag{
+		Name:  "ethrpc-debug",
+		Usage: "Enable the debug namespace of eth json rpc, such as debug_traceTransaction",
+	}
+	ETHRPCWsOriginsFlag = cli.StringFlag{
+		Name:  "ethrpc-ws-origins",
+		Usage: "Comma separated cross origins allowed to open eth json rpc websocket subscriptions, \"*\" allows any `<origins>`",
+	}
 	RPCLocalEnableFlag = cli.BoolFlag{
 		Name:  "localrpc",
 		Usage: "Enable local rpc server",
@@ -182,6 +212,27 @@ var (
 		Usage: "Json rpc local server listening port `<number>`",
 		Value: config.DEFAULT_RPC_LOCAL_PORT,
 	}
+	RPCMaxBatchSizeFlag = cli.UintFlag{
+		Name:  "rpc-max-batch",
+		Usage: "Max number of requests in a json rpc batch, 0 means no limit `<number>`",
+		Value: config.DEFAULT_RPC_MAX_BATCH_SIZE,
+	}
+	RPCRateLimitFlag = cli.UintFlag{
+		Name:  "rpc-rate-limit",
+		Usage: "Max json rpc requests per second of a client ip, 0 means no limit `<number>`",
+	}
+	RPCRateBurstFlag = cli.UintFlag{
+		Name:  "rpc-rate-burst",
+		Usage: "Max burst json rpc requests of a client ip, no less than rpc-rate-limit `<number>`",
+	}
+	RPCAdminTokenFlag = cli.StringFlag{
+		Name:  "rpc-admin-token",
+		Usage: "Token to authenticate the local admin apis such as dropmempooltx, the apis are disabled if not set `<token>`",
+	}
+	RPCMethodRateLimitFlag = cli.StringFlag{
+		Name:  "rpc-method-rate-limit",
+		Usage: "Max calls per second of json rpc methods by a client ip, such as getblocktxsbyheight=5,getblock=50 `<method=number,...>`",
+	}
 
 	//Websocket setting
 	WsEnabledFlag = cli.BoolFlag{
@@ -225,6 +276,10 @@ var (
 		Usage: "GraphQL server maximum connections `<number>`",
 		Value: config.DEFAULT_HTTP_MAX_CONN,
 	}
+	GraphQLOriginsFlag = cli.StringFlag{
+		Name:  "graphql-origins",
+		Usage: "Comma separated cross origins allowed to open GraphQL websocket subscriptions, \"*\" allows any `<origins>`",
+	}
 
 	//Account setting
 	AccountPassFlag = cli.StringFlag{
@@ -487,6 +542,15 @@ var (
 		Usage: "Export `<file>` path",
 		Value: DEFAULT_EXPORT_FILE,
 	}
+	SnapshotFileFlag = cli.StringFlag{
+		Name:  "snapshot-file",
+		Usage: "Path of state snapshot `<file>`",
+		Value: DEFAULT_SNAPSHOT_FILE,
+	}
+	SnapshotStateHashFlag = cli.StringFlag{
+		Name:  "snapshot-state-hash",
+		Usage: "Hash of the states at snapshot height got from trusted nodes, which is printed by snapshot export `<hash>`",
+	}
 	ExportStartHeightFlag = cli.UintFlag{
 		Name:  "start-height",
 		Usage: "Start block height `<number>` to export",
@@ -509,6 +573,22 @@ var (
 		Usage: "Disable preExecute in tx pool",
 	}
 
+	TxPoolCapacityFlag = cli.UintFlag{
+		Name:  "tx-pool-capacity",
+		Usage: "Max number `<number>` of verified transactions in tx pool, the ones with lowest gas price are evicted when full",
+		Value: config.DEFAULT_TX_POOL_CAPACITY,
+	}
+	TxPoolPayerSlotsFlag = cli.UintFlag{
+		Name:  "tx-pool-payer-slots",
+		Usage: "Max number `<number>` of transactions of a single payer in tx pool",
+		Value: config.DEFAULT_TX_POOL_PAYER_SLOTS,
+	}
+	TxPoolPriceBumpFlag = cli.UintFlag{
+		Name:  "tx-pool-price-bump",
+		Usage: "Min gas price bump `<percent>` to replace a transaction with the same payer and nonce",
+		Value: config.DEFAULT_TX_POOL_PRICE_BUMP,
+	}
+
 	//local PreExecute switcher
 	DisableSyncVerifyTxFlag = cli.BoolFlag{
 		Name:  "disable-sync-verify-tx",
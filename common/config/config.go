@@ -51,16 +51,19 @@ const (
 	DBFT_MIN_NODE_NUM        = 4 //min node number of dbft consensus
 	SOLO_MIN_NODE_NUM        = 1 //min node number of solo consensus
 	VBFT_MIN_NODE_NUM        = 4 //min node number of vbft consensus
+	SBFT_MIN_NODE_NUM        = 4 //min node number of sbft consensus
 
 	CONSENSUS_TYPE_DBFT = "dbft"
 	CONSENSUS_TYPE_SOLO = "solo"
 	CONSENSUS_TYPE_VBFT = "vbft"
+	CONSENSUS_TYPE_SBFT = "sbft"
 
 	DEFAULT_LOG_LEVEL                       = log.InfoLog
 	DEFAULT_ETH_RPC_PORT                    = 20339
 	DEFAULT_NODE_PORT                       = 20338
 	DEFAULT_RPC_PORT                        = 20336
 	DEFAULT_RPC_LOCAL_PORT                  = 20337
+	DEFAULT_RPC_MAX_BATCH_SIZE              = 100
 	DEFAULT_GRAPHQL_PORT                    = 20333
 	DEFAULT_REST_PORT                       = 20334
 	DEFAULT_WS_PORT                         = 20335
@@ -76,11 +79,15 @@ const (
 	DEFUALT_CLI_RPC_ADDRESS                 = "127.0.0.1"
 	DEFAULT_MIN_GAS_LIMIT                   = 20000
 	DEFAULT_GAS_PRICE                       = 500
+	DEFAULT_TX_POOL_CAPACITY                = 100140
+	DEFAULT_TX_POOL_PAYER_SLOTS             = 4096
+	DEFAULT_TX_POOL_PRICE_BUMP              = 1
 	DEFAULT_WASM_GAS_FACTOR                 = uint64(10)
 	DEFAULT_WASM_MAX_STEPCOUNT              = uint64(8000000)
 
 	DEFAULT_DATA_DIR      = "./Chain/"
 	DEFAULT_RESERVED_FILE = "./peers.rsv"
+	DEFAULT_DB_BACKEND    = "leveldb"
 
 	//DEFAULT_ETH_BLOCK_GAS_LIMIT = 800000000
 	DEFAULT_ETH_TX_MAX_GAS_LIMIT = 6000000
@@ -208,6 +215,72 @@ func GetNewOntIdHeight() uint32 {
 	}
 }
 
+func GetOntIdProofHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_ONTID_PROOF_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_ONTID_PROOF_POLARIS
+	default:
+		return 0
+	}
+}
+
+func GetCredentialHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_CREDENTIAL_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_CREDENTIAL_POLARIS
+	default:
+		return 0
+	}
+}
+
+func GetAuthScopeHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_AUTH_SCOPE_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_AUTH_SCOPE_POLARIS
+	default:
+		return 0
+	}
+}
+
+func GetLockProxyGuardHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_LOCK_PROXY_GUARD_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_LOCK_PROXY_GUARD_POLARIS
+	default:
+		return 0
+	}
+}
+
+func GetOntFsErasureHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_ONTFS_ERASURE_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_ONTFS_ERASURE_POLARIS
+	default:
+		return 0
+	}
+}
+
+func GetEquivocationHeight() uint32 {
+	switch DefConfig.P2PNode.NetworkId {
+	case NETWORK_ID_MAIN_NET:
+		return constants.BLOCKHEIGHT_EQUIVOCATION_MAINNET
+	case NETWORK_ID_POLARIS_NET:
+		return constants.BLOCKHEIGHT_EQUIVOCATION_POLARIS
+	default:
+		return 0
+	}
+}
+
 func GetCrossChainHeight() uint32 {
 	switch DefConfig.P2PNode.NetworkId {
 	case NETWORK_ID_POLARIS_NET:
@@ -351,6 +424,7 @@ var PolarisConfig = &GenesisConfig{
 	},
 	DBFT: &DBFTConfig{},
 	SOLO: &SOLOConfig{},
+	SBFT: &SBFTConfig{},
 }
 
 var MainNetConfig = &GenesisConfig{
@@ -414,6 +488,7 @@ var MainNetConfig = &GenesisConfig{
 	},
 	DBFT: &DBFTConfig{},
 	SOLO: &SOLOConfig{},
+	SBFT: &SBFTConfig{},
 }
 
 var DefConfig = NewOntologyConfig()
@@ -424,6 +499,7 @@ type GenesisConfig struct {
 	VBFT          *VBFTConfig
 	DBFT          *DBFTConfig
 	SOLO          *SOLOConfig
+	SBFT          *SBFTConfig
 }
 
 func NewGenesisConfig() *GenesisConfig {
@@ -433,6 +509,7 @@ func NewGenesisConfig() *GenesisConfig {
 		VBFT:          &VBFTConfig{},
 		DBFT:          &DBFTConfig{},
 		SOLO:          &SOLOConfig{},
+		SBFT:          &SBFTConfig{},
 	}
 }
 
@@ -626,15 +703,27 @@ type SOLOConfig struct {
 	Bookkeepers  []string
 }
 
+//SBFT genesis config, GenBlockTime is the min interval in second between the blocks
+type SBFTConfig struct {
+	GenBlockTime uint
+	Bookkeepers  []string
+}
+
 type CommonConfig struct {
 	LogLevel       uint
 	NodeType       string
 	EnableEventLog bool
+	EnableArchive  bool
 	SystemFee      map[string]int64
 	MinGasLimit    uint64
 	GasPrice       uint64
 	DataDir        string
+	DBBackend      string
 	ETHTxGasLimit  uint64
+	//tx pool limits
+	TxPoolCapacity   uint
+	TxPoolPayerSlots uint
+	TxPoolPriceBump  uint
 	//NGasLimit        uint64
 	WasmVerifyMethod VerifyMethod
 }
@@ -666,6 +755,7 @@ type P2PNodeConfig struct {
 	MaxConnOutBound           uint
 	MaxConnInBoundForSingleIP uint
 	EVMChainId                uint32
+	FastSync                  bool //download validated block ranges from the fastest peers in parallel
 }
 
 type RpcConfig struct {
@@ -673,6 +763,13 @@ type RpcConfig struct {
 	HttpJsonPort      uint
 	HttpLocalPort     uint
 	EthJsonPort       uint
+	EnableEthDebug    bool     //serve the debug namespace of eth json rpc, it re-executes transactions
+	EthWsOrigins      []string //cross origins allowed to open eth json rpc websocket, "*" allows any
+	MaxBatchSize      uint
+	RateLimit         uint            //requests per second of a client ip, 0 means no limit
+	RateBurst         uint            //max burst requests of a client ip
+	MethodRateLimit   map[string]uint //calls per second of a method by a client ip
+	AdminToken        string          //token of the local admin apis, they are disabled if it is empty
 }
 
 type RestfulConfig struct {
@@ -687,6 +784,7 @@ type GraphQLConfig struct {
 	EnableGraphQL  bool
 	GraphQLPort    uint
 	MaxConnections uint
+	AllowedOrigins []string //cross origins allowed to open websocket subscriptions, "*" allows any
 }
 
 type WebSocketConfig struct {
@@ -716,8 +814,12 @@ func NewOntologyConfig() *OntologyConfig {
 			SystemFee:        make(map[string]int64),
 			MinGasLimit:      DEFAULT_MIN_GAS_LIMIT,
 			DataDir:          DEFAULT_DATA_DIR,
+			DBBackend:        DEFAULT_DB_BACKEND,
 			WasmVerifyMethod: InterpVerifyMethod,
 			ETHTxGasLimit:    DEFAULT_ETH_TX_MAX_GAS_LIMIT,
+			TxPoolCapacity:   DEFAULT_TX_POOL_CAPACITY,
+			TxPoolPayerSlots: DEFAULT_TX_POOL_PAYER_SLOTS,
+			TxPoolPriceBump:  DEFAULT_TX_POOL_PRICE_BUMP,
 		},
 		Consensus: &ConsensusConfig{
 			EnableConsensus: true,
@@ -745,6 +847,7 @@ func NewOntologyConfig() *OntologyConfig {
 			EnableHttpJsonRpc: true,
 			HttpJsonPort:      DEFAULT_RPC_PORT,
 			HttpLocalPort:     DEFAULT_RPC_LOCAL_PORT,
+			MaxBatchSize:      DEFAULT_RPC_MAX_BATCH_SIZE,
 		},
 		Restful: &RestfulConfig{
 			EnableHttpRestful: true,
@@ -772,6 +875,8 @@ func (this *OntologyConfig) GetBookkeepers() ([]keypair.PublicKey, error) {
 		bookKeepers = this.Genesis.DBFT.Bookkeepers
 	case CONSENSUS_TYPE_SOLO:
 		bookKeepers = this.Genesis.SOLO.Bookkeepers
+	case CONSENSUS_TYPE_SBFT:
+		bookKeepers = this.Genesis.SBFT.Bookkeepers
 	default:
 		return nil, fmt.Errorf("Does not support %s consensus", this.Genesis.ConsensusType)
 	}
@@ -822,6 +927,8 @@ func (this *OntologyConfig) getDefNetworkIDFromGenesisConfig(genCfg *GenesisConf
 		configData, err = json.Marshal(genCfg.VBFT)
 	case CONSENSUS_TYPE_DBFT:
 		configData, err = json.Marshal(genCfg.DBFT)
+	case CONSENSUS_TYPE_SBFT:
+		configData, err = json.Marshal(genCfg.SBFT)
 	case CONSENSUS_TYPE_SOLO:
 		return NETWORK_ID_SOLO_NET, nil
 	default:
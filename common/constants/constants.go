@@ -92,6 +92,30 @@ const BLOCKHEIGHT_SELFGOV_REGISTER_POLARIS = 12150000
 const BLOCKHEIGHT_NEW_ONTID_MAINNET = 9000000
 const BLOCKHEIGHT_NEW_ONTID_POLARIS = 12150000
 
+//ONT ID document proof height, not scheduled yet
+const BLOCKHEIGHT_ONTID_PROOF_MAINNET = 0xFFFFFFFF
+const BLOCKHEIGHT_ONTID_PROOF_POLARIS = 0xFFFFFFFF
+
+//credential registry contract height, not scheduled yet
+const BLOCKHEIGHT_CREDENTIAL_MAINNET = 0xFFFFFFFF
+const BLOCKHEIGHT_CREDENTIAL_POLARIS = 0xFFFFFFFF
+
+//auth contract scoped delegation height, not scheduled yet
+const BLOCKHEIGHT_AUTH_SCOPE_MAINNET = 0xFFFFFFFF
+const BLOCKHEIGHT_AUTH_SCOPE_POLARIS = 0xFFFFFFFF
+
+//lock proxy daily limit and pausing height, not scheduled yet
+const BLOCKHEIGHT_LOCK_PROXY_GUARD_MAINNET = 0xFFFFFFFF
+const BLOCKHEIGHT_LOCK_PROXY_GUARD_POLARIS = 0xFFFFFFFF
+
+//ontfs erasure coded storage height, not scheduled yet
+const BLOCKHEIGHT_ONTFS_ERASURE_MAINNET = 0xFFFFFFFF
+const BLOCKHEIGHT_ONTFS_ERASURE_POLARIS = 0xFFFFFFFF
+
+//vbft equivocation report height, not scheduled yet
+const BLOCKHEIGHT_EQUIVOCATION_MAINNET = 0xFFFFFFFF
+const BLOCKHEIGHT_EQUIVOCATION_POLARIS = 0xFFFFFFFF
+
 const BLOCKHEIGHT_ONTFS_MAINNET = 8550000
 const BLOCKHEIGHT_ONTFS_POLARIS = 12250000
 
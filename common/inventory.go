@@ -23,5 +23,6 @@ type InventoryType byte
 const (
 	TRANSACTION InventoryType = 0x01
 	BLOCK       InventoryType = 0x02
+	CMPCT_BLOCK InventoryType = 0x03
 	CONSENSUS   InventoryType = 0xe0
 )
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+)
+
+//blockNode is an uncommitted block proposed by the leader
+type blockNode struct {
+	block   *types.Block
+	justify *QuorumCert // QC of the parent block carried by the proposal
+	qc      *QuorumCert // QC of the block, nil before the block is certified
+}
+
+func (node *blockNode) hash() common.Uint256 {
+	return node.block.Hash()
+}
+
+func (node *blockNode) view() uint64 {
+	return node.block.Header.ConsensusData
+}
+
+//blockTree holds the uncommitted blocks extending the committed head of the ledger
+type blockTree struct {
+	root     *types.Header
+	rootHash common.Uint256
+	nodes    map[common.Uint256]*blockNode
+}
+
+func newBlockTree(root *types.Header) *blockTree {
+	return &blockTree{
+		root:     root,
+		rootHash: root.Hash(),
+		nodes:    make(map[common.Uint256]*blockNode),
+	}
+}
+
+//rootView returns the view in which the committed head was proposed, the genesis block is of view 0
+func (tree *blockTree) rootView() uint64 {
+	if tree.root.Height == 0 {
+		return 0
+	}
+	return tree.root.ConsensusData
+}
+
+//rootQC returns the QC of the committed head, which is trusted without signatures
+func (tree *blockTree) rootQC() *QuorumCert {
+	return &QuorumCert{
+		ViewNum:   tree.rootView(),
+		Height:    tree.root.Height,
+		BlockHash: tree.rootHash,
+	}
+}
+
+func (tree *blockTree) getNode(hash common.Uint256) *blockNode {
+	return tree.nodes[hash]
+}
+
+//header returns the header of the committed head or an uncommitted block
+func (tree *blockTree) header(hash common.Uint256) *types.Header {
+	if hash == tree.rootHash {
+		return tree.root
+	}
+	if node := tree.nodes[hash]; node != nil {
+		return node.block.Header
+	}
+	return nil
+}
+
+//contains checks whether the block is the committed head or an uncommitted block
+func (tree *blockTree) contains(hash common.Uint256) bool {
+	return tree.header(hash) != nil
+}
+
+//insert adds the block whose parent is in the tree, the parent is certified by the justify of the block
+func (tree *blockTree) insert(node *blockNode) {
+	tree.nodes[node.hash()] = node
+	if parent := tree.nodes[node.block.Header.PrevBlockHash]; parent != nil && parent.qc == nil {
+		parent.qc = node.justify
+	}
+}
+
+//branch returns the uncommitted blocks from the child of the committed head to the block,
+//false is returned if the block does not extend the committed head.
+func (tree *blockTree) branch(hash common.Uint256) ([]*blockNode, bool) {
+	var nodes []*blockNode
+	for hash != tree.rootHash {
+		node := tree.nodes[hash]
+		if node == nil {
+			return nil, false
+		}
+		nodes = append(nodes, node)
+		hash = node.block.Header.PrevBlockHash
+	}
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	return nodes, true
+}
+
+//commitCandidate returns the block to be committed when the block is certified, which is the
+//head of a three-chain with consecutive views ending at the certified block.
+func (tree *blockTree) commitCandidate(certified *blockNode) *blockNode {
+	b1 := tree.nodes[certified.block.Header.PrevBlockHash]
+	if b1 == nil || b1.view()+1 != certified.view() {
+		return nil
+	}
+	b0 := tree.nodes[b1.block.Header.PrevBlockHash]
+	if b0 == nil || b0.view()+1 != b1.view() {
+		return nil
+	}
+	return b0
+}
+
+//prune moves the committed head to the root, and drops the blocks not extending it
+func (tree *blockTree) prune(root *types.Header) {
+	tree.root = root
+	tree.rootHash = root.Hash()
+	var dropped []common.Uint256
+	for hash, node := range tree.nodes {
+		if node.block.Header.Height <= root.Height {
+			dropped = append(dropped, hash)
+		}
+	}
+	for _, hash := range dropped {
+		delete(tree.nodes, hash)
+	}
+	dropped = dropped[:0]
+	for hash := range tree.nodes {
+		if _, ok := tree.branch(hash); !ok {
+			dropped = append(dropped, hash)
+		}
+	}
+	for _, hash := range dropped {
+		delete(tree.nodes, hash)
+	}
+}
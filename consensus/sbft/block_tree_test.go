@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockTreeCommitRule(t *testing.T) {
+	accs, _ := newTestAccounts(4)
+	genesis := newTestBlock(common.Uint256{}, 0, 0)
+	tree := newBlockTree(genesis.Header)
+	assert.Equal(t, uint64(0), tree.rootView())
+
+	insert := func(parent *blockNode, view uint64) *blockNode {
+		justify := tree.rootQC()
+		prevHash, height := tree.rootHash, tree.root.Height+1
+		if parent != nil {
+			justify = newTestQC(accs, parent.block)
+			prevHash, height = parent.hash(), parent.block.Header.Height+1
+		}
+		node := &blockNode{block: newTestBlock(prevHash, height, view), justify: justify}
+		tree.insert(node)
+		return node
+	}
+
+	b1 := insert(nil, 1)
+	b2 := insert(b1, 2)
+	b3 := insert(b2, 4) // view 3 timed out
+	assert.NotNil(t, b2.qc)
+	assert.Nil(t, tree.commitCandidate(b3))
+	b4 := insert(b3, 5)
+	b5 := insert(b4, 6)
+	assert.Equal(t, b3, tree.commitCandidate(b5))
+
+	fork := insert(b2, 3)
+	branch, ok := tree.branch(b5.hash())
+	assert.True(t, ok)
+	assert.Equal(t, []*blockNode{b1, b2, b3, b4, b5}, branch)
+
+	tree.prune(b3.block.Header)
+	assert.False(t, tree.contains(b2.hash()))
+	assert.False(t, tree.contains(fork.hash()))
+	assert.True(t, tree.contains(b3.hash()))
+	assert.Equal(t, uint64(4), tree.rootView())
+	branch, ok = tree.branch(b5.hash())
+	assert.True(t, ok)
+	assert.Equal(t, []*blockNode{b4, b5}, branch)
+}
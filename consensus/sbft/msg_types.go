@@ -0,0 +1,264 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/types"
+)
+
+type MsgType uint8
+
+const (
+	ProposalMessage MsgType = iota + 1
+	VoteMessage
+	NewViewMessage
+	BlockFetchMessage
+	BlockFetchRespMessage
+)
+
+const MAX_BOOKKEEPERS = 1024
+
+type ConsensusMsg interface {
+	Type() MsgType
+	Serialization(sink *common.ZeroCopySink)
+	Deserialization(source *common.ZeroCopySource) error
+}
+
+//proposalMsg is broadcasted by the leader of the view, the block extends the block certified
+//by Justify. Timeout is the proof of entering the view when Justify is not of the last view.
+type proposalMsg struct {
+	Block   *types.Block
+	Justify *QuorumCert
+	Timeout *TimeoutCert
+}
+
+func (msg *proposalMsg) Type() MsgType {
+	return ProposalMessage
+}
+
+//ViewNum returns the view of the proposal, which is stored in the header consensus data
+func (msg *proposalMsg) ViewNum() uint64 {
+	return msg.Block.Header.ConsensusData
+}
+
+func (msg *proposalMsg) Serialization(sink *common.ZeroCopySink) {
+	msg.Block.Serialization(sink)
+	msg.Justify.Serialization(sink)
+	sink.WriteBool(msg.Timeout != nil)
+	if msg.Timeout != nil {
+		msg.Timeout.Serialization(sink)
+	}
+}
+
+func (msg *proposalMsg) Deserialization(source *common.ZeroCopySource) error {
+	msg.Block = &types.Block{}
+	if err := msg.Block.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize block: %s", err)
+	}
+	msg.Justify = &QuorumCert{}
+	if err := msg.Justify.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize justify: %s", err)
+	}
+	hasTimeout, irregular, eof := source.NextBool()
+	if irregular {
+		return common.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if hasTimeout {
+		msg.Timeout = &TimeoutCert{}
+		if err := msg.Timeout.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize timeout: %s", err)
+		}
+	}
+	return nil
+}
+
+//voteMsg is sent to the leader of the next view, Sig is the signature of the block hash
+type voteMsg struct {
+	ViewNum   uint64
+	Height    uint32
+	BlockHash common.Uint256
+	Signer    uint16
+	Sig       []byte
+}
+
+func (msg *voteMsg) Type() MsgType {
+	return VoteMessage
+}
+
+func (msg *voteMsg) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(msg.ViewNum)
+	sink.WriteUint32(msg.Height)
+	sink.WriteHash(msg.BlockHash)
+	sink.WriteUint16(msg.Signer)
+	sink.WriteVarBytes(msg.Sig)
+}
+
+func (msg *voteMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof, irregular bool
+	if msg.ViewNum, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.Height, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.BlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.Signer, eof = source.NextUint16(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	msg.Sig, _, irregular, eof = source.NextVarBytes()
+	if irregular {
+		return common.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//newViewMsg is sent to the leader of the view when the last view timed out,
+//it carries the highest QC of the sender so that the leader can extend it.
+type newViewMsg struct {
+	ViewNum uint64
+	HighQC  *QuorumCert
+	Signer  uint16
+	Sig     []byte
+}
+
+func (msg *newViewMsg) Type() MsgType {
+	return NewViewMessage
+}
+
+func (msg *newViewMsg) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(msg.ViewNum)
+	msg.HighQC.Serialization(sink)
+	sink.WriteUint16(msg.Signer)
+	sink.WriteVarBytes(msg.Sig)
+}
+
+func (msg *newViewMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof, irregular bool
+	if msg.ViewNum, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	msg.HighQC = &QuorumCert{}
+	if err := msg.HighQC.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize high qc: %s", err)
+	}
+	if msg.Signer, eof = source.NextUint16(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	msg.Sig, _, irregular, eof = source.NextVarBytes()
+	if irregular {
+		return common.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//blockFetchMsg requests the uncommitted block certified by a QC but missed by the sender
+type blockFetchMsg struct {
+	BlockHash common.Uint256
+}
+
+func (msg *blockFetchMsg) Type() MsgType {
+	return BlockFetchMessage
+}
+
+func (msg *blockFetchMsg) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteHash(msg.BlockHash)
+}
+
+func (msg *blockFetchMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.BlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+type blockFetchRespMsg struct {
+	Block   *types.Block
+	Justify *QuorumCert
+}
+
+func (msg *blockFetchRespMsg) Type() MsgType {
+	return BlockFetchRespMessage
+}
+
+func (msg *blockFetchRespMsg) Serialization(sink *common.ZeroCopySink) {
+	msg.Block.Serialization(sink)
+	msg.Justify.Serialization(sink)
+}
+
+func (msg *blockFetchRespMsg) Deserialization(source *common.ZeroCopySource) error {
+	msg.Block = &types.Block{}
+	if err := msg.Block.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize block: %s", err)
+	}
+	msg.Justify = &QuorumCert{}
+	if err := msg.Justify.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize justify: %s", err)
+	}
+	return nil
+}
+
+func SerializeSbftMsg(msg ConsensusMsg) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteByte(byte(msg.Type()))
+	msg.Serialization(sink)
+	return sink.Bytes()
+}
+
+func DeserializeSbftMsg(data []byte) (ConsensusMsg, error) {
+	source := common.NewZeroCopySource(data)
+	msgType, eof := source.NextByte()
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var msg ConsensusMsg
+	switch MsgType(msgType) {
+	case ProposalMessage:
+		msg = &proposalMsg{}
+	case VoteMessage:
+		msg = &voteMsg{}
+	case NewViewMessage:
+		msg = &newViewMsg{}
+	case BlockFetchMessage:
+		msg = &blockFetchMsg{}
+	case BlockFetchRespMessage:
+		msg = &blockFetchRespMsg{}
+	default:
+		return nil, fmt.Errorf("unknown sbft msg type: %d", msgType)
+	}
+	if err := msg.Deserialization(source); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
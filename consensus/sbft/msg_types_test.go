@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBlock(prev common.Uint256, height uint32, view uint64) *types.Block {
+	return &types.Block{
+		Header: &types.Header{
+			PrevBlockHash:    prev,
+			TransactionsRoot: common.ComputeMerkleRoot(nil),
+			Timestamp:        uint32(time.Now().Unix()),
+			Height:           height,
+			ConsensusData:    view,
+		},
+	}
+}
+
+func newTestQC(accs []*account.Account, block *types.Block) *QuorumCert {
+	hash := block.Hash()
+	qc := &QuorumCert{ViewNum: block.Header.ConsensusData, Height: block.Header.Height, BlockHash: hash}
+	for i := 0; i < quorumSize(len(accs)); i++ {
+		sig, _ := signature.Sign(accs[i], hash[:])
+		qc.Signers = append(qc.Signers, uint16(i))
+		qc.Sigs = append(qc.Sigs, sig)
+	}
+	return qc
+}
+
+func TestSbftMsgSerialization(t *testing.T) {
+	acc := account.NewAccount("")
+	parent := newTestBlock(common.Uint256{}, 1, 1)
+	block := newTestBlock(parent.Hash(), 2, 3)
+	justify := newTestQC([]*account.Account{acc}, parent)
+	tc := &TimeoutCert{ViewNum: 3, Signers: []uint16{0}, Sigs: [][]byte{{1, 2, 3}}}
+
+	msgs := []ConsensusMsg{
+		&proposalMsg{Block: block, Justify: justify, Timeout: tc},
+		&proposalMsg{Block: block, Justify: justify},
+		&voteMsg{ViewNum: 3, Height: 2, BlockHash: block.Hash(), Signer: 0, Sig: []byte{4, 5}},
+		&newViewMsg{ViewNum: 4, HighQC: justify, Signer: 0, Sig: []byte{6}},
+		&blockFetchMsg{BlockHash: block.Hash()},
+		&blockFetchRespMsg{Block: block, Justify: justify},
+	}
+	for _, msg := range msgs {
+		data := SerializeSbftMsg(msg)
+		decoded, err := DeserializeSbftMsg(data)
+		assert.Nil(t, err)
+		assert.Equal(t, msg.Type(), decoded.Type())
+		assert.Equal(t, data, SerializeSbftMsg(decoded))
+
+		_, err = DeserializeSbftMsg(data[:len(data)-1])
+		assert.NotNil(t, err)
+	}
+
+	decoded, _ := DeserializeSbftMsg(SerializeSbftMsg(msgs[1]))
+	assert.Nil(t, decoded.(*proposalMsg).Timeout)
+	assert.Equal(t, uint64(3), decoded.(*proposalMsg).ViewNum())
+
+	_, err := DeserializeSbftMsg([]byte{0xff})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import "time"
+
+const (
+	VIEW_TIMEOUT_DELTA  = 4 * time.Second // the view timeout besides the block interval
+	MAX_TIMEOUT_BACKOFF = 6               // the view timeout is doubled on each consecutive timeout up to 2^6 times
+)
+
+//pacemaker drives the views of the consensus, the view is advanced when a QC or TC of
+//the last view is observed, or the view times out locally.
+type pacemaker struct {
+	curView     uint64
+	timeouts    uint
+	baseTimeout time.Duration
+	timer       *time.Timer
+}
+
+func newPacemaker(view uint64, baseTimeout time.Duration) *pacemaker {
+	return &pacemaker{
+		curView:     view,
+		baseTimeout: baseTimeout,
+		timer:       time.NewTimer(baseTimeout),
+	}
+}
+
+func (p *pacemaker) viewTimeout() time.Duration {
+	backoff := p.timeouts
+	if backoff > MAX_TIMEOUT_BACKOFF {
+		backoff = MAX_TIMEOUT_BACKOFF
+	}
+	return p.baseTimeout << backoff
+}
+
+//advance enters the view if it is higher than the current one, and restarts the view timer
+func (p *pacemaker) advance(view uint64) bool {
+	if view <= p.curView {
+		return false
+	}
+	p.curView = view
+	resetTimer(p.timer, p.viewTimeout())
+	return true
+}
+
+//timeout enters the next view with a doubled timeout
+func (p *pacemaker) timeout() uint64 {
+	p.timeouts++
+	p.advance(p.curView + 1)
+	return p.curView
+}
+
+//progress resets the backoff of view timeout when a QC is formed
+func (p *pacemaker) progress() {
+	p.timeouts = 0
+}
+
+func (p *pacemaker) stop() {
+	p.timer.Stop()
+}
+
+//leaderOf returns the index of the leader of the view, the bookkeepers take turns to be the leader
+func leaderOf(view uint64, n int) uint16 {
+	return uint16(view % uint64(n))
+}
+
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
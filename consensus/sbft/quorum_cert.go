@@ -0,0 +1,263 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+)
+
+//QuorumCert is the aggregated votes of a quorum of the bookkeepers on a block, the
+//signatures are made on the block hash, so that they are filled into the header
+//SigData when the block is committed.
+type QuorumCert struct {
+	ViewNum   uint64
+	Height    uint32
+	BlockHash common.Uint256
+	Signers   []uint16
+	Sigs      [][]byte
+}
+
+func (qc *QuorumCert) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(qc.ViewNum)
+	sink.WriteUint32(qc.Height)
+	sink.WriteHash(qc.BlockHash)
+	serializeSigs(sink, qc.Signers, qc.Sigs)
+}
+
+func (qc *QuorumCert) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if qc.ViewNum, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if qc.Height, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if qc.BlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	var err error
+	qc.Signers, qc.Sigs, err = deserializeSigs(source)
+	return err
+}
+
+//Verify checks the signatures of the bookkeepers reach the quorum
+func (qc *QuorumCert) Verify(bookkeepers []keypair.PublicKey) error {
+	return verifyQuorumSigs(qc.BlockHash[:], bookkeepers, qc.Signers, qc.Sigs)
+}
+
+//TimeoutCert is the aggregated new view messages of a quorum of the bookkeepers,
+//it proves that the views before ViewNum have been timed out.
+type TimeoutCert struct {
+	ViewNum uint64
+	Signers []uint16
+	Sigs    [][]byte
+}
+
+func (tc *TimeoutCert) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(tc.ViewNum)
+	serializeSigs(sink, tc.Signers, tc.Sigs)
+}
+
+func (tc *TimeoutCert) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if tc.ViewNum, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	var err error
+	tc.Signers, tc.Sigs, err = deserializeSigs(source)
+	return err
+}
+
+//Verify checks the signatures of the bookkeepers reach the quorum
+func (tc *TimeoutCert) Verify(bookkeepers []keypair.PublicKey) error {
+	digest := newViewDigest(tc.ViewNum)
+	return verifyQuorumSigs(digest[:], bookkeepers, tc.Signers, tc.Sigs)
+}
+
+//newViewDigest returns the data signed by the bookkeeper entering the view on timeout
+func newViewDigest(view uint64) common.Uint256 {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteString("sbft-new-view")
+	sink.WriteUint64(view)
+	return sha256.Sum256(sink.Bytes())
+}
+
+//quorumSize returns the number of signatures to tolerate (n-1)/3 faulty bookkeepers,
+//it is the same with the multi-signature threshold of the bookkeepers address.
+func quorumSize(n int) int {
+	return n - (n-1)/3
+}
+
+func verifyQuorumSigs(data []byte, bookkeepers []keypair.PublicKey, signers []uint16, sigs [][]byte) error {
+	if len(signers) != len(sigs) {
+		return fmt.Errorf("signers %d not match signatures %d", len(signers), len(sigs))
+	}
+	if len(signers) < quorumSize(len(bookkeepers)) {
+		return fmt.Errorf("not enough signatures: %d, quorum: %d", len(signers), quorumSize(len(bookkeepers)))
+	}
+	signed := make(map[uint16]bool, len(signers))
+	for i, signer := range signers {
+		if int(signer) >= len(bookkeepers) {
+			return fmt.Errorf("invalid signer index %d", signer)
+		}
+		if signed[signer] {
+			return fmt.Errorf("duplicated signer %d", signer)
+		}
+		signed[signer] = true
+		if err := signature.Verify(bookkeepers[signer], data, sigs[i]); err != nil {
+			return fmt.Errorf("invalid signature of signer %d: %s", signer, err)
+		}
+	}
+	return nil
+}
+
+func serializeSigs(sink *common.ZeroCopySink, signers []uint16, sigs [][]byte) {
+	sink.WriteVarUint(uint64(len(signers)))
+	for i, signer := range signers {
+		sink.WriteUint16(signer)
+		sink.WriteVarBytes(sigs[i])
+	}
+}
+
+func deserializeSigs(source *common.ZeroCopySource) ([]uint16, [][]byte, error) {
+	n, _, irregular, eof := source.NextVarUint()
+	if irregular {
+		return nil, nil, common.ErrIrregularData
+	}
+	if eof {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	if n > MAX_BOOKKEEPERS {
+		return nil, nil, fmt.Errorf("too many signatures: %d", n)
+	}
+	signers := make([]uint16, 0, n)
+	sigs := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		signer, eof := source.NextUint16()
+		if eof {
+			return nil, nil, io.ErrUnexpectedEOF
+		}
+		sig, _, irregular, eof := source.NextVarBytes()
+		if irregular {
+			return nil, nil, common.ErrIrregularData
+		}
+		if eof {
+			return nil, nil, io.ErrUnexpectedEOF
+		}
+		signers = append(signers, signer)
+		sigs = append(sigs, sig)
+	}
+	return signers, sigs, nil
+}
+
+//voteCollector aggregates the votes and new view messages sent to the leader
+type voteCollector struct {
+	votes    map[uint64]map[common.Uint256]map[uint16][]byte // view -> block hash -> signer -> sig
+	newViews map[uint64]map[uint16][]byte                    // view -> signer -> sig
+	quorum   int
+}
+
+func newVoteCollector(n int) *voteCollector {
+	return &voteCollector{
+		votes:    make(map[uint64]map[common.Uint256]map[uint16][]byte),
+		newViews: make(map[uint64]map[uint16][]byte),
+		quorum:   quorumSize(n),
+	}
+}
+
+//addVote adds a verified vote, and returns the QC when the votes of the block reach the quorum
+func (vc *voteCollector) addVote(vote *voteMsg) *QuorumCert {
+	blocks := vc.votes[vote.ViewNum]
+	if blocks == nil {
+		blocks = make(map[common.Uint256]map[uint16][]byte)
+		vc.votes[vote.ViewNum] = blocks
+	}
+	sigs := blocks[vote.BlockHash]
+	if sigs == nil {
+		sigs = make(map[uint16][]byte)
+		blocks[vote.BlockHash] = sigs
+	}
+	if _, present := sigs[vote.Signer]; present {
+		return nil
+	}
+	sigs[vote.Signer] = vote.Sig
+	if len(sigs) != vc.quorum {
+		return nil
+	}
+	qc := &QuorumCert{
+		ViewNum:   vote.ViewNum,
+		Height:    vote.Height,
+		BlockHash: vote.BlockHash,
+	}
+	qc.Signers, qc.Sigs = collectSigs(sigs)
+	return qc
+}
+
+//addNewView adds a verified new view message, and returns the TC when the messages reach the quorum
+func (vc *voteCollector) addNewView(view uint64, signer uint16, sig []byte) *TimeoutCert {
+	sigs := vc.newViews[view]
+	if sigs == nil {
+		sigs = make(map[uint16][]byte)
+		vc.newViews[view] = sigs
+	}
+	if _, present := sigs[signer]; present {
+		return nil
+	}
+	sigs[signer] = sig
+	if len(sigs) != vc.quorum {
+		return nil
+	}
+	tc := &TimeoutCert{ViewNum: view}
+	tc.Signers, tc.Sigs = collectSigs(sigs)
+	return tc
+}
+
+//prune drops the messages of the views before the view
+func (vc *voteCollector) prune(view uint64) {
+	for v := range vc.votes {
+		if v < view {
+			delete(vc.votes, v)
+		}
+	}
+	for v := range vc.newViews {
+		if v < view {
+			delete(vc.newViews, v)
+		}
+	}
+}
+
+func collectSigs(sigs map[uint16][]byte) ([]uint16, [][]byte) {
+	signers := make([]uint16, 0, len(sigs))
+	for signer := range sigs {
+		signers = append(signers, signer)
+	}
+	sort.Slice(signers, func(i, j int) bool { return signers[i] < signers[j] })
+	result := make([][]byte, 0, len(signers))
+	for _, signer := range signers {
+		result = append(result, sigs[signer])
+	}
+	return signers, result
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAccounts(n int) ([]*account.Account, []keypair.PublicKey) {
+	var accs []*account.Account
+	var pks []keypair.PublicKey
+	for i := 0; i < n; i++ {
+		acc := account.NewAccount("")
+		accs = append(accs, acc)
+		pks = append(pks, acc.PublicKey)
+	}
+	return accs, pks
+}
+
+func TestQuorumSize(t *testing.T) {
+	assert.Equal(t, 1, quorumSize(1))
+	assert.Equal(t, 3, quorumSize(4))
+	assert.Equal(t, 5, quorumSize(7))
+	assert.Equal(t, 7, quorumSize(10))
+}
+
+func TestQuorumCertVerify(t *testing.T) {
+	accs, pks := newTestAccounts(4)
+	block := newTestBlock(common.Uint256{}, 1, 1)
+	qc := newTestQC(accs, block)
+	assert.Nil(t, qc.Verify(pks))
+
+	short := *qc
+	short.Signers, short.Sigs = qc.Signers[:2], qc.Sigs[:2]
+	assert.NotNil(t, short.Verify(pks))
+
+	dup := *qc
+	dup.Signers = []uint16{0, 0, 1}
+	assert.NotNil(t, dup.Verify(pks))
+
+	other := *qc
+	other.BlockHash = common.Uint256{1}
+	assert.NotNil(t, other.Verify(pks))
+
+	outOfRange := *qc
+	outOfRange.Signers = []uint16{0, 1, 4}
+	assert.NotNil(t, outOfRange.Verify(pks))
+}
+
+func TestVoteCollector(t *testing.T) {
+	accs, pks := newTestAccounts(4)
+	block := newTestBlock(common.Uint256{}, 1, 5)
+	hash := block.Hash()
+	vc := newVoteCollector(len(accs))
+	for i := 3; i >= 0; i-- {
+		sig, _ := signature.Sign(accs[i], hash[:])
+		vote := &voteMsg{ViewNum: 5, Height: 1, BlockHash: hash, Signer: uint16(i), Sig: sig}
+		qc := vc.addVote(vote)
+		assert.Nil(t, vc.addVote(vote))
+		if i != 1 {
+			assert.Nil(t, qc)
+			continue
+		}
+		assert.NotNil(t, qc)
+		assert.Equal(t, []uint16{1, 2, 3}, qc.Signers)
+		assert.Nil(t, qc.Verify(pks))
+	}
+
+	digest := newViewDigest(6)
+	var tc *TimeoutCert
+	for i := 0; i < 3; i++ {
+		sig, _ := signature.Sign(accs[i], digest[:])
+		tc = vc.addNewView(6, uint16(i), sig)
+	}
+	assert.NotNil(t, tc)
+	assert.Nil(t, tc.Verify(pks))
+	tc.ViewNum = 7
+	assert.NotNil(t, tc.Verify(pks))
+
+	vc.prune(6)
+	assert.Equal(t, 0, len(vc.votes))
+	assert.Equal(t, 1, len(vc.newViews))
+}
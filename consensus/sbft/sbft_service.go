@@ -18,13 +18,857 @@
 
 package sbft
 
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	actorTypes "github.com/ontio/ontology/consensus/actor"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/store"
+	"github.com/ontio/ontology/core/types"
+	p2pcommon "github.com/ontio/ontology/p2pserver/common"
+	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
+	p2pmsg "github.com/ontio/ontology/p2pserver/message/types"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	txpool "github.com/ontio/ontology/txnpool/common"
+	"github.com/ontio/ontology/validator/increment"
+)
+
+/*
+*SBFT is a pipelined and responsive BFT consensus in the style of chained HotStuff.
+*The leader of each view proposes a block extending the highest QC it knows, and the
+*votes of the block are sent to the leader of the next view, whose proposal carries
+*the aggregated QC. A block is committed once it heads a three-chain of certified
+*blocks with consecutive views. On timeout the bookkeepers only send the new view
+*message to the next leader, so the view change is linear as the normal case.
+ */
+const (
+	ContextVersion      uint32 = 0
+	CAP_MESSAGE_CHANNEL        = 4096
+	MAX_TIMESTAMP_DRIFT        = 10 // the max seconds of a proposal timestamp ahead of local time
+	MAX_WAITING_BLOCKS         = 64 // the max number of blocks waiting for the parent
+)
+
+//TxPool is the part of the tx pool actor used by the consensus
+type TxPool interface {
+	GetTxnPool(byCount bool, height uint32) []*txpool.VerifiedTx
+	VerifyBlock(txs []*types.Transaction, height uint32) error
+}
+
+type p2pMsgPayload struct {
+	fromPeer uint16
+	payload  *p2pmsg.ConsensusPayload
+}
+
+//waitingBlock is a received block whose parent is being fetched
+type waitingBlock struct {
+	block    *types.Block
+	justify  *QuorumCert
+	proposal *proposalMsg // nil if the block is fetched
+	from     uint16
+}
+
 type SbftService struct {
+	account          *account.Account
+	index            uint16
+	isBookkeeper     bool
+	bookkeepers      []keypair.PublicKey
+	bookkeeperIdx    map[string]uint16
+	bookkeeperAddr   common.Address
+	poolActor        TxPool
+	ledger           store.LedgerStore
+	p2p              p2p.P2P
+	incrValidator    *increment.IncrementValidator
+	genBlockInterval time.Duration
+	pid              *actor.PID
+
+	// the states below are only accessed in the consensus loop
+	peers         map[uint16]p2pcommon.PeerId
+	tree          *blockTree
+	collector     *voteCollector
+	pm            *pacemaker
+	highQC        *QuorumCert
+	highTC        *TimeoutCert
+	preferredView uint64 // the view of the locked block, only the proposals justified not lower than it are voted
+	lastVotedView uint64
+	proposedView  uint64
+	proposeTimer  *time.Timer
+	fetching      map[common.Uint256]bool
+	waiting       []*waitingBlock
+	waitingQCs    []*QuorumCert
+
+	msgC   chan *p2pMsgPayload
+	quitC  chan struct{}
+	quitWg sync.WaitGroup
+}
+
+func NewSbftService(account *account.Account, txpool *actor.PID, p2p p2p.P2P) (*SbftService, error) {
+	bookkeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return nil, fmt.Errorf("get bookkeepers error: %s", err)
+	}
+	genBlockTime := config.DefConfig.Genesis.SBFT.GenBlockTime
+	if genBlockTime == 0 {
+		genBlockTime = config.DEFAULT_GEN_BLOCK_TIME
+	}
+	return newSbftService("consensus_sbft", account, bookkeepers, &actorTypes.TxPoolActor{Pool: txpool},
+		ledger.DefLedger, p2p, time.Duration(genBlockTime)*time.Second)
+}
+
+func newSbftService(name string, account *account.Account, bookkeepers []keypair.PublicKey, pool TxPool,
+	ledger store.LedgerStore, p2p p2p.P2P, genBlockInterval time.Duration) (*SbftService, error) {
+	if len(bookkeepers) == 0 || len(bookkeepers) > MAX_BOOKKEEPERS {
+		return nil, fmt.Errorf("invalid bookkeeper number: %d", len(bookkeepers))
+	}
+	bookkeeperAddr, err := types.AddressFromBookkeepers(bookkeepers)
+	if err != nil {
+		return nil, fmt.Errorf("get bookkeeper address error: %s", err)
+	}
+	service := &SbftService{
+		account:          account,
+		bookkeepers:      bookkeepers,
+		bookkeeperIdx:    make(map[string]uint16, len(bookkeepers)),
+		bookkeeperAddr:   bookkeeperAddr,
+		poolActor:        pool,
+		ledger:           ledger,
+		p2p:              p2p,
+		incrValidator:    increment.NewIncrementValidator(20),
+		genBlockInterval: genBlockInterval,
+		msgC:             make(chan *p2pMsgPayload, CAP_MESSAGE_CHANNEL),
+	}
+	self := pubkeyID(account.PublicKey)
+	for i, pk := range bookkeepers {
+		id := pubkeyID(pk)
+		service.bookkeeperIdx[id] = uint16(i)
+		if id == self {
+			service.index = uint16(i)
+			service.isBookkeeper = true
+		}
+	}
+	if !service.isBookkeeper {
+		log.Warnf("sbft: account %s is not a bookkeeper, following the consensus only", account.Address.ToBase58())
+	}
+
+	props := actor.FromProducer(func() actor.Actor {
+		return service
+	})
+	pid, err := actor.SpawnNamed(props, name)
+	if err != nil {
+		return nil, err
+	}
+	service.pid = pid
+	return service, nil
+}
+
+func (self *SbftService) Receive(context actor.Context) {
+	switch msg := context.Message().(type) {
+	case *actor.Restarting:
+		log.Info("sbft actor restarting")
+	case *actor.Stopping:
+		log.Info("sbft actor stopping")
+	case *actor.Stopped:
+		log.Info("sbft actor stopped")
+	case *actor.Started:
+		log.Info("sbft actor started")
+	case *actor.Restart:
+		log.Info("sbft actor restart")
+	case *actorTypes.StartConsensus:
+		if err := self.start(); err != nil {
+			log.Errorf("sbft start consensus error: %s", err)
+		}
+	case *actorTypes.StopConsensus:
+		self.stop()
+	case *p2pmsg.ConsensusPayload:
+		self.NewConsensusPayload(msg)
+	default:
+		log.Info("sbft actor: Unknown msg ", msg, "type", reflect.TypeOf(msg))
+	}
+}
+
+func (self *SbftService) GetPID() *actor.PID {
+	return self.pid
+}
+
+func (self *SbftService) Start() error {
+	self.pid.Tell(&actorTypes.StartConsensus{})
+	return nil
+}
+
+func (self *SbftService) Halt() error {
+	self.pid.Tell(&actorTypes.StopConsensus{})
+	return nil
+}
+
+//NewConsensusPayload dispatches the consensus message from the bookkeepers to the consensus loop
+func (self *SbftService) NewConsensusPayload(payload *p2pmsg.ConsensusPayload) {
+	peerIdx, present := self.bookkeeperIdx[pubkeyID(payload.Owner)]
+	if !present || payload.BookkeeperIndex != peerIdx {
+		log.Debugf("sbft: invalid consensus node: %s", pubkeyID(payload.Owner))
+		return
+	}
+	if self.quitC == nil {
+		return
+	}
+	select {
+	case self.msgC <- &p2pMsgPayload{fromPeer: peerIdx, payload: payload}:
+	default:
+		log.Warnf("sbft: message channel is full, drop msg from %d", peerIdx)
+	}
+}
+
+func (self *SbftService) start() error {
+	if self.quitC != nil {
+		log.Info("consensus have started")
+		return nil
+	}
+	root, err := self.ledger.GetHeaderByHeight(self.ledger.GetCurrentBlockHeight())
+	if err != nil {
+		return fmt.Errorf("get current header error: %s", err)
+	}
+	self.peers = make(map[uint16]p2pcommon.PeerId)
+	self.tree = newBlockTree(root)
+	self.collector = newVoteCollector(len(self.bookkeepers))
+	self.highQC = self.tree.rootQC()
+	self.highTC = nil
+	self.preferredView = self.highQC.ViewNum
+	self.lastVotedView = self.highQC.ViewNum
+	self.proposedView = self.highQC.ViewNum
+	self.pm = newPacemaker(self.highQC.ViewNum+1, self.genBlockInterval+VIEW_TIMEOUT_DELTA)
+	self.proposeTimer = time.NewTimer(time.Hour)
+	self.proposeTimer.Stop()
+	self.fetching = make(map[common.Uint256]bool)
+	self.waiting = nil
+	self.waitingQCs = nil
+
+	self.quitC = make(chan struct{})
+	self.quitWg.Add(1)
+	go self.run(self.quitC)
+	log.Infof("sbft server %d started at height %d, view %d", self.index, root.Height, self.pm.curView)
+	return nil
+}
+
+func (self *SbftService) stop() {
+	if self.quitC == nil {
+		return
+	}
+	close(self.quitC)
+	self.quitWg.Wait()
+	self.quitC = nil
+	self.incrValidator.Clean()
+	log.Infof("sbft server %d stopped", self.index)
+}
+
+func (self *SbftService) run(quitC chan struct{}) {
+	defer self.quitWg.Done()
+	defer self.proposeTimer.Stop()
+	defer self.pm.stop()
+
+	self.tryPropose()
+	for {
+		select {
+		case msg := <-self.msgC:
+			self.syncLedger()
+			self.processMsg(msg)
+		case <-self.pm.timer.C:
+			self.syncLedger()
+			self.onViewTimeout()
+		case <-self.proposeTimer.C:
+			self.syncLedger()
+			self.tryPropose()
+		case <-quitC:
+			return
+		}
+	}
+}
+
+func (self *SbftService) processMsg(msg *p2pMsgPayload) {
+	cmsg, err := DeserializeSbftMsg(msg.payload.Data)
+	if err != nil {
+		log.Errorf("sbft server %d failed to deserialize msg from %d: %s", self.index, msg.fromPeer, err)
+		return
+	}
+	self.peers[msg.fromPeer] = msg.payload.PeerId
+	switch m := cmsg.(type) {
+	case *proposalMsg:
+		self.onProposal(m, msg.fromPeer)
+	case *voteMsg:
+		self.onVote(m, msg.fromPeer)
+	case *newViewMsg:
+		self.onNewView(m, msg.fromPeer)
+	case *blockFetchMsg:
+		self.onBlockFetch(m, msg.fromPeer)
+	case *blockFetchRespMsg:
+		self.onBlockFetchResp(m, msg.fromPeer)
+	}
+}
+
+func (self *SbftService) onProposal(msg *proposalMsg, from uint16) {
+	view := msg.ViewNum()
+	if leaderOf(view, len(self.bookkeepers)) != from {
+		log.Warnf("sbft server %d: proposal of view %d is not from the leader but %d", self.index, view, from)
+		return
+	}
+	if self.tree.contains(msg.Block.Hash()) {
+		return
+	}
+	justify := msg.Justify
+	if !self.tree.contains(justify.BlockHash) {
+		if justify.Height > self.tree.root.Height {
+			self.fetchBlock(justify.BlockHash, from)
+			self.addWaiting(&waitingBlock{block: msg.Block, justify: justify, proposal: msg, from: from})
+		}
+		return
+	}
+	if err := self.verifyQC(justify); err != nil {
+		log.Warnf("sbft server %d: invalid justify of proposal from %d: %s", self.index, from, err)
+		return
+	}
+	if justify.ViewNum+1 != view {
+		tc := msg.Timeout
+		if tc == nil || tc.ViewNum != view {
+			log.Warnf("sbft server %d: proposal of view %d without timeout cert", self.index, view)
+			return
+		}
+		if err := tc.Verify(self.bookkeepers); err != nil {
+			log.Warnf("sbft server %d: invalid timeout cert of view %d: %s", self.index, view, err)
+			return
+		}
+	}
+	self.processQC(justify)
+	if self.pm.advance(view) {
+		self.onEnterView()
+	}
+
+	node := &blockNode{block: msg.Block, justify: justify}
+	if err := self.verifyBlock(node, from != self.index); err != nil {
+		log.Warnf("sbft server %d: invalid proposal of view %d from %d: %s", self.index, view, from, err)
+		return
+	}
+	self.tree.insert(node)
+	self.vote(node)
+	self.retryWaiting()
 }
 
-func (ss *SbftService) Halt() error {
+func (self *SbftService) vote(node *blockNode) {
+	view := node.view()
+	if !self.isBookkeeper || view != self.pm.curView || view <= self.lastVotedView ||
+		node.justify.ViewNum < self.preferredView {
+		return
+	}
+	hash := node.hash()
+	sig, err := signature.Sign(self.account, hash[:])
+	if err != nil {
+		log.Errorf("sbft server %d: sign block error: %s", self.index, err)
+		return
+	}
+	self.lastVotedView = view
+	vote := &voteMsg{
+		ViewNum:   view,
+		Height:    node.block.Header.Height,
+		BlockHash: hash,
+		Signer:    self.index,
+		Sig:       sig,
+	}
+	if next := leaderOf(view+1, len(self.bookkeepers)); next == self.index {
+		self.onVote(vote, self.index)
+	} else {
+		self.sendTo(next, vote)
+	}
+}
+
+func (self *SbftService) onVote(vote *voteMsg, from uint16) {
+	if vote.Signer != from || leaderOf(vote.ViewNum+1, len(self.bookkeepers)) != self.index {
+		return
+	}
+	if vote.ViewNum+1 < self.pm.curView {
+		return
+	}
+	if err := signature.Verify(self.bookkeepers[from], vote.BlockHash[:], vote.Sig); err != nil {
+		log.Warnf("sbft server %d: invalid vote from %d: %s", self.index, from, err)
+		return
+	}
+	qc := self.collector.addVote(vote)
+	if qc == nil {
+		return
+	}
+	if !self.tree.contains(qc.BlockHash) {
+		self.fetchBlock(qc.BlockHash, from)
+		self.waitingQCs = append(self.waitingQCs, qc)
+		return
+	}
+	if err := self.verifyQC(qc); err != nil {
+		log.Warnf("sbft server %d: invalid qc of view %d: %s", self.index, qc.ViewNum, err)
+		return
+	}
+	self.processQC(qc)
+	self.tryPropose()
+}
+
+func (self *SbftService) onViewTimeout() {
+	view := self.pm.timeout()
+	log.Infof("sbft server %d: view timeout, enter view %d", self.index, view)
+	self.onEnterView()
+	if !self.isBookkeeper {
+		return
+	}
+	digest := newViewDigest(view)
+	sig, err := signature.Sign(self.account, digest[:])
+	if err != nil {
+		log.Errorf("sbft server %d: sign new view error: %s", self.index, err)
+		return
+	}
+	msg := &newViewMsg{
+		ViewNum: view,
+		HighQC:  self.highQC,
+		Signer:  self.index,
+		Sig:     sig,
+	}
+	if leader := leaderOf(view, len(self.bookkeepers)); leader == self.index {
+		self.onNewView(msg, self.index)
+	} else {
+		self.sendTo(leader, msg)
+	}
+}
+
+func (self *SbftService) onNewView(msg *newViewMsg, from uint16) {
+	if msg.Signer != from || leaderOf(msg.ViewNum, len(self.bookkeepers)) != self.index {
+		return
+	}
+	if msg.ViewNum < self.pm.curView {
+		return
+	}
+	digest := newViewDigest(msg.ViewNum)
+	if err := signature.Verify(self.bookkeepers[from], digest[:], msg.Sig); err != nil {
+		log.Warnf("sbft server %d: invalid new view from %d: %s", self.index, from, err)
+		return
+	}
+	if qc := msg.HighQC; self.tree.contains(qc.BlockHash) {
+		if err := self.verifyQC(qc); err == nil {
+			self.processQC(qc)
+		}
+	} else if qc.Height > self.tree.root.Height {
+		self.fetchBlock(qc.BlockHash, from)
+		self.waitingQCs = append(self.waitingQCs, qc)
+	}
+	if tc := self.collector.addNewView(msg.ViewNum, from, msg.Sig); tc != nil {
+		self.highTC = tc
+		if self.pm.advance(tc.ViewNum) {
+			self.onEnterView()
+		}
+	}
+	self.tryPropose()
+}
+
+func (self *SbftService) onBlockFetch(msg *blockFetchMsg, from uint16) {
+	node := self.tree.getNode(msg.BlockHash)
+	if node == nil {
+		return
+	}
+	self.sendTo(from, &blockFetchRespMsg{Block: node.block, Justify: node.justify})
+}
+
+func (self *SbftService) onBlockFetchResp(msg *blockFetchRespMsg, from uint16) {
+	hash := msg.Block.Hash()
+	if !self.fetching[hash] || self.tree.contains(hash) {
+		return
+	}
+	justify := msg.Justify
+	if !self.tree.contains(justify.BlockHash) {
+		if justify.Height > self.tree.root.Height {
+			self.fetchBlock(justify.BlockHash, from)
+			self.addWaiting(&waitingBlock{block: msg.Block, justify: justify, from: from})
+		}
+		return
+	}
+	if err := self.verifyQC(justify); err != nil {
+		log.Warnf("sbft server %d: invalid justify of fetched block from %d: %s", self.index, from, err)
+		return
+	}
+	self.processQC(justify)
+	node := &blockNode{block: msg.Block, justify: justify}
+	if err := self.verifyBlock(node, true); err != nil {
+		log.Warnf("sbft server %d: invalid fetched block from %d: %s", self.index, from, err)
+		return
+	}
+	delete(self.fetching, hash)
+	self.tree.insert(node)
+	self.retryWaiting()
+}
+
+//processQC updates the highest QC and the locked view with a verified QC, and commits
+//the block heading the three-chain ending at the certified block.
+func (self *SbftService) processQC(qc *QuorumCert) {
+	if qc.ViewNum > self.highQC.ViewNum {
+		self.highQC = qc
+	}
+	if node := self.tree.getNode(qc.BlockHash); node != nil {
+		if node.qc == nil {
+			node.qc = qc
+		}
+		if node.justify.ViewNum > self.preferredView {
+			self.preferredView = node.justify.ViewNum
+		}
+		if target := self.tree.commitCandidate(node); target != nil {
+			self.commit(target)
+		}
+	}
+	if qc.ViewNum+1 >= self.pm.curView {
+		self.pm.progress()
+	}
+	if self.pm.advance(qc.ViewNum + 1) {
+		self.onEnterView()
+	}
+}
+
+func (self *SbftService) onEnterView() {
+	view := self.pm.curView
+	if view > 0 {
+		self.collector.prune(view - 1)
+	}
+	self.fetching = make(map[common.Uint256]bool)
+	log.Debugf("sbft server %d enter view %d", self.index, view)
+	self.tryPropose()
+}
+
+func (self *SbftService) tryPropose() {
+	view := self.pm.curView
+	if !self.isBookkeeper || leaderOf(view, len(self.bookkeepers)) != self.index || self.proposedView >= view {
+		return
+	}
+	var tc *TimeoutCert
+	if self.highQC.ViewNum+1 != view {
+		if self.highTC == nil || self.highTC.ViewNum != view {
+			return
+		}
+		tc = self.highTC
+	}
+	parent := self.tree.header(self.highQC.BlockHash)
+	if parent == nil {
+		return
+	}
+	// the block interval is bounded by the min block time to keep timestamps increasing
+	proposeAt := time.Unix(int64(parent.Timestamp), 0).Add(self.genBlockInterval)
+	if delay := time.Until(proposeAt); delay > 0 {
+		resetTimer(self.proposeTimer, delay)
+		return
+	}
+	block, err := self.makeBlock(view, self.highQC.BlockHash)
+	if err != nil {
+		log.Errorf("sbft server %d: make block of view %d error: %s", self.index, view, err)
+		return
+	}
+	self.proposedView = view
+	msg := &proposalMsg{Block: block, Justify: self.highQC, Timeout: tc}
+	log.Infof("sbft server %d: propose block %d of view %d, txs %d", self.index, block.Header.Height, view,
+		len(block.Transactions))
+	self.broadcast(msg)
+	self.onProposal(msg, self.index)
+}
+
+func (self *SbftService) makeBlock(view uint64, parentHash common.Uint256) (*types.Block, error) {
+	parent := self.tree.header(parentHash)
+	branch, ok := self.tree.branch(parentHash)
+	if parent == nil || !ok {
+		return nil, fmt.Errorf("parent block %s not extend the ledger", parentHash.ToHexString())
+	}
+	committed := self.tree.root.Height
+	validHeight := committed
+	start, end := self.incrValidator.BlockRange()
+	if committed+1 == end {
+		validHeight = start
+	} else {
+		self.incrValidator.Clean()
+	}
+
+	// the txs of the uncommitted blocks are still in the pool, and the eip155 txs of the same
+	// payer are postponed since the nonce is not updated in the ledger yet.
+	packed := make(map[common.Uint256]bool)
+	eipPayers := make(map[common.Address]bool)
+	for _, node := range branch {
+		for _, tx := range node.block.Transactions {
+			packed[tx.Hash()] = true
+			if tx.IsEipTx() {
+				eipPayers[tx.Payer] = true
+			}
+		}
+	}
+	txs := self.poolActor.GetTxnPool(true, validHeight)
+	transactions := make([]*types.Transaction, 0, len(txs))
+	nonceCtx := make(map[common.Address]uint64)
+	for _, txEntry := range txs {
+		tx := txEntry.Tx
+		if packed[tx.Hash()] || (tx.IsEipTx() && eipPayers[tx.Payer]) {
+			continue
+		}
+		if err := self.incrValidator.Verify(tx, validHeight, nonceCtx); err != nil {
+			log.Debugf("sbft increment verify failed: %s", err)
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+
+	txHash := make([]common.Uint256, 0, len(transactions))
+	for _, t := range transactions {
+		txHash = append(txHash, t.Hash())
+	}
+	txRoot := common.ComputeMerkleRoot(txHash)
+	timestamp := uint32(time.Now().Unix())
+	if timestamp <= parent.Timestamp {
+		timestamp = parent.Timestamp + 1
+	}
+	header := &types.Header{
+		Version:          ContextVersion,
+		PrevBlockHash:    parentHash,
+		TransactionsRoot: txRoot,
+		BlockRoot:        self.blockRoot(branch, txRoot),
+		Timestamp:        timestamp,
+		Height:           parent.Height + 1,
+		ConsensusData:    view,
+		NextBookkeeper:   self.bookkeeperAddr,
+	}
+	return &types.Block{
+		Header:       header,
+		Transactions: transactions,
+	}, nil
+}
+
+//blockRoot computes the block root of the block extending the uncommitted blocks
+func (self *SbftService) blockRoot(branch []*blockNode, txRoot common.Uint256) common.Uint256 {
+	txRoots := make([]common.Uint256, 0, len(branch)+1)
+	for _, node := range branch {
+		txRoots = append(txRoots, node.block.Header.TransactionsRoot)
+	}
+	txRoots = append(txRoots, txRoot)
+	return self.ledger.GetBlockRootWithNewTxRoots(self.tree.root.Height+1, txRoots)
+}
+
+func (self *SbftService) verifyBlock(node *blockNode, verifyTxs bool) error {
+	header := node.block.Header
+	if header.PrevBlockHash != node.justify.BlockHash {
+		return fmt.Errorf("block does not extend the justify block")
+	}
+	parent := self.tree.header(header.PrevBlockHash)
+	branch, ok := self.tree.branch(header.PrevBlockHash)
+	if parent == nil || !ok {
+		return fmt.Errorf("parent block not extend the ledger")
+	}
+	if header.Version != ContextVersion {
+		return fmt.Errorf("invalid version %d", header.Version)
+	}
+	if header.Height != parent.Height+1 {
+		return fmt.Errorf("invalid height %d, parent height %d", header.Height, parent.Height)
+	}
+	if node.view() <= node.justify.ViewNum {
+		return fmt.Errorf("view %d not higher than justify view %d", node.view(), node.justify.ViewNum)
+	}
+	if header.Timestamp <= parent.Timestamp || header.Timestamp > uint32(time.Now().Unix())+MAX_TIMESTAMP_DRIFT {
+		return fmt.Errorf("invalid timestamp %d, parent timestamp %d", header.Timestamp, parent.Timestamp)
+	}
+	if header.NextBookkeeper != self.bookkeeperAddr {
+		return fmt.Errorf("invalid next bookkeeper %s", header.NextBookkeeper.ToBase58())
+	}
+	txHash := make([]common.Uint256, 0, len(node.block.Transactions))
+	for _, tx := range node.block.Transactions {
+		txHash = append(txHash, tx.Hash())
+	}
+	if common.ComputeMerkleRoot(txHash) != header.TransactionsRoot {
+		return fmt.Errorf("invalid transactions root")
+	}
+	if self.blockRoot(branch, header.TransactionsRoot) != header.BlockRoot {
+		return fmt.Errorf("invalid block root")
+	}
+	packed := make(map[common.Uint256]bool)
+	for _, n := range branch {
+		for _, tx := range n.block.Transactions {
+			packed[tx.Hash()] = true
+		}
+	}
+	for _, hash := range txHash {
+		if packed[hash] {
+			return fmt.Errorf("transaction %s has been packed", hash.ToHexString())
+		}
+	}
+	if verifyTxs && len(node.block.Transactions) > 0 {
+		if err := self.poolActor.VerifyBlock(node.block.Transactions, header.Height); err != nil {
+			return fmt.Errorf("verify transactions error: %s", err)
+		}
+	}
 	return nil
 }
 
-func (ds *SbftService) Start() error {
+//verifyQC checks the QC of a block in the tree, the QC of the committed head is trusted
+func (self *SbftService) verifyQC(qc *QuorumCert) error {
+	header := self.tree.header(qc.BlockHash)
+	if header == nil {
+		return fmt.Errorf("unknown block %s", qc.BlockHash.ToHexString())
+	}
+	if qc.BlockHash == self.tree.rootHash {
+		if qc.ViewNum != self.tree.rootView() || qc.Height != header.Height {
+			return fmt.Errorf("qc not match the committed block")
+		}
+		return nil
+	}
+	if qc.ViewNum != header.ConsensusData || qc.Height != header.Height {
+		return fmt.Errorf("qc of view %d height %d not match the block", qc.ViewNum, qc.Height)
+	}
+	if node := self.tree.getNode(qc.BlockHash); node != nil && node.qc == qc {
+		return nil
+	}
+	return qc.Verify(self.bookkeepers)
+}
+
+//commit commits the uncommitted blocks up to the target block into the ledger
+func (self *SbftService) commit(target *blockNode) {
+	branch, ok := self.tree.branch(target.hash())
+	if !ok {
+		return
+	}
+	for _, node := range branch {
+		if err := self.commitBlock(node); err != nil {
+			log.Errorf("sbft server %d: commit block %d error: %s", self.index, node.block.Header.Height, err)
+			return
+		}
+	}
+	if !self.tree.contains(self.highQC.BlockHash) {
+		self.highQC = self.tree.rootQC()
+	}
+}
+
+func (self *SbftService) commitBlock(node *blockNode) error {
+	if node.qc == nil {
+		return fmt.Errorf("block not certified")
+	}
+	block := node.block
+	header := block.Header
+	if self.ledger.GetCurrentBlockHeight() < header.Height {
+		header.Bookkeepers = self.bookkeepers
+		header.SigData = node.qc.Sigs
+		result, err := self.ledger.ExecuteBlock(block)
+		if err != nil {
+			return fmt.Errorf("execute block error: %s", err)
+		}
+		if err := self.ledger.SubmitBlock(block, nil, result); err != nil {
+			return fmt.Errorf("submit block error: %s", err)
+		}
+		self.incrValidator.AddBlock(block)
+		log.Infof("sbft server %d: commit block %d of view %d, txs %d", self.index, header.Height, node.view(),
+			len(block.Transactions))
+	} else if self.ledger.GetBlockHash(header.Height) != node.hash() {
+		return fmt.Errorf("block conflicts with the ledger")
+	}
+	self.tree.prune(header)
 	return nil
 }
+
+//syncLedger moves the committed head forward when the blocks are saved by the block syncer
+func (self *SbftService) syncLedger() {
+	height := self.ledger.GetCurrentBlockHeight()
+	if height <= self.tree.root.Height {
+		return
+	}
+	header, err := self.ledger.GetHeaderByHeight(height)
+	if err != nil {
+		log.Errorf("sbft server %d: get header %d error: %s", self.index, height, err)
+		return
+	}
+	self.tree.prune(header)
+	if !self.tree.contains(self.highQC.BlockHash) {
+		self.highQC = self.tree.rootQC()
+	}
+	if self.pm.advance(self.tree.rootView() + 1) {
+		self.onEnterView()
+	}
+}
+
+func (self *SbftService) fetchBlock(hash common.Uint256, from uint16) {
+	if self.fetching[hash] || from == self.index {
+		return
+	}
+	self.fetching[hash] = true
+	self.sendTo(from, &blockFetchMsg{BlockHash: hash})
+}
+
+func (self *SbftService) addWaiting(block *waitingBlock) {
+	if len(self.waiting) >= MAX_WAITING_BLOCKS {
+		self.waiting = self.waiting[1:]
+	}
+	self.waiting = append(self.waiting, block)
+}
+
+//retryWaiting handles the blocks and QCs whose block is inserted into the tree
+func (self *SbftService) retryWaiting() {
+	waiting := self.waiting
+	self.waiting = nil
+	for _, w := range waiting {
+		if !self.tree.contains(w.justify.BlockHash) {
+			if w.justify.Height > self.tree.root.Height {
+				self.waiting = append(self.waiting, w)
+			}
+			continue
+		}
+		if w.proposal != nil {
+			self.onProposal(w.proposal, w.from)
+		} else {
+			self.onBlockFetchResp(&blockFetchRespMsg{Block: w.block, Justify: w.justify}, w.from)
+		}
+	}
+	qcs := self.waitingQCs
+	self.waitingQCs = nil
+	for _, qc := range qcs {
+		if !self.tree.contains(qc.BlockHash) {
+			if qc.Height > self.tree.root.Height {
+				self.waitingQCs = append(self.waitingQCs, qc)
+			}
+			continue
+		}
+		if err := self.verifyQC(qc); err == nil {
+			self.processQC(qc)
+			self.tryPropose()
+		}
+	}
+}
+
+func (self *SbftService) newPayload(msg ConsensusMsg) *p2pmsg.ConsensusPayload {
+	payload := &p2pmsg.ConsensusPayload{
+		Version:         ContextVersion,
+		PrevHash:        self.tree.rootHash,
+		Height:          self.tree.root.Height + 1,
+		BookkeeperIndex: self.index,
+		Timestamp:       uint32(time.Now().Unix()),
+		Data:            SerializeSbftMsg(msg),
+		Owner:           self.account.PublicKey,
+	}
+	sink := common.NewZeroCopySink(nil)
+	payload.SerializationUnsigned(sink)
+	payload.Signature, _ = signature.Sign(self.account, sink.Bytes())
+	return payload
+}
+
+//sendTo sends the msg to the bookkeeper, the msg is broadcasted if the peer of the bookkeeper is unknown
+func (self *SbftService) sendTo(to uint16, msg ConsensusMsg) {
+	cons := msgpack.NewConsensus(self.newPayload(msg))
+	if peerId, present := self.peers[to]; present {
+		go self.p2p.SendTo(peerId, cons)
+	} else {
+		go self.p2p.Broadcast(cons)
+	}
+}
+
+func (self *SbftService) broadcast(msg ConsensusMsg) {
+	cons := msgpack.NewConsensus(self.newPayload(msg))
+	go self.p2p.Broadcast(cons)
+}
+
+func pubkeyID(pk keypair.PublicKey) string {
+	return hex.EncodeToString(keypair.SerializePublicKey(pk))
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sbft
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/p2pserver/common"
+	msgTypes "github.com/ontio/ontology/p2pserver/message/types"
+	"github.com/ontio/ontology/p2pserver/mock"
+	"github.com/ontio/ontology/p2pserver/net/netserver"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/ontio/ontology/p2pserver/peer"
+	txpool "github.com/ontio/ontology/txnpool/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type emptyTxPool struct{}
+
+func (self emptyTxPool) GetTxnPool(byCount bool, height uint32) []*txpool.VerifiedTx {
+	return nil
+}
+
+func (self emptyTxPool) VerifyBlock(txs []*types.Transaction, height uint32) error {
+	return nil
+}
+
+//consensusProtocol delivers the consensus messages to the service as the p2p msg handler does
+type consensusProtocol struct {
+	service *SbftService
+}
+
+func (self *consensusProtocol) HandlePeerMessage(ctx *p2p.Context, msg msgTypes.Message) {
+	if cons, ok := msg.(*msgTypes.Consensus); ok && self.service != nil {
+		if err := cons.Cons.Verify(); err != nil {
+			return
+		}
+		cons.Cons.PeerId = ctx.Sender().GetID()
+		self.service.GetPID().Tell(&cons.Cons)
+	}
+}
+
+func (self *consensusProtocol) HandleSystemMessage(net p2p.P2P, msg p2p.SystemMessage) {}
+
+func indexOf(bookkeepers []keypair.PublicKey, pk keypair.PublicKey) int {
+	for i, key := range bookkeepers {
+		if pubkeyID(key) == pubkeyID(pk) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSbftNetwork(t *testing.T) {
+	log.InitLog(log.InfoLog, log.Stdout)
+	const N = 4
+	genesisConfig := *config.DefConfig.Genesis
+	defer func() { *config.DefConfig.Genesis = genesisConfig }()
+
+	accs, _ := newTestAccounts(N)
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SBFT
+	config.DefConfig.Genesis.SBFT = &config.SBFTConfig{GenBlockTime: 1}
+	for _, acc := range accs {
+		config.DefConfig.Genesis.SBFT.Bookkeepers = append(config.DefConfig.Genesis.SBFT.Bookkeepers,
+			pubkeyID(acc.PublicKey))
+	}
+	bookkeepers, err := config.DefConfig.GetBookkeepers()
+	assert.Nil(t, err)
+	sort.Slice(accs, func(i, j int) bool {
+		return indexOf(bookkeepers, accs[i].PublicKey) < indexOf(bookkeepers, accs[j].PublicKey)
+	})
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+
+	dir, err := ioutil.TempDir("", "sbft")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	net := mock.NewNetwork()
+	var nodes []*netserver.NetServer
+	var ledgers []*ledger.Ledger
+	var services []*SbftService
+	for i := 0; i < N; i++ {
+		ldg, err := ledger.InitLedger(filepath.Join(dir, fmt.Sprint(i)), 0, bookkeepers, genesisBlock)
+		assert.Nil(t, err)
+		defer ldg.Close()
+		ledgers = append(ledgers, ldg)
+
+		keyId := common.RandPeerKeyId()
+		info := peer.NewPeerInfo(keyId.Id, 0, 0, true, 0, 0, 0, "1.10", "")
+		proto := &consensusProtocol{}
+		logger := common.LoggerWithContext(common.NewGlobalLoggerWrapper(), fmt.Sprintf("sbft node %d: ", i))
+		node := mock.NewNode(keyId, "", info, proto, net, nil, p2p.AllAddrFilter(), logger)
+		nodes = append(nodes, node)
+
+		service, err := newSbftService(fmt.Sprintf("sbft_test_%d", i), accs[i], bookkeepers, emptyTxPool{},
+			ldg.LedgerStore, node, time.Second)
+		assert.Nil(t, err)
+		proto.service = service
+		services = append(services, service)
+	}
+	for i := 0; i < N; i++ {
+		for j := i + 1; j < N; j++ {
+			net.AllowConnect(nodes[i].GetID(), nodes[j].GetID())
+		}
+	}
+	for _, node := range nodes {
+		go node.Start()
+	}
+	for i := 0; i < N; i++ {
+		for j := i + 1; j < N; j++ {
+			nodes[i].Connect(nodes[j].GetHostInfo().Addr)
+		}
+	}
+	for _, service := range services {
+		assert.Nil(t, service.Start())
+		defer service.Halt()
+	}
+
+	const target = 3
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		reached := true
+		for _, ldg := range ledgers {
+			if ldg.GetCurrentBlockHeight() < target {
+				reached = false
+			}
+		}
+		if reached {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	for height := uint32(1); height <= target; height++ {
+		hash := ledgers[0].GetBlockHash(height)
+		assert.NotEqual(t, hash, comm.Uint256{})
+		for i := 1; i < N; i++ {
+			assert.Equal(t, hash, ledgers[i].GetBlockHash(height), "height %d of node %d", height, i)
+		}
+	}
+}
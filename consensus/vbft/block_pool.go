@@ -38,10 +38,22 @@ var errDupEndorse = errors.New("multi endorsement from same endorser")
 var errDupCommit = errors.New("multi commit from same committer")
 
 type CandidateEndorseSigInfo struct {
-	EndorsedProposer uint32
-	Signature        []byte
-	ForEmpty         bool
-	CrossChainMsgSig []byte
+	EndorsedProposer  uint32
+	EndorsedBlockHash common.Uint256
+	Signature         []byte
+	ForEmpty          bool
+	CrossChainMsgSig  []byte
+}
+
+// endorsedBlock identifies the block endorsed, a faulty proposer may sign conflicting blocks
+// for one height, so the endorsements are counted by the block hash besides the proposer
+type endorsedBlock struct {
+	proposer uint32
+	hash     common.Uint256
+}
+
+func (eSig *CandidateEndorseSigInfo) endorsedBlock() endorsedBlock {
+	return endorsedBlock{proposer: eSig.EndorsedProposer, hash: eSig.EndorsedBlockHash}
 }
 
 type CandidateInfo struct {
@@ -150,9 +162,10 @@ func (pool *BlockPool) newBlockProposal(msg *blockProposalMsg) error {
 	// add endorse-sig
 	proposer := msg.Block.getProposer()
 	eSig := &CandidateEndorseSigInfo{
-		EndorsedProposer: proposer,
-		Signature:        msg.BlockProposerSig,
-		ForEmpty:         false,
+		EndorsedProposer:  proposer,
+		EndorsedBlockHash: msg.blockHash(false),
+		Signature:         msg.BlockProposerSig,
+		ForEmpty:          false,
 	}
 	if msg.Block.Block.Header.Height > 1 && msg.Block.CrossChainMsg != nil {
 		eSig.CrossChainMsgSig = msg.Block.CrossChainMsg.SigData[0]
@@ -297,10 +310,11 @@ func (pool *BlockPool) newBlockEndorsement(msg *blockEndorseMsg) {
 	defer pool.lock.Unlock()
 
 	eSig := &CandidateEndorseSigInfo{
-		EndorsedProposer: msg.EndorsedProposer,
-		Signature:        msg.EndorserSig,
-		ForEmpty:         msg.EndorseForEmpty,
-		CrossChainMsgSig: msg.CrossChainMsgEndorserSig,
+		EndorsedProposer:  msg.EndorsedProposer,
+		EndorsedBlockHash: msg.EndorsedBlockHash,
+		Signature:         msg.EndorserSig,
+		ForEmpty:          msg.EndorseForEmpty,
+		CrossChainMsgSig:  msg.CrossChainMsgEndorserSig,
 	}
 	pool.addBlockEndorsementLocked(msg.GetBlockNum(), msg.Endorser, eSig, false)
 }
@@ -310,23 +324,24 @@ func (pool *BlockPool) newBlockEndorsement(msg *blockEndorseMsg) {
 //
 // return
 //		@ endorsable proposer
+//		@ endorsable block hash
 //		@ for empty commit
 //		@ endorsable
 //
-func (pool *BlockPool) endorseDone(blkNum uint32, C uint32) (uint32, bool, bool) {
+func (pool *BlockPool) endorseDone(blkNum uint32, C uint32) (uint32, common.Uint256, bool, bool) {
 	pool.lock.RLock()
 	defer pool.lock.RUnlock()
 
-	endorseCount := make(map[uint32]uint32)
+	endorseCount := make(map[endorsedBlock]uint32)
 	emptyEndorseCount := 0
 
 	candidate := pool.candidateBlocks[blkNum]
 	if candidate == nil {
-		return math.MaxUint32, false, false
+		return math.MaxUint32, common.UINT256_EMPTY, false, false
 	}
 
 	if uint32(len(candidate.EndorseSigs)) < C+1 {
-		return math.MaxUint32, false, false
+		return math.MaxUint32, common.UINT256_EMPTY, false, false
 	}
 
 	for _, eSigs := range candidate.EndorseSigs {
@@ -335,26 +350,26 @@ func (pool *BlockPool) endorseDone(blkNum uint32, C uint32) (uint32, bool, bool)
 				emptyEndorseCount++
 				if emptyEndorseCount > int(C) {
 					// FIXME: endorsedProposer need fix
-					return esig.EndorsedProposer, true, true
+					return esig.EndorsedProposer, esig.EndorsedBlockHash, true, true
 				}
 			} else {
-				endorseCount[esig.EndorsedProposer] += 1
+				endorseCount[esig.endorsedBlock()] += 1
 				// check if endorse-consensus reached
-				if endorseCount[esig.EndorsedProposer] > C {
-					return esig.EndorsedProposer, false, true
+				if endorseCount[esig.endorsedBlock()] > C {
+					return esig.EndorsedProposer, esig.EndorsedBlockHash, false, true
 				}
 			}
 		}
 	}
 
-	return math.MaxUint32, false, false
+	return math.MaxUint32, common.UINT256_EMPTY, false, false
 }
 
 func (pool *BlockPool) endorseFailed(blkNum uint32, C uint32) bool {
 	pool.lock.RLock()
 	defer pool.lock.RUnlock()
 
-	proposalCount := make(map[uint32]uint32)
+	proposalCount := make(map[endorsedBlock]uint32)
 	endorserCount := make(map[uint32]uint32)
 	candidate := pool.candidateBlocks[blkNum]
 	if candidate == nil {
@@ -369,8 +384,8 @@ func (pool *BlockPool) endorseFailed(blkNum uint32, C uint32) bool {
 	for endorser, eSigs := range candidate.EndorseSigs {
 		for _, esig := range eSigs {
 			if !esig.ForEmpty {
-				proposalCount[esig.EndorsedProposer] += 1
-				if proposalCount[esig.EndorsedProposer] > C+1 {
+				proposalCount[esig.endorsedBlock()] += 1
+				if proposalCount[esig.endorsedBlock()] > C+1 {
 					return false
 				}
 			} else {
@@ -469,9 +484,10 @@ func (pool *BlockPool) newBlockCommitment(msg *blockCommitMsg) error {
 	// add all endorse sigs
 	for endorser, sig := range msg.EndorsersSig {
 		eSig := &CandidateEndorseSigInfo{
-			EndorsedProposer: msg.BlockProposer,
-			Signature:        sig,
-			ForEmpty:         msg.CommitForEmpty,
+			EndorsedProposer:  msg.BlockProposer,
+			EndorsedBlockHash: msg.CommitBlockHash,
+			Signature:         sig,
+			ForEmpty:          msg.CommitForEmpty,
 		}
 		// old version of committer msg is nil, compatible old version
 		if msg.CrossChainMsgCommitterSig != nil {
@@ -485,10 +501,11 @@ func (pool *BlockPool) newBlockCommitment(msg *blockCommitMsg) error {
 
 	// add committer sig
 	pool.addBlockEndorsementLocked(blkNum, msg.Committer, &CandidateEndorseSigInfo{
-		EndorsedProposer: msg.BlockProposer,
-		Signature:        msg.CommitterSig,
-		ForEmpty:         msg.CommitForEmpty,
-		CrossChainMsgSig: msg.CrossChainMsgCommitterSig,
+		EndorsedProposer:  msg.BlockProposer,
+		EndorsedBlockHash: msg.CommitBlockHash,
+		Signature:         msg.CommitterSig,
+		ForEmpty:          msg.CommitForEmpty,
+		CrossChainMsgSig:  msg.CrossChainMsgCommitterSig,
 	}, true)
 
 	// add msg to commit-msgs
@@ -500,29 +517,30 @@ func (pool *BlockPool) newBlockCommitment(msg *blockCommitMsg) error {
 // check if has reached consensus on block-commit
 // return
 //		@ consensused proposer
+//		@ consensused block hash
 //		@ for empty commit
 //		@ consensused
 //
 // Note: Attentions on lock contention.
 // Only shared-lock for this function, because this function will also acquires shared-lock on peer-pool.
 //
-func (pool *BlockPool) commitDone(blkNum uint32, C uint32, N uint32) (uint32, bool, bool) {
+func (pool *BlockPool) commitDone(blkNum uint32, C uint32, N uint32) (uint32, common.Uint256, bool, bool) {
 	pool.lock.RLock()
 	defer pool.lock.RUnlock()
 	candidate := pool.candidateBlocks[blkNum]
 	if candidate == nil {
-		return math.MaxUint32, false, false
+		return math.MaxUint32, common.UINT256_EMPTY, false, false
 	}
 
 	// check consensus with commit msgs
-	proposer, forEmpty := getCommitConsensus(candidate.CommitMsgs, int(C), int(N))
+	proposer, blkHash, forEmpty := getCommitConsensus(candidate.CommitMsgs, int(C), int(N))
 
 	if proposer == math.MaxUint32 {
 		// check consensus with endorse sigs
 		// enforce signature quorum if checking commit-consensus base on signature count
 		C = N - (N-1)/3 - 1
 		var emptyCnt uint32
-		endorseCnt := make(map[uint32]uint32) // proposer -> endorsed-cnt
+		endorseCnt := make(map[endorsedBlock]uint32) // endorsed block -> endorsed-cnt
 		for endorser, eSigs := range candidate.EndorseSigs {
 			// check if from endorser
 			if !pool.server.isEndorser(blkNum, endorser) {
@@ -537,9 +555,10 @@ func (pool *BlockPool) commitDone(blkNum uint32, C uint32, N uint32) (uint32, bo
 				if sig.ForEmpty {
 					emptyCnt++
 				} else {
-					endorseCnt[sig.EndorsedProposer] += 1
-					if endorseCnt[sig.EndorsedProposer] > C {
+					endorseCnt[sig.endorsedBlock()] += 1
+					if endorseCnt[sig.endorsedBlock()] > C {
 						proposer = sig.EndorsedProposer
+						blkHash = sig.EndorsedBlockHash
 						if !forEmpty {
 							forEmpty = emptyCnt > C
 						}
@@ -555,10 +574,10 @@ func (pool *BlockPool) commitDone(blkNum uint32, C uint32, N uint32) (uint32, bo
 	}
 
 	if proposer != math.MaxUint32 {
-		return proposer, forEmpty, true
+		return proposer, blkHash, forEmpty, true
 	}
 
-	return math.MaxUint32, false, false
+	return math.MaxUint32, common.UINT256_EMPTY, false, false
 }
 
 //
@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"math/rand"
+	"time"
+)
+
+//Timer is the timer scheduled on the Clock
+type Timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+//Clock is the source of time of the server. All the consensus timers and the block timestamps
+//are taken from it, so that the servers can be driven by a virtual clock in simulation. The
+//random backoff is drawn from the clock as well, to be replayed with the timers.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+	Int63n(n int64) int64
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (systemClock) Int63n(n int64) int64 {
+	return rand.Int63n(n)
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vconfig
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+)
+
+const (
+	EVIDENCE_PROPOSAL uint8 = 1 // conflicting block proposals of the same height
+	EVIDENCE_ENDORSE  uint8 = 2 // conflicting block endorsements of the same height
+)
+
+// EndorseProof is the endorsement of a block signed by the endorser over EndorseDigest, which
+// binds the endorsed block to the block number, so that two endorsements can be compared.
+type EndorseProof struct {
+	BlockNum  uint32
+	Proposer  uint32
+	BlockHash common.Uint256
+	ForEmpty  bool
+	Sig       []byte
+}
+
+// EndorseDigest returns the digest signed by the endorser for an endorsement
+func EndorseDigest(blockNum uint32, proposer uint32, blockHash common.Uint256, forEmpty bool) common.Uint256 {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte("vbft-endorse"))
+	sink.WriteUint32(blockNum)
+	sink.WriteUint32(proposer)
+	sink.WriteHash(blockHash)
+	sink.WriteBool(forEmpty)
+	return sha256.Sum256(sink.Bytes())
+}
+
+func (self *EndorseProof) Verify(pub keypair.PublicKey) error {
+	digest := EndorseDigest(self.BlockNum, self.Proposer, self.BlockHash, self.ForEmpty)
+	return signature.Verify(pub, digest[:], self.Sig)
+}
+
+func (self *EndorseProof) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(self.BlockNum)
+	sink.WriteUint32(self.Proposer)
+	sink.WriteHash(self.BlockHash)
+	sink.WriteBool(self.ForEmpty)
+	sink.WriteVarBytes(self.Sig)
+}
+
+func (self *EndorseProof) Deserialization(source *common.ZeroCopySource) error {
+	var eof, irregular bool
+	if self.BlockNum, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if self.Proposer, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if self.BlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if self.ForEmpty, irregular, eof = source.NextBool(); irregular {
+		return common.ErrIrregularData
+	} else if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if self.Sig, _, irregular, eof = source.NextVarBytes(); irregular {
+		return common.ErrIrregularData
+	} else if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// Evidence is a self-contained proof that a consensus node signed conflicting messages for the
+// same block number. The proposal evidence holds the block headers signed by the proposer, whose
+// SigData[0] is the proposer signature. The endorse evidence holds two endorse proofs.
+type Evidence struct {
+	Type      uint8
+	Offender  string // pubkey of the offender
+	Proposals []*types.Header
+	Endorses  []*EndorseProof
+}
+
+func (self *Evidence) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint8(self.Type)
+	sink.WriteString(self.Offender)
+	sink.WriteVarUint(uint64(len(self.Proposals)))
+	for _, header := range self.Proposals {
+		header.Serialization(sink)
+	}
+	sink.WriteVarUint(uint64(len(self.Endorses)))
+	for _, endorse := range self.Endorses {
+		endorse.Serialization(sink)
+	}
+}
+
+func (self *Evidence) Deserialization(source *common.ZeroCopySource) error {
+	var eof, irregular bool
+	if self.Type, eof = source.NextUint8(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if self.Offender, _, irregular, eof = source.NextString(); irregular {
+		return common.ErrIrregularData
+	} else if eof {
+		return io.ErrUnexpectedEOF
+	}
+	n, _, irregular, eof := source.NextVarUint()
+	if irregular {
+		return common.ErrIrregularData
+	} else if eof {
+		return io.ErrUnexpectedEOF
+	} else if n > 3 {
+		return fmt.Errorf("too many proposals: %d", n)
+	}
+	self.Proposals = nil
+	for i := uint64(0); i < n; i++ {
+		header := new(types.Header)
+		if err := header.Deserialization(source); err != nil {
+			return err
+		}
+		self.Proposals = append(self.Proposals, header)
+	}
+	n, _, irregular, eof = source.NextVarUint()
+	if irregular {
+		return common.ErrIrregularData
+	} else if eof {
+		return io.ErrUnexpectedEOF
+	} else if n > 2 {
+		return fmt.Errorf("too many endorsements: %d", n)
+	}
+	self.Endorses = nil
+	for i := uint64(0); i < n; i++ {
+		endorse := new(EndorseProof)
+		if err := endorse.Deserialization(source); err != nil {
+			return err
+		}
+		self.Endorses = append(self.Endorses, endorse)
+	}
+	return nil
+}
+
+// BlockNum returns the block number at which the offender equivocated
+func (self *Evidence) BlockNum() uint32 {
+	if len(self.Proposals) > 0 {
+		return self.Proposals[0].Height
+	}
+	if len(self.Endorses) > 0 {
+		return self.Endorses[0].BlockNum
+	}
+	return 0
+}
+
+// Verify checks the evidence proves the offender with the peer index equivocated.
+//
+// A proposer signs at most two headers for a block number, the block and the empty block of its
+// proposal, which only differ in the transactions. So the proposal evidence is valid with three
+// distinct headers, or with two headers which differ in the prev block hash, timestamp or
+// consensus payload. An endorser endorses at most one block and one empty block for a block number,
+// so two endorsements of different blocks with the same empty flag are conflicting.
+func (self *Evidence) Verify(index uint32) error {
+	pub, err := Pubkey(self.Offender)
+	if err != nil {
+		return fmt.Errorf("invalid offender pubkey: %s", err)
+	}
+	switch self.Type {
+	case EVIDENCE_PROPOSAL:
+		if len(self.Endorses) != 0 || len(self.Proposals) < 2 {
+			return fmt.Errorf("invalid proposal evidence")
+		}
+		hashes := make(map[common.Uint256]bool)
+		for _, header := range self.Proposals {
+			if header.Height != self.Proposals[0].Height {
+				return fmt.Errorf("proposals of different block number")
+			}
+			info, err := VbftBlock(header)
+			if err != nil {
+				return err
+			}
+			if info.Proposer != index {
+				return fmt.Errorf("block %d not proposed by %d", header.Height, index)
+			}
+			if len(header.SigData) == 0 {
+				return fmt.Errorf("no proposer sig in block %d", header.Height)
+			}
+			hash := header.Hash()
+			if hashes[hash] {
+				return fmt.Errorf("duplicated proposal %s", hash.ToHexString())
+			}
+			hashes[hash] = true
+			if err := signature.Verify(pub, hash[:], header.SigData[0]); err != nil {
+				return fmt.Errorf("verify proposer sig: %s", err)
+			}
+		}
+		if len(self.Proposals) == 2 && sameProposalContext(self.Proposals[0], self.Proposals[1]) {
+			return fmt.Errorf("proposals may be the block and empty block of one proposal")
+		}
+	case EVIDENCE_ENDORSE:
+		if len(self.Proposals) != 0 || len(self.Endorses) != 2 {
+			return fmt.Errorf("invalid endorse evidence")
+		}
+		e0, e1 := self.Endorses[0], self.Endorses[1]
+		if e0.BlockNum != e1.BlockNum || e0.ForEmpty != e1.ForEmpty {
+			return fmt.Errorf("endorsements not comparable")
+		}
+		if e0.Proposer == e1.Proposer && e0.BlockHash == e1.BlockHash {
+			return fmt.Errorf("endorsements of the same block")
+		}
+		for _, e := range self.Endorses {
+			if err := e.Verify(pub); err != nil {
+				return fmt.Errorf("verify endorse proof: %s", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown evidence type %d", self.Type)
+	}
+	return nil
+}
+
+func sameProposalContext(h0, h1 *types.Header) bool {
+	return h0.Version == h1.Version && h0.PrevBlockHash == h1.PrevBlockHash && h0.Timestamp == h1.Timestamp &&
+		h0.NextBookkeeper == h1.NextBookkeeper && string(h0.ConsensusPayload) == string(h1.ConsensusPayload)
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func signedHeader(t *testing.T, acc *account.Account, proposer uint32, timestamp uint32, nonce uint64) *types.Header {
+	payload, err := json.Marshal(&VbftBlockInfo{Proposer: proposer})
+	assert.Nil(t, err)
+	header := &types.Header{
+		Height:           100,
+		Timestamp:        timestamp,
+		ConsensusData:    nonce,
+		ConsensusPayload: payload,
+	}
+	hash := header.Hash()
+	sig, err := signature.Sign(acc, hash[:])
+	assert.Nil(t, err)
+	header.SigData = [][]byte{sig}
+	return header
+}
+
+func endorseProof(t *testing.T, acc *account.Account, proposer uint32, hash common.Uint256) *EndorseProof {
+	digest := EndorseDigest(100, proposer, hash, false)
+	sig, err := signature.Sign(acc, digest[:])
+	assert.Nil(t, err)
+	return &EndorseProof{BlockNum: 100, Proposer: proposer, BlockHash: hash, Sig: sig}
+}
+
+func TestEvidenceSerialization(t *testing.T) {
+	acc := account.NewAccount("")
+	evidence := &Evidence{
+		Type:      EVIDENCE_ENDORSE,
+		Offender:  PubkeyID(acc.PublicKey),
+		Proposals: []*types.Header{signedHeader(t, acc, 1, 1, 1)},
+		Endorses:  []*EndorseProof{endorseProof(t, acc, 1, common.Uint256{1}), endorseProof(t, acc, 2, common.Uint256{2})},
+	}
+	evidence2 := &Evidence{}
+	err := evidence2.Deserialization(common.NewZeroCopySource(common.SerializeToBytes(evidence)))
+	assert.Nil(t, err)
+	assert.Equal(t, common.SerializeToBytes(evidence), common.SerializeToBytes(evidence2))
+	assert.Equal(t, uint32(100), evidence2.BlockNum())
+}
+
+func TestEvidenceVerifyProposal(t *testing.T) {
+	acc := account.NewAccount("")
+	offender := PubkeyID(acc.PublicKey)
+
+	// the block and empty block of one proposal
+	block, empty := signedHeader(t, acc, 1, 1, 1), signedHeader(t, acc, 1, 1, 2)
+	evidence := &Evidence{Type: EVIDENCE_PROPOSAL, Offender: offender, Proposals: []*types.Header{block, empty}}
+	assert.NotNil(t, evidence.Verify(1))
+
+	evidence.Proposals = append(evidence.Proposals, signedHeader(t, acc, 1, 1, 3))
+	assert.Nil(t, evidence.Verify(1))
+	assert.NotNil(t, evidence.Verify(2))
+
+	evidence.Proposals = []*types.Header{block, signedHeader(t, acc, 1, 2, 1)}
+	assert.Nil(t, evidence.Verify(1))
+
+	evidence.Proposals = []*types.Header{block, block}
+	assert.NotNil(t, evidence.Verify(1))
+
+	other := account.NewAccount("")
+	evidence.Proposals = []*types.Header{block, signedHeader(t, other, 1, 2, 1)}
+	assert.NotNil(t, evidence.Verify(1))
+}
+
+func TestEvidenceVerifyEndorse(t *testing.T) {
+	acc := account.NewAccount("")
+	evidence := &Evidence{
+		Type:     EVIDENCE_ENDORSE,
+		Offender: PubkeyID(acc.PublicKey),
+		Endorses: []*EndorseProof{endorseProof(t, acc, 1, common.Uint256{1}), endorseProof(t, acc, 2, common.Uint256{2})},
+	}
+	assert.Nil(t, evidence.Verify(3))
+
+	evidence.Endorses[1] = endorseProof(t, acc, 1, common.Uint256{1})
+	assert.NotNil(t, evidence.Verify(3))
+
+	evidence.Endorses[1] = endorseProof(t, acc, 2, common.Uint256{2})
+	evidence.Endorses[1].ForEmpty = true
+	assert.NotNil(t, evidence.Verify(3))
+
+	other := account.NewAccount("")
+	evidence.Endorses[1] = endorseProof(t, other, 2, common.Uint256{2})
+	assert.NotNil(t, evidence.Verify(3))
+
+	var pub keypair.PublicKey = acc.PublicKey
+	assert.Nil(t, evidence.Endorses[0].Verify(pub))
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"sync"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/core/utils"
+	gover "github.com/ontio/ontology/smartcontract/service/native/governance"
+	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+// EvidencePool keeps the equivocations detected by the server. The faulty reports are attached
+// to the endorse and commit msgs of the block, and the evidence transactions are packed into
+// the proposals of the server until they are sealed.
+type EvidencePool struct {
+	lock       sync.Mutex
+	historyLen uint32
+	proposals  map[uint32][]*FaultyReport            // faulty proposers, indexed by blockNum
+	endorses   map[uint32][]*FaultyReport            // faulty endorsers, indexed by blockNum
+	offenders  map[string]bool                       // offenders had been reported
+	txs        map[common.Uint256]*types.Transaction // pending evidence transactions
+}
+
+func newEvidencePool(historyLen uint32) *EvidencePool {
+	return &EvidencePool{
+		historyLen: historyLen,
+		proposals:  make(map[uint32][]*FaultyReport),
+		endorses:   make(map[uint32][]*FaultyReport),
+		offenders:  make(map[string]bool),
+		txs:        make(map[common.Uint256]*types.Transaction),
+	}
+}
+
+func (pool *EvidencePool) addEvidence(evidence *vconfig.Evidence, report *FaultyReport, tx *types.Transaction) bool {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	if pool.offenders[evidence.Offender] {
+		return false
+	}
+	pool.offenders[evidence.Offender] = true
+	blkNum := evidence.BlockNum()
+	if evidence.Type == vconfig.EVIDENCE_PROPOSAL {
+		pool.proposals[blkNum] = append(pool.proposals[blkNum], report)
+	} else {
+		pool.endorses[blkNum] = append(pool.endorses[blkNum], report)
+	}
+	pool.txs[tx.Hash()] = tx
+	return true
+}
+
+func (pool *EvidencePool) getFaultyProposals(blkNum uint32) []*FaultyReport {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	return pool.proposals[blkNum]
+}
+
+func (pool *EvidencePool) getFaultyEndorses(blkNum uint32) []*FaultyReport {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	return pool.endorses[blkNum]
+}
+
+func (pool *EvidencePool) getPendingTxs() []*types.Transaction {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	txs := make([]*types.Transaction, 0, len(pool.txs))
+	for _, tx := range pool.txs {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// remove the evidence transactions packed in the block, and the reports out of history
+func (pool *EvidencePool) onBlockSealed(block *types.Block) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	for _, tx := range block.Transactions {
+		delete(pool.txs, tx.Hash())
+	}
+	blkNum := block.Header.Height
+	if blkNum <= pool.historyLen {
+		return
+	}
+	for n := range pool.proposals {
+		if n < blkNum-pool.historyLen {
+			delete(pool.proposals, n)
+		}
+	}
+	for n := range pool.endorses {
+		if n < blkNum-pool.historyLen {
+			delete(pool.endorses, n)
+		}
+	}
+}
+
+// check the conflicting proposal from the same proposer, which had been rejected by block pool
+func (self *Server) onFaultyProposal(msg *blockProposalMsg) {
+	proposer := msg.Block.getProposer()
+	pk := self.peerPool.GetPeerPubKey(proposer)
+	if pk == nil {
+		return
+	}
+	for _, p := range self.blockPool.getBlockProposals(msg.GetBlockNum()) {
+		if p.Block.getProposer() != proposer {
+			continue
+		}
+		headers := proposalEvidenceHeaders(p, msg)
+		evidence := &vconfig.Evidence{
+			Type:     vconfig.EVIDENCE_PROPOSAL,
+			Offender: vconfig.PubkeyID(pk),
+		}
+		// two blocks suffice if they are not of the same proposal, otherwise three headers are needed
+		for _, n := range []int{2, 3} {
+			if len(headers) < n {
+				break
+			}
+			evidence.Proposals = headers[:n]
+			if evidence.Verify(proposer) == nil {
+				break
+			}
+			evidence.Proposals = nil
+		}
+		if len(evidence.Proposals) == 0 {
+			log.Warnf("server %d: proposer %d re-signed proposal of block %d", self.Index, proposer, msg.GetBlockNum())
+			return
+		}
+		msgHash, _ := HashMsg(msg)
+		self.reportEvidence(evidence, proposer, msgHash)
+		return
+	}
+}
+
+// check the endorsement conflicts with the endorsements in msg pool from the same endorser
+func (self *Server) onEndorseMsg(msg *blockEndorseMsg) {
+	if len(msg.EndorseProof) == 0 {
+		return
+	}
+	pk := self.peerPool.GetPeerPubKey(msg.Endorser)
+	if pk == nil {
+		return
+	}
+	proof := msg.endorseProof()
+	if err := proof.Verify(pk); err != nil {
+		log.Errorf("server %d: invalid endorse proof from %d: %s", self.Index, msg.Endorser, err)
+		return
+	}
+	for _, m := range self.msgPool.GetEndorsementsMsgs(msg.GetBlockNum()) {
+		e, ok := m.(*blockEndorseMsg)
+		if !ok || e.Endorser != msg.Endorser || e.EndorseForEmpty != msg.EndorseForEmpty || len(e.EndorseProof) == 0 {
+			continue
+		}
+		if e.EndorsedProposer == msg.EndorsedProposer && e.EndorsedBlockHash == msg.EndorsedBlockHash {
+			continue
+		}
+		prev := e.endorseProof()
+		if err := prev.Verify(pk); err != nil {
+			continue
+		}
+		evidence := &vconfig.Evidence{
+			Type:     vconfig.EVIDENCE_ENDORSE,
+			Offender: vconfig.PubkeyID(pk),
+			Endorses: []*vconfig.EndorseProof{prev, proof},
+		}
+		msgHash, _ := HashMsg(msg)
+		self.reportEvidence(evidence, msg.Endorser, msgHash)
+		return
+	}
+}
+
+func (self *Server) reportEvidence(evidence *vconfig.Evidence, faultyID uint32, msgHash common.Uint256) {
+	tx, err := self.createEvidenceTransaction(evidence)
+	if err != nil {
+		log.Errorf("server %d: failed to create evidence transaction: %s", self.Index, err)
+		return
+	}
+	report := &FaultyReport{
+		FaultyID:      faultyID,
+		FaultyMsgHash: msgHash,
+	}
+	if self.evidencePool.addEvidence(evidence, report, tx) {
+		log.Warnf("server %d: detected equivocation of %d at block %d, evidence type %d, tx %s",
+			self.Index, faultyID, evidence.BlockNum(), evidence.Type, tx.Hash().ToHexString())
+	}
+}
+
+// createEvidenceTransaction invoke governance native contract reportEquivocation
+func (self *Server) createEvidenceTransaction(evidence *vconfig.Evidence) (*types.Transaction, error) {
+	mutable := utils.BuildNativeTransaction(nutils.GovernanceContractAddress, gover.REPORT_EQUIVOCATION,
+		common.SerializeToBytes(evidence))
+	mutable.Nonce = evidence.BlockNum()
+	mutable.Payer = self.account.Address
+	txHash := mutable.Hash()
+	sig, err := signature.Sign(self.account, txHash[:])
+	if err != nil {
+		return nil, err
+	}
+	mutable.Sigs = []types.Sig{{
+		PubKeys: []keypair.PublicKey{self.account.PublicKey},
+		M:       1,
+		SigData: [][]byte{sig},
+	}}
+	return mutable.IntoImmutable()
+}
+
+// collect the distinct headers signed by the proposer in two proposals, the headers of the
+// blocks go first, so that they are checked before the empty blocks
+func proposalEvidenceHeaders(p1, p2 *blockProposalMsg) []*types.Header {
+	var headers []*types.Header
+	hashes := make(map[common.Uint256]bool)
+	add := func(block *types.Block, sig []byte) {
+		if block == nil || len(sig) == 0 || hashes[block.Hash()] {
+			return
+		}
+		hashes[block.Hash()] = true
+		header := *block.Header
+		header.Bookkeepers = nil
+		header.SigData = [][]byte{sig}
+		headers = append(headers, &header)
+	}
+	add(p1.Block.Block, p1.BlockProposerSig)
+	add(p2.Block.Block, p2.BlockProposerSig)
+	add(p1.Block.EmptyBlock, p1.EmptyBlockProposerSig)
+	add(p2.Block.EmptyBlock, p2.EmptyBlockProposerSig)
+	return headers
+}
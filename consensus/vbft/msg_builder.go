@@ -21,13 +21,11 @@ package vbft
 import (
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/log"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
-	"github.com/ontio/ontology/core/ledger"
 	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/types"
 )
@@ -196,7 +194,7 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 	}
 
 	txRoot := common.ComputeMerkleRoot(txHash)
-	blockRoot := ledger.DefLedger.GetBlockRootWithNewTxRoots(lastBlock.Block.Header.Height, []common.Uint256{lastBlock.Block.Header.TransactionsRoot, txRoot})
+	blockRoot := self.ledger.GetBlockRootWithNewTxRoots(lastBlock.Block.Header.Height, []common.Uint256{lastBlock.Block.Header.TransactionsRoot, txRoot})
 
 	blkHeader := &types.Header{
 		PrevBlockHash:    prevBlkHash,
@@ -211,15 +209,18 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 		Header:       blkHeader,
 		Transactions: txs,
 	}
+	return blk, nil
+}
+
+func (self *Server) signBlock(blk *types.Block) error {
 	blkHash := blk.Hash()
 	sig, err := signature.Sign(self.account, blkHash[:])
 	if err != nil {
-		return nil, fmt.Errorf("sign block failed, block hash:%s, error: %s", blkHash.ToHexString(), err)
+		return fmt.Errorf("sign block failed, block hash:%s, error: %s", blkHash.ToHexString(), err)
 	}
-	blkHeader.Bookkeepers = []keypair.PublicKey{self.account.PublicKey}
-	blkHeader.SigData = [][]byte{sig}
-
-	return blk, nil
+	blk.Header.Bookkeepers = []keypair.PublicKey{self.account.PublicKey}
+	blk.Header.SigData = [][]byte{sig}
+	return nil
 }
 
 func (self *Server) constructCrossChainMsg(blkNum uint32) (*types.CrossChainMsg, error) {
@@ -251,7 +252,7 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 	if prevBlk == nil {
 		return nil, fmt.Errorf("failed to get prevBlock (%d)", blkNum-1)
 	}
-	blocktimestamp := uint32(time.Now().Unix())
+	blocktimestamp := uint32(self.clock.Now().Unix())
 	if prevBlk.Block.Header.Timestamp >= blocktimestamp {
 		blocktimestamp = prevBlk.Block.Header.Timestamp + 1
 	}
@@ -288,6 +289,15 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 	if err != nil {
 		return nil, fmt.Errorf("failed to constuct blk: %s", err)
 	}
+	if err := self.signGuard.checkProposal(blkNum, blk.Hash(), emptyBlk.Hash()); err != nil {
+		return nil, fmt.Errorf("refuse to sign proposal: %s", err)
+	}
+	if err := self.signBlock(emptyBlk); err != nil {
+		return nil, err
+	}
+	if err := self.signBlock(blk); err != nil {
+		return nil, err
+	}
 	merkleRoot, err := self.blockPool.getExecMerkleRoot(blkNum - 1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to GetExecMerkleRoot: %s,blkNum:%d", err, blkNum-1)
@@ -309,9 +319,6 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 }
 
 func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty bool) (*blockEndorseMsg, error) {
-
-	// TODO, support faultyMsg reporting
-
 	var proposerSig, endorserSig []byte
 	var blkHash common.Uint256
 	var err error
@@ -328,19 +335,31 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 		proposerSig = proposal.EmptyBlockProposerSig
 		blkHash = proposal.Block.EmptyBlock.Hash()
 	}
+	err = self.signGuard.checkEndorse(proposal.Block.getBlockNum(), proposal.Block.getProposer(), blkHash, forEmpty)
+	if err != nil {
+		return nil, fmt.Errorf("refuse to sign endorsement: %s", err)
+	}
 	endorserSig, err = signature.Sign(self.account, blkHash[:])
 	if err != nil {
 		return nil, fmt.Errorf("endorser failed to sign block. hash:%x, err: %s", blkHash, err)
 	}
 
+	digest := vconfig.EndorseDigest(proposal.Block.getBlockNum(), proposal.Block.getProposer(), blkHash, forEmpty)
+	endorseProof, err := signature.Sign(self.account, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("endorser failed to sign endorse proof. hash:%x, err: %s", blkHash, err)
+	}
+
 	msg := &blockEndorseMsg{
 		Endorser:          self.Index,
 		EndorsedProposer:  proposal.Block.getProposer(),
 		BlockNum:          proposal.Block.getBlockNum(),
 		EndorsedBlockHash: blkHash,
 		EndorseForEmpty:   forEmpty,
+		FaultyProposals:   self.evidencePool.getFaultyProposals(proposal.Block.getBlockNum()),
 		ProposerSig:       proposerSig,
 		EndorserSig:       endorserSig,
+		EndorseProof:      endorseProof,
 	}
 	if proposal.Block.CrossChainMsg != nil {
 		hash := proposal.Block.CrossChainMsg.Hash()
@@ -355,9 +374,6 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 }
 
 func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*blockEndorseMsg, forEmpty bool) (*blockCommitMsg, error) {
-
-	// TODO, support faultyMsg reporting
-
 	var proposerSig, committerSig []byte
 	var blkHash common.Uint256
 	var err error
@@ -403,6 +419,7 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 		BlockNum:                  proposal.Block.getBlockNum(),
 		CommitBlockHash:           blkHash,
 		CommitForEmpty:            forEmpty,
+		FaultyVerifies:            self.evidencePool.getFaultyEndorses(proposal.Block.getBlockNum()),
 		ProposerSig:               proposerSig,
 		EndorsersSig:              endorsersSig,
 		CommitterSig:              committerSig,
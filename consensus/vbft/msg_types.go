@@ -118,6 +118,17 @@ func (msg *blockProposalMsg) GetBlockNum() uint32 {
 	return msg.Block.Block.Header.Height
 }
 
+// blockHash returns the hash of the proposed block, or the empty block if forEmpty
+func (msg *blockProposalMsg) blockHash(forEmpty bool) common.Uint256 {
+	if !forEmpty {
+		return msg.Block.Block.Hash()
+	}
+	if msg.Block.EmptyBlock == nil {
+		return common.UINT256_EMPTY
+	}
+	return msg.Block.EmptyBlock.Hash()
+}
+
 func (msg *blockProposalMsg) Serialize() ([]byte, error) {
 	return msg.Block.Serialize(), nil
 }
@@ -158,6 +169,7 @@ type blockEndorseMsg struct {
 	EndorserSig              []byte          `json:"endorser_sig"`
 	CrossChainMsgHash        common.Uint256  `json:"cross_chain_msg_hash"`
 	CrossChainMsgEndorserSig []byte          `json:"cross_chain_msg_endorser_sig"`
+	EndorseProof             []byte          `json:"endorse_proof,omitempty"`
 }
 
 func (msg *blockEndorseMsg) Type() MsgType {
@@ -173,6 +185,11 @@ func (msg *blockEndorseMsg) Verify(pub keypair.PublicKey) error {
 	if !signature.Verify(pub, hash[:], sig) {
 		return fmt.Errorf("failed to verify block sig")
 	}
+	if len(msg.EndorseProof) != 0 {
+		if err := msg.endorseProof().Verify(pub); err != nil {
+			return fmt.Errorf("failed to verify endorse proof: %s", err)
+		}
+	}
 	if msg.CrossChainMsgEndorserSig != nil {
 		//verify cross states endorse sig
 		cSig, err := signature.Deserialize(msg.CrossChainMsgEndorserSig)
@@ -186,6 +203,16 @@ func (msg *blockEndorseMsg) Verify(pub keypair.PublicKey) error {
 	return nil
 }
 
+func (msg *blockEndorseMsg) endorseProof() *vconfig.EndorseProof {
+	return &vconfig.EndorseProof{
+		BlockNum:  msg.BlockNum,
+		Proposer:  msg.EndorsedProposer,
+		BlockHash: msg.EndorsedBlockHash,
+		ForEmpty:  msg.EndorseForEmpty,
+		Sig:       msg.EndorseProof,
+	}
+}
+
 func (msg *blockEndorseMsg) GetBlockNum() uint32 {
 	return msg.BlockNum
 }
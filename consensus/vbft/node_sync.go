@@ -26,7 +26,6 @@ import (
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/log"
-	"github.com/ontio/ontology/core/ledger"
 )
 
 type SyncCheckReq struct {
@@ -176,7 +175,7 @@ func (self *Syncer) run() {
 			for self.nextReqBlkNum <= self.targetBlkNum {
 				// FIXME: compete with ledger syncing
 				var blk *Block
-				if self.nextReqBlkNum <= ledger.DefLedger.GetCurrentBlockHeight() {
+				if self.nextReqBlkNum <= self.server.ledger.GetCurrentBlockHeight() {
 					blk, _ = self.server.blockPool.getSealedBlock(self.nextReqBlkNum)
 				}
 				if blk == nil {
@@ -417,7 +416,10 @@ func (self *PeerSyncer) requestBlock(blkNum uint32) (*Block, error) {
 		Msg:    msg,
 	}
 
-	t := time.NewTimer(time.Duration(atomic.LoadInt64(&makeProposalTimeout) * 2))
+	timeout := make(chan struct{})
+	t := self.server.clock.AfterFunc(time.Duration(atomic.LoadInt64(&makeProposalTimeout)*2), func() {
+		close(timeout)
+	})
 	defer t.Stop()
 
 	select {
@@ -433,7 +435,7 @@ func (self *PeerSyncer) requestBlock(blkNum uint32) (*Block, error) {
 			}
 			return pMsg.BlockData, nil
 		}
-	case <-t.C:
+	case <-timeout:
 		return nil, fmt.Errorf("timeout fetch block %d from peer %d", blkNum, self.peerIdx)
 	case <-self.server.quitC:
 		return nil, fmt.Errorf("peer syncing %d quit, failed fetching Block %d", self.peerIdx, blkNum)
@@ -448,7 +450,10 @@ func (self *PeerSyncer) requestBlockInfo(startBlkNum uint32) ([]*BlockInfo_, err
 		Msg:    msg,
 	}
 
-	t := time.NewTimer(time.Duration(atomic.LoadInt64(&makeProposalTimeout) * 2))
+	timeout := make(chan struct{})
+	t := self.server.clock.AfterFunc(time.Duration(atomic.LoadInt64(&makeProposalTimeout)*2), func() {
+		close(timeout)
+	})
 	defer t.Stop()
 
 	select {
@@ -464,7 +469,7 @@ func (self *PeerSyncer) requestBlockInfo(startBlkNum uint32) ([]*BlockInfo_, err
 			}
 			return pMsg.Blocks, nil
 		}
-	case <-t.C:
+	case <-timeout:
 		return nil, fmt.Errorf("timeout fetch blockInfo %d from peer %d", startBlkNum, self.peerIdx)
 	case <-self.server.quitC:
 		return nil, fmt.Errorf("peer syncer %d - %d quit, failed fetching BlockInfo %d",
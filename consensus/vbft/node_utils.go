@@ -369,10 +369,10 @@ func calcParticipant(vrf vconfig.VRFValue, dposTable []uint32, k uint32) uint32
 //		@ consensused proposer
 //		@ consensused for empty commit
 //
-func getCommitConsensus(commitMsgs []*blockCommitMsg, C int, N int) (uint32, bool) {
+func getCommitConsensus(commitMsgs []*blockCommitMsg, C int, N int) (uint32, common.Uint256, bool) {
 	emptyCommitCount := 0
 	emptyCommit := false
-	signCount := make(map[uint32]map[uint32]int)
+	signCount := make(map[endorsedBlock]map[uint32]int)
 	for _, c := range commitMsgs {
 		if c.CommitForEmpty {
 			emptyCommitCount++
@@ -381,31 +381,34 @@ func getCommitConsensus(commitMsgs []*blockCommitMsg, C int, N int) (uint32, boo
 				emptyCommit = true
 			}
 		}
-		if _, present := signCount[c.BlockProposer]; !present {
-			signCount[c.BlockProposer] = make(map[uint32]int)
+		blk := endorsedBlock{proposer: c.BlockProposer, hash: c.CommitBlockHash}
+		if _, present := signCount[blk]; !present {
+			signCount[blk] = make(map[uint32]int)
 		}
-		signCount[c.BlockProposer][c.Committer] += 1
+		signCount[blk][c.Committer] += 1
 		for endorser := range c.EndorsersSig {
-			signCount[c.BlockProposer][endorser] += 1
+			signCount[blk][endorser] += 1
 		}
-		if len(signCount[c.BlockProposer])+1 >= N-(N-1)/3 {
-			return c.BlockProposer, emptyCommit
+		if len(signCount[blk])+1 >= N-(N-1)/3 {
+			return c.BlockProposer, c.CommitBlockHash, emptyCommit
 		}
 	}
 
-	return math.MaxUint32, false
+	return math.MaxUint32, common.UINT256_EMPTY, false
 }
 
-func (self *Server) findBlockProposal(blkNum uint32, proposer uint32, forEmpty bool) *blockProposalMsg {
+// findBlockProposal returns the proposal of the proposer whose block, or empty block if forEmpty,
+// has the consensused hash
+func (self *Server) findBlockProposal(blkNum uint32, proposer uint32, blkHash common.Uint256, forEmpty bool) *blockProposalMsg {
 	for _, p := range self.blockPool.getBlockProposals(blkNum) {
-		if p.Block.getProposer() == proposer {
+		if p.Block.getProposer() == proposer && p.blockHash(forEmpty) == blkHash {
 			return p
 		}
 	}
 
 	for _, p := range self.msgPool.GetProposalMsgs(blkNum) {
 		if pMsg := p.(*blockProposalMsg); pMsg != nil {
-			if pMsg.Block.getProposer() == proposer {
+			if pMsg.Block.getProposer() == proposer && pMsg.blockHash(forEmpty) == blkHash {
 				return pMsg
 			}
 		}
@@ -22,6 +22,7 @@ import (
 	"crypto/rand"
 	"crypto/sha512"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/ontio/ontology/common"
@@ -65,6 +66,7 @@ func constructServer() *Server {
 	}
 	server := &Server{
 		Index:                    1,
+		clock:                    systemClock{},
 		stateMgr:                 statemgr,
 		config:                   chainconfig,
 		chainStore:               chainstore,
@@ -149,10 +151,36 @@ func TestGetCommitConsensus(t *testing.T) {
 	}
 	var commitMsgs []*blockCommitMsg
 	commitMsgs = append(commitMsgs, blockcommitmsg)
-	blockproposer, flag := getCommitConsensus(commitMsgs, 2, 7)
+	blockproposer, _, flag := getCommitConsensus(commitMsgs, 2, 7)
 	t.Logf("TestGetCommitConsensus %d ,%v", blockproposer, flag)
 }
 
+func TestGetCommitConsensusConflictingBlocks(t *testing.T) {
+	// the proposer signed two blocks, each is committed by a part of the peers
+	blkA := common.Uint256{1}
+	blkB := common.Uint256{2}
+	var commitMsgs []*blockCommitMsg
+	for committer := uint32(0); committer < 7; committer++ {
+		hash := blkA
+		if committer%2 == 1 {
+			hash = blkB
+		}
+		commitMsgs = append(commitMsgs, &blockCommitMsg{
+			Committer:       committer,
+			BlockProposer:   4,
+			BlockNum:        2,
+			CommitBlockHash: hash,
+		})
+		proposer, blkHash, _ := getCommitConsensus(commitMsgs, 2, 7)
+		if committer < 6 && proposer != math.MaxUint32 {
+			t.Fatalf("consensused on %x by %d conflicting commits", blkHash, len(commitMsgs))
+		}
+		if committer == 6 && (proposer != 4 || blkHash != blkA) {
+			t.Fatalf("expect consensus on block A of proposer 4, got %d %x", proposer, blkHash)
+		}
+	}
+}
+
 func newTestVrfValue() vconfig.VRFValue {
 	v := make([]byte, 1024)
 	rand.Read(v[:])
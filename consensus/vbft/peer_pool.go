@@ -156,15 +156,16 @@ func (pool *PeerPool) peerDisconnected(peerIdx uint32) {
 	pool.lock.Lock()
 	defer pool.lock.Unlock()
 
-	var lastUpdateTime time.Time
-	if p, present := pool.peers[peerIdx]; present {
-		lastUpdateTime = p.LastUpdateTime
+	p, present := pool.peers[peerIdx]
+	if !present {
+		// peer pool has been cleaned when server stopped
+		return
 	}
 
 	pool.peers[peerIdx] = &Peer{
 		Index:          peerIdx,
-		PubKey:         pool.peers[peerIdx].PubKey,
-		LastUpdateTime: lastUpdateTime,
+		PubKey:         p.PubKey,
+		LastUpdateTime: p.LastUpdateTime,
 		connected:      false,
 	}
 }
@@ -22,6 +22,7 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
+	sysconfig "github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	actorTypes "github.com/ontio/ontology/consensus/actor"
 	vconfig "github.com/ontio/ontology/consensus/vbft/config"
@@ -45,6 +47,7 @@ import (
 	gover "github.com/ontio/ontology/smartcontract/service/native/governance"
 	ninit "github.com/ontio/ontology/smartcontract/service/native/init"
 	nutils "github.com/ontio/ontology/smartcontract/service/native/utils"
+	txpool "github.com/ontio/ontology/txnpool/common"
 	"github.com/ontio/ontology/validator/increment"
 )
 
@@ -87,12 +90,19 @@ type p2pMsgPayload struct {
 	payload  *p2pmsg.ConsensusPayload
 }
 
+//TxPool is the part of the tx pool actor used by the consensus
+type TxPool interface {
+	GetTxnPool(byCount bool, height uint32) []*txpool.VerifiedTx
+	VerifyBlock(txs []*types.Transaction, height uint32) error
+}
+
 type Server struct {
 	Index         uint32
 	account       *account.Account
-	poolActor     *actorTypes.TxPoolActor
+	poolActor     TxPool
 	p2p           p2p.P2P
 	ledger        *ledger.Ledger
+	clock         Clock
 	incrValidator *increment.IncrementValidator
 	pid           *actor.PID
 
@@ -112,13 +122,15 @@ type Server struct {
 	config                   *vconfig.ChainConfig
 	currentParticipantConfig *BlockParticipantConfig
 
-	chainStore *ChainStore // block store
-	msgPool    *MsgPool    // consensus msg pool
-	blockPool  *BlockPool  // received block proposals
-	peerPool   *PeerPool   // consensus peers
-	syncer     *Syncer
-	stateMgr   *StateMgr
-	timer      *EventTimer
+	chainStore   *ChainStore   // block store
+	msgPool      *MsgPool      // consensus msg pool
+	evidencePool *EvidencePool // faulty behaviours detected
+	signGuard    *signGuard    // proposals and endorsements signed
+	blockPool    *BlockPool    // received block proposals
+	peerPool     *PeerPool     // consensus peers
+	syncer       *Syncer
+	stateMgr     *StateMgr
+	timer        *EventTimer
 
 	msgRecvC   *sync.Map // map[uint32]chan *p2pMsgPayload
 	msgC       chan ConsensusMsg
@@ -131,30 +143,50 @@ type Server struct {
 }
 
 func NewVbftServer(account *account.Account, txpool *actor.PID, p2p p2p.P2P) (*Server, error) {
+	server, err := newVbftServer("consensus_vbft", account, &actorTypes.TxPoolActor{Pool: txpool}, ledger.DefLedger,
+		p2p, systemClock{})
+	if err != nil {
+		return nil, err
+	}
+	server.sub = events.NewActorSubscriber(server.pid)
+	// the signed blocks are persisted, so that the node never signs conflicting blocks after restart
+	server.signGuard, err = newSignGuard(filepath.Join(sysconfig.DefConfig.Common.DataDir,
+		sysconfig.DefConfig.P2PNode.NetworkName, SIGN_GUARD_FILE_NAME))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := server.initialize(); err != nil {
+		return nil, fmt.Errorf("vbft server start failed: %s", err)
+	}
+	return server, nil
+}
+
+//newVbftServer creates the server on the ledger without subscribing the ledger events, the server
+//learns the sealed blocks from its chain store, so that several servers can run in one process.
+func newVbftServer(name string, account *account.Account, pool TxPool, ledger *ledger.Ledger, p2p p2p.P2P,
+	clock Clock) (*Server, error) {
 	server := &Server{
 		msgHistoryDuration: 64,
 		account:            account,
-		poolActor:          &actorTypes.TxPoolActor{Pool: txpool},
+		poolActor:          pool,
 		p2p:                p2p,
-		ledger:             ledger.DefLedger,
+		ledger:             ledger,
+		clock:              clock,
 		incrValidator:      increment.NewIncrementValidator(20),
 	}
 	server.stateMgr = newStateMgr(server)
+	server.signGuard, _ = newSignGuard("")
 
 	props := actor.FromProducer(func() actor.Actor {
 		return server
 	})
 
-	pid, err := actor.SpawnNamed(props, "consensus_vbft")
+	pid, err := actor.SpawnNamed(props, name)
 	if err != nil {
 		return nil, err
 	}
 	server.pid = pid
-	server.sub = events.NewActorSubscriber(pid)
-
-	if err := server.initialize(); err != nil {
-		return nil, fmt.Errorf("vbft server start failed: %s", err)
-	}
 	return server, nil
 }
 
@@ -213,6 +245,7 @@ func (self *Server) handleBlockPersistCompleted(block *types.Block) {
 	}
 	self.SetCompletedBlockNum(block.Header.Height)
 	self.incrValidator.AddBlock(block)
+	self.evidencePool.onBlockSealed(block)
 	if self.nonConsensusNode() {
 		self.blockPool.ReloadFromLedger()
 		if self.GetCommittedBlockNo() >= self.GetCurrentBlockNo() {
@@ -440,6 +473,7 @@ func (self *Server) initialize() error {
 		return fmt.Errorf("init blockpool: %s", err)
 	}
 	self.msgPool = newMsgPool(self, self.msgHistoryDuration)
+	self.evidencePool = newEvidencePool(self.msgHistoryDuration)
 	self.peerPool = NewPeerPool(0, self) // FIXME: maxSize
 	self.timer = NewEventTimer(self)
 	self.syncer = newSyncer(self)
@@ -479,7 +513,9 @@ func (self *Server) initialize() error {
 	} else {
 		self.Index = math.MaxUint32
 	}
-	self.sub.Subscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	if self.sub != nil {
+		self.sub.Subscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	}
 	go self.syncer.run()
 	go self.stateMgr.run()
 	go self.msgSendLoop()
@@ -539,7 +575,9 @@ func (self *Server) start() error {
 func (self *Server) stop() {
 
 	self.incrValidator.Clean()
-	self.sub.Unsubscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	if self.sub != nil {
+		self.sub.Unsubscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	}
 	// stop syncer, statemgr, msgSendLoop, timer, actionLoop, msgProcessingLoop
 	self.quit = true
 	close(self.quitC)
@@ -711,14 +749,14 @@ func (self *Server) startNewRound() error {
 	}
 
 	chainCfg := self.GetChainConfig()
-	if _, _, done := self.blockPool.commitDone(blkNum, chainCfg.C, chainCfg.N); done && len(commits) > 0 {
+	if _, _, _, done := self.blockPool.commitDone(blkNum, chainCfg.C, chainCfg.N); done && len(commits) > 0 {
 		// resend commit msg to msg-processor to restart commit-done processing
 		// Note: commitDone will set Done flag in block-pool, so removed Done flag checking
 		// in commit msg processing.
 		self.blockPool.setCommitDone(blkNum)
 		self.processConsensusMsg(commits[0])
 		return nil
-	} else if _, _, done := self.blockPool.endorseDone(blkNum, chainCfg.C); done && len(endorses) > 0 {
+	} else if _, _, _, done := self.blockPool.endorseDone(blkNum, chainCfg.C); done && len(endorses) > 0 {
 		// resend endorse msg to msg-processor to restart endorse-done processing
 		self.processConsensusMsg(endorses[0])
 		return nil
@@ -1144,7 +1182,7 @@ func (self *Server) processProposalMsg(msg *blockProposalMsg) {
 
 	prevBlockTimestamp := blk.Block.Header.Timestamp
 	currentBlockTimestamp := msg.Block.Block.Header.Timestamp
-	if currentBlockTimestamp <= prevBlockTimestamp || currentBlockTimestamp > uint32(time.Now().Add(time.Minute*10).Unix()) {
+	if currentBlockTimestamp <= prevBlockTimestamp || currentBlockTimestamp > uint32(self.clock.Now().Add(time.Minute*10).Unix()) {
 		log.Errorf("BlockPrposalMessage check  blocknum:%d,prevBlockTimestamp:%d,currentBlockTimestamp:%d", msg.GetBlockNum(), prevBlockTimestamp, currentBlockTimestamp)
 		self.msgPool.DropMsg(msg)
 		return
@@ -1231,9 +1269,9 @@ func (self *Server) processMsgEvent() error {
 			if msgBlkNum == self.GetCurrentBlockNo() {
 				// add proposal to block-pool
 				if err := self.blockPool.newBlockProposal(pMsg); err != nil {
-					// if err == errDupProposal {
-					// 	// TODO: faulty proposer detected
-					// }
+					if err == errDupProposal {
+						self.onFaultyProposal(pMsg)
+					}
 					log.Errorf("failed to add block proposal (%d): %s", msgBlkNum, err)
 					return nil
 				}
@@ -1285,6 +1323,8 @@ func (self *Server) processMsgEvent() error {
 					self.fetchProposal(msgBlkNum, pMsg.EndorsedProposer)
 				}
 
+				self.onEndorseMsg(pMsg)
+
 				// add endorse to block-pool
 				self.blockPool.newBlockEndorsement(pMsg)
 				log.Infof("server %d received endorse from %d, for proposer %d, block %d, empty: %t",
@@ -1308,12 +1348,12 @@ func (self *Server) processMsgEvent() error {
 					//                      start WaitEndorsementTimer
 
 					// TODO: should only count endorsements from endorsers
-					if proposer, forEmpty, done := self.blockPool.endorseDone(msgBlkNum, self.GetChainConfig().C); done {
+					if proposer, blkHash, forEmpty, done := self.blockPool.endorseDone(msgBlkNum, self.GetChainConfig().C); done {
 						// stop endorse timer
 						self.timer.CancelEndorseMsgTimer(msgBlkNum)
 						// stop empty endorse timer
 						self.timer.CancelEndorseEmptyBlockTimer(msgBlkNum)
-						proposal := self.findBlockProposal(msgBlkNum, proposer, forEmpty)
+						proposal := self.findBlockProposal(msgBlkNum, proposer, blkHash, forEmpty)
 						if proposal == nil {
 							log.Infof("server %d endorse %d done, waiting proposal from %d", self.Index, msgBlkNum, proposer)
 						} else if self.isCommitter(msgBlkNum, self.Index) {
@@ -1361,9 +1401,9 @@ func (self *Server) processMsgEvent() error {
 					self.Index, pMsg.Committer, pMsg.BlockProposer, msgBlkNum, pMsg.CommitForEmpty)
 
 				chainCfg := self.GetChainConfig()
-				if proposer, forEmpty, done := self.blockPool.commitDone(msgBlkNum, chainCfg.C, chainCfg.N); done {
+				if proposer, blkHash, forEmpty, done := self.blockPool.commitDone(msgBlkNum, chainCfg.C, chainCfg.N); done {
 					self.blockPool.setCommitDone(msgBlkNum)
-					proposal := self.findBlockProposal(msgBlkNum, proposer, forEmpty)
+					proposal := self.findBlockProposal(msgBlkNum, proposer, blkHash, forEmpty)
 					if proposal == nil {
 						// TODO: commit done, but we not have the proposal, should request proposal from neighbours
 						//       commitTimeout handle this
@@ -1502,7 +1542,7 @@ func (self *Server) actionLoop() {
 					}
 
 					// check if consensused
-					proposer, forEmpty := getCommitConsensus(commitMsgs, int(chainCfg.C), int(chainCfg.N))
+					proposer, blkHash, forEmpty := getCommitConsensus(commitMsgs, int(chainCfg.C), int(chainCfg.N))
 					if proposer == math.MaxUint32 {
 						if err := self.catchConsensus(blkNum); err != nil {
 							log.Infof("server %d fastforward done, catch consensus: %s", self.Index, err)
@@ -1518,7 +1558,7 @@ func (self *Server) actionLoop() {
 						if !ok {
 							continue
 						}
-						if p.Block.getProposer() == proposer {
+						if p.Block.getProposer() == proposer && p.blockHash(forEmpty) == blkHash {
 							proposal = p
 							break
 						}
@@ -1608,8 +1648,8 @@ func (self *Server) actionLoop() {
 						}
 					}
 					if !committed {
-						if proposer, forEmpty, done := self.blockPool.endorseDone(blkNum, self.GetChainConfig().C); done {
-							proposal := self.findBlockProposal(blkNum, proposer, forEmpty)
+						if proposer, blkHash, forEmpty, done := self.blockPool.endorseDone(blkNum, self.GetChainConfig().C); done {
+							proposal := self.findBlockProposal(blkNum, proposer, blkHash, forEmpty)
 
 							// consensus ok, make endorsement
 							if proposal == nil {
@@ -1737,8 +1777,8 @@ func (self *Server) processTimerEvent(evt *TimerEvent) error {
 		if !isReady(self.getState()) {
 			return nil
 		}
-		if proposer, forEmpty, done := self.blockPool.endorseDone(evt.blockNum, self.GetChainConfig().C); done {
-			proposal := self.findBlockProposal(evt.blockNum, proposer, forEmpty)
+		if proposer, blkHash, forEmpty, done := self.blockPool.endorseDone(evt.blockNum, self.GetChainConfig().C); done {
+			proposal := self.findBlockProposal(evt.blockNum, proposer, blkHash, forEmpty)
 
 			// consensus ok, make endorsement
 			if proposal == nil {
@@ -1795,8 +1835,8 @@ func (self *Server) processTimerEvent(evt *TimerEvent) error {
 		if !isReady(self.getState()) {
 			return nil
 		}
-		if proposer, forEmpty, done := self.blockPool.endorseDone(evt.blockNum, self.GetChainConfig().C); done {
-			proposal := self.findBlockProposal(evt.blockNum, proposer, forEmpty)
+		if proposer, blkHash, forEmpty, done := self.blockPool.endorseDone(evt.blockNum, self.GetChainConfig().C); done {
+			proposal := self.findBlockProposal(evt.blockNum, proposer, blkHash, forEmpty)
 
 			// consensus ok, make endorsement
 			if proposal == nil {
@@ -1841,9 +1881,9 @@ func (self *Server) processTimerEvent(evt *TimerEvent) error {
 		}
 		if !self.blockPool.isCommitHadDone(evt.blockNum) {
 			chainCfg := self.GetChainConfig()
-			if proposer, forEmpty, done := self.blockPool.commitDone(evt.blockNum, chainCfg.C, chainCfg.N); done {
+			if proposer, blkHash, forEmpty, done := self.blockPool.commitDone(evt.blockNum, chainCfg.C, chainCfg.N); done {
 				self.blockPool.setCommitDone(evt.blockNum)
-				proposal := self.findBlockProposal(evt.blockNum, proposer, forEmpty)
+				proposal := self.findBlockProposal(evt.blockNum, proposer, blkHash, forEmpty)
 				if proposal == nil {
 					self.restartSyncing()
 					return fmt.Errorf("commit timeout, consensused proposal not available. need resync")
@@ -2176,7 +2216,7 @@ func (self *Server) checkNeedUpdateChainConfig(blockNum uint32) bool {
 
 //checkUpdateChainConfig query leveldb check is force update
 func (self *Server) checkUpdateChainConfig(blkNum uint32) bool {
-	force, err := isUpdate(self.blockPool.getExecWriteSet(blkNum-1), self.GetChainConfig().View)
+	force, err := isUpdate(self.blockPool.getExecWriteSet(blkNum-1), self.ledger, self.GetChainConfig().View)
 	if err != nil {
 		log.Errorf("checkUpdateChainConfig err:%s", err)
 		return false
@@ -2224,7 +2264,7 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 	//check need upate chainconfig
 	var cfg *vconfig.ChainConfig
 	if self.checkNeedUpdateChainConfig(blkNum) || self.checkUpdateChainConfig(blkNum) {
-		chainconfig, err := getChainConfig(self.blockPool.getExecWriteSet(blkNum-1), blkNum)
+		chainconfig, err := getChainConfig(self.blockPool.getExecWriteSet(blkNum-1), self.ledger, blkNum)
 		if err != nil {
 			return fmt.Errorf("getChainConfig failed:%s", err)
 		}
@@ -2243,9 +2283,22 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 	if self.nonConsensusNode() {
 		return fmt.Errorf("%d quit consensus node", self.Index)
 	}
+	for _, msg := range self.msgPool.GetProposalMsgs(blkNum) {
+		if p := msg.(*blockProposalMsg); p.Block.getProposer() == self.Index {
+			// proposing another block for the same height would be reported as equivocation
+			return fmt.Errorf("server %d already proposed block %d", self.Index, blkNum)
+		}
+	}
 
 	if !forEmpty {
 		nonceCtx := make(map[common.Address]uint64)
+		if blkNum >= sysconfig.GetEquivocationHeight() {
+			for _, tx := range self.evidencePool.getPendingTxs() {
+				if err := self.incrValidator.Verify(tx, validHeight, nonceCtx); err == nil {
+					userTxs = append(userTxs, tx)
+				}
+			}
+		}
 		for _, e := range self.poolActor.GetTxnPool(true, validHeight) {
 			if err := self.incrValidator.Verify(e.Tx, validHeight, nonceCtx); err == nil {
 				userTxs = append(userTxs, e.Tx)
@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/ontio/ontology/common"
+)
+
+// file of the sign guard in the data dir of the network
+const SIGN_GUARD_FILE_NAME = "vbft.sign"
+
+// number of the recent block heights kept by the sign guard, the server refuses to sign the
+// blocks lower than them
+const SIGN_GUARD_HISTORY = 64
+
+type signRole byte
+
+const (
+	signProposal signRole = iota
+	signEndorse
+	signEndorseEmpty
+	signRoleCount
+)
+
+// signRecord is the blocks signed by the server for a height in a role. For the proposal the
+// hashes are the proposed block and empty block, for the endorsement the hash is the endorsed
+// block of the proposer
+type signRecord struct {
+	BlockNum  uint32
+	Proposer  uint32
+	BlockHash common.Uint256
+	EmptyHash common.Uint256
+}
+
+// signGuard remembers the proposals and endorsements signed by the server, and refuses to sign
+// the conflicting ones which would be reported as equivocation. The records are written to the
+// file before signing, so that they survive the restart of the node.
+type signGuard struct {
+	lock    sync.Mutex
+	file    string // the records are kept in memory only if it is empty
+	records [signRoleCount]map[uint32]signRecord
+}
+
+func newSignGuard(file string) (*signGuard, error) {
+	guard := &signGuard{file: file}
+	for i := range guard.records {
+		guard.records[i] = make(map[uint32]signRecord)
+	}
+	if file == "" || !common.FileExisted(file) {
+		return guard, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read sign guard %s: %s", file, err)
+	}
+	source := common.NewZeroCopySource(data)
+	for source.Len() > 0 {
+		role, eof := source.NextByte()
+		blkNum, _ := source.NextUint32()
+		proposer, _ := source.NextUint32()
+		blkHash, _ := source.NextHash()
+		emptyHash, eof2 := source.NextHash()
+		if eof || eof2 || signRole(role) >= signRoleCount {
+			return nil, fmt.Errorf("corrupted sign guard %s", file)
+		}
+		guard.records[role][blkNum] = signRecord{
+			BlockNum:  blkNum,
+			Proposer:  proposer,
+			BlockHash: blkHash,
+			EmptyHash: emptyHash,
+		}
+	}
+	return guard, nil
+}
+
+func (self *signGuard) checkProposal(blkNum uint32, blkHash, emptyHash common.Uint256) error {
+	return self.check(signProposal, signRecord{BlockNum: blkNum, BlockHash: blkHash, EmptyHash: emptyHash})
+}
+
+func (self *signGuard) checkEndorse(blkNum, proposer uint32, blkHash common.Uint256, forEmpty bool) error {
+	role := signEndorse
+	if forEmpty {
+		role = signEndorseEmpty
+	}
+	return self.check(role, signRecord{BlockNum: blkNum, Proposer: proposer, BlockHash: blkHash})
+}
+
+// check return nil if the record does not conflict with the signed ones, the record is saved
+// before return
+func (self *signGuard) check(role signRole, record signRecord) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	records := self.records[role]
+	if signed, present := records[record.BlockNum]; present {
+		if signed != record {
+			return fmt.Errorf("block %d has been signed for %d:%s", record.BlockNum, signed.Proposer,
+				signed.BlockHash.ToHexString())
+		}
+		return nil
+	}
+	for blkNum := range records {
+		if blkNum >= SIGN_GUARD_HISTORY && record.BlockNum <= blkNum-SIGN_GUARD_HISTORY {
+			return fmt.Errorf("block %d is too old to sign, signed block %d", record.BlockNum, blkNum)
+		}
+	}
+
+	records[record.BlockNum] = record
+	if err := self.save(); err != nil {
+		delete(records, record.BlockNum)
+		return err
+	}
+	// the records out of history are removed from the file by the next save
+	for blkNum := range records {
+		if record.BlockNum >= SIGN_GUARD_HISTORY && blkNum <= record.BlockNum-SIGN_GUARD_HISTORY {
+			delete(records, blkNum)
+		}
+	}
+	return nil
+}
+
+// save write the records to a temp file and rename it, so the file is never half written
+func (self *signGuard) save() error {
+	if self.file == "" {
+		return nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	for role, records := range self.records {
+		for _, record := range records {
+			sink.WriteByte(byte(role))
+			sink.WriteUint32(record.BlockNum)
+			sink.WriteUint32(record.Proposer)
+			sink.WriteHash(record.BlockHash)
+			sink.WriteHash(record.EmptyHash)
+		}
+	}
+	tmp := self.file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("save sign guard: %s", err)
+	}
+	if _, err = f.Write(sink.Bytes()); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("save sign guard: %s", err)
+	}
+	if err := os.Rename(tmp, self.file); err != nil {
+		return fmt.Errorf("save sign guard: %s", err)
+	}
+	return nil
+}
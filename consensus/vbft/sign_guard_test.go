@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignGuard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vbft-sign-guard")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, SIGN_GUARD_FILE_NAME)
+
+	blk, other, empty := common.Uint256{1}, common.Uint256{2}, common.Uint256{3}
+	guard, err := newSignGuard(file)
+	assert.Nil(t, err)
+	assert.Nil(t, guard.checkProposal(100, blk, empty))
+	assert.Nil(t, guard.checkProposal(100, blk, empty))
+	assert.NotNil(t, guard.checkProposal(100, other, empty))
+	assert.Nil(t, guard.checkEndorse(100, 1, blk, false))
+	assert.Nil(t, guard.checkEndorse(100, 1, empty, true))
+	assert.NotNil(t, guard.checkEndorse(100, 2, other, false))
+	assert.NotNil(t, guard.checkEndorse(100, 2, other, true))
+
+	// the records survive the restart
+	guard, err = newSignGuard(file)
+	assert.Nil(t, err)
+	assert.Nil(t, guard.checkProposal(100, blk, empty))
+	assert.NotNil(t, guard.checkProposal(100, other, empty))
+	assert.Nil(t, guard.checkEndorse(100, 1, blk, false))
+	assert.NotNil(t, guard.checkEndorse(100, 2, other, false))
+	assert.Nil(t, guard.checkEndorse(101, 2, other, false))
+
+	// the heights out of history are refused
+	assert.Nil(t, guard.checkEndorse(100+SIGN_GUARD_HISTORY, 1, blk, false))
+	assert.NotNil(t, guard.checkEndorse(100, 1, blk, false))
+	assert.NotNil(t, guard.checkEndorse(99, 1, blk, false))
+	assert.Nil(t, guard.checkEndorse(101, 2, other, false))
+
+	assert.Nil(t, ioutil.WriteFile(file, []byte{1, 2, 3}, 0600))
+	_, err = newSignGuard(file)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,805 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"container/heap"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/ec"
+	cryptosig "github.com/ontio/ontology-crypto/signature"
+	"github.com/ontio/ontology/account"
+	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/constants"
+	"github.com/ontio/ontology/common/log"
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/p2pserver/common"
+	p2pmsg "github.com/ontio/ontology/p2pserver/message/types"
+	"github.com/ontio/ontology/p2pserver/mock"
+	"github.com/ontio/ontology/p2pserver/net/netserver"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/ontio/ontology/p2pserver/peer"
+	txpool "github.com/ontio/ontology/txnpool/common"
+)
+
+var simSeed = flag.Int64("vbft.sim.seed", 0, "seed to replay the vbft simulations, 0 for the seeds of the scenarios")
+
+const (
+	simNodes        = 7
+	simJitterStep   = 10 * time.Millisecond
+	simIdleInterval = 10 * time.Millisecond // real time without activity before the virtual clock advances
+	simMaxIdleWait  = 50                    // max idle intervals waited for each step
+)
+
+// simTimer is a timer scheduled on the virtual clock
+type simTimer struct {
+	clock *virtualClock
+	due   time.Time
+	seq   uint64
+	f     func()
+	index int // index in the timer heap, -1 if not pending
+}
+
+func (t *simTimer) Stop() bool {
+	c := t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.remove(t)
+}
+
+func (t *simTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	pending := c.remove(t)
+	c.schedule(t, d)
+	return pending
+}
+
+type simTimerHeap []*simTimer
+
+func (h simTimerHeap) Len() int { return len(h) }
+
+func (h simTimerHeap) Less(i, j int) bool {
+	if h[i].due.Equal(h[j].due) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].due.Before(h[j].due)
+}
+
+func (h simTimerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *simTimerHeap) Push(x interface{}) {
+	t := x.(*simTimer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *simTimerHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	t.index = -1
+	*h = old[:len(old)-1]
+	return t
+}
+
+// virtualClock is the Clock of the simulated servers, the time only moves when the
+// simulation advances it to the next pending timer
+type virtualClock struct {
+	lock     sync.Mutex
+	now      time.Time
+	seq      uint64
+	timers   simTimerHeap
+	rand     *rand.Rand
+	activity uint64
+}
+
+func newVirtualClock(start time.Time, seed int64) *virtualClock {
+	return &virtualClock{
+		now:  start,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (c *virtualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *virtualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &simTimer{clock: c, f: f, index: -1}
+	c.schedule(t, d)
+	return t
+}
+
+func (c *virtualClock) Int63n(n int64) int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.rand.Int63n(n)
+}
+
+// schedule should be called with lock held
+func (c *virtualClock) schedule(t *simTimer, d time.Duration) {
+	c.seq++
+	t.due = c.now.Add(d)
+	t.seq = c.seq
+	heap.Push(&c.timers, t)
+	atomic.AddUint64(&c.activity, 1)
+}
+
+// remove should be called with lock held
+func (c *virtualClock) remove(t *simTimer) bool {
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&c.timers, t.index)
+	atomic.AddUint64(&c.activity, 1)
+	return true
+}
+
+// advance moves the clock to the earliest pending timer not after the limit, and fires
+// the timers due at that time in the order they were scheduled
+func (c *virtualClock) advance(limit time.Time) bool {
+	c.lock.Lock()
+	if len(c.timers) == 0 || c.timers[0].due.After(limit) {
+		c.lock.Unlock()
+		return false
+	}
+	c.now = c.timers[0].due
+	var fired []*simTimer
+	for len(c.timers) > 0 && !c.timers[0].due.After(c.now) {
+		fired = append(fired, heap.Pop(&c.timers).(*simTimer))
+	}
+	c.lock.Unlock()
+
+	for _, t := range fired {
+		t.f()
+	}
+	return true
+}
+
+// simPartition splits the nodes into groups during [Start, End) of the virtual time since
+// the simulation started, the messages between the groups are dropped
+type simPartition struct {
+	Start  time.Duration
+	End    time.Duration
+	Groups [][]int
+}
+
+func (p *simPartition) separates(now time.Duration, from, to int) bool {
+	if now < p.Start || now >= p.End {
+		return false
+	}
+	for _, group := range p.Groups {
+		in := make(map[int]bool)
+		for _, n := range group {
+			in[n] = true
+		}
+		if in[from] || in[to] {
+			return !(in[from] && in[to])
+		}
+	}
+	return true
+}
+
+// simCrash stops the node at the virtual time, or the node which is the first to propose
+// the block if ProposerOf is set
+type simCrash struct {
+	Node       int
+	At         time.Duration
+	ProposerOf uint32
+}
+
+// simScenario scripts the faults injected into the simulation
+type simScenario struct {
+	Name        string
+	Seed        int64
+	Latency     time.Duration // base latency of the messages
+	Jitter      time.Duration // max random latency added to the messages, in steps of simJitterStep
+	DropRate    int           // percent of the messages dropped
+	ReorderRate int           // percent of the messages delayed behind the later ones
+	Partitions  []*simPartition
+	Crashes     []*simCrash
+	Byzantine   []uint32 // the first proposers of the blocks turn to sign two conflicting proposals
+	Target      uint32
+	Duration    time.Duration // virtual time for all the live nodes to reach the target
+	Halts       string        // blocking issue halting the scenario, the live nodes are expected not to reach the target
+}
+
+type simTxPool struct{}
+
+func (self simTxPool) GetTxnPool(byCount bool, height uint32) []*txpool.VerifiedTx {
+	return nil
+}
+
+func (self simTxPool) VerifyBlock(txs []*types.Transaction, height uint32) error {
+	return nil
+}
+
+// simProtocol hands the consensus messages received by the node from the mock net to the simulation
+type simProtocol struct {
+	sim  *simulation
+	node int
+}
+
+func (self *simProtocol) HandlePeerMessage(ctx *p2p.Context, msg p2pmsg.Message) {
+	if cons, ok := msg.(*p2pmsg.Consensus); ok {
+		if err := cons.Cons.Verify(); err != nil {
+			return
+		}
+		cons.Cons.PeerId = ctx.Sender().GetID()
+		self.sim.route(self.node, &cons.Cons)
+	}
+}
+
+func (self *simProtocol) HandleSystemMessage(net p2p.P2P, msg p2p.SystemMessage) {}
+
+type simNode struct {
+	account *account.Account
+	ledger  *ledger.Ledger
+	net     *netserver.NetServer
+	server  *Server
+	crashed bool
+}
+
+type simMsgKey struct {
+	from, to int
+	msgType  MsgType
+	blockNum uint32
+}
+
+type simulation struct {
+	t        *testing.T
+	scenario *simScenario
+	seed     int64
+	clock    *virtualClock
+	start    time.Time
+	nodes    []*simNode
+	owners   map[string]int // pubkey id to node
+
+	lock      sync.Mutex
+	counts    map[simMsgKey]uint64
+	twins     map[comm.Uint256]*p2pmsg.ConsensusPayload
+	routed    uint64
+	dropped   uint64
+	proposed  map[uint32]bool
+	byzantine map[int]bool
+}
+
+var simCount uint32
+
+func newSimulation(t *testing.T, scenario *simScenario, seed int64, dir string) *simulation {
+	sim := &simulation{
+		t:         t,
+		scenario:  scenario,
+		seed:      seed,
+		start:     time.Unix(int64(constants.GENESIS_BLOCK_TIMESTAMP), 0).Add(24 * time.Hour),
+		owners:    make(map[string]int),
+		counts:    make(map[simMsgKey]uint64),
+		twins:     make(map[comm.Uint256]*p2pmsg.ConsensusPayload),
+		proposed:  make(map[uint32]bool),
+		byzantine: make(map[int]bool),
+	}
+	sim.clock = newVirtualClock(sim.start, seed)
+
+	keys := rand.New(rand.NewSource(seed))
+	var accounts []*account.Account
+	vbftConfig := &config.VBFTConfig{
+		N:                    simNodes,
+		C:                    (simNodes - 1) / 3,
+		K:                    simNodes,
+		L:                    16 * simNodes,
+		BlockMsgDelay:        10000,
+		HashMsgDelay:         10000,
+		PeerHandshakeTimeout: 10,
+		MaxBlockChangeView:   10000,
+		MinInitStake:         10000,
+		AdminOntID:           config.DefConfig.Genesis.VBFT.AdminOntID,
+		VrfValue:             config.DefConfig.Genesis.VBFT.VrfValue,
+		VrfProof:             config.DefConfig.Genesis.VBFT.VrfProof,
+	}
+	for i := 0; i < simNodes; i++ {
+		acc := simAccount(keys)
+		accounts = append(accounts, acc)
+		vbftConfig.Peers = append(vbftConfig.Peers, &config.VBFTPeerStakeInfo{
+			Index:      uint32(i + 1),
+			PeerPubkey: vconfig.PubkeyID(acc.PublicKey),
+			Address:    acc.Address.ToBase58(),
+			InitPos:    10000,
+		})
+		sim.owners[vconfig.PubkeyID(acc.PublicKey)] = i
+	}
+	genesisConfig := *config.DefConfig.Genesis
+	defer func() { *config.DefConfig.Genesis = genesisConfig }()
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_VBFT
+	config.DefConfig.Genesis.VBFT = vbftConfig
+	bookkeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		t.Fatalf("get bookkeepers: %s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	if err != nil {
+		t.Fatalf("build genesis block: %s", err)
+	}
+
+	net := mock.NewNetwork()
+	id := atomic.AddUint32(&simCount, 1)
+	for i, acc := range accounts {
+		ldg, err := ledger.InitLedger(filepath.Join(dir, fmt.Sprint(i)), 0, bookkeepers, genesisBlock)
+		if err != nil {
+			t.Fatalf("init ledger of node %d: %s", i, err)
+		}
+		keyId := common.RandPeerKeyId()
+		info := peer.NewPeerInfo(keyId.Id, 0, 0, true, 0, 0, 0, "1.10", "")
+		logger := common.LoggerWithContext(common.NewGlobalLoggerWrapper(), fmt.Sprintf("vbft sim node %d: ", i))
+		node := mock.NewNode(keyId, "", info, &simProtocol{sim: sim, node: i}, net, nil, p2p.AllAddrFilter(), logger)
+		server, err := newVbftServer(fmt.Sprintf("vbft_sim_%d_%d", id, i), acc, simTxPool{}, ldg, node, sim.clock)
+		if err != nil {
+			t.Fatalf("create server of node %d: %s", i, err)
+		}
+		sim.nodes = append(sim.nodes, &simNode{account: acc, ledger: ldg, net: node, server: server})
+	}
+	for i := 0; i < simNodes; i++ {
+		for j := i + 1; j < simNodes; j++ {
+			net.AllowConnect(sim.nodes[i].net.GetID(), sim.nodes[j].net.GetID())
+		}
+	}
+	return sim
+}
+
+// simAccount returns the account with the key drawn from the random source, so that the
+// bookkeepers and the proposers selected by VRF are the same for a seed
+func simAccount(r *rand.Rand) *account.Account {
+	curve := elliptic.P256()
+	buf := make([]byte, 32)
+	r.Read(buf)
+	d := new(big.Int).SetBytes(buf)
+	d.Mod(d, new(big.Int).Sub(curve.Params().N, big.NewInt(1)))
+	d.Add(d, big.NewInt(1))
+	key := &ecdsa.PrivateKey{D: d}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+	pub := &ec.PublicKey{Algorithm: ec.ECDSA, PublicKey: &key.PublicKey}
+	return &account.Account{
+		PrivateKey: &ec.PrivateKey{Algorithm: ec.ECDSA, PrivateKey: key},
+		PublicKey:  pub,
+		Address:    types.AddressFromPubKey(pub),
+		SigScheme:  cryptosig.SHA256withECDSA,
+	}
+}
+
+func (sim *simulation) elapsed() time.Duration {
+	return sim.clock.Now().Sub(sim.start)
+}
+
+// nextRand returns the random source of the n-th message from the node to the peer of the type
+// for the block, so that the faults of the messages do not depend on their arriving order
+func (sim *simulation) nextRand(key simMsgKey) *rand.Rand {
+	sim.lock.Lock()
+	n := sim.counts[key]
+	sim.counts[key] = n + 1
+	sim.lock.Unlock()
+
+	seed := sim.seed
+	for _, v := range []uint64{uint64(key.from), uint64(key.to), uint64(key.msgType), uint64(key.blockNum), n} {
+		seed = seed*1000003 ^ int64(v)
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+func (sim *simulation) route(to int, payload *p2pmsg.ConsensusPayload) {
+	atomic.AddUint64(&sim.routed, 1)
+	from, present := sim.owners[vconfig.PubkeyID(payload.Owner)]
+	if !present {
+		return
+	}
+	msg, err := DeserializeVbftMsg(payload.Data)
+	if err != nil {
+		sim.t.Errorf("node %d received invalid msg from node %d: %s", to, from, err)
+		return
+	}
+	key := simMsgKey{from: from, to: to, msgType: msg.Type(), blockNum: msg.GetBlockNum()}
+	r := sim.nextRand(key)
+
+	if msg.Type() == BlockProposalMessage {
+		sim.onProposal(from, msg.GetBlockNum())
+	}
+	if sim.nodes[from].crashed || sim.nodes[to].crashed {
+		return
+	}
+	now := sim.elapsed()
+	for _, p := range sim.scenario.Partitions {
+		if p.separates(now, from, to) {
+			atomic.AddUint64(&sim.dropped, 1)
+			return
+		}
+	}
+	if r.Intn(100) < sim.scenario.DropRate {
+		atomic.AddUint64(&sim.dropped, 1)
+		return
+	}
+	if msg.Type() == BlockProposalMessage && to%2 == 1 && sim.isByzantine(from) {
+		payload = sim.twinProposal(from, payload, msg.(*blockProposalMsg))
+	}
+
+	delay := sim.scenario.Latency
+	if sim.scenario.Jitter > 0 {
+		delay += time.Duration(r.Int63n(int64(sim.scenario.Jitter/simJitterStep)+1)) * simJitterStep
+	}
+	if r.Intn(100) < sim.scenario.ReorderRate {
+		delay += 3*sim.scenario.Latency + sim.scenario.Jitter
+	}
+	sim.clock.AfterFunc(delay, func() {
+		if !sim.nodes[to].crashed {
+			sim.nodes[to].server.GetPID().Tell(payload)
+		}
+	})
+}
+
+func (sim *simulation) isByzantine(node int) bool {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	return sim.byzantine[node]
+}
+
+// onProposal turns the proposer byzantine or crashes it if it is the first to propose the block scripted
+func (sim *simulation) onProposal(from int, blockNum uint32) {
+	sim.lock.Lock()
+	first := !sim.proposed[blockNum]
+	sim.proposed[blockNum] = true
+	sim.lock.Unlock()
+	if !first {
+		return
+	}
+	for _, n := range sim.scenario.Byzantine {
+		if n == blockNum {
+			sim.lock.Lock()
+			sim.byzantine[from] = true
+			sim.lock.Unlock()
+			sim.t.Logf("node %d turned byzantine at block %d", from, blockNum)
+		}
+	}
+	for _, c := range sim.scenario.Crashes {
+		if c.ProposerOf == blockNum {
+			sim.crash(from)
+		}
+	}
+}
+
+func (sim *simulation) crash(node int) {
+	if sim.halt(node) {
+		sim.t.Logf("node %d crashed at %s", node, sim.elapsed())
+	}
+}
+
+func (sim *simulation) halt(node int) bool {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	if sim.nodes[node].crashed {
+		return false
+	}
+	sim.nodes[node].crashed = true
+	sim.nodes[node].server.Halt()
+	return true
+}
+
+// twinProposal returns the proposal conflicting with the one of the byzantine node, the blocks are
+// signed by the node with a different timestamp
+func (sim *simulation) twinProposal(from int, payload *p2pmsg.ConsensusPayload, msg *blockProposalMsg) *p2pmsg.ConsensusPayload {
+	hash := comm.Uint256(sha256.Sum256(payload.Data))
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	if twin, present := sim.twins[hash]; present {
+		return twin
+	}
+	acc := sim.nodes[from].account
+	resign := func(blk *types.Block) *types.Block {
+		if blk == nil {
+			return nil
+		}
+		h := blk.Header
+		header := &types.Header{
+			Version:          h.Version,
+			PrevBlockHash:    h.PrevBlockHash,
+			TransactionsRoot: h.TransactionsRoot,
+			BlockRoot:        h.BlockRoot,
+			Timestamp:        h.Timestamp + 1,
+			Height:           h.Height,
+			ConsensusData:    h.ConsensusData,
+			ConsensusPayload: h.ConsensusPayload,
+			NextBookkeeper:   h.NextBookkeeper,
+		}
+		blkHash := header.Hash()
+		sig, err := signature.Sign(acc, blkHash[:])
+		if err != nil {
+			sim.t.Fatalf("sign twin block: %s", err)
+		}
+		header.SigData = [][]byte{sig}
+		return &types.Block{Header: header, Transactions: blk.Transactions}
+	}
+	twinMsg := &blockProposalMsg{
+		Block: &Block{
+			Block:              resign(msg.Block.Block),
+			EmptyBlock:         resign(msg.Block.EmptyBlock),
+			Info:               msg.Block.Info,
+			PrevExecMerkleRoot: msg.Block.PrevExecMerkleRoot,
+			CrossChainMsg:      msg.Block.CrossChainMsg,
+		},
+	}
+	twinMsg.BlockProposerSig = twinMsg.Block.Block.Header.SigData[0]
+	if twinMsg.Block.EmptyBlock != nil {
+		twinMsg.EmptyBlockProposerSig = twinMsg.Block.EmptyBlock.Header.SigData[0]
+	}
+	data, err := SerializeVbftMsg(twinMsg)
+	if err != nil {
+		sim.t.Fatalf("serialize twin proposal: %s", err)
+	}
+	twin := &p2pmsg.ConsensusPayload{
+		Data:   data,
+		Owner:  acc.PublicKey,
+		PeerId: payload.PeerId,
+	}
+	sink := comm.NewZeroCopySink(nil)
+	twin.SerializationUnsigned(sink)
+	twin.Signature, _ = signature.Sign(acc, sink.Bytes())
+	sim.twins[hash] = twin
+	return twin
+}
+
+func (sim *simulation) activity() uint64 {
+	return atomic.LoadUint64(&sim.clock.activity) + atomic.LoadUint64(&sim.routed)
+}
+
+// waitIdle waits the servers to process the messages and the timer events
+func (sim *simulation) waitIdle() {
+	last := sim.activity()
+	for i := 0; i < simMaxIdleWait; i++ {
+		time.Sleep(simIdleInterval)
+		current := sim.activity()
+		if current == last {
+			return
+		}
+		last = current
+	}
+}
+
+func (sim *simulation) reached() bool {
+	for _, node := range sim.nodes {
+		if !node.crashed && node.ledger.GetCurrentBlockHeight() < sim.scenario.Target {
+			return false
+		}
+	}
+	return true
+}
+
+func (sim *simulation) run() {
+	for _, node := range sim.nodes {
+		go node.net.Start()
+	}
+	for i := 0; i < simNodes; i++ {
+		for j := i + 1; j < simNodes; j++ {
+			sim.nodes[i].net.Connect(sim.nodes[j].net.GetHostInfo().Addr)
+		}
+	}
+	for i, node := range sim.nodes {
+		if err := node.server.initialize(); err != nil {
+			sim.t.Fatalf("initialize server of node %d: %s", i, err)
+		}
+		if err := node.server.Start(); err != nil {
+			sim.t.Fatalf("start server of node %d: %s", i, err)
+		}
+	}
+
+	limit := sim.start.Add(sim.scenario.Duration)
+	for !sim.reached() {
+		sim.waitIdle()
+		for _, c := range sim.scenario.Crashes {
+			if c.ProposerOf == 0 && sim.elapsed() >= c.At {
+				sim.crash(c.Node)
+			}
+		}
+		if !sim.clock.advance(limit) {
+			break
+		}
+	}
+}
+
+func (sim *simulation) stop() {
+	for i := range sim.nodes {
+		sim.halt(i)
+	}
+	// wait the servers to quit before the ledgers closed
+	time.Sleep(10 * simIdleInterval)
+	for _, node := range sim.nodes {
+		node.ledger.Close()
+	}
+}
+
+// checkSafety asserts no two blocks are committed at one height
+func (sim *simulation) checkSafety() error {
+	committed := make(map[uint32]comm.Uint256)
+	for i, node := range sim.nodes {
+		for height := uint32(1); height <= node.ledger.GetCurrentBlockHeight(); height++ {
+			hash := node.ledger.GetBlockHash(height)
+			if prev, present := committed[height]; present && prev != hash {
+				return fmt.Errorf("safety violated: node %d committed block %s at height %d, others %s",
+					i, hash.ToHexString(), height, prev.ToHexString())
+			}
+			committed[height] = hash
+		}
+	}
+	return nil
+}
+
+// checkLiveness asserts all the live nodes reached the target
+func (sim *simulation) checkLiveness() error {
+	for i, node := range sim.nodes {
+		if height := node.ledger.GetCurrentBlockHeight(); !node.crashed && height < sim.scenario.Target {
+			return fmt.Errorf("liveness violated: node %d at height %d after %s, target %d",
+				i, height, sim.elapsed(), sim.scenario.Target)
+		}
+	}
+	return nil
+}
+
+// runSimulation runs the scenario with the seed given by -vbft.sim.seed or the scenario, the failing
+// seed is reported so that the same faults can be replayed. The safety is asserted for all the
+// scenarios, the liveness is asserted unless the scenario is halted by a blocking issue.
+func runSimulation(t *testing.T, scenario *simScenario) {
+	seed := scenario.Seed
+	if *simSeed != 0 {
+		seed = *simSeed
+	}
+	dir, err := ioutil.TempDir("", "vbft_sim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sim := newSimulation(t, scenario, seed, dir)
+	defer sim.stop()
+	sim.run()
+	t.Logf("scenario %s, seed %d: virtual time %s, msgs routed %d, dropped %d", scenario.Name, seed,
+		sim.elapsed(), atomic.LoadUint64(&sim.routed), atomic.LoadUint64(&sim.dropped))
+	replay := fmt.Sprintf("replay with: go test ./consensus/vbft -run 'TestSimulation.*/%s$' -vbft.sim.seed=%d",
+		scenario.Name, seed)
+	if err := sim.checkSafety(); err != nil {
+		t.Fatalf("%s\n%s", err, replay)
+	}
+	err = sim.checkLiveness()
+	switch {
+	case scenario.Halts == "" && err != nil:
+		t.Fatalf("%s\n%s", err, replay)
+	case scenario.Halts != "" && err != nil:
+		t.Logf("halted by blocking issue: %s: %s", scenario.Halts, err)
+	case scenario.Halts != "":
+		t.Logf("reached the target despite blocking issue: %s", scenario.Halts)
+	}
+}
+
+func TestSimulation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip vbft simulation in short mode")
+	}
+	log.InitLog(log.FatalLog, log.Stdout)
+	defer log.InitLog(log.InfoLog, log.Stdout)
+	for _, scenario := range []*simScenario{
+		{
+			Name:     "honest",
+			Seed:     1,
+			Latency:  10 * time.Millisecond,
+			Target:   4,
+			Duration: 10 * time.Minute,
+		},
+		{
+			Name:        "lossy",
+			Seed:        2,
+			Latency:     10 * time.Millisecond,
+			Jitter:      100 * time.Millisecond,
+			DropRate:    10,
+			ReorderRate: 20,
+			Target:      4,
+			Duration:    20 * time.Minute,
+		},
+		{
+			Name:    "partition",
+			Seed:    3,
+			Latency: 10 * time.Millisecond,
+			Partitions: []*simPartition{
+				{Start: 40 * time.Second, End: 2 * time.Minute, Groups: [][]int{{0, 1, 2, 3, 4}, {5, 6}}},
+			},
+			Target:   4,
+			Duration: 20 * time.Minute,
+		},
+		{
+			Name:     "crashed_proposers",
+			Seed:     4,
+			Latency:  10 * time.Millisecond,
+			Crashes:  []*simCrash{{ProposerOf: 2}, {ProposerOf: 3}},
+			Target:   4,
+			Duration: 20 * time.Minute,
+		},
+	} {
+		t.Run(scenario.Name, func(t *testing.T) {
+			runSimulation(t, scenario)
+		})
+	}
+}
+
+// TestSimulationBlockingIssues runs the scenarios halted by the blocking issues of VBFT, the
+// committers are locked on the block they committed, and no quorum can be reached if the
+// commitments are split. The scenarios assert the safety, the halts are logged.
+func TestSimulationBlockingIssues(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip vbft simulation in short mode")
+	}
+	log.InitLog(log.FatalLog, log.Stdout)
+	defer log.InitLog(log.InfoLog, log.Stdout)
+	for _, scenario := range []*simScenario{
+		{
+			Name:    "even_partition",
+			Seed:    3,
+			Latency: 10 * time.Millisecond,
+			Partitions: []*simPartition{
+				{Start: 40 * time.Second, End: 2 * time.Minute, Groups: [][]int{{0, 1, 2, 3}, {4, 5, 6}}},
+			},
+			Target:   4,
+			Duration: 10 * time.Minute,
+			Halts:    "both sides commit their own proposal for the block, the commitments are never released after healed",
+		},
+		{
+			Name:      "double_signer",
+			Seed:      5,
+			Latency:   10 * time.Millisecond,
+			Byzantine: []uint32{2},
+			Target:    4,
+			Duration:  10 * time.Minute,
+			Halts:     "the honest nodes commit either of the conflicting proposals, none is switched to the empty block",
+		},
+	} {
+		t.Run(scenario.Name, func(t *testing.T) {
+			runSimulation(t, scenario)
+		})
+	}
+}
@@ -21,10 +21,14 @@ package ledger
 import (
 	"fmt"
 
+	types2 "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/store"
 	"github.com/ontio/ontology/core/store/ledgerstore"
 	"github.com/ontio/ontology/core/types"
+	types3 "github.com/ontio/ontology/smartcontract/service/evm/types"
+	"github.com/ontio/ontology/vm/evm"
 )
 
 var DefLedger *Ledger
@@ -37,6 +41,29 @@ func (self *Ledger) GetStore() store.LedgerStore {
 	return self.LedgerStore
 }
 
+// evmTraceStore is implemented by the ledger store supporting evm tracing, it is not a part of
+// store.LedgerStore since vm/evm can not be imported by core/store
+type evmTraceStore interface {
+	TraceEip155Tx(txHash common.Uint256, tracer evm.Tracer) (*types3.ExecutionResult, error)
+	TraceEip155Call(msg types2.Message, height uint32, tracer evm.Tracer) (*types3.ExecutionResult, error)
+}
+
+func (self *Ledger) TraceEip155Tx(txHash common.Uint256, tracer evm.Tracer) (*types3.ExecutionResult, error) {
+	traceStore, ok := self.LedgerStore.(evmTraceStore)
+	if !ok {
+		return nil, fmt.Errorf("evm tracing is not supported by ledger store")
+	}
+	return traceStore.TraceEip155Tx(txHash, tracer)
+}
+
+func (self *Ledger) TraceEip155Call(msg types2.Message, height uint32, tracer evm.Tracer) (*types3.ExecutionResult, error) {
+	traceStore, ok := self.LedgerStore.(evmTraceStore)
+	if !ok {
+		return nil, fmt.Errorf("evm tracing is not supported by ledger store")
+	}
+	return traceStore.TraceEip155Call(msg, height, tracer)
+}
+
 func InitLedger(dataDir string, stateHashHeight uint32, defaultBookkeeper []keypair.PublicKey,
 	genesisBlock *types.Block) (*Ledger, error) {
 	ldgStore, err := ledgerstore.NewLedgerStore(dataDir, stateHashHeight)
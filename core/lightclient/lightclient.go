@@ -0,0 +1,234 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package lightclient verifies the cross chain proof bundle of a vbft chain offline, with the consensus peers of a
+// trusted config block only.
+package lightclient
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/merkle"
+)
+
+//ProofBundle proves a cross chain state at Height
+type ProofBundle struct {
+	Height        uint32
+	ConfigHeaders []*types.Header //config blocks after the trusted height, which transfer the consensus peers
+	ConfigParents []*types.Header //headers before the config blocks, which link them to the former config blocks
+	Header        *types.Header   //header at Height+1, whose bookkeepers signed the cross chain msg
+	CrossChainMsg *types.CrossChainMsg
+	StatesProof   []byte //merkle path of the cross chain state to the states root of CrossChainMsg
+}
+
+func (this *ProofBundle) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteVarUint(uint64(len(this.ConfigHeaders)))
+	for i, header := range this.ConfigHeaders {
+		sink.WriteVarBytes(this.ConfigParents[i].ToArray())
+		sink.WriteVarBytes(header.ToArray())
+	}
+	sink.WriteVarBytes(this.Header.ToArray())
+	sink.WriteVarBytes(common.SerializeToBytes(this.CrossChainMsg))
+	sink.WriteVarBytes(this.StatesProof)
+}
+
+func (this *ProofBundle) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("ProofBundle, deserialization read height error")
+	}
+	count, _, irr, eof := source.NextVarUint()
+	if irr || eof {
+		return fmt.Errorf("ProofBundle, deserialization read config headers length error")
+	}
+	this.ConfigHeaders = make([]*types.Header, 0)
+	this.ConfigParents = make([]*types.Header, 0)
+	for i := uint64(0); i < count; i++ {
+		parent, err := readHeader(source)
+		if err != nil {
+			return fmt.Errorf("ProofBundle, deserialization read config parent header error: %s", err)
+		}
+		header, err := readHeader(source)
+		if err != nil {
+			return fmt.Errorf("ProofBundle, deserialization read config header error: %s", err)
+		}
+		this.ConfigParents = append(this.ConfigParents, parent)
+		this.ConfigHeaders = append(this.ConfigHeaders, header)
+	}
+	header, err := readHeader(source)
+	if err != nil {
+		return fmt.Errorf("ProofBundle, deserialization read header error: %s", err)
+	}
+	this.Header = header
+	raw, _, irr, eof := source.NextVarBytes()
+	if irr || eof {
+		return fmt.Errorf("ProofBundle, deserialization read cross chain msg error")
+	}
+	this.CrossChainMsg = new(types.CrossChainMsg)
+	if err := this.CrossChainMsg.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return err
+	}
+	this.StatesProof, _, irr, eof = source.NextVarBytes()
+	if irr || eof {
+		return fmt.Errorf("ProofBundle, deserialization read states proof error")
+	}
+	return nil
+}
+
+func readHeader(source *common.ZeroCopySource) (*types.Header, error) {
+	raw, _, irr, eof := source.NextVarBytes()
+	if irr || eof {
+		return nil, fmt.Errorf("read header bytes error")
+	}
+	return types.HeaderFromRawBytes(raw)
+}
+
+//TrustedState is the consensus peers of the latest config block a light client trusts
+type TrustedState struct {
+	Height uint32
+	Hash   common.Uint256    //hash of the config block
+	Peers  map[string]uint32 //vconfig.PubkeyID of the peer -> peer index
+}
+
+//NewTrustedState creates the trusted state from a config block header trusted out of band, such as the genesis block
+func NewTrustedState(header *types.Header) (*TrustedState, error) {
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return nil, err
+	}
+	if blkInfo.NewChainConfig == nil {
+		return nil, fmt.Errorf("header of height %d is not a config block", header.Height)
+	}
+	return newState(header, blkInfo.NewChainConfig), nil
+}
+
+func newState(header *types.Header, cfg *vconfig.ChainConfig) *TrustedState {
+	peers := make(map[string]uint32)
+	for _, p := range cfg.Peers {
+		peers[p.ID] = p.Index
+	}
+	return &TrustedState{Height: header.Height, Hash: header.Hash(), Peers: peers}
+}
+
+//VerifyHeader checks the header is signed by more than 2/3 consensus peers of the trusted state
+func (self *TrustedState) VerifyHeader(header *types.Header) error {
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return err
+	}
+	//a config block is signed by the peers of the former config
+	if blkInfo.NewChainConfig != nil {
+		if header.Height <= self.Height {
+			return fmt.Errorf("config block height %d is not greater than trusted height %d", header.Height, self.Height)
+		}
+	} else if blkInfo.LastConfigBlockNum != self.Height {
+		return fmt.Errorf("last config block of height %d is %d, but trusted height is %d", header.Height,
+			blkInfo.LastConfigBlockNum, self.Height)
+	}
+	bookkeepers := make(map[string]bool)
+	for _, bookkeeper := range header.Bookkeepers {
+		pubkey := vconfig.PubkeyID(bookkeeper)
+		if _, present := self.Peers[pubkey]; !present {
+			return fmt.Errorf("invalid bookkeeper %s of height %d", pubkey, header.Height)
+		}
+		if bookkeepers[pubkey] {
+			return fmt.Errorf("duplicated bookkeeper %s of height %d", pubkey, header.Height)
+		}
+		bookkeepers[pubkey] = true
+	}
+	if len(bookkeepers)*3 < len(self.Peers)*2 {
+		return fmt.Errorf("header bookkeepers num %d must more than 2/3 consensus node num %d",
+			len(bookkeepers), len(self.Peers))
+	}
+	hash := header.Hash()
+	if err := signature.VerifyMultiSignature(hash[:], header.Bookkeepers, len(header.Bookkeepers), header.SigData); err != nil {
+		return fmt.Errorf("VerifyMultiSignature of height %d error: %s", header.Height, err)
+	}
+	return nil
+}
+
+//Update verifies a config block and moves the trusted state to its consensus peers. The config block records
+//itself as the last config block, so the former config block is checked with the parent of the config block,
+//which must be the trusted config block, or a block in effect of it.
+func (self *TrustedState) Update(parent, header *types.Header) error {
+	if parent == nil || parent.Height+1 != header.Height || header.PrevBlockHash != parent.Hash() {
+		return fmt.Errorf("config block of height %d is not linked to its parent", header.Height)
+	}
+	if parent.Height != self.Height {
+		parentInfo, err := vconfig.VbftBlock(parent)
+		if err != nil {
+			return err
+		}
+		if parentInfo.NewChainConfig != nil {
+			return fmt.Errorf("config block of height %d is skipped", parent.Height)
+		}
+		if err := self.VerifyHeader(parent); err != nil {
+			return err
+		}
+	} else if parent.Hash() != self.Hash {
+		return fmt.Errorf("parent of config block of height %d mismatch trusted config block", header.Height)
+	}
+	if err := self.VerifyHeader(header); err != nil {
+		return err
+	}
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return err
+	}
+	if blkInfo.NewChainConfig == nil {
+		return fmt.Errorf("header of height %d is not a config block", header.Height)
+	}
+	*self = *newState(header, blkInfo.NewChainConfig)
+	return nil
+}
+
+//Verify checks the proof bundle and returns the proved cross chain state, the trusted state is moved to the
+//last config block of the bundle
+func (self *TrustedState) Verify(bundle *ProofBundle) ([]byte, error) {
+	if bundle.Header == nil || bundle.CrossChainMsg == nil || len(bundle.ConfigParents) != len(bundle.ConfigHeaders) {
+		return nil, fmt.Errorf("incomplete proof bundle")
+	}
+	for i, header := range bundle.ConfigHeaders {
+		if err := self.Update(bundle.ConfigParents[i], header); err != nil {
+			return nil, fmt.Errorf("update trusted state error: %s", err)
+		}
+	}
+	header, msg := bundle.Header, bundle.CrossChainMsg
+	if msg.Height != bundle.Height || header.Height != bundle.Height+1 {
+		return nil, fmt.Errorf("height mismatch, bundle:%d, header:%d, cross chain msg:%d", bundle.Height,
+			header.Height, msg.Height)
+	}
+	if err := self.VerifyHeader(header); err != nil {
+		return nil, err
+	}
+	hash := msg.Hash()
+	if err := signature.VerifyMultiSignature(hash[:], header.Bookkeepers, len(header.Bookkeepers), msg.SigData); err != nil {
+		return nil, fmt.Errorf("verify cross chain msg of height %d error: %s", msg.Height, err)
+	}
+	value, err := merkle.MerkleProve(bundle.StatesProof, msg.StatesRoot)
+	if err != nil {
+		return nil, fmt.Errorf("verify states proof error: %s", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package lightclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/merkle"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPeers(n int) ([]*account.Account, *vconfig.ChainConfig) {
+	accs := make([]*account.Account, 0, n)
+	cfg := &vconfig.ChainConfig{}
+	for i := 0; i < n; i++ {
+		acc := account.NewAccount("")
+		accs = append(accs, acc)
+		cfg.Peers = append(cfg.Peers, &vconfig.PeerConfig{Index: uint32(i + 1), ID: vconfig.PubkeyID(acc.PubKey())})
+	}
+	return accs, cfg
+}
+
+func newHeader(t *testing.T, prev common.Uint256, height, lastConfig uint32, cfg *vconfig.ChainConfig,
+	signers []*account.Account) *types.Header {
+	payload, err := json.Marshal(&vconfig.VbftBlockInfo{LastConfigBlockNum: lastConfig, NewChainConfig: cfg})
+	assert.Nil(t, err)
+	header := &types.Header{PrevBlockHash: prev, Height: height, ConsensusPayload: payload}
+	hash := header.Hash()
+	for _, acc := range signers {
+		sig, err := signature.Sign(acc, hash[:])
+		assert.Nil(t, err)
+		header.Bookkeepers = append(header.Bookkeepers, acc.PubKey())
+		header.SigData = append(header.SigData, sig)
+	}
+	return header
+}
+
+func newBundle(t *testing.T, value []byte, configParents, configHeaders []*types.Header, signers []*account.Account,
+	lastConfig uint32) *ProofBundle {
+	hashes := []common.Uint256{merkle.HashLeaf([]byte("other")), merkle.HashLeaf(value), merkle.HashLeaf([]byte("x"))}
+	proof, err := merkle.MerkleLeafPath(value, hashes)
+	assert.Nil(t, err)
+	msg := &types.CrossChainMsg{Height: 10, StatesRoot: merkle.TreeHasher{}.HashFullTreeWithLeafHash(hashes)}
+	hash := msg.Hash()
+	header := newHeader(t, common.Uint256{10}, 11, lastConfig, nil, signers)
+	for _, acc := range signers {
+		sig, err := signature.Sign(acc, hash[:])
+		assert.Nil(t, err)
+		msg.SigData = append(msg.SigData, sig)
+	}
+	return &ProofBundle{Height: 10, ConfigHeaders: configHeaders, ConfigParents: configParents, Header: header,
+		CrossChainMsg: msg, StatesProof: proof}
+}
+
+func TestVerifyBundle(t *testing.T) {
+	accs0, cfg0 := newPeers(4)
+	accs1, cfg1 := newPeers(4)
+	genesis := newHeader(t, common.UINT256_EMPTY, 0, 0, cfg0, nil)
+	parent := newHeader(t, common.Uint256{3}, 4, 0, nil, accs0[:3])
+	configHeader := newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:3])
+	value := []byte("cross chain state")
+
+	bundle := newBundle(t, value, []*types.Header{parent}, []*types.Header{configHeader}, accs1[:3], 5)
+	sink := common.NewZeroCopySink(nil)
+	bundle.Serialization(sink)
+	decoded := new(ProofBundle)
+	assert.Nil(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+
+	state, err := NewTrustedState(genesis)
+	assert.Nil(t, err)
+	proved, err := state.Verify(decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, value, proved)
+	assert.Equal(t, uint32(5), state.Height)
+	assert.Equal(t, configHeader.Hash(), state.Hash)
+
+	//the state has moved to the config block, the bundle without config headers is verified too
+	proved, err = state.Verify(newBundle(t, value, nil, nil, accs1[:3], 5))
+	assert.Nil(t, err)
+	assert.Equal(t, value, proved)
+
+	//the header at Height+1 is a config block, which is signed by the trusted peers
+	_, cfg2 := newPeers(4)
+	bundle = newBundle(t, value, nil, nil, accs1[:3], 5)
+	bundle.Header = newHeader(t, common.Uint256{10}, 11, 11, cfg2, accs1[:3])
+	proved, err = state.Verify(bundle)
+	assert.Nil(t, err)
+	assert.Equal(t, value, proved)
+
+	//the config block next to the trusted config block
+	state, _ = NewTrustedState(genesis)
+	configHeader = newHeader(t, genesis.Hash(), 1, 1, cfg1, accs0[:3])
+	assert.Nil(t, state.Update(genesis, configHeader))
+	assert.Equal(t, uint32(1), state.Height)
+}
+
+func TestVerifyBundleFailed(t *testing.T) {
+	accs0, cfg0 := newPeers(4)
+	accs1, cfg1 := newPeers(4)
+	genesis := newHeader(t, common.UINT256_EMPTY, 0, 0, cfg0, nil)
+	parent := newHeader(t, common.Uint256{3}, 4, 0, nil, accs0[:3])
+	value := []byte("cross chain state")
+
+	//the config transition is missing
+	state, _ := NewTrustedState(genesis)
+	_, err := state.Verify(newBundle(t, value, nil, nil, accs1[:3], 5))
+	assert.NotNil(t, err)
+
+	//the config block is not signed by 2/3 peers of the trusted state
+	state, _ = NewTrustedState(genesis)
+	_, err = state.Verify(newBundle(t, value, []*types.Header{parent},
+		[]*types.Header{newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:2])}, accs1[:3], 5))
+	assert.NotNil(t, err)
+
+	//the header is signed by the old peers
+	state, _ = NewTrustedState(genesis)
+	_, err = state.Verify(newBundle(t, value, []*types.Header{parent},
+		[]*types.Header{newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:3])}, accs0[:3], 5))
+	assert.NotNil(t, err)
+
+	//the states proof doesn't match the states root
+	state, _ = NewTrustedState(genesis)
+	bundle := newBundle(t, value, []*types.Header{parent},
+		[]*types.Header{newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:3])}, accs1[:3], 5)
+	bundle.StatesProof = newBundle(t, []byte("forged"), nil, nil, accs1[:3], 5).StatesProof
+	_, err = state.Verify(bundle)
+	assert.NotNil(t, err)
+
+	//the parent of the config block is missing
+	state, _ = NewTrustedState(genesis)
+	_, err = state.Verify(newBundle(t, value, nil,
+		[]*types.Header{newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:3])}, accs1[:3], 5))
+	assert.NotNil(t, err)
+
+	_, err = NewTrustedState(newHeader(t, common.UINT256_EMPTY, 3, 0, nil, nil))
+	assert.NotNil(t, err)
+}
+
+func TestUpdateFailed(t *testing.T) {
+	accs0, cfg0 := newPeers(4)
+	accs1, cfg1 := newPeers(4)
+	_, cfg2 := newPeers(4)
+	genesis := newHeader(t, common.UINT256_EMPTY, 0, 0, cfg0, nil)
+
+	//the config block is not linked to the parent
+	state, _ := NewTrustedState(genesis)
+	parent := newHeader(t, common.Uint256{3}, 4, 0, nil, accs0[:3])
+	assert.NotNil(t, state.Update(parent, newHeader(t, common.Uint256{4}, 5, 5, cfg1, accs0[:3])))
+	assert.NotNil(t, state.Update(parent, newHeader(t, parent.Hash(), 6, 6, cfg1, accs0[:3])))
+
+	//the parent is in effect of another config block
+	parent = newHeader(t, common.Uint256{3}, 4, 2, nil, accs0[:3])
+	assert.NotNil(t, state.Update(parent, newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:3])))
+
+	//the config block between is skipped, which is signed by the trusted peers too
+	parent = newHeader(t, common.Uint256{3}, 4, 4, cfg2, accs0[:3])
+	assert.NotNil(t, state.Update(parent, newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:3])))
+
+	//the parent at the trusted height is not the trusted config block
+	forged := newHeader(t, common.Uint256{1}, 0, 0, cfg0, nil)
+	assert.NotNil(t, state.Update(forged, newHeader(t, forged.Hash(), 1, 1, cfg1, accs0[:3])))
+
+	//the parent is not signed by the trusted peers
+	parent = newHeader(t, common.Uint256{3}, 4, 0, nil, accs1[:3])
+	assert.NotNil(t, state.Update(parent, newHeader(t, parent.Hash(), 5, 5, cfg1, accs0[:3])))
+	assert.Equal(t, uint32(0), state.Height)
+}
+
+func TestVerifyHeaderDuplicatedBookkeeper(t *testing.T) {
+	accs, cfg := newPeers(4)
+	state, err := NewTrustedState(newHeader(t, common.UINT256_EMPTY, 0, 0, cfg, nil))
+	assert.Nil(t, err)
+	assert.Nil(t, state.VerifyHeader(newHeader(t, common.Uint256{3}, 4, 0, nil, accs[:3])))
+
+	//3 bookkeepers of 4 peers, but only 2 of them are distinct
+	header := newHeader(t, common.Uint256{3}, 4, 0, nil, []*account.Account{accs[0], accs[1], accs[0]})
+	assert.NotNil(t, state.VerifyHeader(header))
+}
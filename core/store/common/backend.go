@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+//PersistStoreFactory open the PersistStore at path
+type PersistStoreFactory func(path string) (PersistStore, error)
+
+var (
+	backendLock sync.RWMutex
+	backends    = make(map[string]PersistStoreFactory)
+)
+
+//RegisterPersistStore register a PersistStore backend by name. It is usually called in the init function of backend
+func RegisterPersistStore(name string, factory PersistStoreFactory) {
+	backendLock.Lock()
+	defer backendLock.Unlock()
+	if _, ok := backends[name]; ok {
+		panic(fmt.Sprintf("persist store backend %s registered twice", name))
+	}
+	backends[name] = factory
+}
+
+//NewPersistStore open the PersistStore at path with the named backend
+func NewPersistStore(name, path string) (PersistStore, error) {
+	backendLock.RLock()
+	factory, ok := backends[name]
+	backendLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown persist store backend %s, available: %v", name, PersistStoreBackends())
+	}
+	return factory(path)
+}
+
+//PersistStoreBackends return the names of registered backends
+func PersistStoreBackends() []string {
+	backendLock.RLock()
+	defer backendLock.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
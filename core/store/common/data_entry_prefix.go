@@ -33,6 +33,7 @@ const (
 	ST_CONTRACT   DataEntryPrefix = 0x04 //Smart contract deploy code key prefix
 	ST_STORAGE    DataEntryPrefix = 0x05 //Smart contract storage key prefix
 	ST_DESTROYED  DataEntryPrefix = 0x06 // record destroyed smart contract: prefix+address -> height
+	ST_ARCHIVE    DataEntryPrefix = 0x07 // state key + block height => state value before the block
 
 	// eth state
 	ST_ETH_CODE    DataEntryPrefix = 0x30 // eth contract code:hash -> bytes
@@ -47,8 +48,12 @@ const (
 	SYS_BLOCK_MERKLE_TREE    DataEntryPrefix = 0x13 // Block merkle tree root key prefix
 	SYS_STATE_MERKLE_TREE    DataEntryPrefix = 0x20 // state merkle tree root key prefix
 	SYS_CROSS_CHAIN_MSG      DataEntryPrefix = 0x22 // state merkle tree root key prefix
+	SYS_ARCHIVE_HEIGHT       DataEntryPrefix = 0x23 // start and last height of state archive
 
-	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
+	EVENT_NOTIFY         DataEntryPrefix = 0x14 //Event notify key prefix
+	EVENT_INDEX_CONTRACT DataEntryPrefix = 0x15 //Contract address + block height + tx hash => event notify index prefix
+	EVENT_INDEX_TOPIC    DataEntryPrefix = 0x16 //Contract address + topic hash + block height + tx hash => event notify index prefix
+	EVENT_EVM_LOG_BLOOM  DataEntryPrefix = 0x17 //Block height => bloom of evm logs key prefix
 
 	DATA_BLOCK_PRUNE_HEIGHT DataEntryPrefix = 0x80 //  last pruned block height, genesis block can not be pruned
 )
@@ -42,16 +42,17 @@ type StoreIterator interface {
 
 //PersistStore of ledger
 type PersistStore interface {
-	Put(key []byte, value []byte) error      //Put the key-value pair to store
-	Get(key []byte) ([]byte, error)          //Get the value if key in store
-	Has(key []byte) (bool, error)            //Whether the key is exist in store
-	Delete(key []byte) error                 //Delete the key in store
-	NewBatch()                               //Start commit batch
-	BatchPut(key []byte, value []byte)       //Put a key-value pair to batch
-	BatchDelete(key []byte)                  //Delete the key in batch
-	BatchCommit() error                      //Commit batch to store
-	Close() error                            //Close store
-	NewIterator(prefix []byte) StoreIterator //Return the iterator of store
+	Put(key []byte, value []byte) error                 //Put the key-value pair to store
+	Get(key []byte) ([]byte, error)                     //Get the value if key in store
+	Has(key []byte) (bool, error)                       //Whether the key is exist in store
+	Delete(key []byte) error                            //Delete the key in store
+	NewBatch()                                          //Start commit batch
+	BatchPut(key []byte, value []byte)                  //Put a key-value pair to batch
+	BatchDelete(key []byte)                             //Delete the key in batch
+	BatchCommit() error                                 //Commit batch to store
+	Close() error                                       //Close store
+	NewIterator(prefix []byte) StoreIterator            //Return the iterator of store
+	NewRangeIterator(start, limit []byte) StoreIterator //Return the iterator of store with key range [start, limit)
 }
 
 //EventStore save event notify
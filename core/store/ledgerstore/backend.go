@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ontio/ontology/common/config"
+	scom "github.com/ontio/ontology/core/store/common"
+	_ "github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/core/store/memstore"
+	_ "github.com/ontio/ontology/core/store/pebblestore"
+	"github.com/ontio/ontology/merkle"
+)
+
+const MIGRATE_BATCH_SIZE = 10000 //Count of records in one commit batch when migrating store
+
+//newPersistStore open the store at dbDir with the backend in config
+func newPersistStore(dbDir string) (scom.PersistStore, error) {
+	return scom.NewPersistStore(config.DefConfig.Common.DBBackend, dbDir)
+}
+
+//newHashStore open the block merkle hash store. In-memory backend keeps the hashes in memory too
+func newHashStore(path string, treeSize uint32) (merkle.HashStore, error) {
+	if config.DefConfig.Common.DBBackend == memstore.BACKEND_NAME {
+		return merkle.NewMemHashStore(), nil
+	}
+	return merkle.NewFileHashStore(path, treeSize)
+}
+
+//MigrateDataDir copy the ledger data in srcDir saved by backend from, to dstDir saved by backend to.
+//The node should be stopped before migrate, and dstDir should be empty.
+func MigrateDataDir(srcDir, from, dstDir, to string, progress func(db string, count uint64)) error {
+	if from == memstore.BACKEND_NAME || to == memstore.BACKEND_NAME {
+		return fmt.Errorf("in-memory backend can not be migrated")
+	}
+	srcDir, err := filepath.Abs(srcDir)
+	if err != nil {
+		return err
+	}
+	dstDir, err = filepath.Abs(dstDir)
+	if err != nil {
+		return err
+	}
+	if srcDir == dstDir {
+		return fmt.Errorf("source and target data dir are the same")
+	}
+	if entries, err := ioutil.ReadDir(dstDir); err == nil && len(entries) != 0 {
+		return fmt.Errorf("target data dir %s is not empty", dstDir)
+	}
+	if err = os.MkdirAll(dstDir, 0755); err != nil {
+		return err
+	}
+
+	for _, name := range []string{DBDirBlock, DBDirState, DBDirEvent, DBDirCrossChain} {
+		srcPath := filepath.Join(srcDir, name)
+		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
+			continue
+		}
+		err := migratePersistStore(srcPath, from, filepath.Join(dstDir, name), to, func(count uint64) {
+			if progress != nil {
+				progress(name, count)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("migrate %s error %s", name, err)
+		}
+	}
+	err = copyFile(filepath.Join(srcDir, MerkleTreeStorePath), filepath.Join(dstDir, MerkleTreeStorePath))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("copy %s error %s", MerkleTreeStorePath, err)
+	}
+	return nil
+}
+
+func migratePersistStore(srcPath, from, dstPath, to string, progress func(count uint64)) error {
+	src, err := scom.NewPersistStore(from, srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := scom.NewPersistStore(to, dstPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	count := uint64(0)
+	dst.NewBatch()
+	iter := src.NewIterator(nil)
+	for iter.Next() {
+		dst.BatchPut(iter.Key(), iter.Value())
+		count++
+		if count%MIGRATE_BATCH_SIZE == 0 {
+			if err = dst.BatchCommit(); err != nil {
+				break
+			}
+			dst.NewBatch()
+			progress(count)
+		}
+	}
+	iter.Release()
+	if err == nil {
+		err = iter.Error()
+	}
+	if err != nil {
+		return err
+	}
+	if err = dst.BatchCommit(); err != nil {
+		return err
+	}
+	progress(count)
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err = io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/core/store/pebblestore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateDataDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	srcDir, dstDir := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+
+	kvs := map[string]string{"a": "1", "b": "2", "c": ""}
+	for _, name := range []string{DBDirBlock, DBDirState} {
+		store, err := scom.NewPersistStore(leveldbstore.BACKEND_NAME, filepath.Join(srcDir, name))
+		assert.Nil(t, err)
+		for k, v := range kvs {
+			assert.Nil(t, store.Put([]byte(name+k), []byte(v)))
+		}
+		assert.Nil(t, store.Close())
+	}
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(srcDir, MerkleTreeStorePath), []byte("hashes"), 0644))
+
+	err = MigrateDataDir(srcDir, leveldbstore.BACKEND_NAME, dstDir, pebblestore.BACKEND_NAME, nil)
+	assert.Nil(t, err)
+	for _, name := range []string{DBDirBlock, DBDirState} {
+		store, err := scom.NewPersistStore(pebblestore.BACKEND_NAME, filepath.Join(dstDir, name))
+		assert.Nil(t, err)
+		count := 0
+		iter := store.NewIterator(nil)
+		for iter.Next() {
+			assert.Equal(t, kvs[string(iter.Key()[len(name):])], string(iter.Value()))
+			count++
+		}
+		iter.Release()
+		assert.Equal(t, len(kvs), count)
+		assert.Nil(t, store.Close())
+	}
+	_, err = os.Stat(filepath.Join(dstDir, DBDirEvent))
+	assert.True(t, os.IsNotExist(err))
+	data, err := ioutil.ReadFile(filepath.Join(dstDir, MerkleTreeStorePath))
+	assert.Nil(t, err)
+	assert.Equal(t, "hashes", string(data))
+
+	//target dir is not empty now
+	err = MigrateDataDir(srcDir, leveldbstore.BACKEND_NAME, dstDir, pebblestore.BACKEND_NAME, nil)
+	assert.NotNil(t, err)
+}
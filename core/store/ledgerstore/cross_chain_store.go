@@ -24,7 +24,6 @@ import (
 
 	"github.com/ontio/ontology/common"
 	scom "github.com/ontio/ontology/core/store/common"
-	"github.com/ontio/ontology/core/store/leveldbstore"
 	"github.com/ontio/ontology/core/types"
 )
 
@@ -34,14 +33,14 @@ const (
 
 //Block store save the data of block & transaction
 type CrossChainStore struct {
-	dbDir string                     //The path of store file
-	store *leveldbstore.LevelDBStore //block store handler
+	dbDir string            //The path of store file
+	store scom.PersistStore //block store handler
 }
 
 //NewCrossChainStore return cross chain store instance
 func NewCrossChainStore(dataDir string) (*CrossChainStore, error) {
 	dbDir := fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirCrossChain)
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := newPersistStore(dbDir)
 	if err != nil {
 		return nil, fmt.Errorf("NewCrossShardStore error %s", err)
 	}
@@ -0,0 +1,351 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/store"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/smartcontract/event"
+)
+
+// position of an indexed event: block height(big endian) + tx hash
+const eventPositionLen = 4 + common.UINT256_SIZE
+
+// number of blocks committed in one batch when rebuilding event index
+const reindexBatchBlocks = 1000
+
+// number of stale index keys deleted in one batch when rebuilding event index
+const reindexBatchDeletes = 10000
+
+//SaveEventIndex index the event notifies of block by contract address and topic, and save the bloom of evm logs.
+//An empty value is saved for the block without evm log, so the block is known to be indexed at little space cost
+func (this *EventStore) SaveEventIndex(height uint32, notifies []*event.ExecuteNotify) {
+	var bloom ethtypes.Bloom
+	hasEvmLog := false
+	for _, notify := range notifies {
+		for _, key := range genEventIndexKeys(height, notify) {
+			this.store.BatchPut(key, []byte{})
+		}
+		for _, n := range notify.Notify {
+			if evmLog, ok := DecodeEvmLog(n); ok {
+				hasEvmLog = true
+				bloom.Add(evmLog.Address.Bytes())
+				for _, topic := range evmLog.Topics {
+					bloom.Add(topic.Bytes())
+				}
+			}
+		}
+	}
+	if hasEvmLog {
+		this.store.BatchPut(genEvmLogBloomKey(height), bloom.Bytes())
+	} else {
+		this.store.BatchPut(genEvmLogBloomKey(height), []byte{})
+	}
+}
+
+//GetEvmLogBloom return the bloom of evm logs in block, an empty bloom if there is no evm log in block,
+//ErrNotFound if the block is not indexed
+func (this *EventStore) GetEvmLogBloom(height uint32) (ethtypes.Bloom, error) {
+	data, err := this.store.Get(genEvmLogBloomKey(height))
+	if err != nil {
+		return ethtypes.Bloom{}, err
+	}
+	if len(data) == 0 {
+		return ethtypes.Bloom{}, nil
+	}
+	if len(data) != ethtypes.BloomByteLength {
+		return ethtypes.Bloom{}, fmt.Errorf("invalid bloom length %d at height %d", len(data), height)
+	}
+	return ethtypes.BytesToBloom(data), nil
+}
+
+//DecodeEvmLog return the evm log saved in notify
+func DecodeEvmLog(notify *event.NotifyEventInfo) (*types.StorageLog, bool) {
+	if !notify.IsEvm {
+		return nil, false
+	}
+	var raw []byte
+	switch states := notify.States.(type) {
+	case hexutil.Bytes:
+		raw = states
+	case string:
+		data, err := hexutil.Decode(states)
+		if err != nil {
+			return nil, false
+		}
+		raw = data
+	default:
+		return nil, false
+	}
+	evmLog := &types.StorageLog{}
+	if err := evmLog.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, false
+	}
+	return evmLog, true
+}
+
+func (this *EventStore) pruneEventIndex(height uint32, txHash common.Uint256) {
+	notify, err := this.getEventNotifyByTx(txHash, true)
+	if err != nil {
+		return
+	}
+	for _, key := range genEventIndexKeys(height, notify) {
+		this.store.BatchDelete(key)
+	}
+}
+
+//GetEventNotifyByFilter return the notifies matched by filter in the order of block height and tx hash,
+//and the cursor to continue the query if there may be more results
+func (this *EventStore) GetEventNotifyByFilter(filter *store.EventFilter) ([]*store.EventLog, []byte, error) {
+	if len(filter.Contracts) == 0 {
+		return nil, nil, fmt.Errorf("contract address is required")
+	}
+	if filter.Limit == 0 || filter.FromHeight > filter.ToHeight {
+		return nil, nil, nil
+	}
+	start := make([]byte, eventPositionLen)
+	binary.BigEndian.PutUint32(start, filter.FromHeight)
+	if len(filter.Cursor) != 0 {
+		if len(filter.Cursor) != eventPositionLen {
+			return nil, nil, fmt.Errorf("invalid cursor length %d", len(filter.Cursor))
+		}
+		if bytes.Compare(filter.Cursor, start) >= 0 {
+			// the smallest key after cursor
+			start = append(append([]byte{}, filter.Cursor...), 0)
+		}
+	}
+	// larger than any position of ToHeight
+	end := make([]byte, eventPositionLen+1)
+	binary.BigEndian.PutUint32(end, filter.ToHeight)
+	for i := 4; i < len(end); i++ {
+		end[i] = 0xff
+	}
+
+	var positions [][]byte
+	for _, addr := range filter.Contracts {
+		prefix := genEventIndexPrefix(addr, filter.Topic)
+		startKey := append(append([]byte{}, prefix...), start...)
+		endKey := append(append([]byte{}, prefix...), end...)
+		iter := this.store.NewRangeIterator(startKey, endKey)
+		for count := uint32(0); count < filter.Limit && iter.Next(); count++ {
+			positions = append(positions, append([]byte{}, iter.Key()[len(prefix):]...))
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return nil, nil, err
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return bytes.Compare(positions[i], positions[j]) < 0
+	})
+	positions = dedupPositions(positions)
+
+	var cursor []byte
+	if uint32(len(positions)) >= filter.Limit {
+		positions = positions[:filter.Limit]
+		cursor = positions[len(positions)-1]
+	}
+
+	contracts := make(map[common.Address]bool, len(filter.Contracts))
+	for _, addr := range filter.Contracts {
+		contracts[addr] = true
+	}
+	logs := make([]*store.EventLog, 0, len(positions))
+	for _, pos := range positions {
+		txHash, err := common.Uint256ParseFromBytes(pos[4:])
+		if err != nil {
+			return nil, nil, err
+		}
+		notify, err := this.GetEventNotifyByTx(txHash)
+		if err != nil {
+			if err == scom.ErrNotFound {
+				// event notify of pruned block
+				continue
+			}
+			return nil, nil, err
+		}
+		evtLog := &store.EventLog{
+			Height: binary.BigEndian.Uint32(pos[:4]),
+			TxHash: txHash,
+			State:  notify.State,
+		}
+		for _, n := range notify.Notify {
+			if !contracts[n.ContractAddress] {
+				continue
+			}
+			if filter.Topic != "" {
+				if topic, ok := NotifyTopic(n); !ok || topic != filter.Topic {
+					continue
+				}
+			}
+			evtLog.Notify = append(evtLog.Notify, n)
+		}
+		logs = append(logs, evtLog)
+	}
+	return logs, cursor, nil
+}
+
+//ReindexEvents rebuild the event notify index from the event notifies saved in block [0, endHeight]
+func (this *EventStore) ReindexEvents(endHeight uint32, progress func(height uint32)) error {
+	for _, prefix := range []scom.DataEntryPrefix{scom.EVENT_INDEX_CONTRACT, scom.EVENT_INDEX_TOPIC, scom.EVENT_EVM_LOG_BLOOM} {
+		for {
+			keys, err := this.prefixKeys([]byte{byte(prefix)}, reindexBatchDeletes)
+			if err != nil {
+				return err
+			}
+			if len(keys) == 0 {
+				break
+			}
+			this.NewBatch()
+			for _, key := range keys {
+				this.store.BatchDelete(key)
+			}
+			if err := this.CommitTo(); err != nil {
+				return fmt.Errorf("delete event index error:%s", err)
+			}
+		}
+	}
+
+	this.NewBatch()
+	for height := uint32(0); height <= endHeight; height++ {
+		notifies, err := this.getEventNotifyByBlock(height, true)
+		if err != nil && err != scom.ErrNotFound {
+			return fmt.Errorf("getEventNotifyByBlock height:%d error:%s", height, err)
+		}
+		this.SaveEventIndex(height, notifies)
+		if height%reindexBatchBlocks == 0 || height == endHeight {
+			if err := this.CommitTo(); err != nil {
+				return fmt.Errorf("commit event index height:%d error:%s", height, err)
+			}
+			this.NewBatch()
+		}
+		if progress != nil {
+			progress(height)
+		}
+	}
+	return nil
+}
+
+//prefixKeys return at most limit keys with the prefix
+func (this *EventStore) prefixKeys(prefix []byte, limit int) ([][]byte, error) {
+	iter := this.store.NewIterator(prefix)
+	defer iter.Release()
+	var keys [][]byte
+	for len(keys) < limit && iter.Next() {
+		keys = append(keys, append([]byte{}, iter.Key()...))
+	}
+	return keys, iter.Error()
+}
+
+//NotifyTopic return the string form of the first element of notify states, which is the event name
+//for most contracts.
+func NotifyTopic(notify *event.NotifyEventInfo) (string, bool) {
+	if notify.IsEvm {
+		return "", false
+	}
+	raw, err := json.Marshal(notify.States)
+	if err != nil {
+		return "", false
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var states []interface{}
+	if err := dec.Decode(&states); err != nil || len(states) == 0 {
+		return "", false
+	}
+	if topic, ok := states[0].(string); ok {
+		return topic, true
+	}
+	topic, err := json.Marshal(states[0])
+	if err != nil {
+		return "", false
+	}
+	return string(topic), true
+}
+
+func genEventIndexKeys(height uint32, notify *event.ExecuteNotify) [][]byte {
+	var keys [][]byte
+	saved := make(map[string]bool)
+	for _, n := range notify.Notify {
+		prefixes := [][]byte{genEventIndexPrefix(n.ContractAddress, "")}
+		if topic, ok := NotifyTopic(n); ok {
+			prefixes = append(prefixes, genEventIndexPrefix(n.ContractAddress, topic))
+		}
+		for _, prefix := range prefixes {
+			key := genEventIndexKey(prefix, height, notify.TxHash)
+			if !saved[string(key)] {
+				saved[string(key)] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+func genEventIndexPrefix(contract common.Address, topic string) []byte {
+	if topic == "" {
+		key := make([]byte, 0, 1+common.ADDR_LEN+eventPositionLen)
+		key = append(key, byte(scom.EVENT_INDEX_CONTRACT))
+		return append(key, contract[:]...)
+	}
+	topicHash := sha256.Sum256([]byte(topic))
+	key := make([]byte, 0, 1+common.ADDR_LEN+len(topicHash)+eventPositionLen)
+	key = append(key, byte(scom.EVENT_INDEX_TOPIC))
+	key = append(key, contract[:]...)
+	return append(key, topicHash[:]...)
+}
+
+func genEventIndexKey(prefix []byte, height uint32, txHash common.Uint256) []byte {
+	key := make([]byte, len(prefix)+eventPositionLen)
+	copy(key, prefix)
+	binary.BigEndian.PutUint32(key[len(prefix):], height)
+	copy(key[len(prefix)+4:], txHash[:])
+	return key
+}
+
+func genEvmLogBloomKey(height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(scom.EVENT_EVM_LOG_BLOOM)
+	binary.BigEndian.PutUint32(key[1:], height)
+	return key
+}
+
+func dedupPositions(positions [][]byte) [][]byte {
+	if len(positions) == 0 {
+		return positions
+	}
+	result := positions[:1]
+	for _, pos := range positions[1:] {
+		if !bytes.Equal(pos, result[len(result)-1]) {
+			result = append(result, pos)
+		}
+	}
+	return result
+}
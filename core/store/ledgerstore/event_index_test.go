@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/store"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func saveTestEvents(t *testing.T, evtStore *EventStore, height uint32, notifies []*event.ExecuteNotify) {
+	evtStore.NewBatch()
+	var txs []common.Uint256
+	for _, notify := range notifies {
+		assert.Nil(t, evtStore.SaveEventNotifyByTx(notify.TxHash, notify))
+		txs = append(txs, notify.TxHash)
+	}
+	evtStore.SaveEventNotifyByBlock(height, txs)
+	evtStore.SaveEventIndex(height, notifies)
+	evtStore.SaveCurrentBlock(height, common.Uint256{})
+	assert.Nil(t, evtStore.CommitTo())
+}
+
+func newTestNotify(txHash common.Uint256, contract common.Address, topic string) *event.ExecuteNotify {
+	return &event.ExecuteNotify{
+		TxHash: txHash,
+		State:  event.CONTRACT_STATE_SUCCESS,
+		Notify: []*event.NotifyEventInfo{{ContractAddress: contract, States: []interface{}{topic, uint64(100)}}},
+	}
+}
+
+func TestEventIndex(t *testing.T) {
+	evtStore := &EventStore{store: leveldbstore.NewMemLevelDBStore()}
+	contractA := common.Address{1}
+	contractB := common.Address{2}
+	for h := uint32(1); h <= 10; h++ {
+		saveTestEvents(t, evtStore, h, []*event.ExecuteNotify{
+			newTestNotify(common.Uint256{byte(h), 1}, contractA, "transfer"),
+			newTestNotify(common.Uint256{byte(h), 2}, contractA, "approve"),
+			newTestNotify(common.Uint256{byte(h), 3}, contractB, "transfer"),
+		})
+	}
+
+	// indexed block without evm log has an empty bloom
+	bloom, err := evtStore.GetEvmLogBloom(3)
+	assert.Nil(t, err)
+	assert.Equal(t, ethtypes.Bloom{}, bloom)
+	_, err = evtStore.GetEvmLogBloom(11)
+	assert.Equal(t, scom.ErrNotFound, err)
+
+	filter := &store.EventFilter{FromHeight: 3, ToHeight: 5, Contracts: []common.Address{contractA}, Limit: 100}
+	logs, cursor, err := evtStore.GetEventNotifyByFilter(filter)
+	assert.Nil(t, err)
+	assert.Nil(t, cursor)
+	assert.Equal(t, 6, len(logs))
+	assert.Equal(t, uint32(3), logs[0].Height)
+	assert.Equal(t, uint32(5), logs[5].Height)
+
+	filter.Topic = "transfer"
+	logs, _, err = evtStore.GetEventNotifyByFilter(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(logs))
+	for _, l := range logs {
+		assert.Equal(t, common.Uint256{byte(l.Height), 1}, l.TxHash)
+		assert.Equal(t, 1, len(l.Notify))
+	}
+
+	// paging over two contracts
+	filter = &store.EventFilter{FromHeight: 1, ToHeight: 10, Contracts: []common.Address{contractA, contractB},
+		Topic: "transfer", Limit: 3}
+	var all []*store.EventLog
+	for {
+		logs, cursor, err = evtStore.GetEventNotifyByFilter(filter)
+		assert.Nil(t, err)
+		all = append(all, logs...)
+		if cursor == nil {
+			break
+		}
+		filter.Cursor = cursor
+	}
+	assert.Equal(t, 20, len(all))
+	for i := 1; i < len(all); i++ {
+		assert.True(t, all[i-1].Height <= all[i].Height)
+		assert.NotEqual(t, all[i-1].TxHash, all[i].TxHash)
+	}
+
+	// rebuild the index from saved notifies
+	assert.Nil(t, evtStore.ReindexEvents(10, nil))
+	filter = &store.EventFilter{FromHeight: 0, ToHeight: 10, Contracts: []common.Address{contractB}, Limit: 100}
+	logs, _, err = evtStore.GetEventNotifyByFilter(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(logs))
+
+	// pruned block is removed from index
+	evtStore.NewBatch()
+	evtStore.PruneBlock(1, []common.Uint256{{1, 1}, {1, 2}, {1, 3}})
+	assert.Nil(t, evtStore.CommitTo())
+	logs, _, err = evtStore.GetEventNotifyByFilter(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 9, len(logs))
+	_, err = evtStore.GetEvmLogBloom(1)
+	assert.Equal(t, scom.ErrNotFound, err)
+}
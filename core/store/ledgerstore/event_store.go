@@ -28,7 +28,6 @@ import (
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/common/serialization"
 	scom "github.com/ontio/ontology/core/store/common"
-	"github.com/ontio/ontology/core/store/leveldbstore"
 	"github.com/ontio/ontology/smartcontract/event"
 )
 
@@ -38,13 +37,13 @@ var UseNumber = false
 
 //Saving event notifies gen by smart contract execution
 type EventStore struct {
-	dbDir string                     //Store path
-	store *leveldbstore.LevelDBStore //Store handler
+	dbDir string            //Store path
+	store scom.PersistStore //Store handler
 }
 
 //NewEventStore return event store instance
 func NewEventStore(dbDir string) (*EventStore, error) {
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := newPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -83,13 +82,17 @@ func (this *EventStore) SaveEventNotifyByBlock(height uint32, txHashs []common.U
 
 //GetEventNotifyByTx return event notify by trasanction hash
 func (this *EventStore) GetEventNotifyByTx(txHash common.Uint256) (*event.ExecuteNotify, error) {
+	return this.getEventNotifyByTx(txHash, UseNumber)
+}
+
+func (this *EventStore) getEventNotifyByTx(txHash common.Uint256, useNumber bool) (*event.ExecuteNotify, error) {
 	key := genEventNotifyByTxKey(txHash)
 	data, err := this.store.Get(key)
 	if err != nil {
 		return nil, err
 	}
 	var notify event.ExecuteNotify
-	if UseNumber {
+	if useNumber {
 		dec := json.NewDecoder(bytes.NewReader(data))
 		dec.UseNumber()
 		if err = dec.Decode(&notify); err != nil {
@@ -103,6 +106,10 @@ func (this *EventStore) GetEventNotifyByTx(txHash common.Uint256) (*event.Execut
 
 //GetEventNotifyByBlock return all event notify of transaction in block
 func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error) {
+	return this.getEventNotifyByBlock(height, UseNumber)
+}
+
+func (this *EventStore) getEventNotifyByBlock(height uint32, useNumber bool) ([]*event.ExecuteNotify, error) {
 	key := genEventNotifyByBlockKey(height)
 	data, err := this.store.Get(key)
 	if err != nil {
@@ -120,7 +127,7 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 		if err != nil {
 			return nil, fmt.Errorf("txHash.Deserialize error %s", err)
 		}
-		evtNotify, err := this.GetEventNotifyByTx(txHash)
+		evtNotify, err := this.getEventNotifyByTx(txHash, useNumber)
 		if err != nil {
 			log.Errorf("getEventNotifyByTx Height:%d by txhash:%s error:%s", height, txHash.ToHexString(), err)
 			continue
@@ -133,7 +140,9 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 func (this *EventStore) PruneBlock(height uint32, hashes []common.Uint256) {
 	key := genEventNotifyByBlockKey(height)
 	this.store.BatchDelete(key)
+	this.store.BatchDelete(genEvmLogBloomKey(height))
 	for _, hash := range hashes {
+		this.pruneEventIndex(height, hash)
 		this.store.BatchDelete(genEventNotifyByTxKey(hash))
 	}
 }
@@ -132,6 +132,12 @@ func NewLedgerStore(dataDir string, stateHashHeight uint32) (*LedgerStoreImp, er
 	if err != nil {
 		return nil, fmt.Errorf("NewStateStore error %s", err)
 	}
+	if sysconfig.DefConfig.Common.EnableArchive {
+		err = stateStore.EnableArchive()
+		if err != nil {
+			return nil, fmt.Errorf("EnableArchive error %s", err)
+		}
+	}
 	ledgerStore.stateStore = stateStore
 
 	eventState, err := NewEventStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirEvent))
@@ -336,7 +342,7 @@ func (this *LedgerStoreImp) recoverStore() error {
 		if err != nil {
 			return fmt.Errorf("save to state store height:%d error:%s", i, err)
 		}
-		this.saveBlockToEventStore(block)
+		this.saveBlockToEventStore(block, result.Notify)
 		err = this.eventStore.CommitTo()
 		if err != nil {
 			return fmt.Errorf("eventStore.CommitTo height:%d error %s", i, err)
@@ -843,6 +849,13 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 
 	log.Debugf("the state transition hash of block %d is:%s", blockHeight, result.Hash.ToHexString())
 
+	if this.stateStore.archiveEnabled {
+		err = this.stateStore.archiveWriteSet(blockHeight, result.WriteSet)
+		if err != nil {
+			return fmt.Errorf("archiveWriteSet error %s", err)
+		}
+	}
+
 	result.WriteSet.ForEach(func(key, val []byte) {
 		if len(val) == 0 {
 			this.stateStore.BatchDeleteRawKey(key)
@@ -854,7 +867,7 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 	return nil
 }
 
-func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block) {
+func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block, notifies []*event.ExecuteNotify) {
 	blockHash := block.Hash()
 	blockHeight := block.Header.Height
 	txs := make([]common.Uint256, 0)
@@ -865,6 +878,9 @@ func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block) {
 	if len(txs) > 0 {
 		this.eventStore.SaveEventNotifyByBlock(block.Header.Height, txs)
 	}
+	if sysconfig.DefConfig.Common.EnableEventLog {
+		this.eventStore.SaveEventIndex(blockHeight, notifies)
+	}
 	this.eventStore.SaveCurrentBlock(blockHeight, blockHash)
 }
 
@@ -944,7 +960,7 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, crossChainMsg *types
 	if err != nil {
 		return fmt.Errorf("save to state store height:%d error:%s", blockHeight, err)
 	}
-	this.saveBlockToEventStore(block)
+	this.saveBlockToEventStore(block, result.Notify)
 	err = this.blockStore.CommitTo()
 	if err != nil {
 		return fmt.Errorf("blockStore.CommitTo height:%d error %s", blockHeight, err)
@@ -1035,7 +1051,7 @@ func (this *LedgerStoreImp) handleTransaction(overlay *overlaydb.OverlayDB, cach
 			Height:    block.Header.Height,
 			Timestamp: block.Header.Timestamp,
 		}
-		_, err = this.stateStore.HandleEIP155Transaction(this, cache, eiptx, ctx, notify, true)
+		_, err = this.stateStore.HandleEIP155Transaction(this, cache, eiptx, ctx, notify, true, evm2.Config{})
 		if overlay.Error() != nil {
 			return nil, nil, fmt.Errorf("HandleInvokeTransaction tx %s error %s", txHash.ToHexString(), overlay.Error())
 		}
@@ -1208,6 +1224,36 @@ func (this *LedgerStoreImp) GetStorageItem(contract common.Address, key []byte)
 	return storageItem.Value, nil
 }
 
+//GetStorageItemAt return the storage value of the key in smart contract at block height. Wrap function of StateStore.GetStorageStateAt
+func (this *LedgerStoreImp) GetStorageItemAt(contract common.Address, key []byte, height uint32) ([]byte, error) {
+	storageKey := &states.StorageKey{
+		ContractAddress: contract,
+		Key:             key,
+	}
+	storageItem, err := this.stateStore.GetStorageStateAt(storageKey, height)
+	if err != nil {
+		return nil, err
+	}
+	if storageItem == nil {
+		return nil, nil
+	}
+	return storageItem.Value, nil
+}
+
+//NewOverlayDB return a view of the latest state, and the height of the block the state is at
+func (this *LedgerStoreImp) NewOverlayDB() (*overlaydb.OverlayDB, uint32, error) {
+	_, height, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return nil, 0, err
+	}
+	return this.stateStore.NewOverlayDB(), height, nil
+}
+
+//NewOverlayDBAt return a read only view of the state at block height. Wrap function of StateStore.NewOverlayDBAt
+func (this *LedgerStoreImp) NewOverlayDBAt(height uint32) (*overlaydb.OverlayDB, error) {
+	return this.stateStore.NewOverlayDBAt(height)
+}
+
 //GetEventNotifyByTx return the events notify gen by executing of smart contract.  Wrap function of EventStore.GetEventNotifyByTx
 func (this *LedgerStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return this.eventStore.GetEventNotifyByTx(tx)
@@ -1218,6 +1264,16 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
+//GetEventNotifyByFilter return the event notifies matched by filter. Wrap function of EventStore.GetEventNotifyByFilter
+func (this *LedgerStoreImp) GetEventNotifyByFilter(filter *store.EventFilter) ([]*store.EventLog, []byte, error) {
+	return this.eventStore.GetEventNotifyByFilter(filter)
+}
+
+//GetEvmLogBloom return the bloom of evm logs in block. Wrap function of EventStore.GetEvmLogBloom
+func (this *LedgerStoreImp) GetEvmLogBloom(height uint32) (types3.Bloom, error) {
+	return this.eventStore.GetEvmLogBloom(height)
+}
+
 //PreExecuteContract return the result of smart contract execution without commit to store
 func (this *LedgerStoreImp) PreExecuteContractBatch(txes []*types.Transaction, atomic bool) ([]*sstate.PreExecResult, uint32, error) {
 	if atomic {
@@ -1243,10 +1299,64 @@ func (this *LedgerStoreImp) PreExecuteEIP155(tx *types3.Transaction, ctx Eip155C
 	cache := storage.NewCacheDB(overlay)
 
 	notify := &event.ExecuteNotify{State: event.CONTRACT_STATE_FAIL, TxIndex: ctx.TxIndex}
-	result, err := this.stateStore.HandleEIP155Transaction(this, cache, tx, ctx, notify, false)
+	result, err := this.stateStore.HandleEIP155Transaction(this, cache, tx, ctx, notify, false, evm2.Config{})
 	return result, notify, err
 }
 
+//TraceEip155Tx re-execute the evm transaction with tracer on the state before its block, after replaying the
+//transactions ahead of it in the block. The state of the parent block is read from the state archive, and the
+//gas table of the current global params is used for replaying neovm transactions
+func (this *LedgerStoreImp) TraceEip155Tx(txHash common.Uint256, tracer evm2.Tracer) (*types4.ExecutionResult, error) {
+	tx, height, err := this.GetTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+	eiptx, err := tx.GetEIP155Tx()
+	if err != nil {
+		return nil, fmt.Errorf("transaction %s is not an evm transaction", txHash.ToHexString())
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	txIndex := -1
+	for i, t := range block.Transactions {
+		if t.Hash() == txHash {
+			txIndex = i
+			break
+		}
+	}
+	if txIndex < 0 || height == 0 {
+		return nil, fmt.Errorf("transaction %s not found in block %d", txHash.ToHexString(), height)
+	}
+	overlay, err := this.stateStore.NewOverlayDBAt(height - 1)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d is unavailable: %s", height-1, err)
+	}
+	gasTable := make(map[string]uint64)
+	neovm.GAS_TABLE.Range(func(k, value interface{}) bool {
+		gasTable[k.(string)] = value.(uint64)
+		return true
+	})
+	cache := storage.NewCacheDB(overlay)
+	for i, t := range block.Transactions[:txIndex] {
+		cache.Reset()
+		if _, _, err := this.handleTransaction(overlay, cache, gasTable, block, t, uint32(i)); err != nil {
+			return nil, fmt.Errorf("replay transaction %d of block %d error: %s", i, height, err)
+		}
+	}
+	cache.Reset()
+	ctx := Eip155Context{
+		BlockHash: block.Hash(),
+		TxIndex:   uint32(txIndex),
+		Height:    height,
+		Timestamp: block.Header.Timestamp,
+	}
+	notify := &event.ExecuteNotify{TxHash: txHash, State: event.CONTRACT_STATE_FAIL, TxIndex: uint32(txIndex)}
+	return this.stateStore.HandleEIP155Transaction(this, cache, eiptx, ctx, notify, true,
+		evm2.Config{Debug: true, Tracer: tracer})
+}
+
 func (this *LedgerStoreImp) GetEthCode(hash common2.Hash) ([]byte, error) {
 	return this.stateStore.GetEthCode(hash)
 }
@@ -1259,6 +1369,14 @@ func (this *LedgerStoreImp) GetEthAccount(address common2.Address) (*storage.Eth
 	return this.stateStore.GetEthAccount(address)
 }
 
+func (this *LedgerStoreImp) GetEthStateAt(address common2.Address, key common2.Hash, height uint32) ([]byte, error) {
+	return this.stateStore.GetEthStateAt(address, key, height)
+}
+
+func (this *LedgerStoreImp) GetEthAccountAt(address common2.Address, height uint32) (*storage.EthAccount, error) {
+	return this.stateStore.GetEthAccountAt(address, height)
+}
+
 //PreExecuteContract return the result of smart contract execution without commit to store
 func (this *LedgerStoreImp) PreExecuteContractWithParam(tx *types.Transaction, preParam PrexecuteParam) (*sstate.PreExecResult, error) {
 	height := this.GetCurrentBlockHeight()
@@ -1393,7 +1511,30 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.P
 }
 
 func (this *LedgerStoreImp) PreExecuteEip155Tx(msg types3.Message) (*types4.ExecutionResult, error) {
-	height := this.GetCurrentBlockHeight()
+	cache := this.GetCacheDB()
+	return this.preExecuteEip155Msg(msg, cache, this.GetCurrentBlockHeight(), evm2.Config{})
+}
+
+//TraceEip155Call execute the evm message with tracer on top of the state after the block of height, without commit
+//to store. The state before the current block is read from the state archive
+func (this *LedgerStoreImp) TraceEip155Call(msg types3.Message, height uint32, tracer evm2.Tracer) (*types4.ExecutionResult, error) {
+	current := this.GetCurrentBlockHeight()
+	if height > current {
+		return nil, fmt.Errorf("block %d not found, current height is %d", height, current)
+	}
+	cache := this.GetCacheDB()
+	if height < current {
+		overlay, err := this.stateStore.NewOverlayDBAt(height)
+		if err != nil {
+			return nil, fmt.Errorf("state of block %d is unavailable: %s", height, err)
+		}
+		cache = storage.NewCacheDB(overlay)
+	}
+	return this.preExecuteEip155Msg(msg, cache, height, evm2.Config{Debug: true, Tracer: tracer})
+}
+
+func (this *LedgerStoreImp) preExecuteEip155Msg(msg types3.Message, cache *storage.CacheDB, height uint32,
+	vmConfig evm2.Config) (*types4.ExecutionResult, error) {
 	// use previous block time to make it predictable for easy test
 	blockTime := uint32(time.Now().Unix())
 	if header, err := this.GetHeaderByHeight(height); err == nil {
@@ -1409,9 +1550,8 @@ func (this *LedgerStoreImp) PreExecuteEip155Tx(msg types3.Message) (*types4.Exec
 	config := params.GetChainConfig(sysconfig.DefConfig.P2PNode.EVMChainId)
 	txContext := evm.NewEVMTxContext(msg)
 	blockContext := evm.NewEVMBlockContext(height, blockTime, this)
-	cache := this.GetCacheDB()
 	statedb := storage.NewStateDB(cache, common2.Hash{}, common2.Hash(ctx.BlockHash), ong.OngBalanceHandle{})
-	vmenv := evm2.NewEVM(blockContext, txContext, statedb, config, evm2.Config{})
+	vmenv := evm2.NewEVM(blockContext, txContext, statedb, config, vmConfig)
 	res, err := evm.ApplyMessage(vmenv, msg, common2.Address(utils.GovernanceContractAddress))
 	return res, err
 }
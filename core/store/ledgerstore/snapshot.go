@@ -0,0 +1,568 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/common/serialization"
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/merkle"
+)
+
+//Layout of state snapshot:
+//  magic | version | manifest
+//  headers from genesis to snapshot height
+//  block of snapshot height
+//  cross chain msg of snapshot height - 1
+//  tree size and hashes of block merkle hash store
+//  key value pairs of state store, ended by false
+//  sha256 checksum of all the data above
+const (
+	SNAPSHOT_VERSION    = byte(1) //Version of state snapshot
+	SNAPSHOT_BATCH_SIZE = 10000   //Count of records in one commit batch when importing snapshot
+)
+
+var snapshotMagic = []byte("ONTSNAP")
+
+//SnapshotManifest describe the ledger a state snapshot taken from. StateHash is the hash of all the contract
+//states, which is the same on every node at the height, so it can be checked against trusted nodes
+type SnapshotManifest struct {
+	Height          uint32
+	BlockHash       common.Uint256
+	GenesisHash     common.Uint256
+	StateMerkleRoot common.Uint256
+	StateHash       common.Uint256
+}
+
+func (this *SnapshotManifest) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteHash(this.BlockHash)
+	sink.WriteHash(this.GenesisHash)
+	sink.WriteHash(this.StateMerkleRoot)
+	sink.WriteHash(this.StateHash)
+}
+
+func (this *SnapshotManifest) Deserialize(r io.Reader) error {
+	var err error
+	this.Height, err = serialization.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	for _, hash := range []*common.Uint256{&this.BlockHash, &this.GenesisHash, &this.StateMerkleRoot, &this.StateHash} {
+		if _, err = io.ReadFull(r, hash[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ExportSnapshot write the states of current block, with the header chain and block merkle hash store to w.
+//The node should be stopped before export.
+func (this *LedgerStoreImp) ExportSnapshot(w io.Writer) (*SnapshotManifest, error) {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	height, blockHash := this.GetCurrentBlock()
+	stateRoot, err := this.stateStore.GetStateMerkleRoot(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetStateMerkleRoot error %s", err)
+	}
+	stateHash, err := calculateTotalStateHash(this.stateStore.NewOverlayDB())
+	if err != nil {
+		return nil, fmt.Errorf("calculateTotalStateHash error %s", err)
+	}
+	manifest := &SnapshotManifest{
+		Height:          height,
+		BlockHash:       blockHash,
+		GenesisHash:     this.GetBlockHash(0),
+		StateMerkleRoot: stateRoot,
+		StateHash:       stateHash,
+	}
+
+	hasher := sha256.New()
+	bw := bufio.NewWriter(w)
+	writer := io.MultiWriter(bw, hasher)
+	sink := common.NewZeroCopySink(nil)
+	flush := func() error {
+		_, err := writer.Write(sink.Bytes())
+		sink.Reset()
+		return err
+	}
+
+	sink.WriteBytes(snapshotMagic)
+	sink.WriteByte(SNAPSHOT_VERSION)
+	manifest.Serialization(sink)
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	for h := uint32(0); h <= height; h++ {
+		header, err := this.blockStore.GetRawHeader(this.GetBlockHash(h))
+		if err != nil {
+			return nil, fmt.Errorf("GetRawHeader height:%d error %s", h, err)
+		}
+		sink.WriteVarBytes(header.Payload)
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	block, err := this.blockStore.GetBlock(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlock height:%d error %s", height, err)
+	}
+	sink.WriteVarBytes(block.ToArray())
+	var ccMsg *types.CrossChainMsg
+	if height > 0 {
+		ccMsg, err = this.crossChainStore.GetCrossChainMsg(height - 1)
+		if err != nil {
+			return nil, fmt.Errorf("GetCrossChainMsg height:%d error %s", height-1, err)
+		}
+	}
+	sink.WriteBool(ccMsg != nil)
+	if ccMsg != nil {
+		sink.WriteVarBytes(common.SerializeToBytes(ccMsg))
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if this.stateStore.merkleHashStore == nil {
+		return nil, fmt.Errorf("block merkle hash store is not available")
+	}
+	treeSize := this.stateStore.merkleTree.TreeSize()
+	sink.WriteUint32(treeSize)
+	for i := int64(0); i < merkle.StoredHashNum(treeSize); i++ {
+		hash, err := this.stateStore.merkleHashStore.GetHash(uint32(i))
+		if err != nil {
+			return nil, fmt.Errorf("merkle hash store GetHash pos:%d error %s", i, err)
+		}
+		sink.WriteHash(hash)
+		if sink.Size() >= SNAPSHOT_BATCH_SIZE*common.UINT256_SIZE {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	iter := this.stateStore.store.NewIterator(nil)
+	for iter.Next() {
+		key := iter.Key()
+		if isArchiveKey(key) {
+			continue
+		}
+		sink.WriteBool(true)
+		sink.WriteVarBytes(key)
+		sink.WriteVarBytes(iter.Value())
+		if err = flush(); err != nil {
+			break
+		}
+	}
+	iter.Release()
+	if err == nil {
+		err = iter.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("export states error %s", err)
+	}
+	sink.WriteBool(false)
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if _, err := bw.Write(hasher.Sum(nil)); err != nil {
+		return nil, err
+	}
+	return manifest, bw.Flush()
+}
+
+//ImportSnapshot restore an empty ledger store from the state snapshot in r. The headers are verified from genesis
+//block to snapshot height, and the block merkle tree rebuilt from them must match the signed block root of each
+//header. The signed headers do not commit to the states, so the hash of the imported contract states must equal
+//trustedStateHash, which should be got from nodes the operator trusts. The other imported states are checked
+//against the state merkle root of manifest, the block merkle tree and the signed cross chain msg.
+//After import the node syncs blocks from snapshot height + 1.
+func (this *LedgerStoreImp) ImportSnapshot(r io.Reader, genesisBlock *types.Block,
+	trustedStateHash common.Uint256) (*SnapshotManifest, error) {
+	if trustedStateHash == common.UINT256_EMPTY {
+		return nil, fmt.Errorf("trusted state hash is required, the states can not be verified by signed headers")
+	}
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	hasInit, err := this.hasAlreadyInitGenesisBlock()
+	if err != nil {
+		return nil, fmt.Errorf("hasAlreadyInit error %s", err)
+	}
+	if hasInit {
+		return nil, fmt.Errorf("ledger store is not empty")
+	}
+	if err = this.blockStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("blockStore.ClearAll error %s", err)
+	}
+	if err = this.stateStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("stateStore.ClearAll error %s", err)
+	}
+	if err = this.eventStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("eventStore.ClearAll error %s", err)
+	}
+
+	hasher := sha256.New()
+	br := bufio.NewReader(r)
+	reader := io.TeeReader(br, hasher)
+
+	magic := make([]byte, len(snapshotMagic))
+	if _, err = io.ReadFull(reader, magic); err != nil {
+		return nil, fmt.Errorf("read snapshot magic error %s", err)
+	}
+	if !bytes.Equal(magic, snapshotMagic) {
+		return nil, fmt.Errorf("invalid snapshot file")
+	}
+	version, err := serialization.ReadByte(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot version error %s", err)
+	}
+	if version != SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	manifest := new(SnapshotManifest)
+	if err = manifest.Deserialize(reader); err != nil {
+		return nil, fmt.Errorf("read snapshot manifest error %s", err)
+	}
+	if manifest.StateHash != trustedStateHash {
+		return nil, fmt.Errorf("state hash mismatch, trusted:%s, snapshot:%s",
+			trustedStateHash.ToHexString(), manifest.StateHash.ToHexString())
+	}
+	genesisHash := genesisBlock.Hash()
+	if manifest.GenesisHash != genesisHash {
+		return nil, fmt.Errorf("genesis block mismatch, expected:%s, got:%s",
+			genesisHash.ToHexString(), manifest.GenesisHash.ToHexString())
+	}
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) == "vbft" {
+		blkInfo, err := vconfig.VbftBlock(genesisBlock.Header)
+		if err != nil {
+			return nil, err
+		}
+		if blkInfo.NewChainConfig == nil {
+			return nil, fmt.Errorf("genesis block has no chain config")
+		}
+		peerInfo := make(map[string]uint32)
+		for _, p := range blkInfo.NewChainConfig.Peers {
+			peerInfo[p.ID] = p.Index
+		}
+		this.lock.Lock()
+		this.vbftPeerInfoMap[0] = peerInfo
+		this.lock.Unlock()
+	}
+
+	//the hash store is rebuilt from the headers, and then compared with the one in snapshot
+	if this.stateStore.merkleHashStore != nil {
+		this.stateStore.merkleHashStore.Close()
+	}
+	if err = os.Remove(this.stateStore.merklePath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	hashStore, err := newHashStore(this.stateStore.merklePath, 0)
+	if err != nil {
+		return nil, fmt.Errorf("NewFileHashStore error %s", err)
+	}
+	this.stateStore.merkleHashStore = hashStore
+	blockTree := merkle.NewTree(0, nil, hashStore)
+
+	this.blockStore.NewBatch()
+	prevHash := common.UINT256_EMPTY
+	for h := uint32(0); h <= manifest.Height; h++ {
+		payload, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read header height:%d error %s", h, err)
+		}
+		header, err := types.HeaderFromRawBytes(payload)
+		if err != nil {
+			return nil, fmt.Errorf("HeaderFromRawBytes height:%d error %s", h, err)
+		}
+		if header.Height != h {
+			return nil, fmt.Errorf("header height %d not equal expected height %d", header.Height, h)
+		}
+		blockHash := header.Hash()
+		if h == 0 {
+			if blockHash != genesisHash {
+				return nil, fmt.Errorf("genesis header mismatch")
+			}
+			if err = this.blockStore.SaveBlock(genesisBlock); err != nil {
+				return nil, err
+			}
+		} else {
+			if header.PrevBlockHash != prevHash {
+				return nil, fmt.Errorf("prev block hash of header %d is incorrect", h)
+			}
+			if err = this.verifyHeader(header); err != nil {
+				return nil, fmt.Errorf("verifyHeader height:%d error %s", h, err)
+			}
+			blockRoot := blockTree.GetRootWithNewLeaf(header.TransactionsRoot)
+			if blockRoot != header.BlockRoot {
+				return nil, fmt.Errorf("wrong block root at height:%d, expected:%s, got:%s",
+					h, blockRoot.ToHexString(), header.BlockRoot.ToHexString())
+			}
+			if err = this.blockStore.SaveHeader(&types.Block{Header: header}, 0); err != nil {
+				return nil, err
+			}
+			this.delHeaderCache(prevHash)
+		}
+		blockTree.AppendHash(header.TransactionsRoot)
+		this.addHeaderCache(header)
+		this.blockStore.SaveBlockHash(h, blockHash)
+		this.setHeaderIndex(h, blockHash)
+		prevHash = blockHash
+
+		if (h+1)%SNAPSHOT_BATCH_SIZE == 0 {
+			if err = this.blockStore.CommitTo(); err != nil {
+				return nil, fmt.Errorf("blockStore.CommitTo height:%d error %s", h, err)
+			}
+			this.blockStore.NewBatch()
+			log.Infof("ImportSnapshot headers %d/%d", h, manifest.Height)
+		}
+	}
+	if prevHash != manifest.BlockHash {
+		return nil, fmt.Errorf("block hash of snapshot height mismatch")
+	}
+	this.delHeaderCache(prevHash)
+
+	payload, err := serialization.ReadVarBytes(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read block error %s", err)
+	}
+	block, err := types.BlockFromRawBytes(payload)
+	if err != nil {
+		return nil, fmt.Errorf("BlockFromRawBytes error %s", err)
+	}
+	if block.Hash() != manifest.BlockHash {
+		return nil, fmt.Errorf("block of snapshot height mismatch")
+	}
+	txHashes := make([]common.Uint256, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		txHashes = append(txHashes, tx.Hash())
+	}
+	if common.ComputeMerkleRoot(txHashes) != block.Header.TransactionsRoot {
+		return nil, fmt.Errorf("transactions root of block %d mismatch", block.Header.Height)
+	}
+	if err = this.blockStore.SaveBlock(block); err != nil {
+		return nil, err
+	}
+
+	hasMsg, err := serialization.ReadBool(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read cross chain msg error %s", err)
+	}
+	var ccMsg *types.CrossChainMsg
+	if hasMsg {
+		payload, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read cross chain msg error %s", err)
+		}
+		ccMsg = new(types.CrossChainMsg)
+		if err = ccMsg.Deserialization(common.NewZeroCopySource(payload)); err != nil {
+			return nil, fmt.Errorf("cross chain msg deserialize error %s", err)
+		}
+		if ccMsg.Height+1 != manifest.Height {
+			return nil, fmt.Errorf("cross chain msg height %d not equal snapshot height - 1", ccMsg.Height)
+		}
+		if err = this.verifyCrossChainMsg(ccMsg, block.Header.Bookkeepers); err != nil {
+			return nil, fmt.Errorf("verifyCrossChainMsg error %s", err)
+		}
+	}
+
+	treeSize, err := serialization.ReadUint32(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read block merkle tree size error %s", err)
+	}
+	if treeSize != blockTree.TreeSize() {
+		return nil, fmt.Errorf("block merkle tree size %d not equal header count %d", treeSize, blockTree.TreeSize())
+	}
+	if err = hashStore.Flush(); err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < merkle.StoredHashNum(treeSize); i++ {
+		var hash common.Uint256
+		if _, err = io.ReadFull(reader, hash[:]); err != nil {
+			return nil, fmt.Errorf("read block merkle hash error %s", err)
+		}
+		stored, err := hashStore.GetHash(uint32(i))
+		if err != nil {
+			return nil, fmt.Errorf("merkle hash store GetHash pos:%d error %s", i, err)
+		}
+		if stored != hash {
+			return nil, fmt.Errorf("block merkle hash store mismatch at pos:%d", i)
+		}
+	}
+
+	this.stateStore.NewBatch()
+	count := 0
+	for {
+		more, err := serialization.ReadBool(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read states error %s", err)
+		}
+		if !more {
+			break
+		}
+		key, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read states error %s", err)
+		}
+		value, err := serialization.ReadVarBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read states error %s", err)
+		}
+		if len(key) == 0 || isArchiveKey(key) {
+			return nil, fmt.Errorf("invalid state key %x", key)
+		}
+		this.stateStore.BatchPutRawKeyVal(key, value)
+		count++
+		if count%SNAPSHOT_BATCH_SIZE == 0 {
+			if err = this.stateStore.CommitTo(); err != nil {
+				return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+			}
+			this.stateStore.NewBatch()
+		}
+	}
+	if err = this.stateStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+	}
+
+	checksum := make([]byte, sha256.Size)
+	if _, err = io.ReadFull(br, checksum); err != nil {
+		return nil, fmt.Errorf("read snapshot checksum error %s", err)
+	}
+	if !bytes.Equal(checksum, hasher.Sum(nil)) {
+		return nil, fmt.Errorf("snapshot checksum mismatch")
+	}
+
+	if err = this.verifySnapshotStates(manifest, blockTree, ccMsg); err != nil {
+		return nil, err
+	}
+	if err = this.crossChainStore.SaveMsgToCrossChainStore(ccMsg); err != nil {
+		return nil, err
+	}
+
+	var start uint32
+	for ; start+HEADER_INDEX_BATCH_SIZE <= manifest.Height; start += HEADER_INDEX_BATCH_SIZE {
+		indexList := make([]common.Uint256, HEADER_INDEX_BATCH_SIZE)
+		for i := range indexList {
+			indexList[i] = this.getHeaderIndex(start + uint32(i))
+		}
+		this.blockStore.SaveHeaderIndexList(start, indexList)
+	}
+	this.lock.Lock()
+	this.storedIndexCount = start
+	this.lock.Unlock()
+	if err = this.blockStore.SaveCurrentBlock(manifest.Height, manifest.BlockHash); err != nil {
+		return nil, err
+	}
+	if err = this.blockStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+	this.eventStore.NewBatch()
+	this.eventStore.SaveCurrentBlock(manifest.Height, manifest.BlockHash)
+	if err = this.eventStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+	//the version is saved at last, so an interrupted import will be cleared at next start
+	if err = this.initGenesisBlock(); err != nil {
+		return nil, err
+	}
+	this.setCurrentBlock(manifest.Height, manifest.BlockHash)
+
+	this.stateStore.merkleHashStore.Close()
+	if err = this.stateStore.init(manifest.Height); err != nil {
+		return nil, fmt.Errorf("stateStore init error %s", err)
+	}
+	return manifest, nil
+}
+
+func (this *LedgerStoreImp) verifySnapshotStates(manifest *SnapshotManifest, blockTree *merkle.CompactMerkleTree,
+	ccMsg *types.CrossChainMsg) error {
+	blockHash, height, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if height != manifest.Height || blockHash != manifest.BlockHash {
+		return fmt.Errorf("current block of states %d mismatch snapshot height %d", height, manifest.Height)
+	}
+	treeSize, hashes, err := this.stateStore.GetBlockMerkleTree()
+	if err != nil {
+		return fmt.Errorf("GetBlockMerkleTree error %s", err)
+	}
+	if treeSize != blockTree.TreeSize() || merkle.NewTree(treeSize, hashes, nil).Root() != blockTree.Root() {
+		return fmt.Errorf("block merkle tree of states mismatch the headers")
+	}
+	stateHash, err := calculateTotalStateHash(this.stateStore.NewOverlayDB())
+	if err != nil {
+		return fmt.Errorf("calculateTotalStateHash error %s", err)
+	}
+	if stateHash != manifest.StateHash {
+		return fmt.Errorf("state hash mismatch. expected: %s, got: %s",
+			manifest.StateHash.ToHexString(), stateHash.ToHexString())
+	}
+	stateRoot, err := this.stateStore.GetStateMerkleRoot(manifest.Height)
+	if err != nil {
+		return fmt.Errorf("GetStateMerkleRoot error %s", err)
+	}
+	if stateRoot != manifest.StateMerkleRoot {
+		return fmt.Errorf("state merkle root mismatch. expected: %s, got: %s",
+			manifest.StateMerkleRoot.ToHexString(), stateRoot.ToHexString())
+	}
+	if manifest.Height >= this.stateHashCheckHeight {
+		treeSize, hashes, err := this.stateStore.GetStateMerkleTree()
+		if err != nil {
+			return fmt.Errorf("GetStateMerkleTree error %s", err)
+		}
+		if merkle.NewTree(treeSize, hashes, nil).Root() != stateRoot {
+			return fmt.Errorf("state merkle tree mismatch the state merkle root")
+		}
+	}
+	if ccMsg != nil {
+		root, err := this.stateStore.GetCrossStatesRoot(ccMsg.Height)
+		if err != nil {
+			return fmt.Errorf("get cross states root fail:%s", err)
+		}
+		if root != ccMsg.StatesRoot {
+			return fmt.Errorf("cross state root compare fail, expected:%x actual:%x", ccMsg.StatesRoot, root)
+		}
+	}
+	return nil
+}
+
+func isArchiveKey(key []byte) bool {
+	return len(key) > 0 && (key[0] == byte(scom.ST_ARCHIVE) || key[0] == byte(scom.SYS_ARCHIVE_HEIGHT))
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/genesis"
+	"github.com/ontio/ontology/core/signature"
+	"github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	acc := account.NewAccount("")
+	bookkeepers := []keypair.PublicKey{acc.PublicKey}
+	consensusType, solo := config.DefConfig.Genesis.ConsensusType, config.DefConfig.Genesis.SOLO
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+	config.DefConfig.Genesis.SOLO = &config.SOLOConfig{
+		Bookkeepers: []string{hex.EncodeToString(keypair.SerializePublicKey(acc.PublicKey))},
+	}
+	defer func() {
+		config.DefConfig.Genesis.ConsensusType, config.DefConfig.Genesis.SOLO = consensusType, solo
+	}()
+	defer os.RemoveAll("test/snapshot")
+
+	nextBookkeeper, err := types.AddressFromBookkeepers(bookkeepers)
+	assert.Nil(t, err)
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+
+	source, err := NewLedgerStore("test/snapshot/source", 0)
+	assert.Nil(t, err)
+	defer source.Close()
+	assert.Nil(t, source.InitLedgerStoreWithGenesisBlock(genesisBlock, bookkeepers))
+
+	prev := genesisBlock.Header
+	for height := uint32(1); height <= 3; height++ {
+		header := &types.Header{
+			PrevBlockHash:    prev.Hash(),
+			BlockRoot:        source.GetBlockRootWithNewTxRoots(height, []common.Uint256{{}}),
+			Timestamp:        prev.Timestamp + 1,
+			Height:           height,
+			ConsensusData:    common.GetNonce(),
+			NextBookkeeper:   nextBookkeeper,
+			ConsensusPayload: []byte{},
+		}
+		hash := header.Hash()
+		sig, err := signature.Sign(acc, hash[:])
+		assert.Nil(t, err)
+		header.Bookkeepers = bookkeepers
+		header.SigData = [][]byte{sig}
+		block := &types.Block{Header: header}
+
+		result, err := source.ExecuteBlock(block)
+		assert.Nil(t, err)
+		assert.Nil(t, source.SubmitBlock(block, nil, result))
+		prev = header
+	}
+
+	snapshot := new(bytes.Buffer)
+	manifest, err := source.ExportSnapshot(snapshot)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), manifest.Height)
+	assert.Equal(t, prev.Hash(), manifest.BlockHash)
+
+	//tampered snapshot is refused
+	tampered := append([]byte{}, snapshot.Bytes()...)
+	tampered[len(tampered)-40] ^= 1
+	broken, err := NewLedgerStore("test/snapshot/broken", 0)
+	assert.Nil(t, err)
+	defer broken.Close()
+	_, err = broken.ImportSnapshot(bytes.NewReader(tampered), genesisBlock, manifest.StateHash)
+	assert.NotNil(t, err)
+
+	//snapshot without trusted state hash is refused
+	_, err = broken.ImportSnapshot(bytes.NewReader(snapshot.Bytes()), genesisBlock, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	_, err = broken.ImportSnapshot(bytes.NewReader(snapshot.Bytes()), genesisBlock, common.Uint256{1})
+	assert.NotNil(t, err)
+
+	//crafted snapshot with a consistent checksum is refused if its states mismatch the state hash
+	crafted := append([]byte{}, snapshot.Bytes()[:snapshot.Len()-sha256.Size]...)
+	stateHashPos := len(snapshotMagic) + 1 + 4 + 3*common.UINT256_SIZE
+	crafted[stateHashPos] ^= 1
+	checksum := sha256.Sum256(crafted)
+	craftedHash, err := common.Uint256ParseFromBytes(crafted[stateHashPos : stateHashPos+common.UINT256_SIZE])
+	assert.Nil(t, err)
+	_, err = broken.ImportSnapshot(bytes.NewReader(append(crafted, checksum[:]...)), genesisBlock, craftedHash)
+	assert.NotNil(t, err)
+
+	target, err := NewLedgerStore("test/snapshot/target", 0)
+	assert.Nil(t, err)
+	defer target.Close()
+	_, err = target.ImportSnapshot(bytes.NewReader(snapshot.Bytes()), genesisBlock, manifest.StateHash)
+	assert.Nil(t, err)
+	assert.Nil(t, target.InitLedgerStoreWithGenesisBlock(genesisBlock, bookkeepers))
+
+	height, hash := target.GetCurrentBlock()
+	assert.Equal(t, manifest.Height, height)
+	assert.Equal(t, manifest.BlockHash, hash)
+	assert.Equal(t, source.GetBlockRootWithNewTxRoots(4, []common.Uint256{{}}),
+		target.GetBlockRootWithNewTxRoots(4, []common.Uint256{{}}))
+	proof, err := source.GetMerkleProof(1, 3)
+	assert.Nil(t, err)
+	restored, err := target.GetMerkleProof(1, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, proof, restored)
+	header, err := target.GetHeaderByHeight(2)
+	assert.Nil(t, err)
+	assert.Equal(t, source.GetBlockHash(2), header.Hash())
+
+	iter := source.stateStore.store.NewIterator(nil)
+	for iter.Next() {
+		value, err := target.stateStore.store.Get(iter.Key())
+		assert.Nil(t, err)
+		assert.Equal(t, iter.Value(), value)
+	}
+	iter.Release()
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	common2 "github.com/ethereum/go-ethereum/common"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/states"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/ontio/ontology/smartcontract/storage"
+)
+
+// The state archive keeps the value of each state key before it was written by a block, so the state
+// at an earlier height can be rebuilt by rolling back the writes of the later blocks.
+//
+// archive key: ST_ARCHIVE + state key length(uint16) + state key + block height(big endian uint32)
+// archive value: exist flag(1 byte) + value of state key before the block
+
+var ErrArchiveDisabled = errors.New("state archive is not enabled")
+
+//EnableArchive start archiving the state write set of blocks. if the archive was interrupted, the state before
+//the next block is unavailable
+func (self *StateStore) EnableArchive() error {
+	next := uint32(0)
+	_, height, err := self.GetCurrentBlock()
+	if err == nil {
+		next = height + 1
+	} else if err != scom.ErrNotFound {
+		return err
+	}
+	start, last, err := self.getArchiveHeight()
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	if err == scom.ErrNotFound || next == 0 || last+1 != next {
+		start = next
+		log.Infof("state archive start from block %d", start)
+	}
+	self.archiveEnabled = true
+	self.archiveStart = start
+	return nil
+}
+
+//archiveWriteSet save the previous values of the keys in write set of block to batch
+func (self *StateStore) archiveWriteSet(height uint32, writeSet *overlaydb.MemDB) error {
+	var err error
+	writeSet.ForEach(func(key, val []byte) {
+		if err != nil {
+			return
+		}
+		prev, e := self.store.Get(key)
+		value := []byte{1}
+		if e == scom.ErrNotFound {
+			value = []byte{0}
+		} else if e != nil {
+			err = e
+			return
+		}
+		self.store.BatchPut(genArchiveKey(key, height), append(value, prev...))
+	})
+	if err != nil {
+		return err
+	}
+	self.store.BatchPut(genArchiveHeightKey(), genArchiveHeightValue(self.archiveStart, height))
+	return nil
+}
+
+//ArchivedHeight return the lowest height of which the state can be queried
+func (self *StateStore) ArchivedHeight() (uint32, error) {
+	if !self.archiveEnabled {
+		return 0, ErrArchiveDisabled
+	}
+	if self.archiveStart == 0 {
+		return 0, nil
+	}
+	return self.archiveStart - 1, nil
+}
+
+//getStateAt return the value of state key after the block of height was executed
+func (self *StateStore) getStateAt(key []byte, height uint32) ([]byte, error) {
+	_, current, err := self.GetCurrentBlock()
+	if err != nil {
+		return nil, err
+	}
+	if height >= current {
+		return self.store.Get(key)
+	}
+	lowest, err := self.ArchivedHeight()
+	if err != nil {
+		return nil, err
+	}
+	if height < lowest {
+		return nil, fmt.Errorf("state of height %d is not archived, lowest archived height is %d", height, lowest)
+	}
+
+	// the first write after height holds the value at height
+	prefix := genArchiveKeyPrefix(key)
+	limit := append(append([]byte{}, prefix...), 0xff, 0xff, 0xff, 0xff, 0xff)
+	iter := self.store.NewRangeIterator(genArchiveKey(key, height+1), limit)
+	defer iter.Release()
+	if !iter.First() {
+		if err := iter.Error(); err != nil {
+			return nil, err
+		}
+		return self.store.Get(key)
+	}
+	value := iter.Value()
+	if len(value) == 0 || value[0] == 0 {
+		return nil, scom.ErrNotFound
+	}
+	return append([]byte{}, value[1:]...), nil
+}
+
+//archivedStore serves the point reads of the state after the block of height. iterating and writing go to the
+//underlying store, so they must not be used
+type archivedStore struct {
+	scom.PersistStore
+	state  *StateStore
+	height uint32
+}
+
+func (self *archivedStore) Get(key []byte) ([]byte, error) {
+	return self.state.getStateAt(key, self.height)
+}
+
+func (self *archivedStore) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == scom.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//NewOverlayDBAt return an overlay db of the state after the block of height was executed, only the point reads
+//are served by the archive
+func (self *StateStore) NewOverlayDBAt(height uint32) (*overlaydb.OverlayDB, error) {
+	_, current, err := self.GetCurrentBlock()
+	if err != nil {
+		return nil, err
+	}
+	if height >= current {
+		return self.NewOverlayDB(), nil
+	}
+	lowest, err := self.ArchivedHeight()
+	if err != nil {
+		return nil, err
+	}
+	if height < lowest {
+		return nil, fmt.Errorf("state of height %d is not archived, lowest archived height is %d", height, lowest)
+	}
+	return overlaydb.NewOverlayDB(&archivedStore{PersistStore: self.store, state: self, height: height}), nil
+}
+
+//GetStorageStateAt return the storage value of the key in smart contract at block height
+func (self *StateStore) GetStorageStateAt(key *states.StorageKey, height uint32) (*states.StorageItem, error) {
+	data, err := self.getStateAt(self.genStorageKey(key), height)
+	if err != nil {
+		return nil, err
+	}
+	storageState := new(states.StorageItem)
+	err = storageState.Deserialization(common.NewZeroCopySource(data))
+	if err != nil {
+		return nil, err
+	}
+	return storageState, nil
+}
+
+//GetEthAccountAt return the eth account at block height
+func (self *StateStore) GetEthAccountAt(address common2.Address, height uint32) (*storage.EthAccount, error) {
+	value, err := self.getStateAt(genEthAccountKey(address), height)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return &storage.EthAccount{}, nil
+		}
+		return nil, err
+	}
+	account := new(storage.EthAccount)
+	err = account.Deserialization(common.NewZeroCopySource(value))
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+//GetEthStateAt return the eth contract storage at block height
+func (self *StateStore) GetEthStateAt(addr common2.Address, stateKey common2.Hash, height uint32) ([]byte, error) {
+	return self.getStateAt(genStateKey(addr, stateKey), height)
+}
+
+func (self *StateStore) getArchiveHeight() (start, last uint32, err error) {
+	value, err := self.store.Get(genArchiveHeightKey())
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(value) != 8 {
+		return 0, 0, fmt.Errorf("invalid archive height value: %x", value)
+	}
+	return binary.BigEndian.Uint32(value), binary.BigEndian.Uint32(value[4:]), nil
+}
+
+func genArchiveHeightKey() []byte {
+	return []byte{byte(scom.SYS_ARCHIVE_HEIGHT)}
+}
+
+func genArchiveHeightValue(start, last uint32) []byte {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint32(value, start)
+	binary.BigEndian.PutUint32(value[4:], last)
+	return value
+}
+
+func genArchiveKeyPrefix(key []byte) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 3+len(key)+4))
+	buf.WriteByte(byte(scom.ST_ARCHIVE))
+	binary.Write(buf, binary.BigEndian, uint16(len(key)))
+	buf.Write(key)
+	return buf.Bytes()
+}
+
+func genArchiveKey(key []byte, height uint32) []byte {
+	prefix := genArchiveKeyPrefix(key)
+	var h [4]byte
+	binary.BigEndian.PutUint32(h[:], height)
+	return append(prefix, h[:]...)
+}
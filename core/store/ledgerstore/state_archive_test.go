@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateArchive(t *testing.T) {
+	db := NewMemStateStore(0)
+	assert.Nil(t, db.EnableArchive())
+
+	key := []byte{byte(scom.ST_STORAGE), 1, 2, 3}
+	longer := []byte{byte(scom.ST_STORAGE), 1, 2, 3, 4}
+	submit := func(height uint32, kvs map[string][]byte) {
+		writeSet := overlaydb.NewMemDB(0, 0)
+		for k, v := range kvs {
+			if v == nil {
+				writeSet.Delete([]byte(k))
+			} else {
+				writeSet.Put([]byte(k), v)
+			}
+		}
+		db.NewBatch()
+		if db.archiveEnabled {
+			assert.Nil(t, db.archiveWriteSet(height, writeSet))
+		}
+		writeSet.ForEach(func(key, val []byte) {
+			if len(val) == 0 {
+				db.BatchDeleteRawKey(key)
+			} else {
+				db.BatchPutRawKeyVal(key, val)
+			}
+		})
+		assert.Nil(t, db.SaveCurrentBlock(height, common.Uint256{}))
+		assert.Nil(t, db.CommitTo())
+	}
+
+	submit(0, map[string][]byte{string(longer): []byte("x")})
+	submit(1, map[string][]byte{string(key): []byte("a")})
+	submit(2, nil)
+	submit(3, map[string][]byte{string(key): []byte("b"), string(longer): []byte("y")})
+	submit(4, map[string][]byte{string(key): nil})
+	submit(5, map[string][]byte{string(key): []byte("c")})
+
+	expected := []string{"", "a", "a", "b", "", "c", "c"}
+	for height, value := range expected {
+		val, err := db.getStateAt(key, uint32(height))
+		if value == "" {
+			assert.Equal(t, scom.ErrNotFound, err, "height %d", height)
+		} else {
+			assert.Nil(t, err)
+			assert.Equal(t, value, string(val), "height %d", height)
+		}
+	}
+	val, err := db.getStateAt(longer, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "x", string(val))
+
+	// archive restarted after interruption only serves the state after restart
+	db.archiveEnabled = false
+	submit(6, map[string][]byte{string(key): []byte("d")})
+	assert.Nil(t, db.EnableArchive())
+	submit(7, map[string][]byte{string(key): []byte("e")})
+	lowest, err := db.ArchivedHeight()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(6), lowest)
+	val, err = db.getStateAt(key, 6)
+	assert.Nil(t, err)
+	assert.Equal(t, "d", string(val))
+	_, err = db.getStateAt(key, 5)
+	assert.NotNil(t, err)
+
+	overlay, err := db.NewOverlayDBAt(6)
+	assert.Nil(t, err)
+	val, err = overlay.Get(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "d", string(val))
+	val, err = overlay.Get([]byte{byte(scom.ST_STORAGE), 9})
+	assert.Nil(t, err)
+	assert.Nil(t, val)
+	overlay, err = db.NewOverlayDBAt(7)
+	assert.Nil(t, err)
+	val, err = overlay.Get(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "e", string(val))
+	_, err = db.NewOverlayDBAt(5)
+	assert.NotNil(t, err)
+}
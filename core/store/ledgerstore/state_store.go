@@ -53,12 +53,14 @@ type StateStore struct {
 	deltaMerkleTree      *merkle.CompactMerkleTree //Merkle tree of delta state root
 	merkleHashStore      merkle.HashStore
 	stateHashCheckHeight uint32
+	archiveEnabled       bool   //Whether keep the history of states
+	archiveStart         uint32 //First block height of state archive
 }
 
 //NewStateStore return state store instance
 func NewStateStore(dbDir, merklePath string, stateHashCheckHeight uint32) (*StateStore, error) {
 	var err error
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := newPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +115,7 @@ func (self *StateStore) init(currBlockHeight uint32) error {
 	if treeSize > 0 && treeSize != currBlockHeight+1 {
 		return fmt.Errorf("merkle tree size is inconsistent with blockheight: %d", currBlockHeight+1)
 	}
-	self.merkleHashStore, err = merkle.NewFileHashStore(self.merklePath, treeSize)
+	self.merkleHashStore, err = newHashStore(self.merklePath, treeSize)
 	if err != nil {
 		log.Warn("merkle store is inconsistent with ChainStore. persistence will be disabled")
 	}
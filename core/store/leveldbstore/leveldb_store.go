@@ -39,6 +39,15 @@ type LevelDBStore struct {
 // too small will lead to high false positive rate.
 const BITSPERKEY = 10
 
+//BACKEND_NAME is the name of leveldb in PersistStore backend registry
+const BACKEND_NAME = "leveldb"
+
+func init() {
+	common.RegisterPersistStore(BACKEND_NAME, func(path string) (common.PersistStore, error) {
+		return NewLevelDBStore(path)
+	})
+}
+
 //NewLevelDBStore return LevelDBStore instance
 func NewLevelDBStore(file string) (*LevelDBStore, error) {
 	openFileCache := opt.DefaultOpenFilesCacheCapacity
@@ -157,3 +166,8 @@ func (self *LevelDBStore) NewIterator(prefix []byte) common.StoreIterator {
 
 	return iter
 }
+
+//NewRangeIterator return a iterator of leveldb with the key range [start, limit)
+func (self *LevelDBStore) NewRangeIterator(start, limit []byte) common.StoreIterator {
+	return self.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+}
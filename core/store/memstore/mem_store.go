@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package memstore
+
+import (
+	"sync"
+
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+//BACKEND_NAME is the name of in-memory store in PersistStore backend registry
+const BACKEND_NAME = "memory"
+
+func init() {
+	common.RegisterPersistStore(BACKEND_NAME, func(path string) (common.PersistStore, error) {
+		return NewMemStore(), nil
+	})
+}
+
+//MemStore is a PersistStore keeping all data in an overlaydb.MemDB, the data is lost after close.
+//MemDB treats empty value as deleted, so the values are saved with a one byte prefix.
+type MemStore struct {
+	lock  sync.RWMutex
+	db    *overlaydb.MemDB
+	batch *overlaydb.MemDB
+}
+
+//NewMemStore return MemStore instance
+func NewMemStore() *MemStore {
+	return &MemStore{
+		db: overlaydb.NewMemDB(0, 0),
+	}
+}
+
+func wrapValue(value []byte) []byte {
+	val := make([]byte, 0, len(value)+1)
+	val = append(val, 1)
+	return append(val, value...)
+}
+
+//Put a key-value pair to store
+func (self *MemStore) Put(key []byte, value []byte) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.db.Put(key, wrapValue(value))
+	return nil
+}
+
+//Get the value of a key from store
+func (self *MemStore) Get(key []byte) ([]byte, error) {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	val, unknown := self.db.Get(key)
+	if unknown || len(val) == 0 {
+		return nil, common.ErrNotFound
+	}
+	return append([]byte{}, val[1:]...), nil
+}
+
+//Has return whether the key is exist in store
+func (self *MemStore) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == common.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Delete the key in store
+func (self *MemStore) Delete(key []byte) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.db.Delete(key)
+	return nil
+}
+
+//NewBatch start commit batch
+func (self *MemStore) NewBatch() {
+	self.batch = overlaydb.NewMemDB(0, 0)
+}
+
+//BatchPut put a key-value pair to batch
+func (self *MemStore) BatchPut(key []byte, value []byte) {
+	self.batch.Put(key, wrapValue(value))
+}
+
+//BatchDelete delete a key in batch
+func (self *MemStore) BatchDelete(key []byte) {
+	self.batch.Delete(key)
+}
+
+//BatchCommit commit batch to store
+func (self *MemStore) BatchCommit() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.batch.ForEach(func(key, val []byte) {
+		self.db.Put(key, val)
+	})
+	self.batch = nil
+	return nil
+}
+
+//Close store and drop all the data
+func (self *MemStore) Close() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.db.Reset()
+	return nil
+}
+
+//NewIterator return a iterator of store with the key prefix
+func (self *MemStore) NewIterator(prefix []byte) common.StoreIterator {
+	return self.newIterator(util.BytesPrefix(prefix))
+}
+
+//NewRangeIterator return a iterator of store with the key range [start, limit)
+func (self *MemStore) NewRangeIterator(start, limit []byte) common.StoreIterator {
+	return self.newIterator(&util.Range{Start: start, Limit: limit})
+}
+
+func (self *MemStore) newIterator(slice *util.Range) common.StoreIterator {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return &memIterator{store: self, iter: self.db.NewIterator(slice)}
+}
+
+//memIterator skips the deleted keys and strips the value prefix
+type memIterator struct {
+	store *MemStore
+	iter  iterator.Iterator
+}
+
+func (self *memIterator) Next() bool {
+	self.store.lock.RLock()
+	defer self.store.lock.RUnlock()
+	for self.iter.Next() {
+		if len(self.iter.Value()) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *memIterator) First() bool {
+	self.store.lock.RLock()
+	defer self.store.lock.RUnlock()
+	if !self.iter.First() {
+		return false
+	}
+	for len(self.iter.Value()) == 0 {
+		if !self.iter.Next() {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *memIterator) Key() []byte {
+	return self.iter.Key()
+}
+
+func (self *memIterator) Value() []byte {
+	val := self.iter.Value()
+	if len(val) == 0 {
+		return nil
+	}
+	return val[1:]
+}
+
+func (self *memIterator) Release() {
+	self.iter.Release()
+}
+
+func (self *memIterator) Error() error {
+	return self.iter.Error()
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package memstore
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPersistStore(t *testing.T, store common.PersistStore) {
+	assert.Nil(t, store.Put([]byte("foo"), []byte("bar")))
+	assert.Nil(t, store.Put([]byte("empty"), []byte{}))
+	val, err := store.Get([]byte("foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("bar"), val)
+	val, err = store.Get([]byte("empty"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(val))
+	assert.Nil(t, store.Delete([]byte("foo")))
+	_, err = store.Get([]byte("foo"))
+	assert.Equal(t, common.ErrNotFound, err)
+	has, err := store.Has([]byte("foo"))
+	assert.Nil(t, err)
+	assert.False(t, has)
+
+	store.NewBatch()
+	store.BatchPut([]byte("foo1"), []byte("bar1"))
+	store.BatchPut([]byte("foo2"), []byte("bar2"))
+	store.BatchPut([]byte("foo3"), []byte("bar3"))
+	store.BatchDelete([]byte("foo2"))
+	store.BatchPut([]byte("goo"), []byte("baz"))
+	assert.Nil(t, store.BatchCommit())
+
+	kvs := make(map[string]string)
+	iter := store.NewIterator([]byte("fo"))
+	for iter.Next() {
+		kvs[string(iter.Key())] = string(iter.Value())
+	}
+	iter.Release()
+	assert.Nil(t, iter.Error())
+	assert.Equal(t, map[string]string{"foo1": "bar1", "foo3": "bar3"}, kvs)
+
+	var keys []string
+	iter = store.NewRangeIterator([]byte("foo3"), []byte("h"))
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	iter.Release()
+	assert.Equal(t, []string{"foo3", "goo"}, keys)
+}
+
+func TestMemStore(t *testing.T) {
+	store, err := common.NewPersistStore(BACKEND_NAME, "")
+	assert.Nil(t, err)
+	testPersistStore(t, store)
+	assert.Nil(t, store.Close())
+}
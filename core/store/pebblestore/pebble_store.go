@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pebblestore
+
+import (
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+	"github.com/ontio/ontology/core/store/common"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+//BACKEND_NAME is the name of pebble in PersistStore backend registry
+const BACKEND_NAME = "pebble"
+
+// used to compute the size of bloom filter bits array .
+const BITSPERKEY = 10
+
+func init() {
+	common.RegisterPersistStore(BACKEND_NAME, func(path string) (common.PersistStore, error) {
+		return NewPebbleStore(path)
+	})
+}
+
+//Pebble store
+type PebbleStore struct {
+	db    *pebble.DB // Pebble instance
+	batch *pebble.Batch
+}
+
+//NewPebbleStore return PebbleStore instance
+func NewPebbleStore(dir string) (*PebbleStore, error) {
+	opts := &pebble.Options{}
+	opts.Levels = []pebble.LevelOptions{{FilterPolicy: bloom.FilterPolicy(BITSPERKEY)}}
+	opts.EnsureDefaults()
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PebbleStore{
+		db: db,
+	}, nil
+}
+
+//Put a key-value pair to pebble
+func (self *PebbleStore) Put(key []byte, value []byte) error {
+	return self.db.Set(key, value, pebble.Sync)
+}
+
+//Get the value of a key from pebble
+func (self *PebbleStore) Get(key []byte) ([]byte, error) {
+	dat, closer, err := self.db.Get(key)
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, common.ErrNotFound
+		}
+		return nil, err
+	}
+	defer closer.Close()
+	return append([]byte{}, dat...), nil
+}
+
+//Has return whether the key is exist in pebble
+func (self *PebbleStore) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == common.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Delete the key in pebble
+func (self *PebbleStore) Delete(key []byte) error {
+	return self.db.Delete(key, pebble.Sync)
+}
+
+//NewBatch start commit batch
+func (self *PebbleStore) NewBatch() {
+	self.batch = self.db.NewBatch()
+}
+
+//BatchPut put a key-value pair to pebble batch
+func (self *PebbleStore) BatchPut(key []byte, value []byte) {
+	self.batch.Set(key, value, nil)
+}
+
+//BatchDelete delete a key to pebble batch
+func (self *PebbleStore) BatchDelete(key []byte) {
+	self.batch.Delete(key, nil)
+}
+
+//BatchCommit commit batch to pebble
+func (self *PebbleStore) BatchCommit() error {
+	err := self.batch.Commit(pebble.Sync)
+	if err != nil {
+		return err
+	}
+	self.batch.Close()
+	self.batch = nil
+	return nil
+}
+
+//Close pebble
+func (self *PebbleStore) Close() error {
+	return self.db.Close()
+}
+
+//NewIterator return a iterator of pebble with the key prefix
+func (self *PebbleStore) NewIterator(prefix []byte) common.StoreIterator {
+	r := util.BytesPrefix(prefix)
+	return self.NewRangeIterator(r.Start, r.Limit)
+}
+
+//NewRangeIterator return a iterator of pebble with the key range [start, limit)
+func (self *PebbleStore) NewRangeIterator(start, limit []byte) common.StoreIterator {
+	iter := self.db.NewIter(&pebble.IterOptions{LowerBound: start, UpperBound: limit})
+	return &pebbleIterator{iter: iter}
+}
+
+//pebbleIterator adapts pebble.Iterator to StoreIterator, which is positioned before the first item
+type pebbleIterator struct {
+	iter     *pebble.Iterator
+	started  bool
+	released bool
+	err      error
+}
+
+func (self *pebbleIterator) Next() bool {
+	if self.released {
+		return false
+	}
+	if !self.started {
+		return self.First()
+	}
+	return self.iter.Next()
+}
+
+func (self *pebbleIterator) First() bool {
+	if self.released {
+		return false
+	}
+	self.started = true
+	return self.iter.First()
+}
+
+func (self *pebbleIterator) Key() []byte {
+	if self.released || !self.iter.Valid() {
+		return nil
+	}
+	return self.iter.Key()
+}
+
+func (self *pebbleIterator) Value() []byte {
+	if self.released || !self.iter.Valid() {
+		return nil
+	}
+	return self.iter.Value()
+}
+
+func (self *pebbleIterator) Release() {
+	if !self.released {
+		self.err = self.iter.Close()
+		self.released = true
+	}
+}
+
+func (self *pebbleIterator) Error() error {
+	if self.released {
+		return self.err
+	}
+	return self.iter.Error()
+}
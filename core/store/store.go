@@ -42,6 +42,24 @@ type ExecuteResult struct {
 	Notify          []*event.ExecuteNotify
 }
 
+// EventFilter selects notify events by the contract emitting them and the first element of their states.
+type EventFilter struct {
+	FromHeight uint32
+	ToHeight   uint32
+	Contracts  []common.Address
+	Topic      string // match the first element of notify states, empty to match all
+	Cursor     []byte // position returned by previous query, nil to start from FromHeight
+	Limit      uint32
+}
+
+// EventLog contains the notifies of a transaction matched by EventFilter
+type EventLog struct {
+	Height uint32
+	TxHash common.Uint256
+	State  byte
+	Notify []*event.NotifyEventInfo
+}
+
 // LedgerStore provides func with store package.
 type LedgerStore interface {
 	InitLedgerStoreWithGenesisBlock(genesisblock *types.Block, defaultBookkeeper []keypair.PublicKey) error
@@ -69,14 +87,21 @@ type LedgerStore interface {
 	GetContractState(contractHash common.Address) (*payload.DeployCode, error)
 	GetBookkeeperState() (*states.BookkeeperState, error)
 	GetStorageItem(codeHash common.Address, key []byte) ([]byte, error)
+	GetStorageItemAt(codeHash common.Address, key []byte, height uint32) ([]byte, error)
+	NewOverlayDB() (*overlaydb.OverlayDB, uint32, error)
+	NewOverlayDBAt(height uint32) (*overlaydb.OverlayDB, error)
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
 	PreExecuteContractBatch(txes []*types.Transaction, atomic bool) ([]*cstates.PreExecResult, uint32, error)
 	PreExecuteEip155Tx(msg types2.Message) (*types3.ExecutionResult, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	GetEventNotifyByFilter(filter *EventFilter) ([]*EventLog, []byte, error)
+	GetEvmLogBloom(height uint32) (types2.Bloom, error)
 	GetEthCode(hash common2.Hash) ([]byte, error)
 	GetEthState(address common2.Address, key common2.Hash) ([]byte, error)
 	GetEthAccount(address common2.Address) (*storage.EthAccount, error)
+	GetEthStateAt(address common2.Address, key common2.Hash, height uint32) ([]byte, error)
+	GetEthAccountAt(address common2.Address, height uint32) (*storage.EthAccount, error)
 	//cross chain states root
 	GetCrossStatesRoot(height uint32) (common.Uint256, error)
 	GetCrossChainMsg(height uint32) (*types.CrossChainMsg, error)
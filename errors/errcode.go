@@ -60,6 +60,8 @@ const (
 	ErrETHTxGaslimitExceed  ErrCode = 45023
 	ErrSameNonceExist       ErrCode = 45024
 	ErrETHTxNonceToobig     ErrCode = 45025
+	ErrTxDropped            ErrCode = 45026
+	ErrTxPoolPayerFull      ErrCode = 45027
 )
 
 func (err ErrCode) Error() string {
@@ -113,9 +115,13 @@ func (err ErrCode) Error() string {
 	case ErrETHTxGaslimitExceed:
 		return "eth transaction gaslimit exceeded"
 	case ErrSameNonceExist:
-		return "eth transaction with same nonce existed"
+		return "transaction with same nonce existed"
 	case ErrETHTxNonceToobig:
 		return "eth transaction nonce is much greater than tx pool"
+	case ErrTxDropped:
+		return "transaction dropped from tx pool"
+	case ErrTxPoolPayerFull:
+		return "too many transactions of the payer in tx pool"
 	}
 
 	return fmt.Sprintf("Unknown error? Error code = %d", err)
@@ -24,11 +24,13 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/ledger"
 	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/store"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/smartcontract/event"
 	types3 "github.com/ontio/ontology/smartcontract/service/evm/types"
 	cstate "github.com/ontio/ontology/smartcontract/states"
 	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/vm/evm"
 )
 
 const (
@@ -41,6 +43,11 @@ func GetHeaderByHeight(height uint32) (*types.Header, error) {
 	return ledger.DefLedger.GetHeaderByHeight(height)
 }
 
+//GetHeaderByHash from ledger
+func GetHeaderByHash(hash common.Uint256) (*types.Header, error) {
+	return ledger.DefLedger.GetHeaderByHash(hash)
+}
+
 //GetBlockByHeight from ledger
 func GetBlockByHeight(height uint32) (*types.Block, error) {
 	return ledger.DefLedger.GetBlockByHeight(height)
@@ -77,6 +84,11 @@ func GetStorageItem(address common.Address, key []byte) ([]byte, error) {
 	return ledger.DefLedger.GetStorageItem(address, key)
 }
 
+//GetStorageItemAt from ledger
+func GetStorageItemAt(address common.Address, key []byte, height uint32) ([]byte, error) {
+	return ledger.DefLedger.GetStorageItemAt(address, key, height)
+}
+
 //GetContractStateFromStore from ledger
 func GetContractStateFromStore(hash common.Address) (*payload.DeployCode, error) {
 	hash = updateNativeSCAddr(hash)
@@ -108,6 +120,16 @@ func GetEventNotifyByHeight(height uint32) ([]*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByBlock(height)
 }
 
+//GetEventNotifyByFilter from ledger
+func GetEventNotifyByFilter(filter *store.EventFilter) ([]*store.EventLog, []byte, error) {
+	return ledger.DefLedger.GetEventNotifyByFilter(filter)
+}
+
+//GetEvmLogBloom from ledger
+func GetEvmLogBloom(height uint32) (types2.Bloom, error) {
+	return ledger.DefLedger.GetEvmLogBloom(height)
+}
+
 //GetMerkleProof from ledger
 func GetMerkleProof(proofHeight uint32, rootHeight uint32) ([]common.Uint256, error) {
 	return ledger.DefLedger.GetMerkleProof(proofHeight, rootHeight)
@@ -125,6 +147,10 @@ func GetEthAccount(address common2.Address) (*storage.EthAccount, error) {
 	return ledger.DefLedger.GetEthAccount(address)
 }
 
+func GetEthAccountAt(address common2.Address, height uint32) (*storage.EthAccount, error) {
+	return ledger.DefLedger.GetEthAccountAt(address, height)
+}
+
 func GetEthCode(hash common2.Hash) ([]byte, error) {
 	return ledger.DefLedger.GetEthCode(hash)
 }
@@ -133,7 +159,21 @@ func GetEthStorage(addr common2.Address, key common2.Hash) ([]byte, error) {
 	return ledger.DefLedger.GetEthState(addr, key)
 }
 
+func GetEthStorageAt(addr common2.Address, key common2.Hash, height uint32) ([]byte, error) {
+	return ledger.DefLedger.GetEthStateAt(addr, key, height)
+}
+
 func PreExecuteEip155Tx(msg types2.Message) (*types3.ExecutionResult, error) {
 	res, err := ledger.DefLedger.PreExecuteEip155Tx(msg)
 	return res, err
 }
+
+//TraceEip155Tx from ledger
+func TraceEip155Tx(txHash common.Uint256, tracer evm.Tracer) (*types3.ExecutionResult, error) {
+	return ledger.DefLedger.TraceEip155Tx(txHash, tracer)
+}
+
+//TraceEip155Call from ledger
+func TraceEip155Call(msg types2.Message, height uint32, tracer evm.Tracer) (*types3.ExecutionResult, error) {
+	return ledger.DefLedger.TraceEip155Call(msg, height, tracer)
+}
@@ -21,14 +21,34 @@ package actor
 import (
 	"github.com/ontio/ontology/p2pserver/common"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/ontio/ontology/p2pserver/protocols/block_sync"
 )
 
 var netServer p2p.P2P
 
+//SyncProgressReporter report the block sync progress
+type SyncProgressReporter interface {
+	GetSyncProgress() block_sync.SyncProgress
+}
+
+var syncReporter SyncProgressReporter
+
 func SetNetServer(p2p p2p.P2P) {
 	netServer = p2p
 }
 
+func SetSyncProgressReporter(reporter SyncProgressReporter) {
+	syncReporter = reporter
+}
+
+//GetSyncProgress from block sync manager
+func GetSyncProgress() block_sync.SyncProgress {
+	if syncReporter == nil {
+		return block_sync.SyncProgress{}
+	}
+	return syncReporter.GetSyncProgress()
+}
+
 //GetConnectionCnt from netSever actor
 func GetConnectionCnt() uint32 {
 	if netServer == nil {
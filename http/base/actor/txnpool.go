@@ -76,3 +76,23 @@ func GetTxnCount() []uint32 {
 func GetTxnHashList() []common.Uint256 {
 	return txPoolService.GetTxList()
 }
+
+//GetTxnInfoList returns the txs and their states from txpool
+func GetTxnInfoList() []*tcomn.PoolTxInfo {
+	return txPoolService.GetTxInfoList()
+}
+
+//GetTxnsByAddress returns the txs paid by the address from txpool
+func GetTxnsByAddress(addr common.Address) []*tcomn.PoolTxInfo {
+	return txPoolService.GetTxsByAddress(addr)
+}
+
+//GetTxnPoolStats returns the statistics of txpool
+func GetTxnPoolStats() *tcomn.TxPoolStats {
+	return txPoolService.GetTxPoolStats()
+}
+
+//DropTxFromPool removes the tx from txpool
+func DropTxFromPool(hash common.Uint256) bool {
+	return txPoolService.DropTransaction(hash)
+}
@@ -21,31 +21,46 @@ package common
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/constants"
 	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/common/serialization"
+	vconfig "github.com/ontio/ontology/consensus/vbft/config"
 	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/lightclient"
 	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/store"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/types"
 	cutils "github.com/ontio/ontology/core/utils"
 	ontErrors "github.com/ontio/ontology/errors"
 	bactor "github.com/ontio/ontology/http/base/actor"
 	common2 "github.com/ontio/ontology/p2pserver/common"
 	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native/credential"
+	"github.com/ontio/ontology/smartcontract/service/native/cross_chain/lock_proxy"
 	"github.com/ontio/ontology/smartcontract/service/native/ont"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 	cstate "github.com/ontio/ontology/smartcontract/states"
+	"github.com/ontio/ontology/smartcontract/storage"
+	tcomn "github.com/ontio/ontology/txnpool/common"
 	"github.com/ontio/ontology/vm/neovm"
 )
 
 const MAX_SEARCH_HEIGHT uint32 = 100
+const MAX_EVENT_FILTER_LIMIT uint32 = 100
 const MAX_REQUEST_BODY_SIZE = 1 << 20
 
 type BalanceOfRsp struct {
@@ -102,6 +117,18 @@ type NotifyEventInfo struct {
 	States          interface{}
 }
 
+type EventLog struct {
+	Height uint32
+	TxHash string
+	State  byte
+	Notify []NotifyEventInfo
+}
+
+type EventFilterResult struct {
+	Events []*EventLog
+	Cursor string
+}
+
 type TxAttributeInfo struct {
 	Usage types.TransactionAttributeUsage
 	Data  string
@@ -128,6 +155,38 @@ type CrossStatesProof struct {
 	AuditPath string
 }
 
+type CredentialStatusInfo struct {
+	CredentialId string
+	Issuer       string
+	Holder       string
+	Status       string
+	CommitHeight uint32
+	RevokeHeight uint32
+	RevokedBy    string
+}
+
+type LockProxyBindings struct {
+	Paused  bool
+	Proxies []ProxyBindingInfo
+	Assets  []AssetBindingInfo
+}
+
+type ProxyBindingInfo struct {
+	ChainId   uint64
+	ProxyHash string
+}
+
+type AssetBindingInfo struct {
+	AssetHash       string
+	ChainId         uint64
+	TargetAssetHash string
+	Limit           string
+	CrossedAmount   string
+	DailyLimit      string
+	DailyLocked     string
+	DailyUnlocked   string
+}
+
 type Transactions struct {
 	Version    byte
 	Nonce      uint32
@@ -192,6 +251,78 @@ type TXNEntryInfo struct {
 	State []TXNAttrInfo // the result from each validator
 }
 
+type MemPoolTxInfo struct {
+	Hash     string
+	Payer    string
+	Nonce    uint32
+	GasPrice uint64
+	GasLimit uint64
+	TxType   types.TransactionType
+	State    string
+	Attrs    []TXNAttrInfo
+	Tx       *Transactions // the whole transaction, only returned in verbose mode
+}
+
+//MemPoolContent groups the txs in mempool by state and payer
+type MemPoolContent struct {
+	Pending   map[string][]*MemPoolTxInfo
+	Queued    map[string][]*MemPoolTxInfo
+	Verifying map[string][]*MemPoolTxInfo
+}
+
+func NewMemPoolTxInfo(info *tcomn.PoolTxInfo, verbose bool) *MemPoolTxInfo {
+	tx := info.Tx
+	attrs := []TXNAttrInfo{}
+	for _, t := range info.Attrs {
+		attrs = append(attrs, TXNAttrInfo{t.Height, int(t.Type), int(t.ErrCode)})
+	}
+	ret := &MemPoolTxInfo{
+		Hash:     tx.Hash().ToHexString(),
+		Payer:    tx.Payer.ToBase58(),
+		Nonce:    tx.Nonce,
+		GasPrice: tx.GasPrice,
+		GasLimit: tx.GasLimit,
+		TxType:   tx.TxType,
+		State:    info.State.String(),
+		Attrs:    attrs,
+	}
+	if verbose {
+		ret.Tx = TransArryByteToHexString(tx)
+	}
+	return ret
+}
+
+func NewMemPoolTxInfoList(infos []*tcomn.PoolTxInfo, verbose bool) []*MemPoolTxInfo {
+	ret := make([]*MemPoolTxInfo, 0, len(infos))
+	for _, info := range infos {
+		ret = append(ret, NewMemPoolTxInfo(info, verbose))
+	}
+	return ret
+}
+
+func NewMemPoolContent(infos []*tcomn.PoolTxInfo, verbose bool) *MemPoolContent {
+	content := &MemPoolContent{
+		Pending:   make(map[string][]*MemPoolTxInfo),
+		Queued:    make(map[string][]*MemPoolTxInfo),
+		Verifying: make(map[string][]*MemPoolTxInfo),
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Tx.Nonce < infos[j].Tx.Nonce
+	})
+	for _, info := range infos {
+		group := content.Pending
+		switch info.State {
+		case tcomn.TxQueued:
+			group = content.Queued
+		case tcomn.TxVerifying:
+			group = content.Verifying
+		}
+		payer := info.Tx.Payer.ToBase58()
+		group[payer] = append(group[payer], NewMemPoolTxInfo(info, verbose))
+	}
+	return content
+}
+
 func GetLogEvent(obj *event.LogEventArgs) (map[string]bool, LogEventArgs) {
 	hash := obj.TxHash
 	addr := obj.ContractAddress.ToHexString()
@@ -211,6 +342,60 @@ func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify)
 		obj.GasStepUsed, obj.TxIndex, obj.CreatedContract.ToHexString()}
 }
 
+// NewEventFilter build the event filter from request params, toHeight 0 means current block height
+func NewEventFilter(fromHeight, toHeight uint32, contracts []string, topic string, cursor string, limit uint32) (*store.EventFilter, error) {
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("contracts is empty")
+	}
+	filter := &store.EventFilter{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+		Topic:      topic,
+		Limit:      limit,
+	}
+	if filter.ToHeight == 0 {
+		filter.ToHeight = bactor.GetCurrentBlockHeight()
+	}
+	if filter.Limit == 0 || filter.Limit > MAX_EVENT_FILTER_LIMIT {
+		filter.Limit = MAX_EVENT_FILTER_LIMIT
+	}
+	for _, str := range contracts {
+		addr, err := GetAddress(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract address %s: %s", str, err)
+		}
+		filter.Contracts = append(filter.Contracts, addr)
+	}
+	if cursor != "" {
+		data, err := common.HexToBytes(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", err)
+		}
+		filter.Cursor = data
+	}
+	return filter, nil
+}
+
+func GetEventFilterResult(logs []*store.EventLog, cursor []byte) *EventFilterResult {
+	result := &EventFilterResult{
+		Events: make([]*EventLog, 0, len(logs)),
+		Cursor: common.ToHexString(cursor),
+	}
+	for _, l := range logs {
+		evts := []NotifyEventInfo{}
+		for _, v := range l.Notify {
+			evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		}
+		result.Events = append(result.Events, &EventLog{
+			Height: l.Height,
+			TxHash: l.TxHash.ToHexString(),
+			State:  l.State,
+			Notify: evts,
+		})
+	}
+	return result
+}
+
 func ConvertPreExecuteResult(obj *cstate.PreExecResult) PreExecuteResult {
 	evts := []NotifyEventInfo{}
 	for _, v := range obj.Notify {
@@ -262,6 +447,89 @@ func TransferCrossChainMsg(msg *types.CrossChainMsg, pks []keypair.PublicKey) st
 	return common.ToHexString(sink.Bytes())
 }
 
+//build the cross chain proof bundle of the cross chain state key at height for a light client trusting the
+//consensus peers at trustedHeight
+func GetCrossChainProofBundle(height uint32, key []byte, trustedHeight uint32) (*lightclient.ProofBundle, error) {
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) != config.CONSENSUS_TYPE_VBFT {
+		return nil, fmt.Errorf("proof bundle is only supported by vbft")
+	}
+	if trustedHeight > height {
+		return nil, fmt.Errorf("trusted height %d is greater than height %d", trustedHeight, height)
+	}
+	msg, err := bactor.GetCrossChainMsg(height)
+	if err != nil {
+		return nil, fmt.Errorf("get cross chain msg error: %s", err)
+	}
+	header, err := bactor.GetHeaderByHeight(height + 1)
+	if err != nil {
+		return nil, fmt.Errorf("get header of height %d error: %s", height+1, err)
+	}
+	proof, err := bactor.GetCrossStatesProof(height, key)
+	if err != nil {
+		return nil, fmt.Errorf("get cross states proof error: %s", err)
+	}
+	trusted, err := getLastConfigBlockNum(trustedHeight)
+	if err != nil {
+		return nil, err
+	}
+	//walk back through the config blocks from the one which signed the header at height+1, a config block is
+	//signed by the former config
+	configNum, err := getLastConfigBlockNum(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	if configNum == height+1 {
+		if configNum, err = getLastConfigBlockNum(height); err != nil {
+			return nil, err
+		}
+	}
+	configHeaders := make([]*types.Header, 0)
+	configParents := make([]*types.Header, 0)
+	for configNum > trusted {
+		configHeader, err := bactor.GetHeaderByHeight(configNum)
+		if err != nil {
+			return nil, fmt.Errorf("get header of height %d error: %s", configNum, err)
+		}
+		parent, err := bactor.GetHeaderByHeight(configNum - 1)
+		if err != nil {
+			return nil, fmt.Errorf("get header of height %d error: %s", configNum-1, err)
+		}
+		configHeaders = append(configHeaders, configHeader)
+		configParents = append(configParents, parent)
+		if configNum, err = getLastConfigBlockNum(configNum - 1); err != nil {
+			return nil, err
+		}
+	}
+	if configNum != trusted {
+		return nil, fmt.Errorf("config block %d is not found from height %d", trusted, height)
+	}
+	for i, j := 0, len(configHeaders)-1; i < j; i, j = i+1, j-1 {
+		configHeaders[i], configHeaders[j] = configHeaders[j], configHeaders[i]
+		configParents[i], configParents[j] = configParents[j], configParents[i]
+	}
+	return &lightclient.ProofBundle{
+		Height:        height,
+		ConfigHeaders: configHeaders,
+		ConfigParents: configParents,
+		Header:        header,
+		CrossChainMsg: msg,
+		StatesProof:   proof,
+	}, nil
+}
+
+//get the height of the config block in effect at height
+func getLastConfigBlockNum(height uint32) (uint32, error) {
+	header, err := bactor.GetHeaderByHeight(height)
+	if err != nil {
+		return 0, fmt.Errorf("get header of height %d error: %s", height, err)
+	}
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return 0, err
+	}
+	return blkInfo.LastConfigBlockNum, nil
+}
+
 func SendTxToPool(txn *types.Transaction) (ontErrors.ErrCode, string) {
 	if errCode, desc := bactor.AppendTxToPool(txn); errCode != ontErrors.ErrNoError {
 		log.Warn("TxnPool verify error:", errCode.Error())
@@ -327,6 +595,38 @@ func GetBalance(address common.Address) (*BalanceOfRsp, error) {
 	}, nil
 }
 
+//GetBalanceAt return the ont and ong balance of address at block height, state archive is required
+func GetBalanceAt(address common.Address, height uint32) (*BalanceOfRsp, error) {
+	ont, err := GetNativeBalanceAt(utils.OntContractAddress, address, height)
+	if err != nil {
+		return nil, fmt.Errorf("get ont balance error:%s", err)
+	}
+	ong, err := GetNativeBalanceAt(utils.OngContractAddress, address, height)
+	if err != nil {
+		return nil, fmt.Errorf("get ong balance error:%s", err)
+	}
+	return &BalanceOfRsp{
+		Ont:    fmt.Sprintf("%d", ont),
+		Ong:    fmt.Sprintf("%d", ong),
+		Height: fmt.Sprintf("%d", height),
+	}, nil
+}
+
+//GetNativeBalanceAt read the balance of ont or ong contract from the archived state at block height
+func GetNativeBalanceAt(contract, address common.Address, height uint32) (uint64, error) {
+	if current := bactor.GetCurrentBlockHeight(); height > current {
+		return 0, fmt.Errorf("height %d is higher than current block height %d", height, current)
+	}
+	value, err := bactor.GetStorageItemAt(contract, address[:], height)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return serialization.ReadUint64(bytes.NewBuffer(value))
+}
+
 func GetOep4Balance(contractAddress common.Address, addrs []common.Address) (*Oep4BalanceOfRsp, error) {
 	balances, height, err := GetOep4ContractBalance(contractAddress, addrs, true)
 	if err != nil {
@@ -364,6 +664,90 @@ func GetGrantOng(addr common.Address) (string, error) {
 	return fmt.Sprintf("%v", boundong), nil
 }
 
+//GetCredentialStatus read the credential status from the credential registry contract, nil if not committed
+func GetCredentialStatus(id []byte) (*CredentialStatusInfo, error) {
+	value, err := bactor.GetStorageItem(utils.CredentialContractAddress, credential.ConcatCredentialKey(id))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	status := new(credential.CredentialStatus)
+	if err := status.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, err
+	}
+	info := &CredentialStatusInfo{
+		CredentialId: string(id),
+		Issuer:       string(status.Issuer),
+		Holder:       string(status.Holder),
+		Status:       "committed",
+		CommitHeight: status.CommitHeight,
+		RevokeHeight: status.RevokeHeight,
+		RevokedBy:    string(status.RevokedBy),
+	}
+	if status.Status == credential.STATUS_REVOKED {
+		info.Status = "revoked"
+	}
+	return info, nil
+}
+
+//get the bound proxies and assets of lock proxy contract with the volume of today
+func GetLockProxyBindings() (*LockProxyBindings, error) {
+	// the latest state is read from the live store, the archived view does not support iteration
+	overlay, height, err := ledger.DefLedger.NewOverlayDB()
+	if err != nil {
+		return nil, err
+	}
+	header, err := ledger.DefLedger.GetHeaderByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	cacheDB := storage.NewCacheDB(overlay)
+	contract := utils.LockProxyContractAddress
+	paused, err := cacheDB.Get(lock_proxy.GenPausedKey(contract))
+	if err != nil {
+		return nil, err
+	}
+	proxies, err := lock_proxy.ListProxyBindings(cacheDB, contract)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := lock_proxy.ListAssetBindings(cacheDB, contract)
+	if err != nil {
+		return nil, err
+	}
+	rsp := &LockProxyBindings{
+		Paused:  paused != nil,
+		Proxies: make([]ProxyBindingInfo, 0, len(proxies)),
+		Assets:  make([]AssetBindingInfo, 0, len(assets)),
+	}
+	for _, proxy := range proxies {
+		rsp.Proxies = append(rsp.Proxies, ProxyBindingInfo{
+			ChainId:   proxy.TargetChainId,
+			ProxyHash: hex.EncodeToString(proxy.TargetHash),
+		})
+	}
+	for _, asset := range assets {
+		volume, err := lock_proxy.DailyVolumeOf(cacheDB, contract, asset.SourceAssetHash, asset.TargetChainId,
+			header.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		rsp.Assets = append(rsp.Assets, AssetBindingInfo{
+			AssetHash:       asset.SourceAssetHash.ToHexString(),
+			ChainId:         asset.TargetChainId,
+			TargetAssetHash: hex.EncodeToString(asset.TargetAssetHash),
+			Limit:           asset.Limit.String(),
+			CrossedAmount:   asset.CrossedAmount.String(),
+			DailyLimit:      asset.DailyLimit.String(),
+			DailyLocked:     volume.Locked.String(),
+			DailyUnlocked:   volume.Unlocked.String(),
+		})
+	}
+	return rsp, nil
+}
+
 func GetAllowance(asset string, from, to common.Address) (string, error) {
 	var contractAddr common.Address
 	switch strings.ToLower(asset) {
@@ -580,6 +964,32 @@ func BuildNeoVMInvokeCode(smartContractAddress common.Address, params []interfac
 	return args, nil
 }
 
+//CheckAdminToken checks the token of the local admin apis, they are disabled if the admin token is not configured
+func CheckAdminToken(token string) bool {
+	adminToken := config.DefConfig.Rpc.AdminToken
+	return adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+//NewOriginChecker accepts non-browser clients, same origin pages and the configured
+//cross origins, so that an arbitrary web page can not drive the node api over websocket.
+func NewOriginChecker(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]bool)
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowed["*"] || allowed[strings.ToLower(origin)] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return strings.EqualFold(u.Host, r.Host)
+	}
+}
+
 func GetAddress(str string) (common.Address, error) {
 	var address common.Address
 	var err error
@@ -592,19 +1002,28 @@ func GetAddress(str string) (common.Address, error) {
 }
 
 type SyncStatus struct {
-	CurrentBlockHeight uint32
-	ConnectCount       uint32
-	MaxPeerBlockHeight uint64
+	CurrentBlockHeight  uint32
+	ConnectCount        uint32
+	MaxPeerBlockHeight  uint64
+	CurrentHeaderHeight uint32
+	FastSync            bool
+	BlocksPerSecond     float64
+	ETA                 uint64 //estimated seconds to catch up with peers, 0 if synced or unknown
 }
 
 func GetSyncStatus() (SyncStatus, error) {
 	height := bactor.GetMaxPeerBlockHeight()
 	cnt := bactor.GetConnectionCnt()
 	curBlockHeight := bactor.GetCurrentBlockHeight()
+	progress := bactor.GetSyncProgress()
 
 	return SyncStatus{
-		CurrentBlockHeight: curBlockHeight,
-		ConnectCount:       cnt,
-		MaxPeerBlockHeight: height,
+		CurrentBlockHeight:  curBlockHeight,
+		ConnectCount:        cnt,
+		MaxPeerBlockHeight:  height,
+		CurrentHeaderHeight: progress.CurrentHeaderHeight,
+		FastSync:            progress.FastSync,
+		BlocksPerSecond:     progress.BlocksPerSecond,
+		ETA:                 progress.ETA,
 	}, nil
 }
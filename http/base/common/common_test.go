@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginChecker(t *testing.T) {
+	check := NewOriginChecker([]string{"https://explorer.ont.io/"})
+	request := func(origin string) bool {
+		r := httptest.NewRequest("GET", "http://127.0.0.1:20337/query", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return check(r)
+	}
+	assert.True(t, request(""))
+	assert.True(t, request("http://127.0.0.1:20337"))
+	assert.True(t, request("https://explorer.ont.io"))
+	assert.False(t, request("https://evil.example.com"))
+
+	assert.True(t, NewOriginChecker([]string{"*"})(httptest.NewRequest("GET", "/query", nil)))
+}
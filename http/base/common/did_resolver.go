@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/ledger"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
+	"github.com/ontio/ontology/smartcontract/storage"
+)
+
+const (
+	DID_RESOLUTION_CONTEXT  = "https://w3id.org/did-resolution/v1"
+	DID_LD_JSON_CONTENTTYPE = "application/did+ld+json"
+)
+
+//DID resolution errors of the W3C DID Resolution spec
+const (
+	DID_INVALID        = "invalidDid"
+	DID_NOT_FOUND      = "notFound"
+	DID_INTERNAL_ERROR = "internalError"
+)
+
+type DIDResolution struct {
+	Context            string                `json:"@context"`
+	DidDocument        json.RawMessage       `json:"didDocument"`
+	ResolutionMetadata DIDResolutionMetadata `json:"didResolutionMetadata"`
+	DocumentMetadata   DIDDocumentMetadata   `json:"didDocumentMetadata"`
+}
+
+type DIDResolutionMetadata struct {
+	ContentType  string `json:"contentType,omitempty"`
+	Error        string `json:"error,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+type DIDDocumentMetadata struct {
+	Created            string `json:"created,omitempty"`
+	Updated            string `json:"updated,omitempty"`
+	Deactivated        bool   `json:"deactivated,omitempty"`
+	VersionId          string `json:"versionId,omitempty"`          //block height of the resolved state
+	CreatedBlockHeight uint32 `json:"createdBlockHeight,omitempty"` //block height of the created time
+	UpdatedBlockHeight uint32 `json:"updatedBlockHeight,omitempty"` //block height of the updated time
+}
+
+//ResolveDID resolve the did:ont document with the ONT ID contract. the versionId is the block height and the
+//versionTime is a RFC3339 time of the resolved state, the latest state is resolved if both are empty
+func ResolveDID(did, versionId, versionTime string) *DIDResolution {
+	res := &DIDResolution{Context: DID_RESOLUTION_CONTEXT}
+	if !account.VerifyID(did) {
+		return res.fail(DID_INVALID, fmt.Errorf("%s is not a valid did:ont", did))
+	}
+	height, err := resolveHeight(versionId, versionTime)
+	if err != nil {
+		return res.fail(DID_INVALID, err)
+	}
+	// the state of history height is unavailable if the state archive is disabled or pruned, which is a
+	// limitation of the node rather than a missing did
+	overlay, err := ledger.DefLedger.NewOverlayDBAt(height)
+	if err != nil {
+		return res.fail(DID_INTERNAL_ERROR, err)
+	}
+	srvc := &native.NativeService{CacheDB: storage.NewCacheDB(overlay), Height: height}
+	if header, err := bactor.GetHeaderByHeight(height); err == nil {
+		srvc.Time = header.Timestamp
+	}
+	res.DocumentMetadata.VersionId = strconv.FormatUint(uint64(height), 10)
+
+	revoked, err := ontid.IsRevokedID(srvc, []byte(did))
+	if err != nil {
+		return res.fail(DID_INVALID, err)
+	}
+	if revoked {
+		res.DocumentMetadata.Deactivated = true
+		return res
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(did))
+	srvc.Input = sink.Bytes()
+	doc, err := ontid.GetDocumentJson(srvc)
+	if err != nil {
+		return res.fail(DID_INTERNAL_ERROR, err)
+	}
+	if len(doc) == 0 {
+		return res.fail(DID_NOT_FOUND, fmt.Errorf("%s is not registered at height %d", did, height))
+	}
+	times := struct {
+		Created uint32 `json:"created"`
+		Updated uint32 `json:"updated"`
+	}{}
+	if err := json.Unmarshal(doc, &times); err != nil {
+		return res.fail(DID_INTERNAL_ERROR, err)
+	}
+	res.DidDocument = doc
+	res.ResolutionMetadata.ContentType = DID_LD_JSON_CONTENTTYPE
+	res.DocumentMetadata.Created = formatDIDTime(times.Created)
+	res.DocumentMetadata.Updated = formatDIDTime(times.Updated)
+	res.DocumentMetadata.CreatedBlockHeight = blockHeightOfTime(times.Created, height)
+	res.DocumentMetadata.UpdatedBlockHeight = blockHeightOfTime(times.Updated, height)
+	return res
+}
+
+func (self *DIDResolution) fail(code string, err error) *DIDResolution {
+	self.ResolutionMetadata.Error = code
+	self.ResolutionMetadata.ErrorMessage = err.Error()
+	return self
+}
+
+//resolveHeight return the block height of versionId, or the highest block not later than versionTime
+func resolveHeight(versionId, versionTime string) (uint32, error) {
+	current := bactor.GetCurrentBlockHeight()
+	if versionId != "" {
+		height, err := strconv.ParseUint(versionId, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid versionId %s", versionId)
+		}
+		if uint32(height) > current {
+			return 0, fmt.Errorf("versionId %d is higher than current block height %d", height, current)
+		}
+		return uint32(height), nil
+	}
+	if versionTime == "" {
+		return current, nil
+	}
+	t, err := time.Parse(time.RFC3339, versionTime)
+	if err != nil {
+		return 0, fmt.Errorf("invalid versionTime %s", versionTime)
+	}
+	// the first block later than versionTime
+	next, err := searchBlock(current, func(timestamp uint32) bool {
+		return int64(timestamp) > t.Unix()
+	})
+	if err != nil {
+		return 0, err
+	}
+	if next == 0 {
+		return 0, fmt.Errorf("versionTime %s is earlier than genesis block", versionTime)
+	}
+	return next - 1, nil
+}
+
+//blockHeightOfTime return the height of the block with the timestamp, which is recorded by the ONT ID contract
+//as the time of the transaction, zero if not found
+func blockHeightOfTime(timestamp, current uint32) uint32 {
+	if timestamp == 0 {
+		return 0
+	}
+	height, err := searchBlock(current, func(t uint32) bool {
+		return t >= timestamp
+	})
+	if err != nil || height > current {
+		return 0
+	}
+	if header, err := bactor.GetHeaderByHeight(height); err != nil || header.Timestamp != timestamp {
+		return 0
+	}
+	return height
+}
+
+//searchBlock return the lowest height not higher than current+1 of the block whose timestamp satisfies f, the
+//block timestamps increase with the height
+func searchBlock(current uint32, f func(timestamp uint32) bool) (uint32, error) {
+	var searchErr error
+	height := sort.Search(int(current)+1, func(i int) bool {
+		header, err := bactor.GetHeaderByHeight(uint32(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return f(header.Timestamp)
+	})
+	return uint32(height), searchErr
+}
+
+func formatDIDTime(timestamp uint32) string {
+	if timestamp == 0 {
+		return ""
+	}
+	return time.Unix(int64(timestamp), 0).UTC().Format(time.RFC3339)
+}
@@ -19,7 +19,9 @@
 package rest
 
 import (
+	"net"
 	"strconv"
+	"strings"
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
@@ -354,6 +356,49 @@ func GetSmartCodeEventByTxHash(cmd map[string]interface{}) map[string]interface{
 	return resp
 }
 
+//get smartcontract event by filter
+func GetSmartCodeEventByFilter(cmd map[string]interface{}) map[string]interface{} {
+	if !config.DefConfig.Common.EnableEventLog {
+		return ResponsePack(berr.INVALID_METHOD)
+	}
+
+	resp := ResponsePack(berr.SUCCESS)
+	var fromHeight, toHeight, limit uint64
+	var err error
+	if str, ok := cmd["FromHeight"].(string); ok && str != "" {
+		if fromHeight, err = strconv.ParseUint(str, 10, 32); err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	if str, ok := cmd["ToHeight"].(string); ok && str != "" {
+		if toHeight, err = strconv.ParseUint(str, 10, 32); err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	if str, ok := cmd["Limit"].(string); ok && str != "" {
+		if limit, err = strconv.ParseUint(str, 10, 32); err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+	}
+	str, ok := cmd["Contracts"].(string)
+	if !ok || str == "" {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	contracts := strings.Split(str, ",")
+	topic, _ := cmd["Topic"].(string)
+	cursor, _ := cmd["Cursor"].(string)
+	filter, err := bcomn.NewEventFilter(uint32(fromHeight), uint32(toHeight), contracts, topic, cursor, uint32(limit))
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	logs, next, err := bactor.GetEventNotifyByFilter(filter)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = bcomn.GetEventFilterResult(logs, next)
+	return resp
+}
+
 //get contract state
 func GetContractState(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -398,7 +443,16 @@ func GetStorage(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	value, err := bactor.GetStorageItem(address, item)
+	var value []byte
+	if str, ok := cmd["Height"].(string); ok && str != "" {
+		height, e := strconv.ParseUint(str, 10, 32)
+		if e != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		value, err = bactor.GetStorageItemAt(address, item, uint32(height))
+	} else {
+		value, err = bactor.GetStorageItem(address, item)
+	}
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return ResponsePack(berr.SUCCESS)
@@ -420,7 +474,16 @@ func GetBalance(cmd map[string]interface{}) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	balance, err := bcomn.GetBalance(address)
+	var balance *bcomn.BalanceOfRsp
+	if str, ok := cmd["Height"].(string); ok && str != "" {
+		height, e := strconv.ParseUint(str, 10, 32)
+		if e != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		balance, err = bcomn.GetBalanceAt(address, uint32(height))
+	} else {
+		balance, err = bcomn.GetBalance(address)
+	}
 	if err != nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
@@ -552,6 +615,28 @@ func GetGrantOng(cmd map[string]interface{}) map[string]interface{} {
 	return resp
 }
 
+//get credential status by hex encoded credential id
+func GetCredentialStatus(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Id"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	id, err := common.HexToBytes(str)
+	if err != nil || len(id) == 0 {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	rsp, err := bcomn.GetCredentialStatus(id)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	if rsp == nil {
+		return resp
+	}
+	resp["Result"] = rsp
+	return resp
+}
+
 //get memory pool transaction count
 func GetMemPoolTxCount(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -590,3 +675,64 @@ func GetMemPoolTxState(cmd map[string]interface{}) map[string]interface{} {
 	resp["Result"] = bcomn.TXNEntryInfo{attrs}
 	return resp
 }
+
+//get memory pool transactions paid by the address
+func GetMemPoolTxsByAddress(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	verbose, _ := cmd["Verbose"].(string)
+	resp["Result"] = bcomn.NewMemPoolTxInfoList(bactor.GetTxnsByAddress(address), verbose == "1")
+	return resp
+}
+
+//get memory pool transactions grouped by state and payer
+func GetMemPoolContent(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	verbose, _ := cmd["Verbose"].(string)
+	resp["Result"] = bcomn.NewMemPoolContent(bactor.GetTxnInfoList(), verbose == "1")
+	return resp
+}
+
+//get memory pool statistics
+func GetMemPoolStats(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	resp["Result"] = bactor.GetTxnPoolStats()
+	return resp
+}
+
+//drop a transaction from memory pool, only available on local host with the admin token
+func DropMemPoolTx(cmd map[string]interface{}) map[string]interface{} {
+	remoteAddr, _ := cmd["RemoteAddr"].(string)
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return ResponsePack(berr.INVALID_METHOD)
+	}
+	token, _ := cmd["Token"].(string)
+	if !bcomn.CheckAdminToken(token) {
+		return ResponsePack(berr.SESSION_EXPIRED)
+	}
+	str, ok := cmd["Hash"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	hash, err := common.Uint256FromHexString(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	if !bactor.DropTxFromPool(hash) {
+		return ResponsePack(berr.UNKNOWN_TRANSACTION)
+	}
+	resp := ResponsePack(berr.SUCCESS)
+	resp["Result"] = true
+	return resp
+}
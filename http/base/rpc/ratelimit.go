@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rpc
+
+import (
+	"sync"
+	"time"
+)
+
+//idle buckets are evicted once the limiter tracks more keys than this
+const RATE_LIMITER_MAX_KEYS = 10000
+
+//tokenBucket refills rate tokens per second up to burst tokens
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+//RateLimiter keeps a token bucket for every key, such as a client ip or a method called by a client ip
+type RateLimiter struct {
+	sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+//NewRateLimiter returns a limiter allowing rate requests per second with at most burst requests at once for each key.
+//burst is raised to rate when it is smaller.
+func NewRateLimiter(rate, burst uint) *RateLimiter {
+	if burst < rate {
+		burst = rate
+	}
+	return &RateLimiter{
+		rate:    float64(rate),
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+//Allow takes a token from the bucket of key, it returns false when the bucket is empty
+func (self *RateLimiter) Allow(key string) bool {
+	return self.allowAt(key, time.Now())
+}
+
+func (self *RateLimiter) allowAt(key string, now time.Time) bool {
+	self.Lock()
+	defer self.Unlock()
+	bucket, ok := self.buckets[key]
+	if !ok {
+		if len(self.buckets) >= RATE_LIMITER_MAX_KEYS {
+			self.evictIdle(now)
+		}
+		bucket = &tokenBucket{tokens: self.burst, last: now}
+		self.buckets[key] = bucket
+	}
+	self.refill(bucket, now)
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens -= 1
+	return true
+}
+
+func (self *RateLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.last).Seconds()
+	if elapsed > 0 {
+		bucket.tokens += elapsed * self.rate
+		if bucket.tokens > self.burst {
+			bucket.tokens = self.burst
+		}
+	}
+	bucket.last = now
+}
+
+//evictIdle drops the buckets which are full again, they behave the same as a new bucket
+func (self *RateLimiter) evictIdle(now time.Time) {
+	for key, bucket := range self.buckets {
+		self.refill(bucket, now)
+		if bucket.tokens >= self.burst {
+			delete(self.buckets, key)
+		}
+	}
+}
@@ -20,9 +20,11 @@
 package rpc
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -30,6 +32,7 @@ import (
 
 	// fast json marshal/unmarshal
 	jsoniter "github.com/json-iterator/go"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/http/base/common"
 	berr "github.com/ontio/ontology/http/base/error"
@@ -48,15 +51,27 @@ type JReq struct {
 
 func init() {
 	mainMux.m = make(map[string]func([]interface{}) map[string]interface{})
+	mainMux.local = make(map[string]bool)
 }
 
 //an instance of the multiplexer
 var mainMux ServeMux
 
+//limits shared by all the rpc servers
+var limits = &rpcLimits{maxBatchSize: config.DEFAULT_RPC_MAX_BATCH_SIZE}
+
+type rpcLimits struct {
+	sync.RWMutex
+	maxBatchSize   uint
+	clientLimiter  *RateLimiter
+	methodLimiters map[string]*RateLimiter
+}
+
 //multiplexer that keeps track of every function to be called on specific rpc call
 type ServeMux struct {
 	sync.RWMutex
 	m               map[string]func([]interface{}) map[string]interface{}
+	local           map[string]bool //methods only answered to the clients on local host
 	defaultFunction func(http.ResponseWriter, *http.Request)
 }
 
@@ -67,11 +82,54 @@ func HandleFunc(pattern string, handler func([]interface{}) map[string]interface
 	mainMux.m[pattern] = handler
 }
 
+//HandleLocalFunc registers a function which is only called for the requests from local host
+func HandleLocalFunc(pattern string, handler func([]interface{}) map[string]interface{}) {
+	mainMux.Lock()
+	defer mainMux.Unlock()
+	mainMux.m[pattern] = handler
+	mainMux.local[pattern] = true
+}
+
 //a function to be called if the request is not a HTTP JSON RPC call
 func SetDefaultFunc(def func(http.ResponseWriter, *http.Request)) {
 	mainMux.defaultFunction = def
 }
 
+//SetMaxBatchSize sets the max number of requests in a batch, 0 means no limit
+func SetMaxBatchSize(size uint) {
+	limits.Lock()
+	defer limits.Unlock()
+	limits.maxBatchSize = size
+}
+
+//SetRateLimit limits the requests per second of every client ip, and the calls per second of a method
+//by every client ip. A zero rate disables the limit.
+func SetRateLimit(rate, burst uint, methodRates map[string]uint) {
+	limits.Lock()
+	defer limits.Unlock()
+	limits.clientLimiter = nil
+	if rate != 0 {
+		limits.clientLimiter = NewRateLimiter(rate, burst)
+	}
+	limits.methodLimiters = make(map[string]*RateLimiter)
+	for method, methodRate := range methodRates {
+		if methodRate != 0 {
+			limits.methodLimiters[method] = NewRateLimiter(methodRate, methodRate)
+		}
+	}
+}
+
+func allow(method, clientIP string) bool {
+	limits.RLock()
+	clientLimiter := limits.clientLimiter
+	methodLimiter := limits.methodLimiters[method]
+	limits.RUnlock()
+	if clientLimiter != nil && !clientLimiter.Allow(clientIP) {
+		return false
+	}
+	return methodLimiter == nil || methodLimiter.Allow(clientIP)
+}
+
 // this is the function that should be called in order to answer an rpc call
 // should be registered like "http.HandleFunc("/", httpjsonrpc.Handle)"
 func Handle(w http.ResponseWriter, r *http.Request) {
@@ -103,43 +161,86 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		mainMux.RUnlock()
 		return
 	}
-	var request JReq
 	defer r.Body.Close()
-	decoder := json.NewDecoder(io.LimitReader(r.Body, common.MAX_REQUEST_BODY_SIZE))
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, common.MAX_REQUEST_BODY_SIZE))
+	if err != nil {
+		log.Error("HTTP JSON RPC Handle - read body: ", err)
+		return
+	}
+	response, ok := dispatch(body, remoteIP(r))
+	if !ok {
+		return
+	}
+	writeResponse(w, response)
+}
+
+//dispatch answers a single request or a JSON-RPC 2.0 batch, the responses of a batch are returned in the order
+//of the requests. It returns false if the body is not a valid request.
+func dispatch(body []byte, clientIP string) (interface{}, bool) {
+	if body = bytes.TrimLeft(body, " \t\r\n"); len(body) > 0 && body[0] == '[' {
+		return dispatchBatch(body, clientIP)
+	}
+	var request JReq
+	decoder := json.NewDecoder(bytes.NewReader(body))
 	err := decoder.Decode(&request)
 	if err != nil {
 		log.Error("HTTP JSON RPC Handle - json.Unmarshal: ", err)
-		return
+		return nil, false
 	}
 	if request.Method == "" {
 		log.Error("HTTP JSON RPC Handle - method is not string: ")
-		return
+		return nil, false
+	}
+	return call(&request, clientIP), true
+}
+
+func dispatchBatch(body []byte, clientIP string) (interface{}, bool) {
+	var requests []jsoniter.RawMessage
+	if err := json.Unmarshal(body, &requests); err != nil {
+		log.Error("HTTP JSON RPC Handle - json.Unmarshal batch: ", err)
+		return nil, false
+	}
+	if len(requests) == 0 {
+		return errorResponse(nil, berr.ILLEGAL_DATAFORMAT, "empty batch"), true
+	}
+	limits.RLock()
+	maxBatch := limits.maxBatchSize
+	limits.RUnlock()
+	if maxBatch != 0 && uint(len(requests)) > maxBatch {
+		return errorResponse(nil, berr.SERVICE_CEILING,
+			fmt.Sprintf("batch size %d exceeds limit %d", len(requests), maxBatch)), true
+	}
+	responses := make([]map[string]interface{}, 0, len(requests))
+	for _, raw := range requests {
+		var request JReq
+		if err := json.Unmarshal(raw, &request); err != nil || request.Method == "" {
+			responses = append(responses, errorResponse(request.ID, berr.ILLEGAL_DATAFORMAT, "invalid request"))
+			continue
+		}
+		responses = append(responses, call(&request, clientIP))
+	}
+	return responses, true
+}
+
+//call checks the rate limits and then calls the function registered for the request method
+func call(request *JReq, clientIP string) map[string]interface{} {
+	if !allow(request.Method, clientIP) {
+		log.Debugf("HTTP JSON RPC Handle - rate limit exceeded, method: %s, client: %s", request.Method, clientIP)
+		return errorResponse(request.ID, berr.SERVICE_CEILING, "rate limit exceeded")
 	}
 	//get the corresponding function
 	mainMux.RLock()
 	function, ok := mainMux.m[request.Method]
+	localOnly := mainMux.local[request.Method]
 	mainMux.RUnlock()
-	if ok {
-		response := function(request.Params)
-		data, err := json.Marshal(map[string]interface{}{
-			"jsonrpc": "2.0",
-			"error":   response["error"],
-			"desc":    response["desc"],
-			"result":  response["result"],
-			"id":      request.ID,
-		})
-		if err != nil {
-			log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
-			return
-		}
-		w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("content-type", "application/json;charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Write(data)
-	} else {
+	if ok && localOnly && !isLoopback(clientIP) {
+		log.Warnf("HTTP JSON RPC Handle - %s is called by remote client %s", request.Method, clientIP)
+		return errorResponse(request.ID, berr.INVALID_METHOD, "method is only available on local host")
+	}
+	if !ok {
 		//if the function does not exist
 		log.Warn("HTTP JSON RPC Handle - No function to call for ", request.Method)
-		data, err := json.Marshal(map[string]interface{}{
+		return map[string]interface{}{
 			"error": berr.INVALID_METHOD,
 			"result": map[string]interface{}{
 				"code":    -32601,
@@ -147,16 +248,51 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 				"data":    "The called method was not found on the server",
 			},
 			"id": request.ID,
-		})
-		if err != nil {
-			log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
-			return
 		}
-		w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("content-type", "application/json;charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Write(data)
 	}
+	response := function(request.Params)
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error":   response["error"],
+		"desc":    response["desc"],
+		"result":  response["result"],
+		"id":      request.ID,
+	}
+}
+
+func errorResponse(id interface{}, errcode int64, result interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error":   errcode,
+		"desc":    berr.ErrMap[errcode],
+		"result":  result,
+		"id":      id,
+	}
+}
+
+func writeResponse(w http.ResponseWriter, response interface{}) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
+		return
+	}
+	w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("content-type", "application/json;charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(data)
+}
+
+func isLoopback(clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	return ip != nil && ip.IsLoopback()
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
 // Call sends RPC request to server
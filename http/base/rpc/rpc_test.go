@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	berr "github.com/ontio/ontology/http/base/error"
+	"github.com/stretchr/testify/assert"
+)
+
+const testClientIP = "10.0.0.1"
+
+func init() {
+	HandleFunc("testecho", func(params []interface{}) map[string]interface{} {
+		return ResponseSuccess(params)
+	})
+}
+
+func TestBatchRequest(t *testing.T) {
+	defer SetMaxBatchSize(0)
+
+	response, ok := dispatch([]byte(`{"jsonrpc":"2.0","method":"testecho","params":[1],"id":1}`), testClientIP)
+	assert.True(t, ok)
+	assert.Equal(t, berr.SUCCESS, response.(map[string]interface{})["error"])
+
+	response, ok = dispatch([]byte(` [{"jsonrpc":"2.0","method":"testecho","params":["a"],"id":1},
+		{"jsonrpc":"2.0","method":"notexist","params":[],"id":2},
+		{"jsonrpc":"2.0","params":[],"id":3},
+		{"jsonrpc":"2.0","method":"testecho","params":["b"],"id":4}]`), testClientIP)
+	assert.True(t, ok)
+	responses := response.([]map[string]interface{})
+	assert.Equal(t, 4, len(responses))
+	assert.Equal(t, []interface{}{"a"}, responses[0]["result"])
+	assert.Equal(t, berr.INVALID_METHOD, responses[1]["error"])
+	assert.Equal(t, berr.ILLEGAL_DATAFORMAT, responses[2]["error"])
+	assert.Equal(t, float64(3), responses[2]["id"])
+	assert.Equal(t, []interface{}{"b"}, responses[3]["result"])
+	assert.Equal(t, float64(4), responses[3]["id"])
+
+	response, ok = dispatch([]byte(`[]`), testClientIP)
+	assert.True(t, ok)
+	assert.Equal(t, berr.ILLEGAL_DATAFORMAT, response.(map[string]interface{})["error"])
+
+	_, ok = dispatch([]byte(`[{"method":"testecho"`), testClientIP)
+	assert.False(t, ok)
+
+	SetMaxBatchSize(2)
+	response, ok = dispatch([]byte(`[{"method":"testecho","id":1},{"method":"testecho","id":2},{"method":"testecho","id":3}]`), testClientIP)
+	assert.True(t, ok)
+	assert.Equal(t, berr.SERVICE_CEILING, response.(map[string]interface{})["error"])
+}
+
+func TestRateLimit(t *testing.T) {
+	SetRateLimit(0, 0, map[string]uint{"testecho": 2})
+	defer SetRateLimit(0, 0, nil)
+
+	response, ok := dispatch([]byte(`[{"method":"testecho","id":1},{"method":"testecho","id":2},{"method":"testecho","id":3}]`), testClientIP)
+	assert.True(t, ok)
+	responses := response.([]map[string]interface{})
+	assert.Equal(t, 3, len(responses))
+	assert.Equal(t, berr.SUCCESS, responses[0]["error"])
+	assert.Equal(t, berr.SUCCESS, responses[1]["error"])
+	assert.Equal(t, berr.SERVICE_CEILING, responses[2]["error"])
+	assert.True(t, allow("testecho", "10.0.0.2"))
+	assert.True(t, allow("othermethod", testClientIP))
+
+	SetRateLimit(1, 1, nil)
+	assert.True(t, allow("othermethod", testClientIP))
+	assert.False(t, allow("testecho", testClientIP))
+
+	limiter := NewRateLimiter(1, 3)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.allowAt("ip", now))
+	}
+	assert.False(t, limiter.allowAt("ip", now))
+	assert.True(t, limiter.allowAt("ip", now.Add(time.Second)))
+	assert.False(t, limiter.allowAt("ip", now.Add(time.Second)))
+
+	limiter.evictIdle(now.Add(time.Hour))
+	assert.Equal(t, 0, len(limiter.buckets))
+}
+
+func TestLocalFunc(t *testing.T) {
+	HandleLocalFunc("testlocal", func(params []interface{}) map[string]interface{} {
+		return ResponseSuccess(true)
+	})
+	request := []byte(`{"jsonrpc":"2.0","method":"testlocal","params":[],"id":1}`)
+	response, ok := dispatch(request, testClientIP)
+	assert.True(t, ok)
+	assert.Equal(t, berr.INVALID_METHOD, response.(map[string]interface{})["error"])
+	response, ok = dispatch(request, "127.0.0.1")
+	assert.True(t, ok)
+	assert.Equal(t, berr.SUCCESS, response.(map[string]interface{})["error"])
+	response, ok = dispatch(request, "::1")
+	assert.True(t, ok)
+	assert.Equal(t, berr.SUCCESS, response.(map[string]interface{})["error"])
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package debug
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	"github.com/ontio/ontology/http/ethrpc/eth"
+	types2 "github.com/ontio/ontology/http/ethrpc/types"
+	utils2 "github.com/ontio/ontology/http/ethrpc/utils"
+	types3 "github.com/ontio/ontology/smartcontract/service/evm/types"
+	"github.com/ontio/ontology/vm/evm"
+)
+
+// name of the tracer returning the nested call tree
+const CallTracerName = "callTracer"
+
+// TraceConfig holds extra parameters to trace functions.
+type TraceConfig struct {
+	*evm.LogConfig
+	Tracer *string
+}
+
+// ExecutionResult groups all structured logs emitted by the EVM
+// while replaying a transaction in debug mode as well as transaction
+// execution status, the amount of gas used and the return value
+type ExecutionResult struct {
+	Gas         uint64         `json:"gas"`
+	Failed      bool           `json:"failed"`
+	ReturnValue string         `json:"returnValue"`
+	StructLogs  []StructLogRes `json:"structLogs"`
+}
+
+// StructLogRes stores a structured log emitted by the EVM while replaying a
+// transaction in debug mode
+type StructLogRes struct {
+	Pc      uint64             `json:"pc"`
+	Op      string             `json:"op"`
+	Gas     uint64             `json:"gas"`
+	GasCost uint64             `json:"gasCost"`
+	Depth   int                `json:"depth"`
+	Error   string             `json:"error,omitempty"`
+	Stack   *[]string          `json:"stack,omitempty"`
+	Memory  *[]string          `json:"memory,omitempty"`
+	Storage *map[string]string `json:"storage,omitempty"`
+}
+
+// PublicDebugAPI offers the evm tracing of transactions and calls
+type PublicDebugAPI struct{}
+
+// NewPublicDebugAPI creates a new debug API
+func NewPublicDebugAPI() *PublicDebugAPI {
+	return &PublicDebugAPI{}
+}
+
+// TraceTransaction returns the structured logs created during the execution of evm
+// transaction and returns them as a JSON object, or the call tree with callTracer.
+func (api *PublicDebugAPI) TraceTransaction(hash common.Hash, config *TraceConfig) (interface{}, error) {
+	return traceWith(config, func(tracer evm.Tracer) (*types3.ExecutionResult, error) {
+		return bactor.TraceEip155Tx(utils2.EthToOntHash(hash), tracer)
+	})
+}
+
+// TraceCall lets you trace a given eth_call on top of the state of the block, the state of
+// an earlier block needs the state archive.
+func (api *PublicDebugAPI) TraceCall(args types2.CallArgs, blockNum types2.BlockNumber, config *TraceConfig) (interface{}, error) {
+	height := bactor.GetCurrentBlockHeight()
+	if !blockNum.IsLatest() && !blockNum.IsPending() && blockNum >= 0 {
+		height = uint32(blockNum)
+	}
+	msg := args.AsMessage(eth.RPCGasCap)
+	return traceWith(config, func(tracer evm.Tracer) (*types3.ExecutionResult, error) {
+		return bactor.TraceEip155Call(msg, height, tracer)
+	})
+}
+
+func traceWith(config *TraceConfig, execute func(tracer evm.Tracer) (*types3.ExecutionResult, error)) (interface{}, error) {
+	if config != nil && config.Tracer != nil {
+		if *config.Tracer != CallTracerName {
+			return nil, fmt.Errorf("unsupported tracer: %s", *config.Tracer)
+		}
+		tracer := NewCallTracer()
+		if _, err := execute(tracer); err != nil {
+			return nil, err
+		}
+		return tracer.Result()
+	}
+
+	var logConfig *evm.LogConfig
+	if config != nil {
+		logConfig = config.LogConfig
+	}
+	tracer := evm.NewStructLogger(logConfig)
+	result, err := execute(tracer)
+	if err != nil {
+		return nil, err
+	}
+	// If the result contains a revert reason, return it.
+	returnVal := fmt.Sprintf("%x", result.Return())
+	if len(result.Revert()) > 0 {
+		returnVal = fmt.Sprintf("%x", result.Revert())
+	}
+	return &ExecutionResult{
+		Gas:         result.UsedGas,
+		Failed:      result.Failed(),
+		ReturnValue: returnVal,
+		StructLogs:  FormatLogs(tracer.StructLogs()),
+	}, nil
+}
+
+// FormatLogs formats EVM returned structured logs for json output
+func FormatLogs(logs []evm.StructLog) []StructLogRes {
+	formatted := make([]StructLogRes, len(logs))
+	for index, trace := range logs {
+		formatted[index] = StructLogRes{
+			Pc:      trace.Pc,
+			Op:      trace.Op.String(),
+			Gas:     trace.Gas,
+			GasCost: trace.GasCost,
+			Depth:   trace.Depth,
+			Error:   trace.ErrorString(),
+		}
+		if trace.Stack != nil {
+			stack := make([]string, len(trace.Stack))
+			for i, stackValue := range trace.Stack {
+				stack[i] = fmt.Sprintf("%x", math.PaddedBigBytes(stackValue, 32))
+			}
+			formatted[index].Stack = &stack
+		}
+		if trace.Memory != nil {
+			memory := make([]string, 0, (len(trace.Memory)+31)/32)
+			for i := 0; i+32 <= len(trace.Memory); i += 32 {
+				memory = append(memory, fmt.Sprintf("%x", trace.Memory[i:i+32]))
+			}
+			formatted[index].Memory = &memory
+		}
+		if trace.Storage != nil {
+			storage := make(map[string]string)
+			for i, storageValue := range trace.Storage {
+				storage[fmt.Sprintf("%x", i)] = fmt.Sprintf("%x", storageValue)
+			}
+			formatted[index].Storage = &storage
+		}
+	}
+	return formatted
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package debug
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ontio/ontology/vm/evm"
+)
+
+const errExecutionReverted = "execution reverted"
+
+// CallFrame is a node of the call tree produced by CallTracer
+type CallFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to,omitempty"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Calls        []*CallFrame    `json:"calls,omitempty"`
+
+	gasIn   uint64
+	gasCost uint64
+	outOff  int64
+	outLen  int64
+}
+
+// CallTracer is a native port of the go-ethereum javascript call tracer, it rebuilds the
+// tree of CALL/CREATE frames from the opcodes executed by the evm.
+type CallTracer struct {
+	callstack  []*CallFrame
+	descended  bool
+	precompile map[common.Address]bool
+}
+
+// NewCallTracer returns a new call tracer
+func NewCallTracer() *CallTracer {
+	return &CallTracer{callstack: []*CallFrame{{}}}
+}
+
+// CaptureStart implements the Tracer interface to initialize the top level frame
+func (t *CallTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	top := t.callstack[0]
+	top.Type = "CALL"
+	if create {
+		top.Type = "CREATE"
+	}
+	top.From = from
+	top.To = &to
+	top.Input = common.CopyBytes(input)
+	top.Gas = hexutil.Uint64(gas)
+	if value != nil {
+		top.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+}
+
+// CaptureState implements the Tracer interface to track the nested calls
+func (t *CallTracer) CaptureState(env *evm.EVM, pc uint64, op evm.OpCode, gas, cost uint64, memory *evm.Memory,
+	stack *evm.Stack, rStack *evm.ReturnStack, rData []byte, contract *evm.Contract, depth int, err error) {
+	if err != nil {
+		t.CaptureFault(env, pc, op, gas, cost, memory, stack, rStack, contract, depth, err)
+		return
+	}
+	switch op {
+	case evm.CREATE, evm.CREATE2:
+		inOff := stackInt(stack, 1)
+		inLen := stackInt(stack, 2)
+		t.callstack = append(t.callstack, &CallFrame{
+			Type:    op.String(),
+			From:    contract.Address(),
+			Input:   memory.GetCopy(inOff, inLen),
+			Value:   (*hexutil.Big)(stack.Back(0).ToBig()),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return
+	case evm.SELFDESTRUCT:
+		to := common.Address(stack.Back(0).Bytes20())
+		t.appendCall(&CallFrame{
+			Type:    op.String(),
+			From:    contract.Address(),
+			To:      &to,
+			Value:   (*hexutil.Big)(env.StateDB.GetBalance(contract.Address())),
+			Gas:     hexutil.Uint64(gas),
+			GasUsed: hexutil.Uint64(cost),
+		})
+		return
+	case evm.CALL, evm.CALLCODE, evm.DELEGATECALL, evm.STATICCALL:
+		to := common.Address(stack.Back(1).Bytes20())
+		if t.isPrecompiled(env, to) {
+			return
+		}
+		off := 1
+		if op == evm.DELEGATECALL || op == evm.STATICCALL {
+			off = 0
+		}
+		call := &CallFrame{
+			Type:    op.String(),
+			From:    contract.Address(),
+			To:      &to,
+			Input:   memory.GetCopy(stackInt(stack, 2+off), stackInt(stack, 3+off)),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  stackInt(stack, 4+off),
+			outLen:  stackInt(stack, 5+off),
+		}
+		if op != evm.DELEGATECALL && op != evm.STATICCALL {
+			call.Value = (*hexutil.Big)(stack.Back(2).ToBig())
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return
+	}
+
+	// the first opcode of the callee reports the gas available to it
+	if t.descended {
+		if depth >= len(t.callstack) {
+			t.callstack[len(t.callstack)-1].Gas = hexutil.Uint64(gas)
+		}
+		t.descended = false
+	}
+	if op == evm.REVERT {
+		t.callstack[len(t.callstack)-1].Error = errExecutionReverted
+		return
+	}
+	// returned from the callee, the result of the call is on the top of the caller stack
+	if depth == len(t.callstack)-1 {
+		call := t.callstack[len(t.callstack)-1]
+		t.callstack = t.callstack[:len(t.callstack)-1]
+		ret := stack.Back(0)
+		if call.Type == evm.CREATE.String() || call.Type == evm.CREATE2.String() {
+			call.GasUsed = hexutil.Uint64(call.gasIn - call.gasCost - gas)
+			if !ret.IsZero() {
+				to := common.Address(ret.Bytes20())
+				call.To = &to
+				call.Output = env.StateDB.GetCode(to)
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else {
+			call.GasUsed = hexutil.Uint64(call.gasIn - call.gasCost + uint64(call.Gas) - gas)
+			if !ret.IsZero() {
+				call.Output = memory.GetCopy(call.outOff, call.outLen)
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		if call.Error == errExecutionReverted {
+			call.Output = common.CopyBytes(rData)
+		}
+		t.appendCall(call)
+	}
+}
+
+// CaptureFault implements the Tracer interface, the failed frame consumes all its gas
+func (t *CallTracer) CaptureFault(env *evm.EVM, pc uint64, op evm.OpCode, gas, cost uint64, memory *evm.Memory,
+	stack *evm.Stack, rStack *evm.ReturnStack, contract *evm.Contract, depth int, err error) {
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	call.Error = err.Error()
+	call.GasUsed = call.Gas
+	if len(t.callstack) > 0 {
+		t.appendCall(call)
+		return
+	}
+	// top level call failed, leave it in the stack
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd implements the Tracer interface to finalize the top level frame
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	top := t.callstack[0]
+	top.GasUsed = hexutil.Uint64(gasUsed)
+	top.Output = common.CopyBytes(output)
+	if top.Error == "" && err != nil {
+		top.Error = err.Error()
+	}
+}
+
+// Result returns the call tree of the traced execution
+func (t *CallTracer) Result() (*CallFrame, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+	top := t.callstack[0]
+	finalize(top)
+	return top, nil
+}
+
+func (t *CallTracer) appendCall(call *CallFrame) {
+	parent := t.callstack[len(t.callstack)-1]
+	parent.Calls = append(parent.Calls, call)
+}
+
+func (t *CallTracer) isPrecompiled(env *evm.EVM, addr common.Address) bool {
+	if t.precompile == nil {
+		t.precompile = make(map[common.Address]bool)
+		for _, p := range env.ActivePrecompiles() {
+			t.precompile[p] = true
+		}
+	}
+	return t.precompile[addr]
+}
+
+// finalize decodes the revert reasons and drops the output of failed frames
+func finalize(call *CallFrame) {
+	if call.Error == errExecutionReverted && len(call.Output) != 0 {
+		if reason, err := abi.UnpackRevert(call.Output); err == nil {
+			call.RevertReason = reason
+		}
+	} else if call.Error != "" {
+		call.Output = nil
+	}
+	for _, sub := range call.Calls {
+		finalize(sub)
+	}
+}
+
+func stackInt(stack *evm.Stack, n int) int64 {
+	v := stack.Back(n)
+	if !v.IsUint64() || v.Uint64() > uint64(1<<31) {
+		return 0
+	}
+	return int64(v.Uint64())
+}
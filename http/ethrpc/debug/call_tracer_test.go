@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package debug
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ontio/ontology/core/store/leveldbstore"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/ontio/ontology/smartcontract/service/native/ong"
+	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/ontio/ontology/vm/evm"
+	"github.com/ontio/ontology/vm/evm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallTracer(t *testing.T) {
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(leveldbstore.NewMemLevelDBStore()))
+	statedb := storage.NewStateDB(db, common.Hash{}, common.Hash{}, ong.OngBalanceHandle{})
+	caller := common.HexToAddress("0x0a")
+	callee := common.HexToAddress("0x0b")
+	// callee reverts with Error("no")
+	statedb.SetCode(callee, []byte{
+		byte(evm.PUSH32), 0x08, 0xc3, 0x79, 0xa0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		byte(evm.PUSH1), 0, byte(evm.MSTORE),
+		byte(evm.PUSH1), 0x20, byte(evm.PUSH1), 4, byte(evm.MSTORE),
+		byte(evm.PUSH1), 2, byte(evm.PUSH1), 0x24, byte(evm.MSTORE),
+		byte(evm.PUSH2), 'n', 'o', byte(evm.PUSH1), 0xf0, byte(evm.SHL), byte(evm.PUSH1), 0x44, byte(evm.MSTORE),
+		byte(evm.PUSH1), 0x64, byte(evm.PUSH1), 0, byte(evm.REVERT),
+	})
+	// caller calls callee and returns the call result
+	statedb.SetCode(caller, []byte{
+		byte(evm.PUSH1), 0, byte(evm.PUSH1), 0, byte(evm.PUSH1), 0, byte(evm.PUSH1), 0, byte(evm.PUSH1), 0,
+		byte(evm.PUSH1), 0x0b, byte(evm.GAS), byte(evm.CALL),
+		byte(evm.PUSH1), 0, byte(evm.MSTORE),
+		byte(evm.PUSH1), 0x20, byte(evm.PUSH1), 0, byte(evm.RETURN),
+	})
+
+	tracer := NewCallTracer()
+	ret, _, err := runtime.Call(caller, nil, &runtime.Config{
+		State:     statedb,
+		EVMConfig: evm.Config{Debug: true, Tracer: tracer},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, common.LeftPadBytes(nil, 32), ret)
+
+	top, err := tracer.Result()
+	assert.Nil(t, err)
+	assert.Equal(t, "CALL", top.Type)
+	assert.Equal(t, caller, *top.To)
+	assert.Empty(t, top.Error)
+	assert.Len(t, top.Calls, 1)
+
+	sub := top.Calls[0]
+	assert.Equal(t, "CALL", sub.Type)
+	assert.Equal(t, caller, sub.From)
+	assert.Equal(t, callee, *sub.To)
+	assert.Equal(t, errExecutionReverted, sub.Error)
+	assert.Equal(t, "no", sub.RevertReason)
+	assert.True(t, sub.GasUsed > 0 && sub.GasUsed < sub.Gas)
+}
@@ -43,6 +43,7 @@ import (
 	"github.com/ontio/ontology/smartcontract/service/evm"
 	types3 "github.com/ontio/ontology/smartcontract/service/evm/types"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/ontio/ontology/smartcontract/storage"
 	errors2 "github.com/ontio/ontology/vm/evm/errors"
 	"github.com/ontio/ontology/vm/evm/params"
 )
@@ -76,11 +77,52 @@ func (api *EthereumAPI) BlockNumber() (hexutil.Uint64, error) {
 	return hexutil.Uint64(height), nil
 }
 
-func (api *EthereumAPI) GetBalance(address common.Address, _ rpc.BlockNumberOrHash) (*hexutil.Big, error) {
-	balance, err := getOngBalance(address)
+func (api *EthereumAPI) GetBalance(address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	height, err := heightOfBlockNumberOrHash(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	var balance uint64
+	if height != nil {
+		balance, err = hComm.GetNativeBalanceAt(utils.OngContractAddress, oComm.Address(address), *height)
+	} else {
+		balance, err = getOngBalance(address)
+	}
 	return (*hexutil.Big)(big.NewInt(int64(balance))), err
 }
 
+// heightOfBlockNumberOrHash return the explicit block height, nil means the latest state
+func heightOfBlockNumberOrHash(blockNrOrHash rpc.BlockNumberOrHash) (*uint32, error) {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		header, err := bactor.GetHeaderByHash(utils2.EthToOntHash(hash))
+		if err != nil {
+			return nil, err
+		}
+		return &header.Height, nil
+	}
+	if number, ok := blockNrOrHash.Number(); ok && number >= 0 {
+		height := uint32(number)
+		return &height, nil
+	}
+	return nil, nil
+}
+
+// heightOfBlockNumber return the explicit block height, nil means the latest state
+func heightOfBlockNumber(blockNum types2.BlockNumber) *uint32 {
+	if blockNum.IsLatest() || blockNum.IsPending() || blockNum < 0 {
+		return nil
+	}
+	height := uint32(blockNum)
+	return &height
+}
+
+func getEthAccount(address common.Address, blockNum types2.BlockNumber) (*storage.EthAccount, error) {
+	if height := heightOfBlockNumber(blockNum); height != nil {
+		return bactor.GetEthAccountAt(address, *height)
+	}
+	return bactor.GetEthAccount(address)
+}
+
 func getOngBalance(address common.Address) (uint64, error) {
 	balances, _, err := hComm.GetContractBalance(0, []oComm.Address{utils.OngContractAddress}, oComm.Address(address), true)
 	if err != nil {
@@ -130,6 +172,9 @@ func (api *EthereumAPI) Accounts() ([]common.Address, error) {
 }
 
 func (api *EthereumAPI) GetStorageAt(address common.Address, key string, blockNum types2.BlockNumber) (hexutil.Bytes, error) {
+	if height := heightOfBlockNumber(blockNum); height != nil {
+		return bactor.GetEthStorageAt(address, common.HexToHash(key), *height)
+	}
 	return bactor.GetEthStorage(address, common.HexToHash(key))
 }
 
@@ -139,7 +184,7 @@ func (api *EthereumAPI) GetTransactionCount(address common.Address, blockNum typ
 		n := hexutil.Uint64(nonce)
 		return &n, nil
 	}
-	account, err := bactor.GetEthAccount(address)
+	account, err := getEthAccount(address, blockNum)
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +225,7 @@ func (api *EthereumAPI) GetUncleCountByBlockNumber(number int64) hexutil.Uint {
 }
 
 func (api *EthereumAPI) GetCode(address common.Address, blockNumber types2.BlockNumber) (hexutil.Bytes, error) {
-	account, err := bactor.GetEthAccount(address)
+	account, err := getEthAccount(address, blockNumber)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,295 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package filters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	otypes "github.com/ontio/ontology/core/types"
+	utils2 "github.com/ontio/ontology/http/ethrpc/utils"
+)
+
+// filters not polled within deadline will be uninstalled
+const deadline = 5 * time.Minute
+
+// filter is a helper struct that holds meta information over the filter type
+// and associated subscription in the event system.
+type filter struct {
+	typ      Type
+	deadline *time.Timer // filter is inactive when deadline triggers
+	hashes   []common.Hash
+	crit     FilterCriteria
+	logs     []*types.Log
+	s        *Subscription // associated subscription in event system
+}
+
+// PublicFilterAPI offers support to create and manage filters. This will allow external clients to retrieve various
+// information related to the ontology protocol such as blocks, transactions and logs.
+type PublicFilterAPI struct {
+	events    *EventSystem
+	filtersMu sync.Mutex
+	filters   map[rpc.ID]*filter
+}
+
+// NewPublicFilterAPI returns a new PublicFilterAPI instance.
+func NewPublicFilterAPI(txpool TxPoolService) *PublicFilterAPI {
+	api := &PublicFilterAPI{
+		events:  NewEventSystem(txpool),
+		filters: make(map[rpc.ID]*filter),
+	}
+	go api.timeoutLoop()
+	return api
+}
+
+// timeoutLoop runs every 5 minutes and deletes filters that have not been recently used.
+func (api *PublicFilterAPI) timeoutLoop() {
+	ticker := time.NewTicker(deadline)
+	defer ticker.Stop()
+	for range ticker.C {
+		api.filtersMu.Lock()
+		for id, f := range api.filters {
+			select {
+			case <-f.deadline.C:
+				delete(api.filters, id)
+				api.events.Unsubscribe(f.s)
+			default:
+				continue
+			}
+		}
+		api.filtersMu.Unlock()
+	}
+}
+
+func (api *PublicFilterAPI) install(typ Type, crit FilterCriteria, sub *Subscription) rpc.ID {
+	api.filtersMu.Lock()
+	api.filters[sub.ID] = &filter{typ: typ, crit: crit, deadline: time.NewTimer(deadline), s: sub}
+	api.filtersMu.Unlock()
+	return sub.ID
+}
+
+// NewPendingTransactionFilter creates a filter that fetches pending transaction hashes
+// as transactions enter the pending state.
+func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
+	sub := api.events.SubscribePendingTxs()
+	id := api.install(PendingTransactionsSubscription, FilterCriteria{}, sub)
+	go func() {
+		for hashes := range sub.hashes {
+			api.filtersMu.Lock()
+			if f, found := api.filters[id]; found {
+				f.hashes = append(f.hashes, hashes...)
+			}
+			api.filtersMu.Unlock()
+		}
+	}()
+	return id
+}
+
+// NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
+func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
+	sub := api.events.SubscribeNewHeads()
+	id := api.install(BlocksSubscription, FilterCriteria{}, sub)
+	go func() {
+		for block := range sub.headers {
+			api.filtersMu.Lock()
+			if f, found := api.filters[id]; found {
+				f.hashes = append(f.hashes, utils2.OntToEthHash(block.Hash()))
+			}
+			api.filtersMu.Unlock()
+		}
+	}()
+	return id
+}
+
+// NewFilter creates a new filter and returns the filter id. It can be
+// used to retrieve logs when the state changes.
+func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
+	if crit.BlockHash != nil {
+		return "", fmt.Errorf("blockHash is not supported by eth_newFilter")
+	}
+	sub := api.events.SubscribeLogs(crit)
+	id := api.install(LogsSubscription, crit, sub)
+	go func() {
+		for logs := range sub.logs {
+			api.filtersMu.Lock()
+			if f, found := api.filters[id]; found {
+				f.logs = append(f.logs, logs...)
+			}
+			api.filtersMu.Unlock()
+		}
+	}()
+	return id, nil
+}
+
+// GetLogs returns logs matching the given argument that are stored within the state.
+func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+	logs, err := NewFilterFromCriteria(crit).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return returnLogs(logs), nil
+}
+
+// UninstallFilter removes the filter with the given filter id.
+func (api *PublicFilterAPI) UninstallFilter(id rpc.ID) bool {
+	api.filtersMu.Lock()
+	f, found := api.filters[id]
+	if found {
+		delete(api.filters, id)
+	}
+	api.filtersMu.Unlock()
+	if found {
+		api.events.Unsubscribe(f.s)
+	}
+	return found
+}
+
+// GetFilterLogs returns the logs for the filter with the given id.
+// If the filter could not be found an empty array of logs is returned.
+func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Log, error) {
+	api.filtersMu.Lock()
+	f, found := api.filters[id]
+	api.filtersMu.Unlock()
+
+	if !found || f.typ != LogsSubscription {
+		return nil, fmt.Errorf("filter not found")
+	}
+	return api.GetLogs(ctx, f.crit)
+}
+
+// GetFilterChanges returns the logs for the filter with the given id since
+// last time it was called. This can be used for polling.
+//
+// For pending transaction and block filters the result is []common.Hash.
+// (pending)Log filters return []Log.
+func (api *PublicFilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
+	api.filtersMu.Lock()
+	defer api.filtersMu.Unlock()
+
+	f, found := api.filters[id]
+	if !found {
+		return []interface{}{}, fmt.Errorf("filter not found")
+	}
+	if !f.deadline.Stop() {
+		// timer expired but filter is not yet removed in timeout loop
+		// receive timer value and reset timer
+		<-f.deadline.C
+	}
+	f.deadline.Reset(deadline)
+
+	switch f.typ {
+	case PendingTransactionsSubscription, BlocksSubscription:
+		hashes := f.hashes
+		f.hashes = nil
+		return returnHashes(hashes), nil
+	case LogsSubscription:
+		logs := f.logs
+		f.logs = nil
+		return returnLogs(logs), nil
+	}
+	return []interface{}{}, fmt.Errorf("filter not found")
+}
+
+// NewHeads send a notification each time a new block is appended to the chain.
+func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	sub := api.events.SubscribeNewHeads()
+	return api.notify(ctx, sub, func(notifier *rpc.Notifier, rpcSub *rpc.Subscription) {
+		for block := range sub.headers {
+			notifier.Notify(rpcSub.ID, headerFromOntology(block))
+		}
+	})
+}
+
+// Logs creates a subscription that fires for all new log that match the given filter criteria.
+func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+	if crit.BlockHash != nil || crit.FromBlock != nil || crit.ToBlock != nil {
+		return nil, fmt.Errorf("block range is not supported by logs subscription")
+	}
+	sub := api.events.SubscribeLogs(crit)
+	return api.notify(ctx, sub, func(notifier *rpc.Notifier, rpcSub *rpc.Subscription) {
+		for logs := range sub.logs {
+			for _, log := range logs {
+				notifier.Notify(rpcSub.ID, log)
+			}
+		}
+	})
+}
+
+// NewPendingTransactions creates a subscription that is triggered each time a transaction
+// enters the transaction pool.
+func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	sub := api.events.SubscribePendingTxs()
+	return api.notify(ctx, sub, func(notifier *rpc.Notifier, rpcSub *rpc.Subscription) {
+		for hashes := range sub.hashes {
+			for _, hash := range hashes {
+				notifier.Notify(rpcSub.ID, hash)
+			}
+		}
+	})
+}
+
+// notify forwards the events of sub to the rpc subscription until the client unsubscribes
+// or the connection is closed.
+func (api *PublicFilterAPI) notify(ctx context.Context, sub *Subscription,
+	forward func(notifier *rpc.Notifier, rpcSub *rpc.Subscription)) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		api.events.Unsubscribe(sub)
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	go forward(notifier, rpcSub)
+	go func() {
+		select {
+		case <-rpcSub.Err():
+		case <-notifier.Closed():
+		}
+		api.events.Unsubscribe(sub)
+	}()
+	return rpcSub, nil
+}
+
+func headerFromOntology(block *otypes.Block) map[string]interface{} {
+	header := utils2.EthBlockFromOntology(block, false)
+	delete(header, "transactions")
+	return header
+}
+
+// returnHashes is a helper that will return an empty hash array case the given hash array is nil,
+// otherwise the given hashes array is returned.
+func returnHashes(hashes []common.Hash) []common.Hash {
+	if hashes == nil {
+		return []common.Hash{}
+	}
+	return hashes
+}
+
+// returnLogs is a helper that will return an empty log array in case the given logs array is nil,
+// otherwise the given logs array is returned.
+func returnLogs(logs []*types.Log) []*types.Log {
+	if logs == nil {
+		return []*types.Log{}
+	}
+	return logs
+}
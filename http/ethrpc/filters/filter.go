@@ -0,0 +1,370 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	oComm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/ledgerstore"
+	bactor "github.com/ontio/ontology/http/base/actor"
+	utils2 "github.com/ontio/ontology/http/ethrpc/utils"
+)
+
+// max number of blocks can be searched by one log filter
+const MaxFilterBlockRange = 10000
+
+// ErrLogIndexUnavailable is returned when the node does not save event notifies
+var ErrLogIndexUnavailable = errors.New("log index not available, event log is disabled on this node")
+
+// FilterCriteria represents a request to create a new filter, same as ethereum.FilterQuery
+type FilterCriteria struct {
+	BlockHash *common.Hash     // used by eth_getLogs, return logs only from block with this hash
+	FromBlock *big.Int         // beginning of the queried range, nil means latest block
+	ToBlock   *big.Int         // end of the range, nil means latest block
+	Addresses []common.Address // restricts matches to events created by specific contracts
+	Topics    [][]common.Hash  // restricts matches to particular event topics
+}
+
+// UnmarshalJSON sets *args fields with given data.
+func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
+	type input struct {
+		BlockHash *common.Hash     `json:"blockHash"`
+		FromBlock *rpc.BlockNumber `json:"fromBlock"`
+		ToBlock   *rpc.BlockNumber `json:"toBlock"`
+		Addresses interface{}      `json:"address"`
+		Topics    []interface{}    `json:"topics"`
+	}
+
+	var raw input
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if raw.BlockHash != nil {
+		if raw.FromBlock != nil || raw.ToBlock != nil {
+			return fmt.Errorf("cannot specify both BlockHash and FromBlock/ToBlock, choose one or the other")
+		}
+		args.BlockHash = raw.BlockHash
+	} else {
+		if raw.FromBlock != nil {
+			args.FromBlock = big.NewInt(raw.FromBlock.Int64())
+		}
+		if raw.ToBlock != nil {
+			args.ToBlock = big.NewInt(raw.ToBlock.Int64())
+		}
+	}
+
+	args.Addresses = []common.Address{}
+	if raw.Addresses != nil {
+		// raw.Address can contain a single address or an array of addresses
+		switch rawAddr := raw.Addresses.(type) {
+		case []interface{}:
+			for i, addr := range rawAddr {
+				strAddr, ok := addr.(string)
+				if !ok {
+					return fmt.Errorf("non-string address at index %d", i)
+				}
+				addr, err := decodeAddress(strAddr)
+				if err != nil {
+					return fmt.Errorf("invalid address at index %d: %v", i, err)
+				}
+				args.Addresses = append(args.Addresses, addr)
+			}
+		case string:
+			addr, err := decodeAddress(rawAddr)
+			if err != nil {
+				return fmt.Errorf("invalid address: %v", err)
+			}
+			args.Addresses = []common.Address{addr}
+		default:
+			return errors.New("invalid addresses in query")
+		}
+	}
+
+	// topics is an array consisting of strings and/or arrays of strings.
+	// JSON null values are ignored by the filter.
+	if len(raw.Topics) > 0 {
+		args.Topics = make([][]common.Hash, len(raw.Topics))
+		for i, t := range raw.Topics {
+			switch topic := t.(type) {
+			case nil:
+				// ignore topic when matching logs
+			case string:
+				top, err := decodeTopic(topic)
+				if err != nil {
+					return err
+				}
+				args.Topics[i] = []common.Hash{top}
+			case []interface{}:
+				// or case e.g. [null, "topic0", "topic1"]
+				for _, rawTopic := range topic {
+					if rawTopic == nil {
+						// null component, match all
+						args.Topics[i] = nil
+						break
+					}
+					str, ok := rawTopic.(string)
+					if !ok {
+						return fmt.Errorf("invalid topic(s)")
+					}
+					parsed, err := decodeTopic(str)
+					if err != nil {
+						return err
+					}
+					args.Topics[i] = append(args.Topics[i], parsed)
+				}
+			default:
+				return fmt.Errorf("invalid topic(s)")
+			}
+		}
+	}
+
+	return nil
+}
+
+func decodeAddress(s string) (common.Address, error) {
+	b, err := hexutil.Decode(s)
+	if err == nil && len(b) != common.AddressLength {
+		err = fmt.Errorf("hex has invalid length %d after decoding; expected %d for address", len(b), common.AddressLength)
+	}
+	return common.BytesToAddress(b), err
+}
+
+func decodeTopic(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err == nil && len(b) != common.HashLength {
+		err = fmt.Errorf("hex has invalid length %d after decoding; expected %d for topic", len(b), common.HashLength)
+	}
+	return common.BytesToHash(b), err
+}
+
+// Filter can be used to retrieve and filter logs.
+type Filter struct {
+	addresses []common.Address
+	topics    [][]common.Hash
+
+	block      *common.Hash // block filter if non-nil, otherwise range filter
+	begin, end int64        // range of blocks, negative value means latest block
+}
+
+// NewRangeFilter creates a new filter which inspects the blocks in range [begin, end]
+func NewRangeFilter(begin, end int64, addresses []common.Address, topics [][]common.Hash) *Filter {
+	return &Filter{
+		addresses: addresses,
+		topics:    topics,
+		begin:     begin,
+		end:       end,
+	}
+}
+
+// NewBlockFilter creates a new filter which directly inspects the contents of a block
+func NewBlockFilter(block common.Hash, addresses []common.Address, topics [][]common.Hash) *Filter {
+	return &Filter{
+		addresses: addresses,
+		topics:    topics,
+		block:     &block,
+	}
+}
+
+// NewFilterFromCriteria creates a block or range filter by criteria
+func NewFilterFromCriteria(crit FilterCriteria) *Filter {
+	if crit.BlockHash != nil {
+		return NewBlockFilter(*crit.BlockHash, crit.Addresses, crit.Topics)
+	}
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	return NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
+}
+
+// Logs searches the blockchain for matching log entries.
+func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return nil, ErrLogIndexUnavailable
+	}
+	if f.block != nil {
+		header, err := bactor.GetHeaderByHash(oComm.Uint256(*f.block))
+		if err != nil {
+			if err == scom.ErrNotFound {
+				return nil, fmt.Errorf("block: %v not found", f.block.Hex())
+			}
+			return nil, err
+		}
+		return f.blockLogs(header.Height)
+	}
+
+	head := int64(bactor.GetCurrentBlockHeight())
+	begin, end := f.begin, f.end
+	if begin < 0 {
+		begin = head
+	}
+	if end < 0 || end > head {
+		end = head
+	}
+	if begin > end {
+		return nil, nil
+	}
+	if end-begin >= MaxFilterBlockRange {
+		return nil, fmt.Errorf("block range should be less than %d", MaxFilterBlockRange)
+	}
+	var logs []*types.Log
+	for height := begin; height <= end; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		found, err := f.blockLogs(uint32(height))
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, found...)
+	}
+	return logs, nil
+}
+
+func (f *Filter) blockLogs(height uint32) ([]*types.Log, error) {
+	bloom, err := bactor.GetEvmLogBloom(height)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, err
+	}
+	// every indexed block has a bloom, which is empty if the block has no evm log. a block without bloom
+	// was saved before the bloom index existed, scan its event notifies instead.
+	if err == nil && (bloom == types.Bloom{} || !bloomFilter(bloom, f.addresses, f.topics)) {
+		return nil, nil
+	}
+	logs, err := GetBlockLogs(height)
+	if err != nil {
+		return nil, err
+	}
+	return filterLogs(logs, f.addresses, f.topics), nil
+}
+
+// GetBlockLogs return all the evm logs in block
+func GetBlockLogs(height uint32) ([]*types.Log, error) {
+	notifies, err := bactor.GetEventNotifyByHeight(height)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	blockHash := utils2.OntToEthHash(bactor.GetBlockHashFromStore(height))
+	var logs []*types.Log
+	for _, notify := range notifies {
+		for _, n := range notify.Notify {
+			storageLog, ok := ledgerstore.DecodeEvmLog(n)
+			if !ok {
+				continue
+			}
+			logs = append(logs, &types.Log{
+				Address:     storageLog.Address,
+				Topics:      storageLog.Topics,
+				Data:        storageLog.Data,
+				BlockNumber: uint64(height),
+				TxHash:      utils2.OntToEthHash(notify.TxHash),
+				TxIndex:     uint(notify.TxIndex),
+				BlockHash:   blockHash,
+				Index:       uint(len(logs)),
+			})
+		}
+	}
+	return logs, nil
+}
+
+func includes(addresses []common.Address, a common.Address) bool {
+	for _, addr := range addresses {
+		if addr == a {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLogs creates a slice of logs matching the given criteria.
+func filterLogs(logs []*types.Log, addresses []common.Address, topics [][]common.Hash) []*types.Log {
+	var ret []*types.Log
+Logs:
+	for _, log := range logs {
+		if len(addresses) > 0 && !includes(addresses, log.Address) {
+			continue
+		}
+		// If the to filtered topics is greater than the amount of topics in logs, skip.
+		if len(topics) > len(log.Topics) {
+			continue
+		}
+		for i, sub := range topics {
+			match := len(sub) == 0 // empty rule set == wildcard
+			for _, topic := range sub {
+				if log.Topics[i] == topic {
+					match = true
+					break
+				}
+			}
+			if !match {
+				continue Logs
+			}
+		}
+		ret = append(ret, log)
+	}
+	return ret
+}
+
+func bloomFilter(bloom types.Bloom, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		var included bool
+		for _, addr := range addresses {
+			if types.BloomLookup(bloom, addr) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, sub := range topics {
+		included := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if types.BloomLookup(bloom, topic) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
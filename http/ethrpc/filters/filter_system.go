@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package filters
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ontio/ontology/common/log"
+	otypes "github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/events/message"
+	bactor "github.com/ontio/ontology/http/base/actor"
+)
+
+// Type determines the kind of filter and is used to put the filter in to
+// the correct bucket when added.
+type Type byte
+
+const (
+	// UnknownSubscription indicates an unknown subscription type
+	UnknownSubscription Type = iota
+	// LogsSubscription queries for new or removed logs
+	LogsSubscription
+	// PendingTransactionsSubscription queries tx hashes for pending transactions entering the pending state
+	PendingTransactionsSubscription
+	// BlocksSubscription queries hashes for blocks that are imported
+	BlocksSubscription
+)
+
+const (
+	subscriptionChanSize = 64
+	pendingPollInterval  = time.Second
+)
+
+// TxPoolService provides the pending evm transactions of the tx pool
+type TxPoolService interface {
+	PendingEIPTransactions() []*types.Transaction
+}
+
+// Subscription is created when the client registers itself for a particular event.
+type Subscription struct {
+	ID      rpc.ID
+	typ     Type
+	created time.Time
+	crit    FilterCriteria
+	logs    chan []*types.Log
+	hashes  chan []common.Hash
+	headers chan *otypes.Block
+}
+
+// EventSystem dispatches saved blocks, evm logs and pending transactions to
+// subscribers. slow subscribers will miss messages instead of blocking the event actor.
+type EventSystem struct {
+	once    sync.Once
+	txpool  TxPoolService
+	lock    sync.RWMutex
+	subs    map[rpc.ID]*Subscription
+	pending int
+}
+
+// NewEventSystem creates a new event system
+func NewEventSystem(txpool TxPoolService) *EventSystem {
+	return &EventSystem{
+		txpool: txpool,
+		subs:   make(map[rpc.ID]*Subscription),
+	}
+}
+
+func (self *EventSystem) start() {
+	self.once.Do(func() {
+		bactor.SubscribeEvent(message.TOPIC_SAVE_BLOCK_COMPLETE, self.onBlock)
+		if self.txpool != nil {
+			go self.pollPendingTxs()
+		}
+	})
+}
+
+func (self *EventSystem) subscribe(sub *Subscription) *Subscription {
+	self.start()
+	sub.ID = rpc.NewID()
+	sub.created = time.Now()
+	self.lock.Lock()
+	self.subs[sub.ID] = sub
+	if sub.typ == PendingTransactionsSubscription {
+		self.pending += 1
+	}
+	self.lock.Unlock()
+	return sub
+}
+
+// SubscribeLogs creates a subscription that will write all evm logs matching the
+// given criteria to the returned channel.
+func (self *EventSystem) SubscribeLogs(crit FilterCriteria) *Subscription {
+	return self.subscribe(&Subscription{
+		typ:  LogsSubscription,
+		crit: crit,
+		logs: make(chan []*types.Log, subscriptionChanSize),
+	})
+}
+
+// SubscribeNewHeads creates a subscription that writes the block of a newly saved block.
+func (self *EventSystem) SubscribeNewHeads() *Subscription {
+	return self.subscribe(&Subscription{
+		typ:     BlocksSubscription,
+		headers: make(chan *otypes.Block, subscriptionChanSize),
+	})
+}
+
+// SubscribePendingTxs creates a subscription that writes transaction hashes for
+// transactions that enter the transaction pool.
+func (self *EventSystem) SubscribePendingTxs() *Subscription {
+	return self.subscribe(&Subscription{
+		typ:    PendingTransactionsSubscription,
+		hashes: make(chan []common.Hash, subscriptionChanSize),
+	})
+}
+
+// Unsubscribe uninstalls the subscription from the event system, its channel will be closed.
+func (self *EventSystem) Unsubscribe(sub *Subscription) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if _, ok := self.subs[sub.ID]; !ok {
+		return
+	}
+	delete(self.subs, sub.ID)
+	switch sub.typ {
+	case LogsSubscription:
+		close(sub.logs)
+	case BlocksSubscription:
+		close(sub.headers)
+	case PendingTransactionsSubscription:
+		self.pending -= 1
+		close(sub.hashes)
+	}
+}
+
+func (self *EventSystem) onBlock(v interface{}) {
+	block, ok := v.(otypes.Block)
+	if !ok {
+		return
+	}
+
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	var logs []*types.Log
+	var logsLoaded bool
+	for _, sub := range self.subs {
+		switch sub.typ {
+		case BlocksSubscription:
+			select {
+			case sub.headers <- &block:
+			default:
+				log.Debugf("ethrpc: drop block %d for slow subscriber", block.Header.Height)
+			}
+		case LogsSubscription:
+			if !logsLoaded {
+				var err error
+				logs, err = GetBlockLogs(block.Header.Height)
+				if err != nil {
+					log.Errorf("ethrpc: load logs of block %d: %s", block.Header.Height, err)
+				}
+				logsLoaded = true
+			}
+			matched := filterLogs(logs, sub.crit.Addresses, sub.crit.Topics)
+			if len(matched) == 0 {
+				continue
+			}
+			select {
+			case sub.logs <- matched:
+			default:
+				log.Debugf("ethrpc: drop logs of block %d for slow subscriber", block.Header.Height)
+			}
+		}
+	}
+}
+
+// pollPendingTxs periodically diffs the pending evm transactions of the tx pool
+// while there are pending transaction subscribers.
+func (self *EventSystem) pollPendingTxs() {
+	ticker := time.NewTicker(pendingPollInterval)
+	defer ticker.Stop()
+	// seen is nil until the first poll after someone subscribed, the transactions
+	// already in the pool at that moment are not reported
+	var seen map[common.Hash]bool
+	for range ticker.C {
+		self.lock.RLock()
+		pending := self.pending
+		self.lock.RUnlock()
+		if pending == 0 {
+			seen = nil
+			continue
+		}
+
+		current := make(map[common.Hash]bool)
+		var hashes []common.Hash
+		for _, tx := range self.txpool.PendingEIPTransactions() {
+			hash := tx.Hash()
+			current[hash] = true
+			if seen != nil && !seen[hash] {
+				hashes = append(hashes, hash)
+			}
+		}
+		seen = current
+		if len(hashes) == 0 {
+			continue
+		}
+
+		self.lock.RLock()
+		for _, sub := range self.subs {
+			if sub.typ != PendingTransactionsSubscription {
+				continue
+			}
+			select {
+			case sub.hashes <- hashes:
+			default:
+				log.Debugf("ethrpc: drop pending txs for slow subscriber")
+			}
+		}
+		self.lock.RUnlock()
+	}
+}
@@ -20,11 +20,16 @@ package ethrpc
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/rpc"
 	cfg "github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/http/base/common"
+	"github.com/ontio/ontology/http/ethrpc/debug"
 	"github.com/ontio/ontology/http/ethrpc/eth"
+	"github.com/ontio/ontology/http/ethrpc/filters"
 	"github.com/ontio/ontology/http/ethrpc/net"
+	txpoolapi "github.com/ontio/ontology/http/ethrpc/txpool"
 	"github.com/ontio/ontology/http/ethrpc/web3"
 	tp "github.com/ontio/ontology/txnpool/proc"
 )
@@ -36,19 +41,53 @@ func StartEthServer(txpool *tp.TXPoolServer) error {
 	if err != nil {
 		return err
 	}
+	err = server.RegisterName("eth", filters.NewPublicFilterAPI(txpool))
+	if err != nil {
+		return err
+	}
 	netRpcService := net.NewPublicNetAPI()
 	err = server.RegisterName("net", netRpcService)
 	if err != nil {
 		return err
 	}
+	if cfg.DefConfig.Rpc.EnableEthDebug {
+		err = server.RegisterName("debug", debug.NewPublicDebugAPI())
+		if err != nil {
+			return err
+		}
+	}
+	err = server.RegisterName("txpool", txpoolapi.NewPublicTxPoolAPI(txpool))
+	if err != nil {
+		return err
+	}
 	web3API := web3.NewAPI()
 	err = server.RegisterName("web3", web3API)
 	if err != nil {
 		return err
 	}
-	err = http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.EthJsonPort)), server)
+	// websocket clients share the port with http clients, subscriptions are only served over websocket.
+	// the origin is checked before the upgrade, so the websocket handler itself accepts any origin.
+	wsHandler := server.WebsocketHandler([]string{"*"})
+	checkOrigin := common.NewOriginChecker(cfg.DefConfig.Rpc.EthWsOrigins)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWebsocket(r) {
+			if !checkOrigin(r) {
+				http.Error(w, "websocket origin not allowed", http.StatusForbidden)
+				return
+			}
+			wsHandler.ServeHTTP(w, r)
+			return
+		}
+		server.ServeHTTP(w, r)
+	})
+	err = http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.EthJsonPort)), handler)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func isWebsocket(r *http.Request) bool {
+	return strings.ToLower(r.Header.Get("Upgrade")) == "websocket" &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
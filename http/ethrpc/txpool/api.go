@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package txpool
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ontio/ontology/common/log"
+	types2 "github.com/ontio/ontology/http/ethrpc/types"
+	utils2 "github.com/ontio/ontology/http/ethrpc/utils"
+	tc "github.com/ontio/ontology/txnpool/common"
+)
+
+// TxPoolService provides the transactions with their states in the tx pool
+type TxPoolService interface {
+	TxInfoList() []*tc.PoolTxInfo
+}
+
+// PublicTxPoolAPI offers the txpool namespace of ethereum, only the eip155
+// transactions are returned. The transactions being verified are counted as queued.
+type PublicTxPoolAPI struct {
+	txpool TxPoolService
+}
+
+func NewPublicTxPoolAPI(txpool TxPoolService) *PublicTxPoolAPI {
+	return &PublicTxPoolAPI{txpool: txpool}
+}
+
+type poolTx struct {
+	tx     *types.Transaction
+	rpcTx  *types2.Transaction
+	queued bool
+}
+
+func (api *PublicTxPoolAPI) eipTxs() []*poolTx {
+	var ret []*poolTx
+	for _, info := range api.txpool.TxInfoList() {
+		if !info.Tx.IsEipTx() {
+			continue
+		}
+		tx, err := info.Tx.GetEIP155Tx()
+		if err != nil {
+			log.Errorf("txpool: invalid eip155 tx %s: %s", info.Tx.Hash().ToHexString(), err)
+			continue
+		}
+		rpcTx, err := utils2.NewTransaction(tx, tx.Hash(), common.Hash{}, 0, 0)
+		if err != nil {
+			log.Errorf("txpool: invalid eip155 tx %s: %s", info.Tx.Hash().ToHexString(), err)
+			continue
+		}
+		ret = append(ret, &poolTx{tx: tx, rpcTx: rpcTx, queued: info.State != tc.TxPending})
+	}
+	return ret
+}
+
+func group(content map[string]map[string]map[string]interface{}, tx *poolTx, value interface{}) {
+	state := "pending"
+	if tx.queued {
+		state = "queued"
+	}
+	account := tx.rpcTx.From.Hex()
+	if content[state][account] == nil {
+		content[state][account] = make(map[string]interface{})
+	}
+	content[state][account][fmt.Sprintf("%d", tx.tx.Nonce())] = value
+}
+
+// Content returns the transactions contained within the transaction pool.
+func (api *PublicTxPoolAPI) Content() map[string]map[string]map[string]interface{} {
+	content := map[string]map[string]map[string]interface{}{
+		"pending": make(map[string]map[string]interface{}),
+		"queued":  make(map[string]map[string]interface{}),
+	}
+	for _, tx := range api.eipTxs() {
+		group(content, tx, tx.rpcTx)
+	}
+	return content
+}
+
+// Status returns the number of pending and queued transaction in the pool.
+func (api *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
+	var pending, queued uint
+	for _, tx := range api.eipTxs() {
+		if tx.queued {
+			queued++
+		} else {
+			pending++
+		}
+	}
+	return map[string]hexutil.Uint{
+		"pending": hexutil.Uint(pending),
+		"queued":  hexutil.Uint(queued),
+	}
+}
+
+// Inspect retrieves the content of the transaction pool and flattens it into an
+// easily inspectable list.
+func (api *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]interface{} {
+	content := map[string]map[string]map[string]interface{}{
+		"pending": make(map[string]map[string]interface{}),
+		"queued":  make(map[string]map[string]interface{}),
+	}
+	for _, tx := range api.eipTxs() {
+		summary := fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.tx.Value(), tx.tx.Gas(), tx.tx.GasPrice())
+		if to := tx.tx.To(); to != nil {
+			summary = fmt.Sprintf("%s: %v wei + %v gas × %v wei", to.Hex(), tx.tx.Value(), tx.tx.Gas(), tx.tx.GasPrice())
+		}
+		group(content, tx, summary)
+	}
+	return content
+}
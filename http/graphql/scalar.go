@@ -102,9 +102,7 @@ func (t *Addr) UnmarshalGraphQL(input interface{}) error {
 	case string:
 		if strings.HasPrefix(input, "0x") {
 			t.Address, err = common.AddressFromHexString(input[2:])
-		}
-
-		if err == nil {
+		} else {
 			t.Address, err = common.AddressFromBase58(input)
 		}
 	default:
@@ -96,7 +96,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _schemaGraphql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x55\x4d\x6f\xdb\x30\x0c\xbd\xfb\x57\xb0\xc8\xa5\xbb\xf4\xd0\xb5\xc5\xe0\x5b\xb3\x16\x48\xd1\xaf\x6c\xc9\x36\x0c\x45\x31\x30\x32\x6b\x0b\xb1\x25\x4f\x94\xd3\x18\x45\xff\xfb\x20\xcb\x76\xe5\xc4\x1d\xd0\x53\x22\xea\xf1\xe9\x3d\x92\x92\x27\xb0\x42\xa6\xd3\x2f\xa0\x0d\x64\xb4\x05\xb6\x46\xaa\x14\x30\x49\x0c\x31\x47\x2c\x30\x47\x03\xe7\xed\x72\x12\x62\xf4\x13\x64\xc8\xd9\xf1\xe9\x59\x07\x9b\xb9\xff\xbb\x98\xb2\x5a\xe5\x52\xc0\x9a\xea\x0e\x36\xaf\x56\xd7\x54\x47\xdd\xf2\x87\x54\xf6\xf3\x71\x34\x81\x4a\x2a\x7b\x76\x02\xa4\x84\x4e\x28\x01\xe4\x96\x25\x04\x9e\x9d\x44\x11\xa9\xaa\x80\xe5\x76\x59\x97\x04\x2f\x11\x00\xc0\xd5\xdd\xcf\xfb\xeb\xcb\x3f\x77\x97\xf7\xe1\xf2\xd7\xf9\xe2\xb6\x59\x5f\x5c\xce\x6f\xee\x7f\xf7\xdb\xed\xb2\xd9\x7e\x8d\x22\xa9\x2c\x99\x27\x14\x04\x73\xac\x73\x8d\x49\x4b\xea\x54\x40\x0c\x8b\x46\xc3\x81\x43\x5a\x77\xe2\x95\xda\xe8\x35\x7d\x75\x9b\xb2\x28\x73\x2a\x48\x59\x1e\x49\xdd\xcf\xbc\xa0\x32\xd7\xf5\x47\x32\x5d\x64\x53\x38\xa3\xc3\x98\xc2\x62\x17\x45\x86\xa5\x56\xc3\x20\x56\x36\xd3\x66\x18\xa3\x02\x65\x3e\x0c\x25\xc4\x62\xa0\x76\x02\xd6\xa0\x62\x14\x56\x6a\xe5\x9a\x50\x09\x5b\x19\x72\xdd\xd4\xca\xea\x5c\xa7\xb5\x77\xb4\x0c\x60\x2f\x43\x1d\xbe\xab\xfe\x00\x37\x26\x71\x33\x1d\xad\x7c\xad\x04\x0d\x21\x76\xeb\x5d\xfa\xb6\xfa\x58\x8a\x3c\x37\x72\x17\x99\x22\xdf\xc8\x42\xda\x61\xb4\xc4\x9a\x4c\xdc\x0d\x6a\x1f\x73\x95\x8d\xbb\x12\xfb\x28\xcb\x94\x63\x78\x58\xc8\xf4\xe0\xf1\x20\xf2\xfa\x48\xa6\x59\x40\xd8\x35\x6c\x21\xd3\xd6\x16\xcb\xf4\x02\x2d\xba\x3c\x5f\xa6\xc7\xf6\x88\x66\x94\x1d\x9f\x1f\xea\x2e\x7e\x3b\x20\x9b\xc0\x34\xd7\x62\xfd\xbf\x4a\x7a\x80\x3f\x6c\x02\xcb\x8c\x20\x23\x4c\xc8\x38\xa4\xcd\x24\xc3\xca\x01\x8e\x5a\xb9\x6e\x27\x86\x59\xf3\xdb\x7a\xf0\x49\x41\xdf\x38\xc8\x03\xa9\x44\x5e\x25\x94\x78\x82\x10\x15\xc3\x43\xd0\x45\xa7\xbf\x11\xec\xb9\x41\x3a\x96\x50\x0b\x7a\x42\x2f\xba\x05\x85\xaa\xdb\xfe\xf7\xb2\x7d\xe6\xd1\xf8\x6c\x84\x6e\x91\xb3\xd1\xa4\x70\x76\x02\x7c\x69\x68\x23\x75\xd5\xf9\x73\x28\x8f\x77\x1b\xb3\xf1\x1c\x51\x19\x43\xca\x76\x29\x4d\xd3\x8f\x46\x07\x20\xac\xa8\x2c\x88\x2d\x16\x65\x58\xce\x94\x14\x19\xb4\x7d\x3d\x3b\xcc\x28\x43\x41\x66\x9d\xbb\xd6\x10\x81\xd1\xda\xc2\xb3\xb4\x19\xe4\x84\x1b\x62\x78\x32\xba\x68\xe8\xb8\x27\xb7\x7a\xaf\xe3\xcd\xdf\xef\x5a\xdb\x11\x57\x83\x96\x37\xfc\x23\x23\x63\xb7\xfc\x4e\xba\xd0\x8a\x49\x71\xc5\x90\xa0\xc5\xb1\xdc\x1e\xe1\x6f\x80\x7f\x85\x87\x0e\xab\xdc\xca\xee\x9b\xe1\x28\x84\x56\xba\x65\x55\x3a\x21\x86\xe7\x4c\x83\x40\xd5\x17\x0e\x14\x6d\x6d\x78\x88\x5b\x4f\xb5\x5e\xaf\x89\xca\xc1\x45\x1e\x4a\xdd\x67\xed\x19\xf7\x6a\xd6\xb3\x0d\xaf\x67\x40\xc8\x32\x55\xd8\xdd\xc7\x8f\xb1\x8f\x3d\x08\xdd\xb3\x31\xc5\x1c\x95\xe8\xbe\x4d\x5a\xd9\xb7\xa2\xf9\x40\x3a\x0c\xbc\xf7\xfc\x7c\xab\xc8\xd4\x2d\x4b\x4a\xb6\x79\x22\xa6\xf5\xac\x41\x1f\xee\x24\x7d\x8a\xfd\x13\xb2\x0b\x46\xce\x0e\x83\x2b\x34\x0a\xf3\xa0\x3d\xbe\xb7\xf7\x3a\x25\xbb\xdc\xee\xd0\x04\x8f\x46\x4f\xe6\x7d\x1f\xba\x41\x78\xeb\xa0\x3b\xd2\x6f\x34\xce\x58\x64\x54\x60\xeb\xea\xaf\x73\x18\x7b\xa3\xd1\x6b\xf4\x2f\x00\x00\xff\xff\x28\x4d\x93\xb3\x8d\x08\x00\x00")
+var _schemaGraphql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xcc\x56\x4f\x6f\xdb\xb8\x13\xbd\xeb\x53\x4c\xe0\x4b\x0a\x04\x39\xf4\xd7\x06\x3f\xe8\xd6\xfc\x01\x52\xb4\xf9\xb3\x75\x76\x17\x8b\x20\x58\x8c\xa5\xb1\xc4\xb5\x44\x7a\xc9\x91\x6d\x21\xe8\x77\x5f\x50\x14\x69\xd2\x56\x0a\xf4\xb0\xc0\x9e\x6c\x91\x6f\x1e\x87\xef\x0d\x39\x9c\xc1\x02\x0d\x7d\xfc\x3f\x28\x0d\x35\xed\xc0\xb0\x16\xb2\x02\x2c\x4b\x4d\xc6\x64\xa6\xc0\x06\x35\x7c\x1a\x3f\x67\x31\x46\x2d\xa1\x46\x53\xbf\xff\x78\xe1\x61\xb7\xf6\xff\x21\x66\xdd\x2d\x1a\x51\xc0\x8a\x7a\x0f\x7b\xec\x16\x5f\xa8\xcf\xfc\xe7\xaf\x42\xf2\xff\xde\x67\x33\xe8\x84\xe4\x8b\x0f\x40\xb2\x50\x25\x95\x80\x66\x64\x89\x81\x17\x1f\xb2\x8c\x64\xd7\xc2\xd3\xee\xa9\x5f\x13\xbc\x66\x00\x00\x9f\xef\x7f\x7b\xf8\x72\xf3\xe7\xfd\xcd\x43\xfc\xf9\xfb\xa7\xf9\xdd\xf0\x7d\x7d\xf3\xf8\xf5\xe1\x8f\x30\x3d\x7e\x0e\xd3\xdf\xb3\x4c\x48\x26\xbd\xc4\x82\xe0\x11\xfb\x46\x61\x39\x92\xda\x2c\x20\x87\xf9\x90\xc3\x89\x45\xb2\x5d\xf1\xb3\xdc\xa8\x15\x5d\xd9\x49\xd1\xae\x1b\x6a\x49\xb2\x99\x08\x3d\x8e\xbc\xa6\x75\xa3\xfa\x9f\x89\x04\x00\xd8\xb4\x76\xa3\xe9\x98\xc4\xf6\x10\x45\xda\x08\x25\xd3\x41\xec\xb8\x56\x3a\x1d\xa3\x16\x45\x93\x0e\x95\x64\x8a\x24\xdb\x19\xb0\x46\x69\xb0\x60\xa1\xa4\x35\xa1\x2b\xb8\xd3\x64\xdd\x54\x92\x55\xa3\xaa\xde\xed\xe8\x29\x82\xbd\xa6\x79\x38\x57\xdd\x02\xb6\x4c\xf2\xa1\x3a\xc6\xf4\x95\x2c\x28\x85\xf0\xce\xed\xd2\xd9\xea\xc6\x2a\x34\x8f\x5a\x1c\x22\x2b\x34\x5f\x45\x2b\x38\x1d\x5d\x63\x4f\x3a\xf7\x85\x1a\xc6\xac\xb2\xb9\x97\xd8\x8d\x1a\x51\x99\x1c\x9e\xe7\xa2\x3a\x79\x39\xc9\x5c\x7e\x24\xaa\x3a\x22\xf4\x86\xcd\x45\x35\x6e\xcb\x88\xea\x1a\x19\x6d\x9c\x93\xe9\x65\x5c\x62\x28\x65\xcb\xe7\x8a\xda\x8f\xdf\x25\x64\x33\xb8\x6c\x54\xb1\xfa\x91\x92\x0e\xe0\x16\x9b\xc1\x53\x4d\x50\x13\x96\xa4\x2d\x92\x6b\x61\x60\x61\x01\xe7\x63\xba\x76\x26\x87\xdb\xe1\xf7\x24\x8b\x82\x22\xdf\x4c\x14\x07\x42\x16\x4d\x57\x52\xe9\x08\x62\x54\x0e\xcf\x91\x8b\x27\x2f\x63\xc2\x8e\x1b\x84\x65\x89\x73\x41\x47\xe8\x92\x1e\x41\x71\xd6\xa3\xff\x21\x6d\x17\x79\x3e\x5d\x1b\xf1\x6e\xd1\xd4\x93\x41\x71\xed\x44\xf8\xb5\xa6\x8d\x50\x9d\xdf\x9f\x45\x39\xbc\x9d\xb8\x9d\x8e\x29\x3a\xad\x49\xb2\x0f\x19\x4c\x3f\x9f\x2c\x80\x58\x51\xd1\x92\x61\x6c\xd7\xb1\x9c\x15\x49\xd2\xc8\x41\x4f\x8f\x99\x64\x68\x49\xaf\x1a\x6b\x0d\x11\x68\xa5\x18\xb6\x82\x6b\x68\x08\x37\x64\x60\xa9\x55\x3b\xd0\x99\x40\xce\xea\xc8\xf1\xe1\xef\x37\xa5\x78\x62\x57\x89\xe5\x03\xff\x44\xc9\xf0\xce\xbc\x11\x5e\x28\x69\x48\x9a\xce\x40\x89\x8c\x53\xb1\x01\xe1\x4e\x80\xbb\x85\xd3\x1d\x76\x0d\x0b\xdf\x33\x2c\x45\xa1\xa4\x1a\x59\xa5\x2a\xc9\xc0\xb6\x56\x50\xa0\x0c\xc2\x81\xa4\x1d\xc7\x8b\xd8\xef\x4b\xa5\x56\x2b\xa2\x75\x72\x90\xd3\x54\x8f\x59\x03\xe3\x91\x66\x81\x2d\x3d\x9e\x11\xa1\x11\x95\x44\x7f\x1e\x7f\x8e\x7d\xea\x42\x18\x0e\xce\xbd\x62\xb1\xec\x41\x18\x40\x30\x42\x56\x0d\x01\x6d\x48\x32\x50\x2b\x98\xa9\x84\x45\x0f\x68\x17\x63\x8d\x05\x43\xd9\xd9\x68\xa0\x1d\x15\x9d\xb5\xd0\x1d\xac\x91\xe4\x75\xf2\x80\x24\x96\xc3\xb6\x16\x45\x1d\xc8\x87\x34\x87\xf5\xbc\xef\x6f\x9c\x85\xc8\x2c\xae\x69\x9f\xcf\x8f\xe9\x3c\x6c\x74\x67\xda\xa6\xbf\x8c\x92\xa1\x89\x1b\x46\x26\x13\xca\x2a\xe2\x72\x33\x07\x7d\xe7\x66\xd0\x81\xf6\x22\xda\xe4\x82\x38\xa0\xc9\x74\x0d\xbb\x7b\x28\x12\xc1\x89\x96\xc6\xbe\x4e\xec\x3f\x2c\x7b\xd4\x51\xae\x94\x34\x5d\x4b\xe5\xbe\xbc\x5d\x9f\xb2\x54\x39\x3c\x3b\xce\xe0\xf1\x95\x57\x6b\x4c\xb0\x1c\x1a\x3b\x95\xee\xc9\x80\xb2\x84\x96\x18\x41\xc8\xa5\x72\xb9\x7a\xdd\x5c\xa2\x21\xfc\x3f\xd4\xf2\x5d\x13\xc2\x06\x65\xe1\x1f\x55\x4a\x72\x2a\x87\x92\x55\x3a\xf0\x56\xdf\xfc\xa5\x23\xed\x1d\xa8\x88\x87\xde\x76\xd9\xdf\x0e\xe8\xd3\x83\xa0\x77\xb9\xeb\x7d\x87\x60\x34\xf5\x69\x74\xf7\x4f\xc2\x1c\xe8\x88\x6f\x6f\x76\x45\xfc\xb4\x3b\xa0\x89\xba\xdd\x58\xb4\x8e\x01\x84\x01\xb5\xb6\xc3\xd8\x9c\xc1\xdf\xc3\x16\xac\xb9\xa8\x8b\x5a\x6c\x7c\x29\x03\xa6\xfd\x03\xc4\xd2\xb6\x1c\x13\xca\xda\xe6\xe6\x64\x3c\xb5\x67\x6c\x7f\x44\xce\x0e\xe4\xb2\x3b\x72\xc0\x90\xeb\xbc\x45\xcd\xf6\x7d\x78\x63\x8f\xc9\x65\x7f\x94\x7b\x52\xe2\x6f\x45\xbd\xa9\xf3\x73\x12\xee\x9f\x2a\x15\xb1\x2f\xc8\x83\x8c\xdf\xe5\xa1\x54\x47\xa5\x56\x34\x1c\x4a\xfb\xc0\x1f\x0f\xf8\x19\x68\xe2\x4e\x4b\xff\x4e\x08\x13\xb0\xc1\xa6\x23\x50\x1a\x64\xd7\x34\x56\x26\xa9\x18\x68\x27\x0c\x9f\xff\x4b\xba\xcf\x59\x69\xac\x8e\x75\x5f\x51\x1f\x6a\x7d\xc2\x05\x37\x13\x8a\xf7\xae\x63\x8c\x1e\xb4\x33\xe0\x1d\x88\xe3\xed\x69\xdc\xc6\x57\x50\xaa\x43\x34\x11\x3d\x4d\x0c\xc9\xf2\x1b\x6e\xa3\x02\x3c\xe5\x5d\xc8\x2c\x14\x6e\x78\x7d\x76\x0b\x53\x68\xb1\x4e\x92\xb9\x0d\x2f\x31\xda\x58\xa5\x24\x6d\x9b\x1e\x0c\x5a\xa1\x92\x76\xba\x75\x48\xb3\x7f\x26\x3a\x02\x67\xbe\xbb\x88\x87\x7b\xd9\x31\xb8\x3b\x96\xca\xe4\x39\x71\x06\x4b\xd1\x30\x69\xd7\xb5\xe2\x3e\x61\x46\xb6\xc3\x76\x41\x2d\x6c\x6b\x92\x7b\x1c\x08\xe3\x8c\x6f\xd7\xdc\x9f\x47\xb7\xea\x69\x80\xe4\xf0\xec\xbd\x7a\x79\x97\x8f\x09\x0e\x32\x98\xa2\xa6\x16\xc7\xbd\x0f\x95\x91\xbb\xbb\x65\x18\x68\x47\xa3\xf2\x60\x99\x53\x39\x92\x2d\x4f\x44\xcc\xbe\x67\xff\x0c\x00\x96\x54\xde\x93\xef\x0e\x00\x00")
 
 func schemaGraphqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -111,7 +111,7 @@ func schemaGraphql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "schema.graphql", size: 2189, mode: os.FileMode(438), modTime: time.Unix(1598341445, 0)}
+	info := bindataFileInfo{name: "schema.graphql", size: 3823, mode: os.FileMode(438), modTime: time.Unix(1598341445, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
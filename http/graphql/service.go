@@ -19,6 +19,9 @@
 package graphql
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
 	"strconv"
@@ -29,10 +32,13 @@ import (
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/payload"
+	scom "github.com/ontio/ontology/core/store/common"
 	"github.com/ontio/ontology/core/types"
+	ontErrors "github.com/ontio/ontology/errors"
 	"github.com/ontio/ontology/http/base/actor"
 	comm "github.com/ontio/ontology/http/base/common"
 	"github.com/ontio/ontology/http/graphql/schema"
+	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 	"golang.org/x/net/netutil"
 )
@@ -292,7 +298,22 @@ func (self *resolver) GetTx(args struct{ Hash H256 }) (*transaction, error) {
 	return NewTransaction(tx, height), nil
 }
 
-func (self *resolver) GetBalance(args struct{ Addr Addr }) (*balance, error) {
+func (self *resolver) GetBalance(args struct {
+	Addr   Addr
+	Height *Uint32
+}) (*balance, error) {
+	if args.Height != nil {
+		height := uint32(*args.Height)
+		ont, err := comm.GetNativeBalanceAt(utils.OntContractAddress, args.Addr.Address, height)
+		if err != nil {
+			return nil, err
+		}
+		ong, err := comm.GetNativeBalanceAt(utils.OngContractAddress, args.Addr.Address, height)
+		if err != nil {
+			return nil, err
+		}
+		return &balance{Height: Uint32(height), Ont: Uint64(ont), Ong: Uint64(ong)}, nil
+	}
 	balances, height, err := comm.GetContractBalance(0,
 		[]common.Address{utils.OntContractAddress, utils.OngContractAddress}, args.Addr.Address, true)
 	if err != nil {
@@ -306,6 +327,144 @@ func (self *resolver) GetBalance(args struct{ Addr Addr }) (*balance, error) {
 	}, nil
 }
 
+type notify struct {
+	TxHash          H256
+	ContractAddress Addr
+	States          string
+}
+
+func NewNotify(txHash common.Uint256, n *event.NotifyEventInfo) *notify {
+	states, err := json.Marshal(n.States)
+	if err != nil {
+		states = []byte("null")
+	}
+	return &notify{
+		TxHash:          H256(txHash),
+		ContractAddress: Addr{n.ContractAddress},
+		States:          string(states),
+	}
+}
+
+type executeNotify struct {
+	TxHash      H256
+	State       Uint32
+	GasConsumed Uint64
+	Notify      []*notify
+}
+
+func NewExecuteNotify(evt *event.ExecuteNotify) *executeNotify {
+	var notifies []*notify
+	for _, n := range evt.Notify {
+		notifies = append(notifies, NewNotify(evt.TxHash, n))
+	}
+	return &executeNotify{
+		TxHash:      H256(evt.TxHash),
+		State:       Uint32(evt.State),
+		GasConsumed: Uint64(evt.GasConsumed),
+		Notify:      notifies,
+	}
+}
+
+func (self *resolver) GetSmartCodeEventByTx(args struct{ Hash H256 }) (*executeNotify, error) {
+	evt, err := actor.GetEventNotifyByTxHash(common.Uint256(args.Hash))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return NewExecuteNotify(evt), nil
+}
+
+func (self *resolver) GetSmartCodeEventByHeight(args struct{ Height Uint32 }) ([]*executeNotify, error) {
+	evts, err := actor.GetEventNotifyByHeight(uint32(args.Height))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result []*executeNotify
+	for _, evt := range evts {
+		result = append(result, NewExecuteNotify(evt))
+	}
+	return result, nil
+}
+
+func (self *resolver) GetContract(args struct{ Addr Addr }) (*deployCodePayload, error) {
+	contract, err := actor.GetContractStateFromStore(args.Addr.Address)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	pl, _ := NewTxPayload(contract).ToDeployCode()
+	return pl, nil
+}
+
+func (self *resolver) GetStorage(args struct {
+	Addr   Addr
+	Key    string
+	Height *Uint32
+}) (*string, error) {
+	key, err := common.HexToBytes(args.Key)
+	if err != nil {
+		return nil, err
+	}
+	var value []byte
+	if args.Height != nil {
+		value, err = actor.GetStorageItemAt(args.Addr.Address, key, uint32(*args.Height))
+	} else {
+		value, err = actor.GetStorageItem(args.Addr.Address, key)
+	}
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	val := common.ToHexString(value)
+	return &val, nil
+}
+
+func (self *resolver) SendRawTransaction(args struct{ Tx string }) (H256, error) {
+	raw, err := common.HexToBytes(args.Tx)
+	if err != nil {
+		return H256{}, err
+	}
+	txn, err := types.TransactionFromRawBytes(raw)
+	if err != nil {
+		return H256{}, err
+	}
+	if errCode, desc := comm.SendTxToPool(txn); errCode != ontErrors.ErrNoError {
+		return H256{}, fmt.Errorf("send transaction error: %s, %s", errCode.Error(), desc)
+	}
+
+	return H256(txn.Hash()), nil
+}
+
+func (self *resolver) NewHeaders(ctx context.Context) <-chan *header {
+	return defHub.subscribeHeaders(ctx)
+}
+
+func (self *resolver) Notify(ctx context.Context, args struct{ Contracts *[]Addr }) <-chan *notify {
+	var contracts []common.Address
+	if args.Contracts != nil {
+		for _, addr := range *args.Contracts {
+			contracts = append(contracts, addr.Address)
+		}
+	}
+	return defHub.subscribeNotify(ctx, contracts)
+}
+
 func StartServer(cfg *config.GraphQLConfig) {
 	if !cfg.EnableGraphQL || cfg.GraphQLPort == 0 {
 		return
@@ -316,7 +475,8 @@ func StartServer(cfg *config.GraphQLConfig) {
 		w.Write(page)
 	}))
 
-	serverMut.Handle("/query", &relay.Handler{Schema: ontSchema})
+	defHub.start()
+	serverMut.Handle("/query", newWsHandler(ontSchema, &relay.Handler{Schema: ontSchema}, cfg.AllowedOrigins))
 
 	server := &http.Server{Handler: serverMut}
 	listener, err := net.Listen("tcp", ":"+strconv.Itoa(int(cfg.GraphQLPort)))
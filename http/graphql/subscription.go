@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/events/message"
+	"github.com/ontio/ontology/http/base/actor"
+	"github.com/ontio/ontology/smartcontract/event"
+)
+
+const subscriptionChanSize = 64
+
+var defHub = newSubscriptionHub()
+
+type notifySubscriber struct {
+	contracts map[common.Address]bool
+	ch        chan *notify
+}
+
+// subscriptionHub dispatches saved blocks and contract events to graphql subscribers.
+// slow subscribers will miss messages instead of blocking the event actor.
+type subscriptionHub struct {
+	once     sync.Once
+	lock     sync.RWMutex
+	nextId   uint64
+	headers  map[uint64]chan *header
+	notifies map[uint64]*notifySubscriber
+}
+
+func newSubscriptionHub() *subscriptionHub {
+	return &subscriptionHub{
+		headers:  make(map[uint64]chan *header),
+		notifies: make(map[uint64]*notifySubscriber),
+	}
+}
+
+func (self *subscriptionHub) start() {
+	self.once.Do(func() {
+		actor.SubscribeEvent(message.TOPIC_SAVE_BLOCK_COMPLETE, self.onBlock)
+		actor.SubscribeEvent(message.TOPIC_SMART_CODE_EVENT, self.onSmartCodeEvent)
+	})
+}
+
+func (self *subscriptionHub) subscribeHeaders(ctx context.Context) <-chan *header {
+	ch := make(chan *header, subscriptionChanSize)
+	self.lock.Lock()
+	id := self.nextId
+	self.nextId += 1
+	self.headers[id] = ch
+	self.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		self.lock.Lock()
+		delete(self.headers, id)
+		close(ch)
+		self.lock.Unlock()
+	}()
+
+	return ch
+}
+
+func (self *subscriptionHub) subscribeNotify(ctx context.Context, contracts []common.Address) <-chan *notify {
+	sub := &notifySubscriber{
+		contracts: make(map[common.Address]bool),
+		ch:        make(chan *notify, subscriptionChanSize),
+	}
+	for _, addr := range contracts {
+		sub.contracts[addr] = true
+	}
+	self.lock.Lock()
+	id := self.nextId
+	self.nextId += 1
+	self.notifies[id] = sub
+	self.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		self.lock.Lock()
+		delete(self.notifies, id)
+		close(sub.ch)
+		self.lock.Unlock()
+	}()
+
+	return sub.ch
+}
+
+func (self *subscriptionHub) onBlock(v interface{}) {
+	block, ok := v.(types.Block)
+	if !ok {
+		return
+	}
+	hd := NewHeader(block.Header)
+
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	for _, ch := range self.headers {
+		select {
+		case ch <- hd:
+		default:
+			log.Debugf("graphql: drop header %d for slow subscriber", block.Header.Height)
+		}
+	}
+}
+
+func (self *subscriptionHub) onSmartCodeEvent(v interface{}) {
+	evt, ok := v.(types.SmartCodeEvent)
+	if !ok {
+		return
+	}
+	notifies, ok := evt.Result.(*event.ExecuteNotify)
+	if !ok {
+		return
+	}
+
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	for _, n := range notifies.Notify {
+		nt := NewNotify(notifies.TxHash, n)
+		for _, sub := range self.notifies {
+			if len(sub.contracts) != 0 && !sub.contracts[n.ContractAddress] {
+				continue
+			}
+			select {
+			case sub.ch <- nt:
+			default:
+				log.Debugf("graphql: drop notify of tx %s for slow subscriber", notifies.TxHash.ToHexString())
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/ontio/ontology/common/log"
+	comm "github.com/ontio/ontology/http/base/common"
+)
+
+// message types of the graphql-ws protocol (subscriptions-transport-ws)
+const (
+	gqlConnectionInit      = "connection_init"
+	gqlConnectionAck       = "connection_ack"
+	gqlConnectionError     = "connection_error"
+	gqlConnectionTerminate = "connection_terminate"
+	gqlStart               = "start"
+	gqlStop                = "stop"
+	gqlData                = "data"
+	gqlError               = "error"
+	gqlComplete            = "complete"
+)
+
+const graphqlWsProtocol = "graphql-ws"
+
+const graphqlWsReadLimit = 1024 * 1024
+
+type wsMessage struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type wsStartPayload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// wsHandler serves graphql subscriptions over websocket and delegates plain http
+// requests to the next handler.
+type wsHandler struct {
+	schema   *graphql.Schema
+	next     http.Handler
+	upgrader websocket.Upgrader
+}
+
+func newWsHandler(schema *graphql.Schema, next http.Handler, allowedOrigins []string) *wsHandler {
+	return &wsHandler{
+		schema: schema,
+		next:   next,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{graphqlWsProtocol},
+			CheckOrigin:  comm.NewOriginChecker(allowedOrigins),
+		},
+	}
+}
+
+func (self *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		self.next.ServeHTTP(w, r)
+		return
+	}
+	conn, err := self.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warnf("graphql: upgrade websocket error: %s", err)
+		return
+	}
+	conn.SetReadLimit(graphqlWsReadLimit)
+	newWsConnection(self.schema, conn).serve()
+}
+
+type wsConnection struct {
+	schema *graphql.Schema
+	conn   *websocket.Conn
+
+	writeLock sync.Mutex
+	lock      sync.Mutex
+	ops       map[string]context.CancelFunc
+}
+
+func newWsConnection(schema *graphql.Schema, conn *websocket.Conn) *wsConnection {
+	return &wsConnection{
+		schema: schema,
+		conn:   conn,
+		ops:    make(map[string]context.CancelFunc),
+	}
+}
+
+func (self *wsConnection) serve() {
+	defer self.close()
+	for {
+		msg := &wsMessage{}
+		if err := self.conn.ReadJSON(msg); err != nil {
+			log.Debugf("graphql: read websocket message error: %s", err)
+			return
+		}
+		switch msg.Type {
+		case gqlConnectionInit:
+			self.write(&wsMessage{Type: gqlConnectionAck})
+		case gqlConnectionTerminate:
+			return
+		case gqlStart:
+			self.start(msg)
+		case gqlStop:
+			self.stop(msg.Id)
+		default:
+			self.writeError(gqlConnectionError, msg.Id, "unknown message type: "+msg.Type)
+		}
+	}
+}
+
+func (self *wsConnection) start(msg *wsMessage) {
+	payload := &wsStartPayload{}
+	if err := json.Unmarshal(msg.Payload, payload); err != nil {
+		self.writeError(gqlError, msg.Id, err.Error())
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	self.lock.Lock()
+	if _, present := self.ops[msg.Id]; present {
+		self.lock.Unlock()
+		cancel()
+		self.writeError(gqlError, msg.Id, "duplicated operation id")
+		return
+	}
+	self.ops[msg.Id] = cancel
+	self.lock.Unlock()
+
+	responses, err := self.schema.Subscribe(ctx, payload.Query, payload.OperationName, payload.Variables)
+	if err != nil {
+		self.stop(msg.Id)
+		self.writeError(gqlError, msg.Id, err.Error())
+		return
+	}
+	go func() {
+		for resp := range responses {
+			data, err := json.Marshal(resp)
+			if err != nil {
+				log.Warnf("graphql: marshal subscription response error: %s", err)
+				continue
+			}
+			self.write(&wsMessage{Id: msg.Id, Type: gqlData, Payload: data})
+		}
+		self.write(&wsMessage{Id: msg.Id, Type: gqlComplete})
+		self.stop(msg.Id)
+	}()
+}
+
+func (self *wsConnection) stop(id string) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if cancel, ok := self.ops[id]; ok {
+		cancel()
+		delete(self.ops, id)
+	}
+}
+
+func (self *wsConnection) close() {
+	self.lock.Lock()
+	for id, cancel := range self.ops {
+		cancel()
+		delete(self.ops, id)
+	}
+	self.lock.Unlock()
+	self.conn.Close()
+}
+
+func (self *wsConnection) writeError(ty string, id string, desc string) {
+	payload, _ := json.Marshal(map[string]string{"message": desc})
+	self.write(&wsMessage{Id: id, Type: ty, Payload: payload})
+}
+
+func (self *wsConnection) write(msg *wsMessage) {
+	self.writeLock.Lock()
+	defer self.writeLock.Unlock()
+	if err := self.conn.WriteJSON(msg); err != nil {
+		log.Debugf("graphql: write websocket message error: %s", err)
+	}
+}
@@ -169,6 +169,52 @@ func GetMemPoolTxState(params []interface{}) map[string]interface{} {
 	}
 }
 
+//parse the optional verbose param, 1 means returning the whole transactions
+func parseVerbose(params []interface{}, index int) (bool, bool) {
+	if len(params) <= index {
+		return false, true
+	}
+	verbose, ok := params[index].(float64)
+	if !ok {
+		return false, false
+	}
+	return verbose == 1, true
+}
+
+//get memory pool transactions paid by the address
+func GetMemPoolTxsByAddress(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	verbose, ok := parseVerbose(params, 1)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	return rpc.ResponseSuccess(bcomn.NewMemPoolTxInfoList(bactor.GetTxnsByAddress(address), verbose))
+}
+
+//get memory pool transactions grouped by state and payer
+func GetMemPoolContent(params []interface{}) map[string]interface{} {
+	verbose, ok := parseVerbose(params, 0)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	return rpc.ResponseSuccess(bcomn.NewMemPoolContent(bactor.GetTxnInfoList(), verbose))
+}
+
+//get memory pool statistics
+func GetMemPoolStats(params []interface{}) map[string]interface{} {
+	return rpc.ResponseSuccess(bactor.GetTxnPoolStats())
+}
+
 // get raw transaction in raw or json
 // A JSON example for getrawtransaction method as following:
 //   {"jsonrpc": "2.0", "method": "getrawtransaction", "params": ["transactioin hash in hex"], "id": 0}
@@ -244,7 +290,17 @@ func GetStorage(params []interface{}) map[string]interface{} {
 	default:
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
-	value, err := bactor.GetStorageItem(address, key)
+	var value []byte
+	var err error
+	if len(params) > 2 {
+		height, ok := params[2].(float64)
+		if !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+		value, err = bactor.GetStorageItemAt(address, key, uint32(height))
+	} else {
+		value, err = bactor.GetStorageItem(address, key)
+	}
 	if err != nil {
 		if err == scom.ErrNotFound {
 			return rpc.ResponseSuccess(nil)
@@ -394,6 +450,66 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 }
 
+//get smartconstract event by filter
+//params: [fromHeight, toHeight, [contract addresses], topic, cursor, limit], the last three params are optional
+func GetSmartCodeEventByFilter(params []interface{}) map[string]interface{} {
+	if !config.DefConfig.Common.EnableEventLog {
+		return rpc.ResponsePack(berr.INVALID_METHOD, "")
+	}
+	if len(params) < 3 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, nil)
+	}
+	fromHeight, ok := params[0].(float64)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	toHeight, ok := params[1].(float64)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	var contracts []string
+	switch addrs := params[2].(type) {
+	case string:
+		contracts = append(contracts, addrs)
+	case []interface{}:
+		for _, addr := range addrs {
+			str, ok := addr.(string)
+			if !ok {
+				return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+			}
+			contracts = append(contracts, str)
+		}
+	default:
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	var topic, cursor string
+	var limit float64
+	if len(params) > 3 {
+		if topic, ok = params[3].(string); !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	if len(params) > 4 {
+		if cursor, ok = params[4].(string); !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	if len(params) > 5 {
+		if limit, ok = params[5].(float64); !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	filter, err := bcomn.NewEventFilter(uint32(fromHeight), uint32(toHeight), contracts, topic, cursor, uint32(limit))
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	logs, next, err := bactor.GetEventNotifyByFilter(filter)
+	if err != nil {
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, "")
+	}
+	return rpc.ResponseSuccess(bcomn.GetEventFilterResult(logs, next))
+}
+
 //get block height by transaction hash
 func GetBlockHeightByTxHash(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -432,7 +548,16 @@ func GetBalance(params []interface{}) map[string]interface{} {
 	if err != nil {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
-	rsp, err := bcomn.GetBalance(address)
+	var rsp *bcomn.BalanceOfRsp
+	if len(params) > 1 {
+		height, ok := params[1].(float64)
+		if !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+		rsp, err = bcomn.GetBalanceAt(address, uint32(height))
+	} else {
+		rsp, err = bcomn.GetBalance(address)
+	}
 	if err != nil {
 		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
 	}
@@ -620,6 +745,26 @@ func GetGrantOng(params []interface{}) map[string]interface{} {
 	return rpc.ResponseSuccess(rsp)
 }
 
+//get credential status by hex encoded credential id
+func GetCredentialStatus(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	id, err := common.HexToBytes(str)
+	if err != nil || len(id) == 0 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	rsp, err := bcomn.GetCredentialStatus(id)
+	if err != nil {
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, "")
+	}
+	return rpc.ResponseSuccess(rsp)
+}
+
 //get cross chain message by height
 func GetCrossChainMsg(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -642,6 +787,47 @@ func GetCrossChainMsg(params []interface{}) map[string]interface{} {
 	return rpc.ResponseSuccess(bcomn.TransferCrossChainMsg(msg, header.Bookkeepers))
 }
 
+//get the cross chain proof bundle by height, key and the trusted height of light client
+func GetCrossChainProofBundle(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	height, ok := params[0].(float64)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[1].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	key, err := hex.DecodeString(str)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	var trustedHeight float64
+	if len(params) > 2 {
+		if trustedHeight, ok = params[2].(float64); !ok {
+			return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	bundle, err := bcomn.GetCrossChainProofBundle(uint32(height), key, uint32(trustedHeight))
+	if err != nil {
+		log.Errorf("GetCrossChainProofBundle, get proof bundle error:%s", err)
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, "")
+	}
+	return rpc.ResponseSuccess(hex.EncodeToString(common.SerializeToBytes(bundle)))
+}
+
+//get the bound proxies and assets of lock proxy contract
+func GetLockProxyBindings(params []interface{}) map[string]interface{} {
+	rsp, err := bcomn.GetLockProxyBindings()
+	if err != nil {
+		log.Errorf("GetLockProxyBindings, get lock proxy bindings error:%s", err)
+		return rpc.ResponsePack(berr.INTERNAL_ERROR, "")
+	}
+	return rpc.ResponseSuccess(rsp)
+}
+
 //get cross chain state proof
 func GetCrossStatesProof(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
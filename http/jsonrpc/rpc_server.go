@@ -31,6 +31,8 @@ import (
 
 func StartRPCServer() error {
 	log.Debug()
+	rpc.SetMaxBatchSize(cfg.DefConfig.Rpc.MaxBatchSize)
+	rpc.SetRateLimit(cfg.DefConfig.Rpc.RateLimit, cfg.DefConfig.Rpc.RateBurst, cfg.DefConfig.Rpc.MethodRateLimit)
 	http.HandleFunc("/", rpc.Handle)
 	rpc.HandleFunc("getbestblockhash", GetBestBlockHash)
 	rpc.HandleFunc("getblock", GetBlock)
@@ -50,7 +52,11 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getmempooltxcount", GetMemPoolTxCount)
 	rpc.HandleFunc("getmempooltxstate", GetMemPoolTxState)
 	rpc.HandleFunc("getmempooltxhashlist", GetMemPoolTxHashList)
+	rpc.HandleFunc("getmempooltxsbyaddress", GetMemPoolTxsByAddress)
+	rpc.HandleFunc("getmempoolcontent", GetMemPoolContent)
+	rpc.HandleFunc("getmempoolstats", GetMemPoolStats)
 	rpc.HandleFunc("getsmartcodeevent", GetSmartCodeEvent)
+	rpc.HandleFunc("getsmartcodeeventbyfilter", GetSmartCodeEventByFilter)
 	rpc.HandleFunc("getblockheightbytxhash", GetBlockHeightByTxHash)
 
 	rpc.HandleFunc("getbalance", GetBalance)
@@ -61,8 +67,11 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getgasprice", GetGasPrice)
 	rpc.HandleFunc("getunboundong", GetUnboundOng)
 	rpc.HandleFunc("getgrantong", GetGrantOng)
+	rpc.HandleFunc("getcredentialstatus", GetCredentialStatus)
 
 	rpc.HandleFunc("getcrosschainmsg", GetCrossChainMsg)
+	rpc.HandleFunc("getcrosschainproofbundle", GetCrossChainProofBundle)
+	rpc.HandleFunc("getlockproxybindings", GetLockProxyBindings)
 	rpc.HandleFunc("getcrossstatesproof", GetCrossStatesProof)
 
 	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
@@ -21,6 +21,7 @@ package localrpc
 import (
 	"time"
 
+	comm "github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/log"
 	bactor "github.com/ontio/ontology/http/base/actor"
 	"github.com/ontio/ontology/http/base/common"
@@ -84,3 +85,26 @@ func SetDebugInfo(params []interface{}) map[string]interface{} {
 	}
 	return rpc.ResponsePack(berr.SUCCESS, true)
 }
+
+//drop a transaction from memory pool, params: tx hash, admin token
+func DropMemPoolTx(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	hash, err := comm.Uint256FromHexString(str)
+	if err != nil {
+		return rpc.ResponsePack(berr.INVALID_PARAMS, "")
+	}
+	token, ok := params[1].(string)
+	if !ok || !common.CheckAdminToken(token) {
+		return rpc.ResponsePack(berr.SESSION_EXPIRED, "invalid admin token")
+	}
+	if !bactor.DropTxFromPool(hash) {
+		return rpc.ResponsePack(berr.UNKNOWN_TRANSACTION, "unknown transaction")
+	}
+	return rpc.ResponsePack(berr.SUCCESS, true)
+}
@@ -43,6 +43,7 @@ func StartLocalServer() error {
 	rpc.HandleFunc("startconsensus", StartConsensus)
 	rpc.HandleFunc("stopconsensus", StopConsensus)
 	rpc.HandleFunc("setdebuginfo", SetDebugInfo)
+	rpc.HandleLocalFunc("dropmempooltx", DropMemPoolTx)
 
 	// TODO: only listen to local host
 	err := http.ListenAndServe(LOCAL_HOST+":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpLocalPort)), nil)
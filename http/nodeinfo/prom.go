@@ -59,11 +59,37 @@ var (
 		Name: "ontology_p2p_reconnect_count",
 		Help: "ontology p2p reconnect count",
 	})
+
+	peerMsgsMetric = prom.NewGaugeVec(prom.GaugeOpts{
+		Name: "ontology_p2p_peer_msgs",
+		Help: "ontology p2p message count of peer by message type",
+	}, []string{"id", "type", "direction"})
+
+	peerMsgBytesMetric = prom.NewGaugeVec(prom.GaugeOpts{
+		Name: "ontology_p2p_peer_msg_bytes",
+		Help: "ontology p2p message bytes of peer by message type",
+	}, []string{"id", "type", "direction"})
+
+	peerLimitedMsgsMetric = prom.NewGaugeVec(prom.GaugeOpts{
+		Name: "ontology_p2p_peer_limited_msgs",
+		Help: "ontology p2p message count of peer dropped by rate limit",
+	}, []string{"id", "type"})
+
+	peerReputationMetric = prom.NewGaugeVec(prom.GaugeOpts{
+		Name: "ontology_p2p_peer_reputation",
+		Help: "ontology p2p peer reputation score",
+	}, []string{"id"})
+
+	bannedCountMetric = prom.NewGauge(prom.GaugeOpts{
+		Name: "ontology_p2p_banned_count",
+		Help: "ontology p2p banned address count",
+	})
 )
 
 var (
 	metrics = []prom.Collector{nodePortMetric, blockHeightMetric, inboundsCountMetric,
-		outboundsCountMetric, peerStatusMetric, reconnectCountMetric}
+		outboundsCountMetric, peerStatusMetric, reconnectCountMetric, peerMsgsMetric, peerMsgBytesMetric,
+		peerLimitedMsgsMetric, peerReputationMetric, bannedCountMetric}
 )
 
 func initMetric() error {
@@ -88,13 +114,33 @@ func metricUpdate(n p2p.P2P) {
 
 	inboundsCountMetric.Set(float64(ns.ConnectController().InboundsCount()))
 	outboundsCountMetric.Set(float64(ns.ConnectController().OutboundsCount()))
+	bannedCountMetric.Set(float64(ns.ConnectController().BannedCount()))
+
+	// drop the series of disconnected peers
+	peerMsgsMetric.Reset()
+	peerMsgBytesMetric.Reset()
+	peerLimitedMsgsMetric.Reset()
+	peerReputationMetric.Reset()
 
 	peers := ns.GetNeighbors()
 	for _, curPeer := range peers {
 		id := curPeer.GetID()
+		hexId := id.ToHexString()
 
 		// label: IP PeedID
-		peerStatusMetric.WithLabelValues(curPeer.GetAddr(), id.ToHexString()).Set(float64(curPeer.GetHeight()))
+		peerStatusMetric.WithLabelValues(curPeer.GetAddr(), hexId).Set(float64(curPeer.GetHeight()))
+
+		peerReputationMetric.WithLabelValues(hexId).Set(float64(curPeer.Link.GetReputation()))
+		for cmd, counter := range curPeer.Link.GetStats().Snapshot() {
+			// label: PeerID MsgType Direction
+			peerMsgsMetric.WithLabelValues(hexId, cmd, "recv").Set(float64(counter.RecvMsgs))
+			peerMsgsMetric.WithLabelValues(hexId, cmd, "send").Set(float64(counter.SendMsgs))
+			peerMsgBytesMetric.WithLabelValues(hexId, cmd, "recv").Set(float64(counter.RecvBytes))
+			peerMsgBytesMetric.WithLabelValues(hexId, cmd, "send").Set(float64(counter.SendBytes))
+			if counter.LimitedMsgs != 0 {
+				peerLimitedMsgsMetric.WithLabelValues(hexId, cmd).Set(float64(counter.LimitedMsgs))
+			}
+		}
 	}
 
 	pt := ns.Protocol()
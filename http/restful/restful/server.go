@@ -26,6 +26,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -68,21 +69,30 @@ const (
 	GET_CONTRACT_STATE    = "/api/v1/contract/:hash"
 	GET_SMTCOCE_EVT_TXS   = "/api/v1/smartcode/event/transactions/:height"
 	GET_SMTCOCE_EVTS      = "/api/v1/smartcode/event/txhash/:hash"
+	GET_SMTCOCE_EVT_FLT   = "/api/v1/smartcode/event/filter"
 	GET_BLK_HGT_BY_TXHASH = "/api/v1/block/height/txhash/:hash"
 	GET_MERKLE_PROOF      = "/api/v1/merkleproof/:hash"
 	GET_GAS_PRICE         = "/api/v1/gasprice"
 	GET_ALLOWANCE         = "/api/v1/allowance/:asset/:from/:to"
 	GET_UNBOUNDONG        = "/api/v1/unboundong/:addr"
 	GET_GRANTONG          = "/api/v1/grantong/:addr"
+	GET_CREDENTIAL_STATUS = "/api/v1/credential/status/:id"
 	GET_MEMPOOL_TXCOUNT   = "/api/v1/mempool/txcount"
 	GET_MEMPOOL_TXSTATE   = "/api/v1/mempool/txstate/:hash"
 	GET_MEMPOOL_TXHASHS   = "/api/v1/mempool/txhashlist"
+	GET_MEMPOOL_TXS       = "/api/v1/mempool/txs/:addr"
+	GET_MEMPOOL_CONTENT   = "/api/v1/mempool/content"
+	GET_MEMPOOL_STATS     = "/api/v1/mempool/stats"
 	GET_VERSION           = "/api/v1/version"
 	GET_NETWORKID         = "/api/v1/networkid"
 
-	POST_RAW_TX = "/api/v1/transaction"
+	POST_RAW_TX       = "/api/v1/transaction"
+	POST_MEMPOOL_DROP = "/api/v1/mempool/drop"
 )
 
+//universal resolver compatible did resolution path, followed by the did
+const DID_RESOLVER_PATH = "/1.0/identifiers/"
+
 //init restful server
 func InitRestServer() rest.ApiServer {
 	rt := &restServer{}
@@ -91,6 +101,7 @@ func InitRestServer() rest.ApiServer {
 	rt.registryMethod()
 	rt.initGetHandler()
 	rt.initPostHandler()
+	rt.initDIDResolver()
 	return rt
 }
 
@@ -161,6 +172,7 @@ func (this *restServer) registryMethod() {
 		GET_CONTRACT_STATE:    {name: "getcontract", handler: rest.GetContractState},
 		GET_SMTCOCE_EVT_TXS:   {name: "getsmartcodeeventbyheight", handler: rest.GetSmartCodeEventTxsByHeight},
 		GET_SMTCOCE_EVTS:      {name: "getsmartcodeeventbyhash", handler: rest.GetSmartCodeEventByTxHash},
+		GET_SMTCOCE_EVT_FLT:   {name: "getsmartcodeeventbyfilter", handler: rest.GetSmartCodeEventByFilter},
 		GET_BLK_HGT_BY_TXHASH: {name: "getblockheightbytxhash", handler: rest.GetBlockHeightByTxHash},
 		GET_STORAGE:           {name: "getstorage", handler: rest.GetStorage},
 		GET_BALANCE:           {name: "getbalance", handler: rest.GetBalance},
@@ -169,15 +181,20 @@ func (this *restServer) registryMethod() {
 		GET_GAS_PRICE:         {name: "getgasprice", handler: rest.GetGasPrice},
 		GET_UNBOUNDONG:        {name: "getunboundong", handler: rest.GetUnboundOng},
 		GET_GRANTONG:          {name: "getgrantong", handler: rest.GetGrantOng},
+		GET_CREDENTIAL_STATUS: {name: "getcredentialstatus", handler: rest.GetCredentialStatus},
 		GET_MEMPOOL_TXCOUNT:   {name: "getmempooltxcount", handler: rest.GetMemPoolTxCount},
 		GET_MEMPOOL_TXSTATE:   {name: "getmempooltxstate", handler: rest.GetMemPoolTxState},
 		GET_MEMPOOL_TXHASHS:   {name: "getmempooltxhashlist", handler: rest.GetMemPoolTxHashList},
+		GET_MEMPOOL_TXS:       {name: "getmempooltxsbyaddress", handler: rest.GetMemPoolTxsByAddress},
+		GET_MEMPOOL_CONTENT:   {name: "getmempoolcontent", handler: rest.GetMemPoolContent},
+		GET_MEMPOOL_STATS:     {name: "getmempoolstats", handler: rest.GetMemPoolStats},
 		GET_VERSION:           {name: "getversion", handler: rest.GetNodeVersion},
 		GET_NETWORKID:         {name: "getnetworkid", handler: rest.GetNetworkId},
 	}
 
 	postMethodMap := map[string]Action{
-		POST_RAW_TX: {name: "sendrawtransaction", handler: rest.SendRawTransaction},
+		POST_RAW_TX:       {name: "sendrawtransaction", handler: rest.SendRawTransaction},
+		POST_MEMPOOL_DROP: {name: "dropmempooltx", handler: rest.DropMemPoolTx},
 	}
 	this.postMap = postMethodMap
 	this.getMap = getMethodMap
@@ -215,8 +232,12 @@ func (this *restServer) getPath(url string) string {
 		return GET_UNBOUNDONG
 	} else if strings.Contains(url, strings.TrimRight(GET_GRANTONG, ":addr")) {
 		return GET_GRANTONG
+	} else if strings.Contains(url, strings.TrimRight(GET_CREDENTIAL_STATUS, ":id")) {
+		return GET_CREDENTIAL_STATUS
 	} else if strings.Contains(url, strings.TrimRight(GET_MEMPOOL_TXSTATE, ":hash")) {
 		return GET_MEMPOOL_TXSTATE
+	} else if strings.Contains(url, strings.TrimRight(GET_MEMPOOL_TXS, ":addr")) {
+		return GET_MEMPOOL_TXS
 	}
 	return url
 }
@@ -242,14 +263,19 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["PreExec"] = r.FormValue("preExec")
 	case GET_STORAGE:
 		req["Hash"], req["Key"] = getParam(r, "hash"), getParam(r, "key")
+		req["Height"] = r.FormValue("height")
 	case GET_SMTCOCE_EVT_TXS:
 		req["Height"] = getParam(r, "height")
 	case GET_SMTCOCE_EVTS:
 		req["Hash"] = getParam(r, "hash")
+	case GET_SMTCOCE_EVT_FLT:
+		req["FromHeight"], req["ToHeight"] = r.FormValue("from"), r.FormValue("to")
+		req["Contracts"], req["Topic"] = r.FormValue("contracts"), r.FormValue("topic")
+		req["Cursor"], req["Limit"] = r.FormValue("cursor"), r.FormValue("limit")
 	case GET_BLK_HGT_BY_TXHASH:
 		req["Hash"] = getParam(r, "hash")
 	case GET_BALANCE:
-		req["Addr"] = getParam(r, "addr")
+		req["Addr"], req["Height"] = getParam(r, "addr"), r.FormValue("height")
 	case GET_MERKLE_PROOF:
 		req["Hash"] = getParam(r, "hash")
 	case GET_ALLOWANCE:
@@ -259,8 +285,16 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["Addr"] = getParam(r, "addr")
 	case GET_GRANTONG:
 		req["Addr"] = getParam(r, "addr")
+	case GET_CREDENTIAL_STATUS:
+		req["Id"] = getParam(r, "id")
 	case GET_MEMPOOL_TXSTATE:
 		req["Hash"] = getParam(r, "hash")
+	case GET_MEMPOOL_TXS:
+		req["Addr"], req["Verbose"] = getParam(r, "addr"), r.FormValue("verbose")
+	case GET_MEMPOOL_CONTENT:
+		req["Verbose"] = r.FormValue("verbose")
+	case POST_MEMPOOL_DROP:
+		req["RemoteAddr"] = r.RemoteAddr
 	default:
 	}
 	return req
@@ -321,6 +355,35 @@ func (this *restServer) initPostHandler() {
 	}
 
 }
+//init did resolver handler, the response follows the W3C DID Resolution instead of the restful api format
+func (this *restServer) initDIDResolver() {
+	this.router.Get(regexp.QuoteMeta(DID_RESOLVER_PATH)+".+", func(w http.ResponseWriter, r *http.Request) {
+		did := strings.TrimPrefix(r.URL.Path, DID_RESOLVER_PATH)
+		res := common.ResolveDID(did, r.FormValue("versionId"), r.FormValue("versionTime"))
+		status := http.StatusOK
+		switch {
+		case res.ResolutionMetadata.Error == common.DID_INVALID:
+			status = http.StatusBadRequest
+		case res.ResolutionMetadata.Error == common.DID_NOT_FOUND:
+			status = http.StatusNotFound
+		case res.ResolutionMetadata.Error != "":
+			status = http.StatusInternalServerError
+		case res.DocumentMetadata.Deactivated:
+			status = http.StatusGone
+		}
+		data, err := json.Marshal(res)
+		if err != nil {
+			log.Errorf("DID resolver - json.Marshal: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("content-type", `application/ld+json;profile="https://w3id.org/did-resolution"`)
+		w.WriteHeader(status)
+		w.Write(data)
+	})
+}
+
 func (this *restServer) write(w http.ResponseWriter, data []byte) {
 	w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
 	w.Header().Set("content-type", "application/json;charset=utf-8")
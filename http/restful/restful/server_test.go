@@ -0,0 +1,208 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package restful
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/ledger"
+	"github.com/ontio/ontology/core/store"
+	scom "github.com/ontio/ontology/core/store/common"
+	"github.com/ontio/ontology/core/store/overlaydb"
+	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/http/base/common"
+	"github.com/ontio/ontology/smartcontract"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/ontio/ontology/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLedgerStore serves the headers and the states of a short chain, the state of a height is not archived
+// if it is nil
+type mockLedgerStore struct {
+	store.LedgerStore
+	headers []*types.Header
+	states  []*overlaydb.OverlayDB
+}
+
+func (self *mockLedgerStore) GetCurrentBlockHeight() uint32 {
+	return uint32(len(self.headers) - 1)
+}
+
+func (self *mockLedgerStore) GetHeaderByHeight(height uint32) (*types.Header, error) {
+	if int(height) >= len(self.headers) {
+		return nil, scom.ErrNotFound
+	}
+	return self.headers[height], nil
+}
+
+func (self *mockLedgerStore) NewOverlayDBAt(height uint32) (*overlaydb.OverlayDB, error) {
+	if int(height) >= len(self.states) || self.states[height] == nil {
+		return nil, fmt.Errorf("state of height %d is not archived", height)
+	}
+	return self.states[height], nil
+}
+
+type ontIdOp struct {
+	method string
+	input  []byte
+}
+
+// newDIDTestLedger build a chain of 7 blocks, the ONT ID is registered at height 1, updated at height 3 and revoked
+// at height 5, the state of height 2 is not archived
+func newDIDTestLedger(t *testing.T, id string, acc *account.Account) *mockLedgerStore {
+	ontid.Init()
+	sink := comm.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	sink.WriteVarBytes(keypair.SerializePublicKey(acc.PubKey()))
+	reg := sink.Bytes()
+	sink = comm.NewZeroCopySink(nil)
+	(&ontid.Context{OntId: []byte(id), Contexts: [][]byte{[]byte("https://example.com/context/v1")},
+		Index: 1}).Serialization(sink)
+	update := sink.Bytes()
+	sink = comm.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	utils.EncodeVarUint(sink, 1)
+	revoke := sink.Bytes()
+	ops := map[uint32]ontIdOp{
+		1: {"regIDWithPublicKey", reg},
+		3: {"addContext", update},
+		5: {"revokeID", revoke},
+	}
+
+	ldg := &mockLedgerStore{}
+	for h := uint32(0); h <= 6; h++ {
+		ldg.headers = append(ldg.headers, &types.Header{Height: h, Timestamp: 1600000000 + h*10})
+	}
+	for h := uint32(0); h <= 6; h++ {
+		overlay := testsuite.NewOverlayDB()
+		for i := uint32(1); i <= h; i++ {
+			op, ok := ops[i]
+			if !ok {
+				continue
+			}
+			cache := storage.NewCacheDB(overlay)
+			sc := &smartcontract.SmartContract{
+				Config: &smartcontract.Config{
+					Time:   ldg.headers[i].Timestamp,
+					Height: i,
+					Tx:     &types.Transaction{SignedAddr: []comm.Address{acc.Address}},
+				},
+				CacheDB: cache,
+				Gas:     math.MaxUint64,
+			}
+			srvc, err := sc.NewNativeService()
+			assert.Nil(t, err)
+			_, err = srvc.NativeCall(utils.OntIDContractAddress, op.method, op.input)
+			assert.Nil(t, err)
+			cache.Commit()
+		}
+		if h == 2 {
+			overlay = nil
+		}
+		ldg.states = append(ldg.states, overlay)
+	}
+	return ldg
+}
+
+func TestDIDResolver(t *testing.T) {
+	// the new ONT ID apis are enabled from genesis
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	ldg := newDIDTestLedger(t, id, account.NewAccount(""))
+	ledger.DefLedger = &ledger.Ledger{LedgerStore: ldg}
+	defer func() { ledger.DefLedger = nil }()
+
+	rt := &restServer{router: NewRouter()}
+	rt.initDIDResolver()
+	resolve := func(query string) (int, *common.DIDResolution) {
+		w := httptest.NewRecorder()
+		rt.router.ServeHTTP(w, httptest.NewRequest("GET", DID_RESOLVER_PATH+query, nil))
+		res := &common.DIDResolution{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), res))
+		return w.Code, res
+	}
+	timeOf := func(height uint32) string {
+		return time.Unix(int64(ldg.headers[height].Timestamp), 0).UTC().Format(time.RFC3339)
+	}
+
+	status, res := resolve(id + "?versionId=4")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, common.DID_LD_JSON_CONTENTTYPE, res.ResolutionMetadata.ContentType)
+	assert.Equal(t, common.DIDDocumentMetadata{
+		Created:            timeOf(1),
+		Updated:            timeOf(3),
+		VersionId:          "4",
+		CreatedBlockHeight: 1,
+		UpdatedBlockHeight: 3,
+	}, res.DocumentMetadata)
+	doc := struct {
+		Id string `json:"id"`
+	}{}
+	assert.Nil(t, json.Unmarshal(res.DidDocument, &doc))
+	assert.Equal(t, id, doc.Id)
+
+	// the latest block not later than versionTime
+	versionTime := time.Unix(int64(ldg.headers[1].Timestamp+5), 0).UTC().Format(time.RFC3339)
+	status, res = resolve(id + "?versionTime=" + versionTime)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, common.DIDDocumentMetadata{
+		Created:            timeOf(1),
+		VersionId:          "1",
+		CreatedBlockHeight: 1,
+	}, res.DocumentMetadata)
+
+	// the latest state is revoked
+	status, res = resolve(id)
+	assert.Equal(t, http.StatusGone, status)
+	assert.True(t, res.DocumentMetadata.Deactivated)
+	assert.Equal(t, "6", res.DocumentMetadata.VersionId)
+
+	status, res = resolve(id + "?versionId=0")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, common.DID_NOT_FOUND, res.ResolutionMetadata.Error)
+
+	// the state is not archived
+	status, res = resolve(id + "?versionId=2")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, common.DID_INTERNAL_ERROR, res.ResolutionMetadata.Error)
+
+	for _, query := range []string{"did:ont:abcd1234", id + "?versionId=abc", id + "?versionId=7",
+		id + "?versionTime=yesterday", id + "?versionTime=2000-01-01T00:00:00Z"} {
+		status, res = resolve(query)
+		assert.Equal(t, http.StatusBadRequest, status, query)
+		assert.Equal(t, common.DID_INVALID, res.ResolutionMetadata.Error, query)
+	}
+}
@@ -199,6 +199,7 @@ func (self *WsServer) registryMethod() {
 		"getgasprice":               {handler: rest.GetGasPrice},
 		"getunboundong":             {handler: rest.GetUnboundOng},
 		"getgrantong":               {handler: rest.GetGrantOng},
+		"getcredentialstatus":       {handler: rest.GetCredentialStatus},
 		"getmempooltxcount":         {handler: rest.GetMemPoolTxCount},
 		"getmempooltxstate":         {handler: rest.GetMemPoolTxState},
 		"getmempooltxhashlist":      {handler: rest.GetMemPoolTxHashList},
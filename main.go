@@ -73,6 +73,9 @@ func setupAPP() *cli.App {
 		cmd.ContractCommand,
 		cmd.ImportCommand,
 		cmd.ExportCommand,
+		cmd.ReindexEventCommand,
+		cmd.SnapshotCommand,
+		cmd.DBCommand,
 		cmd.TxCommond,
 		cmd.SigTxCommand,
 		cmd.MultiSigAddrCommand,
@@ -87,7 +90,9 @@ func setupAPP() *cli.App {
 		utils.LogDirFlag,
 		utils.DisableLogFileFlag,
 		utils.DisableEventLogFlag,
+		utils.EnableArchiveFlag,
 		utils.DataDirFlag,
+		utils.DBBackendFlag,
 		utils.ETHTxGasLimitFlag,
 		utils.WasmVerifyMethodFlag,
 		//account setting
@@ -101,6 +106,9 @@ func setupAPP() *cli.App {
 		utils.GasPriceFlag,
 		utils.GasLimitFlag,
 		utils.TxpoolPreExecDisableFlag,
+		utils.TxPoolCapacityFlag,
+		utils.TxPoolPayerSlotsFlag,
+		utils.TxPoolPriceBumpFlag,
 		utils.DisableSyncVerifyTxFlag,
 		utils.DisableBroadcastNetTxFlag,
 		//p2p setting
@@ -112,6 +120,7 @@ func setupAPP() *cli.App {
 		utils.MaxConnInBoundFlag,
 		utils.MaxConnOutBoundFlag,
 		utils.MaxConnInBoundForSingleIPFlag,
+		utils.FastSyncFlag,
 		//test mode setting
 		utils.EnableTestModeFlag,
 		utils.TestModeGenBlockTimeFlag,
@@ -119,6 +128,13 @@ func setupAPP() *cli.App {
 		utils.RPCDisabledFlag,
 		utils.RPCPortFlag,
 		utils.ETHRPCPortFlag,
+		utils.ETHRPCDebugFlag,
+		utils.ETHRPCWsOriginsFlag,
+		utils.RPCMaxBatchSizeFlag,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateBurstFlag,
+		utils.RPCMethodRateLimitFlag,
+		utils.RPCAdminTokenFlag,
 		utils.RPCLocalEnableFlag,
 		utils.RPCLocalProtFlag,
 		//rest setting
@@ -129,6 +145,7 @@ func setupAPP() *cli.App {
 		utils.GraphQLEnableFlag,
 		utils.GraphQLPortFlag,
 		utils.GraphQLMaxConnsFlag,
+		utils.GraphQLOriginsFlag,
 		//ws setting
 		utils.WsEnabledFlag,
 		utils.WsPortFlag,
@@ -318,6 +335,7 @@ func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer, acct *account.A
 	}
 	txpoolSvr.Net = p2p.GetNetwork()
 	bactor.SetNetServer(p2p.GetNetwork())
+	bactor.SetSyncProgressReporter(p2p)
 	p2p.WaitForPeersStart()
 	log.Infof("P2P init success")
 	return p2p, p2p.GetNetwork(), nil
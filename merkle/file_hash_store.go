@@ -65,6 +65,11 @@ func NewFileHashStore(name string, tree_size uint32) (HashStore, error) {
 	return store, nil
 }
 
+// StoredHashNum returns the number of hashes persisted in hash store for a tree of tree_size
+func StoredHashNum(tree_size uint32) int64 {
+	return getStoredHashNum(tree_size)
+}
+
 func getStoredHashNum(tree_size uint32) int64 {
 	subtreesize := getSubTreeSize(tree_size)
 	sum := int64(0)
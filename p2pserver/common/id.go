@@ -32,6 +32,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/signature"
 	"github.com/ontio/ontology/core/types"
 )
 
@@ -67,10 +68,21 @@ func (self *PeerId) ToHexString() string {
 	return self.val.ToHexString()
 }
 
+//PeerIdFromHexString parse the peer id from the output of ToHexString
+func PeerIdFromHexString(s string) (PeerId, error) {
+	val, err := common.AddressFromHexString(s)
+	if err != nil {
+		return PeerId{}, err
+	}
+	return PeerId{val: val}, nil
+}
+
 type PeerKeyId struct {
 	PublicKey keypair.PublicKey
 
 	Id PeerId
+
+	signer signature.Signer // nil if the private key is not owned, eg. the key id of remote peer
 }
 
 func (self PeerId) GenRandPeerId(prefix uint) PeerId {
@@ -179,7 +191,26 @@ func RandPeerKeyId() *PeerKeyId {
 	return &PeerKeyId{
 		PublicKey: acc.PublicKey,
 		Id:        kid,
+		signer:    acc,
+	}
+}
+
+//CanSign return whether the private key of the key id is owned
+func (this *PeerKeyId) CanSign() bool {
+	return this.signer != nil
+}
+
+//Sign data with the private key of the key id, to prove the possession of the key id
+func (this *PeerKeyId) Sign(data []byte) ([]byte, error) {
+	if this.signer == nil {
+		return nil, errors.New("private key of peer key id is not owned")
 	}
+	return signature.Sign(this.signer, data)
+}
+
+//Verify the signature of data is signed by the key id
+func (this *PeerKeyId) Verify(data, sig []byte) error {
+	return signature.Verify(this.PublicKey, data, sig)
 }
 
 func validatePublicKey(pubKey keypair.PublicKey) bool {
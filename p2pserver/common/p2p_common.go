@@ -53,8 +53,15 @@ const (
 
 //msg type const
 const (
-	MAX_ADDR_NODE_CNT = 64 //the maximum peer address from msg
-	MAX_INV_BLK_CNT   = 64 //the maximum blk hash cnt of inv msg
+	MAX_ADDR_NODE_CNT = 64  //the maximum peer address from msg
+	MAX_INV_BLK_CNT   = 64  //the maximum blk hash cnt of inv msg
+	MAX_BLK_RANGE_CNT = 128 //the maximum blk cnt of block range req msg
+)
+
+//compact block const
+const (
+	MAX_PENDING_CMPCT_BLK_CNT = 16 //the maximum compact blocks waiting for missing txs
+	CMPCT_BLK_PENDING_TIMEOUT = 30 //timeout in sec of compact block waiting for missing txs
 )
 
 //info update const
@@ -76,6 +83,35 @@ const (
 
 //cap flag
 const HTTP_INFO_FLAG = 0 //peer`s http info bit in cap field
+const ENCRYPT_FLAG = 1   //peer`s encrypted transport bit in cap field
+const CMPCT_BLK_FLAG = 2 //peer`s compact block relay bit in cap field
+
+//message rate limit const, messages per second and burst size of a single peer
+const (
+	GET_DATA_RATE_LIMIT      = 400
+	GET_DATA_RATE_BURST      = 800
+	GET_HEADERS_RATE_LIMIT   = 10
+	GET_HEADERS_RATE_BURST   = 20
+	GET_BLK_RANGE_RATE_LIMIT = 10
+	GET_BLK_RANGE_RATE_BURST = 20
+	INV_RATE_LIMIT           = 100
+	INV_RATE_BURST           = 200
+	TX_RATE_LIMIT            = 1000
+	TX_RATE_BURST            = 2000
+	GET_BLK_TXN_RATE_LIMIT   = 100
+	GET_BLK_TXN_RATE_BURST   = 200
+)
+
+//peer reputation const
+const (
+	REPUTATION_MAX_SCORE      = 100     //upper bound of the reputation score
+	REPUTATION_BAN_SCORE      = -100    //peer is disconnected and banned when the score drops to it
+	REPUTATION_SYNC_MIN_SCORE = -50     //peer with lower score is not selected for block sync
+	REPUTATION_RATE_LIMITED   = -2      //penalty of a message exceeding the rate limit
+	REPUTATION_INVALID_DATA   = -20     //penalty of an invalid block or header response
+	REPUTATION_VALID_BLOCK    = 1       //reward of a block response saved to ledger
+	PEER_BAN_DURATION         = 30 * 60 //ban duration in sec of the peer with exhausted reputation
+)
 
 //recent contact const
 const (
@@ -83,6 +119,11 @@ const (
 	RECENT_FILE_NAME = "peers.recent"
 )
 
+//peer admin const
+const (
+	ADMIN_FILE_NAME = "peers.admin" //file to persist the peers managed by admin rpc
+)
+
 //PeerAddr represent peer`s net information
 type PeerAddr struct {
 	Time     int64    //latest timestamp
@@ -110,10 +151,16 @@ const (
 	TX_TYPE            = "tx"          //transaction
 	CONSENSUS_TYPE     = "consensus"   //consensus payload
 	GET_BLOCKS_TYPE    = "getblocks"   //req blks from peer
+	GET_BLK_RANGE_TYPE = "getblkrange" //req blks by height range from peer
 	NOT_FOUND_TYPE     = "notfound"    //peer can`t find blk according to the hash
 	FINDNODE_TYPE      = "findnode"    // find node using dht
 	FINDNODE_RESP_TYPE = "findnodeack" // find node using dht
 	UPDATE_KADID_TYPE  = "updatekadid" //update node kadid
+	SECURE_HELLO_TYPE  = "securehello" //ephemeral key of encrypted transport
+	SECURE_AUTH_TYPE   = "secureauth"  //proof of kad key possession of encrypted transport
+	CMPCT_BLOCK_TYPE   = "cmpctblock"  //blk header with short tx ids
+	GET_BLK_TXN_TYPE   = "getblocktxn" //req missing txs of compact blk
+	BLK_TXN_TYPE       = "blocktxn"    //missing txs of compact blk
 
 	GET_SUBNET_MEMBERS_TYPE = "getmembers" // request subnet members
 	SUBNET_MEMBERS_TYPE     = "members"    // response subnet members
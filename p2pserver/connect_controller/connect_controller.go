@@ -21,9 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ontio/ontology/p2pserver/common"
 	"github.com/ontio/ontology/p2pserver/handshake"
@@ -53,6 +55,8 @@ type ConnectController struct {
 	inboundListenAddress *strset.Set    // in bound listen address
 	connecting           *strset.Set
 	peers                map[common.PeerId]*connectedPeer // all connected peers
+	bannedIps            map[string]int64                 // Map ip => ban expire time in unix seconds
+	bannedIds            map[common.PeerId]int64          // Map peer id => ban expire time in unix seconds
 
 	ownListenAddr string
 	nextConnectId uint64
@@ -70,6 +74,8 @@ func NewConnectController(peerInfo *peer.PeerInfo, keyid *common.PeerKeyId,
 		inboundListenAddress: strset.New(),
 		connecting:           strset.New(),
 		peers:                make(map[common.PeerId]*connectedPeer),
+		bannedIps:            make(map[string]int64),
+		bannedIds:            make(map[common.PeerId]int64),
 		logger:               logger,
 	}
 
@@ -182,7 +188,7 @@ func (self *ConnectController) AcceptConnect(conn net.Conn) (*peer.PeerInfo, net
 		return nil, nil, err
 	}
 
-	peerInfo, err := handshake.HandshakeServer(self.peerInfo, self.selfId, conn)
+	peerInfo, conn, err := handshake.HandshakeServer(self.peerInfo, self.selfId, self.Encrypt, conn)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -216,11 +222,12 @@ func (self *ConnectController) Connect(addr string) (*peer.PeerInfo, net.Conn, e
 		return nil, nil, err
 	}
 
-	peerInfo, err := handshake.HandshakeClient(self.peerInfo, self.selfId, conn)
+	peerInfo, link, err := handshake.HandshakeClient(self.peerInfo, self.selfId, self.Encrypt, conn)
 	if err != nil {
 		_ = conn.Close()
 		return nil, nil, err
 	}
+	conn = link
 
 	err = self.afterHandshakeCheck(peerInfo, conn.RemoteAddr().String())
 	if err != nil {
@@ -234,10 +241,90 @@ func (self *ConnectController) Connect(addr string) (*peer.PeerInfo, net.Conn, e
 	return peerInfo, wrapped, nil
 }
 
+//BanPeer reject the connections from the ip and peer id of a misbehaving peer for the duration
+func (self *ConnectController) BanPeer(kid common.PeerId, addr string, duration time.Duration) {
+	expire := time.Now().Add(duration).Unix()
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if !kid.IsEmpty() {
+		self.bannedIds[kid] = expire
+	}
+	if ip, err := common.ParseIPAddr(addr); err == nil && ip != "" {
+		self.bannedIps[ip] = expire
+	}
+	self.logger.Infof("ban peer %s, address: %s", kid.ToHexString(), addr)
+}
+
+//BanInfo describe a banned ip or peer id
+type BanInfo struct {
+	Ip     string `json:"ip,omitempty"`
+	Id     string `json:"id,omitempty"`
+	Expire int64  `json:"expire"` // unix time in seconds
+}
+
+//ListBans return the unexpired bans
+func (self *ConnectController) ListBans() []BanInfo {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.clearExpiredBansLocked(time.Now().Unix())
+	bans := make([]BanInfo, 0, len(self.bannedIps)+len(self.bannedIds))
+	for ip, expire := range self.bannedIps {
+		bans = append(bans, BanInfo{Ip: ip, Expire: expire})
+	}
+	for kid, expire := range self.bannedIds {
+		bans = append(bans, BanInfo{Id: kid.ToHexString(), Expire: expire})
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Expire < bans[j].Expire
+	})
+	return bans
+}
+
+//BannedCount return the count of banned ips
+func (self *ConnectController) BannedCount() int {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.clearExpiredBansLocked(time.Now().Unix())
+	return len(self.bannedIps)
+}
+
+func (self *ConnectController) clearExpiredBansLocked(now int64) {
+	for ip, expire := range self.bannedIps {
+		if expire <= now {
+			delete(self.bannedIps, ip)
+		}
+	}
+	for kid, expire := range self.bannedIds {
+		if expire <= now {
+			delete(self.bannedIds, kid)
+		}
+	}
+}
+
+func (self *ConnectController) isBanned(kid *common.PeerId, addr string) bool {
+	now := time.Now().Unix()
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if kid != nil {
+		if expire, ok := self.bannedIds[*kid]; ok && expire > now {
+			return true
+		}
+	}
+	ip, err := common.ParseIPAddr(addr)
+	if err != nil {
+		return false
+	}
+	expire, ok := self.bannedIps[ip]
+	return ok && expire > now
+}
+
 func (self *ConnectController) afterHandshakeCheck(remotePeer *peer.PeerInfo, remoteAddr string) error {
 	if err := self.isHandWithSelf(remotePeer, remoteAddr); err != nil {
 		return err
 	}
+	if self.isBanned(&remotePeer.Id, remoteAddr) {
+		return fmt.Errorf("peer %s is banned", remotePeer.Id.ToHexString())
+	}
 
 	return self.checkPeerIdAndIP(remotePeer, remoteAddr)
 }
@@ -252,6 +339,10 @@ func (self *ConnectController) beforeHandshakeCheck(addr string, index int) erro
 		return fmt.Errorf("peer %s already in connection records", addr)
 	}
 
+	if self.isBanned(nil, addr) {
+		return fmt.Errorf("address %s is banned", addr)
+	}
+
 	if self.OwnAddress() == addr {
 		return fmt.Errorf("connecting with self address %s", addr)
 	}
@@ -108,7 +108,7 @@ func TestConnectController_CanDetectSelfAddress(t *testing.T) {
 
 		c, s := trans.Pipe()
 		go func() {
-			_, _ = handshake.HandshakeClient(server.peerInfo, server.Key, c)
+			_, _, _ = handshake.HandshakeClient(server.peerInfo, server.Key, server.Encrypt, c)
 		}()
 
 		_, _, err := server.AcceptConnect(s)
@@ -132,7 +132,7 @@ func TestConnectController_AcceptConnect_MaxInBound(t *testing.T) {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			_, err := handshake.HandshakeClient(client.peerInfo, client.Key, conn1)
+			_, _, err := handshake.HandshakeClient(client.peerInfo, client.Key, client.Encrypt, conn1)
 			if i < int(maxInboud) {
 				assert.Nil(t, err)
 			} else {
@@ -194,7 +194,7 @@ func TestConnCtrlOption_MaxInBoundPerIp(t *testing.T) {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			_, err := handshake.HandshakeClient(client.peerInfo, client.Key, conn1)
+			_, _, err := handshake.HandshakeClient(client.peerInfo, client.Key, client.Encrypt, conn1)
 			if i < int(maxInBoundPerIp) {
 				assert.Nil(t, err)
 			} else {
@@ -251,3 +251,38 @@ func TestCheckReserveWithDomain(t *testing.T) {
 	rsvPeers = NewStaticReserveFilter([]string{"192.168.1.2", "www.baidu.com", "192.168.1.1"})
 	a.Equal(rsvPeers.ReservedPeers[len(rsvPeers.ReservedPeers)-1], "www.baidu.com", "fail")
 }
+
+func TestConnectController_BanPeer(t *testing.T) {
+	trans := NewTransport(t)
+	server := NewNode(NewConnCtrlOption())
+	client := NewNode(NewConnCtrlOption())
+
+	// banned by peer id
+	server.BanPeer(client.Info.Id, "10.0.0.1:20338", time.Minute)
+	c, s := trans.Pipe()
+	go func() {
+		_, _, _ = handshake.HandshakeClient(client.peerInfo, client.Key, client.Encrypt, c)
+	}()
+	_, _, err := server.AcceptConnect(s)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "banned")
+	assert.Equal(t, server.BannedCount(), 1)
+	_ = c.Close()
+	_ = s.Close()
+
+	// banned by ip before handshake
+	server.BanPeer(common.PeerId{}, "127.0.0.1:20338", time.Minute)
+	c, s = trans.Pipe()
+	_, _, err = server.AcceptConnect(s)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "banned")
+	assert.Equal(t, server.BannedCount(), 2)
+	_ = c.Close()
+	_ = s.Close()
+
+	// expired bans are ignored
+	other := NewNode(NewConnCtrlOption())
+	other.BanPeer(client.Info.Id, "127.0.0.1:20338", -time.Second)
+	assert.False(t, other.isBanned(&client.Info.Id, "127.0.0.1:20338"))
+	assert.Equal(t, other.BannedCount(), 0)
+}
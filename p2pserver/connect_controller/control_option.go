@@ -27,6 +27,7 @@ type ConnCtrlOption struct {
 	MaxConnInBound      uint
 	MaxConnInBoundPerIP uint
 	ReservedPeers       p2p.AddressFilter // enabled if not empty
+	Encrypt             bool              // advertise the encrypted transport in handshake
 	dialer              Dialer
 }
 
@@ -36,6 +37,7 @@ func NewConnCtrlOption() ConnCtrlOption {
 		MaxConnOutBound:     config.DEFAULT_MAX_CONN_OUT_BOUND,
 		MaxConnInBoundPerIP: config.DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP,
 		ReservedPeers:       p2p.AllAddrFilter(),
+		Encrypt:             true,
 		dialer:              &noTlsDialer{},
 	}
 }
@@ -60,6 +62,11 @@ func (self ConnCtrlOption) ReservedOnly(peers []string) ConnCtrlOption {
 	return self
 }
 
+func (self ConnCtrlOption) WithEncrypt(encrypt bool) ConnCtrlOption {
+	self.Encrypt = encrypt
+	return self
+}
+
 func (self ConnCtrlOption) WithDialer(dialer Dialer) ConnCtrlOption {
 	self.dialer = dialer
 	return self
@@ -76,6 +83,8 @@ func ConnCtrlOptionFromConfig(config *config.P2PNodeConfig, reserveFilter p2p.Ad
 		MaxConnInBound:      config.MaxConnInBound,
 		MaxConnInBoundPerIP: config.MaxConnInBoundForSingleIP,
 		ReservedPeers:       reserveFilter,
+		// the links of tls mode are encrypted already
+		Encrypt: !config.IsTLS,
 
 		dialer: dialer,
 	}, nil
@@ -20,21 +20,42 @@ package connect_controller
 import (
 	"net"
 	"sort"
+	"sync"
 )
 
 type StaticReserveFilter struct {
+	lock sync.RWMutex
 	//format: host or ip
 	ReservedPeers []string
 }
 
 func NewStaticReserveFilter(peers []string) *StaticReserveFilter {
+	return &StaticReserveFilter{
+		ReservedPeers: sortReservedPeers(peers),
+	}
+}
+
+func sortReservedPeers(peers []string) []string {
 	// put domain to the end
 	sort.Slice(peers, func(i, j int) bool {
 		return net.ParseIP(peers[i]) != nil
 	})
-	return &StaticReserveFilter{
-		ReservedPeers: peers,
-	}
+	return peers
+}
+
+//SetReservedPeers replace the reserved peers at runtime
+func (self *StaticReserveFilter) SetReservedPeers(peers []string) {
+	peers = sortReservedPeers(append([]string{}, peers...))
+	self.lock.Lock()
+	self.ReservedPeers = peers
+	self.lock.Unlock()
+}
+
+//GetReservedPeers return a copy of the reserved peers
+func (self *StaticReserveFilter) GetReservedPeers() []string {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return append([]string{}, self.ReservedPeers...)
 }
 
 // remoteAddr format 192.168.1.1:61234
@@ -50,7 +71,7 @@ func (self *StaticReserveFilter) Contains(remoteIPPort string) bool {
 		return false
 	}
 	// we don't load domain in start because we consider domain's A/AAAA record may change sometimes
-	for _, curIPOrName := range self.ReservedPeers {
+	for _, curIPOrName := range self.GetReservedPeers() {
 		curIPs, err := net.LookupHost(curIPOrName)
 		if err != nil {
 			continue
@@ -31,10 +31,12 @@ import (
 
 var HANDSHAKE_DURATION = 10 * time.Second // handshake time can not exceed this duration, or will treat as attack.
 
-func HandshakeClient(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Conn) (*peer.PeerInfo, error) {
-	version := newVersion(info)
+//HandshakeClient exchange version with the server. If both sides advertise the encrypted transport, the returned
+//conn is encrypted and the peer id is proved by the kad key, otherwise the original conn is returned
+func HandshakeClient(info *peer.PeerInfo, selfId *common.PeerKeyId, encrypt bool, conn net.Conn) (*peer.PeerInfo, net.Conn, error) {
+	version := newVersion(info, encrypt && selfId.CanSign())
 	if err := conn.SetDeadline(time.Now().Add(HANDSHAKE_DURATION)); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer func() {
 		_ = conn.SetDeadline(time.Time{}) //reset back
@@ -43,62 +45,69 @@ func HandshakeClient(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Con
 	// 1. sendMsg version
 	err := sendMsg(conn, version)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 2. read version
 	msg, _, err := types.ReadMessage(conn)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	receivedVersion, ok := msg.(*types.Version)
 	if !ok {
-		return nil, fmt.Errorf("expected version message, but got message type: %s", msg.CmdType())
+		return nil, nil, fmt.Errorf("expected version message, but got message type: %s", msg.CmdType())
 	}
 
 	// 3. update kadId
 	kid := common.PseudoPeerIdFromUint64(receivedVersion.P.Nonce)
-	if useDHT(receivedVersion.P.SoftVersion, info.SoftVersion) {
+	link := conn
+	if useSecure(version, receivedVersion) {
+		link, kid, err = secureHandshake(conn, selfId, version, receivedVersion, true)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if useDHT(receivedVersion.P.SoftVersion, info.SoftVersion) {
 		err = sendMsg(conn, &types.UpdatePeerKeyId{KadKeyId: selfId})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		// 4. read kadkeyid
 		msg, _, err = types.ReadMessage(conn)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		kadKeyId, ok := msg.(*types.UpdatePeerKeyId)
 		if !ok {
-			return nil, fmt.Errorf("handshake failed, expect kad id message, got %s", msg.CmdType())
+			return nil, nil, fmt.Errorf("handshake failed, expect kad id message, got %s", msg.CmdType())
 		}
 
 		kid = kadKeyId.KadKeyId.Id
 	}
 
 	// 5. sendMsg ack
-	err = sendMsg(conn, &types.VerACK{})
+	err = sendMsg(link, &types.VerACK{})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	msg, _, err = types.ReadMessage(conn)
+	msg, _, err = types.ReadMessage(link)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 6. receive verack
 	if _, ok := msg.(*types.VerACK); !ok {
-		return nil, fmt.Errorf("handshake failed, expect verack message, got %s", msg.CmdType())
+		return nil, nil, fmt.Errorf("handshake failed, expect verack message, got %s", msg.CmdType())
 	}
 
-	return createPeerInfo(receivedVersion, kid, conn.RemoteAddr().String()), nil
+	return createPeerInfo(receivedVersion, kid, conn.RemoteAddr().String()), link, nil
 }
 
-func HandshakeServer(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Conn) (*peer.PeerInfo, error) {
-	ver := newVersion(info)
+//HandshakeServer exchange version with the client, the returned conn is encrypted if negotiated
+func HandshakeServer(info *peer.PeerInfo, selfId *common.PeerKeyId, encrypt bool, conn net.Conn) (*peer.PeerInfo, net.Conn, error) {
+	ver := newVersion(info, encrypt && selfId.CanSign())
 	if err := conn.SetDeadline(time.Now().Add(HANDSHAKE_DURATION)); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer func() {
 		_ = conn.SetDeadline(time.Time{}) //reset back
@@ -107,54 +116,60 @@ func HandshakeServer(info *peer.PeerInfo, selfId *common.PeerKeyId, conn net.Con
 	// 1. read version
 	msg, _, err := types.ReadMessage(conn)
 	if err != nil {
-		return nil, fmt.Errorf("[HandshakeServer] ReadMessage failed, error: %s", err)
+		return nil, nil, fmt.Errorf("[HandshakeServer] ReadMessage failed, error: %s", err)
 	}
 	if msg.CmdType() != common.VERSION_TYPE {
-		return nil, fmt.Errorf("[HandshakeServer] expected version message")
+		return nil, nil, fmt.Errorf("[HandshakeServer] expected version message")
 	}
 	version := msg.(*types.Version)
 
 	// 2. sendMsg version
 	err = sendMsg(conn, ver)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 3. read update kadkey id
 	kid := common.PseudoPeerIdFromUint64(version.P.Nonce)
-	if useDHT(version.P.SoftVersion, info.SoftVersion) {
+	link := conn
+	if useSecure(ver, version) {
+		link, kid, err = secureHandshake(conn, selfId, version, ver, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("[HandshakeServer] secure handshake failed, error: %s", err)
+		}
+	} else if useDHT(version.P.SoftVersion, info.SoftVersion) {
 		msg, _, err := types.ReadMessage(conn)
 		if err != nil {
-			return nil, fmt.Errorf("[HandshakeServer] ReadMessage failed, error: %s", err)
+			return nil, nil, fmt.Errorf("[HandshakeServer] ReadMessage failed, error: %s", err)
 		}
 		kadkeyId, ok := msg.(*types.UpdatePeerKeyId)
 		if !ok {
-			return nil, fmt.Errorf("[HandshakeServer] expected update kadkeyid message")
+			return nil, nil, fmt.Errorf("[HandshakeServer] expected update kadkeyid message")
 		}
 		kid = kadkeyId.KadKeyId.Id
 		// 4. sendMsg update kadkey id
 		err = sendMsg(conn, &types.UpdatePeerKeyId{KadKeyId: selfId})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	// 5. read version ack
-	msg, _, err = types.ReadMessage(conn)
+	msg, _, err = types.ReadMessage(link)
 	if err != nil {
-		return nil, fmt.Errorf("[HandshakeServer] ReadMessage failed, error: %s", err)
+		return nil, nil, fmt.Errorf("[HandshakeServer] ReadMessage failed, error: %s", err)
 	}
 	if msg.CmdType() != common.VERACK_TYPE {
-		return nil, fmt.Errorf("[HandshakeServer] expected version ack message")
+		return nil, nil, fmt.Errorf("[HandshakeServer] expected version ack message")
 	}
 
 	// 6. sendMsg ack
-	err = sendMsg(conn, &types.VerACK{})
+	err = sendMsg(link, &types.VerACK{})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return createPeerInfo(version, kid, conn.RemoteAddr().String()), nil
+	return createPeerInfo(version, kid, conn.RemoteAddr().String()), link, nil
 }
 
 func sendMsg(conn net.Conn, msg types.Message) error {
@@ -169,11 +184,13 @@ func sendMsg(conn net.Conn, msg types.Message) error {
 }
 
 func createPeerInfo(version *types.Version, kid common.PeerId, addr string) *peer.PeerInfo {
-	return peer.NewPeerInfo(kid, version.P.Version, version.P.Services, version.P.Relay != 0, version.P.HttpInfoPort,
+	info := peer.NewPeerInfo(kid, version.P.Version, version.P.Services, version.P.Relay != 0, version.P.HttpInfoPort,
 		version.P.SyncPort, version.P.StartHeight, version.P.SoftVersion, addr)
+	info.CompactBlock = version.P.Cap[common.CMPCT_BLK_FLAG] == 0x01
+	return info
 }
 
-func newVersion(peerInfo *peer.PeerInfo) *types.Version {
+func newVersion(peerInfo *peer.PeerInfo, encrypt bool) *types.Version {
 	var version types.Version
 	version.P = types.VersionPayload{
 		Version:      peerInfo.Version,
@@ -197,6 +214,12 @@ func newVersion(peerInfo *peer.PeerInfo) *types.Version {
 	} else {
 		version.P.Cap[common.HTTP_INFO_FLAG] = 0x00
 	}
+	if encrypt {
+		version.P.Cap[common.ENCRYPT_FLAG] = 0x01
+	}
+	if peerInfo.CompactBlock {
+		version.P.Cap[common.CMPCT_BLK_FLAG] = 0x01
+	}
 
 	return &version
 }
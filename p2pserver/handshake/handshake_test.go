@@ -18,6 +18,7 @@
 package handshake
 
 import (
+	"io"
 	"math/rand"
 	"net"
 	"sync"
@@ -68,6 +69,7 @@ func TestHandshakeNormal(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		client.Info.SoftVersion = versions[rand.Intn(len(versions))]
 		server.Info.SoftVersion = versions[rand.Intn(len(versions))]
+		clientEncrypt, serverEncrypt := rand.Intn(2) == 0, rand.Intn(2) == 0
 
 		wg := sync.WaitGroup{}
 		wg.Add(2)
@@ -76,13 +78,13 @@ func TestHandshakeNormal(t *testing.T) {
 			err  error
 		}, 2)
 		go func() {
-			info, err := HandshakeClient(client.Info, client.Id, client.Conn)
+			info, _, err := HandshakeClient(client.Info, client.Id, clientEncrypt, client.Conn)
 			result[0].err = err
 			result[0].info = [2]*peer.PeerInfo{info, server.Info}
 			wg.Done()
 		}()
 		go func() {
-			info, err := HandshakeServer(server.Info, server.Id, server.Conn)
+			info, _, err := HandshakeServer(server.Info, server.Id, serverEncrypt, server.Conn)
 			result[1].err = err
 			result[1].info = [2]*peer.PeerInfo{info, client.Info}
 			wg.Done()
@@ -99,7 +101,7 @@ func TestHandshakeNormal(t *testing.T) {
 func TestHandshakeTimeout(t *testing.T) {
 	client, _ := NewPair()
 
-	_, err := HandshakeClient(client.Info, client.Id, client.Conn)
+	_, _, err := HandshakeClient(client.Info, client.Id, true, client.Conn)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "deadline exceeded")
 }
@@ -111,11 +113,102 @@ func TestHandshakeWrongMsg(t *testing.T) {
 		assert.Nil(t, err)
 	}()
 
-	_, err := HandshakeServer(server.Info, server.Id, server.Conn)
+	_, _, err := HandshakeServer(server.Info, server.Id, true, server.Conn)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "expected version message")
 }
 
+func TestHandshakeSecure(t *testing.T) {
+	client, server := NewPair()
+	client.Info.SoftVersion = "v1.20"
+	server.Info.SoftVersion = "v1.20"
+
+	var clientInfo, serverInfo *peer.PeerInfo
+	var clientLink, serverLink net.Conn
+	var clientErr, serverErr error
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		clientInfo, clientLink, clientErr = HandshakeClient(client.Info, client.Id, true, client.Conn)
+	}()
+	go func() {
+		defer wg.Done()
+		serverInfo, serverLink, serverErr = HandshakeServer(server.Info, server.Id, true, server.Conn)
+	}()
+	wg.Wait()
+
+	assert.Nil(t, clientErr)
+	assert.Nil(t, serverErr)
+	assert.Equal(t, server.Id.Id, clientInfo.Id)
+	assert.Equal(t, client.Id.Id, serverInfo.Id)
+	assert.IsType(t, &secureConn{}, clientLink)
+	assert.IsType(t, &secureConn{}, serverLink)
+
+	payload := make([]byte, 3*SECURE_MAX_FRAME_LEN+100)
+	rand.Read(payload)
+	go func() {
+		_, err := clientLink.Write(payload)
+		assert.Nil(t, err)
+	}()
+	received := make([]byte, len(payload))
+	_, err := io.ReadFull(serverLink, received)
+	assert.Nil(t, err)
+	assert.Equal(t, payload, received)
+}
+
+func TestHandshakeSecureTampered(t *testing.T) {
+	client, server := NewPair()
+	clientVer, serverVer := newVersion(client.Info, true), newVersion(server.Info, true)
+	tampered := *clientVer
+	tampered.P.StartHeight += 1
+
+	var clientErr, serverErr error
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _, clientErr = secureHandshake(client.Conn, client.Id, clientVer, serverVer, true)
+		_ = client.Conn.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		// the server received a version modified by a man in the middle
+		_, _, serverErr = secureHandshake(server.Conn, server.Id, &tampered, serverVer, false)
+		_ = server.Conn.Close()
+	}()
+	wg.Wait()
+
+	assert.NotNil(t, clientErr)
+	assert.NotNil(t, serverErr)
+}
+
+func TestHandshakeFallbackPlain(t *testing.T) {
+	client, server := NewPair()
+	client.Info.SoftVersion = "v1.20"
+	server.Info.SoftVersion = "v1.20"
+
+	var clientLink, serverLink net.Conn
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		var err error
+		_, clientLink, err = HandshakeClient(client.Info, client.Id, true, client.Conn)
+		assert.Nil(t, err)
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		_, serverLink, err = HandshakeServer(server.Info, server.Id, false, server.Conn)
+		assert.Nil(t, err)
+	}()
+	wg.Wait()
+
+	assert.Equal(t, client.Conn, clientLink)
+	assert.Equal(t, server.Conn, serverLink)
+}
+
 func TestVersion(t *testing.T) {
 	assert.True(t, supportDHT(common.MIN_VERSION_FOR_DHT))
 	assert.True(t, supportDHT("1.9.1"))
@@ -132,3 +225,12 @@ func TestVersion(t *testing.T) {
 	assert.False(t, supportDHT("1.8.0-beta-9-geeaeewwf"))
 	assert.False(t, supportDHT("1.8.0"))
 }
+
+func TestCompactBlockCap(t *testing.T) {
+	kid := common.PseudoPeerIdFromUint64(1)
+	info := createPeerInfo(newVersion(&peer.PeerInfo{CompactBlock: true}, false), kid, "")
+	assert.True(t, info.CompactBlock)
+
+	info = createPeerInfo(newVersion(&peer.PeerInfo{}, false), kid, "")
+	assert.False(t, info.CompactBlock)
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handshake
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	common2 "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/message/types"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	SECURE_PROTOCOL_NAME = "ontology-p2p-secure-v1" //domain separation of the key derivation and signatures
+	SECURE_MAX_FRAME_LEN = 64 * 1024                //max plaintext length of an encrypted frame
+)
+
+//useSecure return whether both sides advertise the encrypted transport. The kad key id is required to prove
+//identity, so it is only used between DHT nodes
+func useSecure(local, remote *types.Version) bool {
+	return local.P.Cap[common.ENCRYPT_FLAG] == 0x01 && remote.P.Cap[common.ENCRYPT_FLAG] == 0x01 &&
+		useDHT(local.P.SoftVersion, remote.P.SoftVersion)
+}
+
+//secureHandshake run after the version exchange. Both sides exchange ephemeral x25519 keys in cleartext and
+//derive the session keys with the transcript of versions and ephemeral keys, then prove the possession of kad
+//key by signing the transcript over the encrypted transport. It returns the encrypted conn and the proved peer id
+func secureHandshake(conn net.Conn, selfId *common.PeerKeyId, clientVer, serverVer *types.Version,
+	isClient bool) (net.Conn, common.PeerId, error) {
+	var priv, pub [types.SECURE_EPHEMERAL_KEY_LEN]byte
+	if _, err := rand.Read(priv[:]); err != nil {
+		return nil, common.PeerId{}, err
+	}
+	curve25519.ScalarBaseMult(&pub, &priv)
+	local := &types.SecureHello{EphemeralKey: pub}
+
+	if isClient {
+		if err := sendMsg(conn, local); err != nil {
+			return nil, common.PeerId{}, err
+		}
+	}
+	msg, _, err := types.ReadMessage(conn)
+	if err != nil {
+		return nil, common.PeerId{}, err
+	}
+	remote, ok := msg.(*types.SecureHello)
+	if !ok {
+		return nil, common.PeerId{}, fmt.Errorf("handshake failed, expect secure hello message, got %s", msg.CmdType())
+	}
+	if !isClient {
+		if err := sendMsg(conn, local); err != nil {
+			return nil, common.PeerId{}, err
+		}
+	}
+
+	var shared, zero [types.SECURE_EPHEMERAL_KEY_LEN]byte
+	curve25519.ScalarMult(&shared, &priv, &remote.EphemeralKey)
+	if shared == zero {
+		return nil, common.PeerId{}, errors.New("handshake failed, invalid ephemeral key")
+	}
+
+	clientHello, serverHello := local, remote
+	localRole, remoteRole := "client", "server"
+	if !isClient {
+		clientHello, serverHello = remote, local
+		localRole, remoteRole = "server", "client"
+	}
+	transcript := secureTranscript(clientVer, serverVer, clientHello, serverHello)
+	clientKey, serverKey, err := deriveSessionKeys(shared[:], transcript)
+	if err != nil {
+		return nil, common.PeerId{}, err
+	}
+	var secured *secureConn
+	if isClient {
+		secured, err = newSecureConn(conn, clientKey, serverKey)
+	} else {
+		secured, err = newSecureConn(conn, serverKey, clientKey)
+	}
+	if err != nil {
+		return nil, common.PeerId{}, err
+	}
+
+	sig, err := selfId.Sign(authData(transcript, localRole))
+	if err != nil {
+		return nil, common.PeerId{}, err
+	}
+	localAuth := &types.SecureAuth{KadKeyId: selfId, Signature: sig}
+	if isClient {
+		if err := sendMsg(secured, localAuth); err != nil {
+			return nil, common.PeerId{}, err
+		}
+	}
+	msg, _, err = types.ReadMessage(secured)
+	if err != nil {
+		return nil, common.PeerId{}, err
+	}
+	remoteAuth, ok := msg.(*types.SecureAuth)
+	if !ok {
+		return nil, common.PeerId{}, fmt.Errorf("handshake failed, expect secure auth message, got %s", msg.CmdType())
+	}
+	if err := remoteAuth.KadKeyId.Verify(authData(transcript, remoteRole), remoteAuth.Signature); err != nil {
+		return nil, common.PeerId{}, fmt.Errorf("handshake failed, invalid kad key proof: %s", err)
+	}
+	if !isClient {
+		if err := sendMsg(secured, localAuth); err != nil {
+			return nil, common.PeerId{}, err
+		}
+	}
+
+	return secured, remoteAuth.KadKeyId.Id, nil
+}
+
+//secureTranscript bind the session to the version messages and ephemeral keys of both sides, so any tamper of
+//them by a man in the middle makes the signature verification failed
+func secureTranscript(clientVer, serverVer *types.Version, clientHello, serverHello *types.SecureHello) []byte {
+	sink := common2.NewZeroCopySink(nil)
+	sink.WriteString(SECURE_PROTOCOL_NAME)
+	clientVer.Serialization(sink)
+	serverVer.Serialization(sink)
+	clientHello.Serialization(sink)
+	serverHello.Serialization(sink)
+	hash := sha256.Sum256(sink.Bytes())
+	return hash[:]
+}
+
+func authData(transcript []byte, role string) []byte {
+	data := make([]byte, 0, len(transcript)+len(role))
+	data = append(data, transcript...)
+	return append(data, role...)
+}
+
+func deriveSessionKeys(shared, transcript []byte) (clientKey, serverKey []byte, err error) {
+	keys := make([]byte, 2*chacha20poly1305.KeySize)
+	reader := hkdf.New(sha256.New, shared, transcript, []byte(SECURE_PROTOCOL_NAME))
+	if _, err := io.ReadFull(reader, keys); err != nil {
+		return nil, nil, err
+	}
+	return keys[:chacha20poly1305.KeySize], keys[chacha20poly1305.KeySize:], nil
+}
+
+//secureConn encrypt the stream with chacha20-poly1305 frames, every frame is prefixed with the ciphertext length
+//and uses a counter nonce of its direction
+type secureConn struct {
+	net.Conn
+
+	readLock  sync.Mutex
+	readAead  cipher.AEAD
+	readNonce uint64
+	readBuf   []byte // decrypted data not consumed yet
+
+	writeLock  sync.Mutex
+	writeAead  cipher.AEAD
+	writeNonce uint64
+}
+
+func newSecureConn(conn net.Conn, sendKey, recvKey []byte) (*secureConn, error) {
+	writeAead, err := chacha20poly1305.New(sendKey)
+	if err != nil {
+		return nil, err
+	}
+	readAead, err := chacha20poly1305.New(recvKey)
+	if err != nil {
+		return nil, err
+	}
+	return &secureConn{
+		Conn:      conn,
+		readAead:  readAead,
+		writeAead: writeAead,
+	}, nil
+}
+
+func frameNonce(counter uint64) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce, counter)
+	return nonce
+}
+
+// Write overwrite net.Conn
+func (self *secureConn) Write(b []byte) (int, error) {
+	self.writeLock.Lock()
+	defer self.writeLock.Unlock()
+	written := 0
+	for len(b) > 0 {
+		n := len(b)
+		if n > SECURE_MAX_FRAME_LEN {
+			n = SECURE_MAX_FRAME_LEN
+		}
+		frame := make([]byte, 4, 4+n+self.writeAead.Overhead())
+		binary.BigEndian.PutUint32(frame, uint32(n+self.writeAead.Overhead()))
+		frame = self.writeAead.Seal(frame, frameNonce(self.writeNonce), b[:n], nil)
+		self.writeNonce++
+		if _, err := self.Conn.Write(frame); err != nil {
+			return written, err
+		}
+		written += n
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// Read overwrite net.Conn
+func (self *secureConn) Read(b []byte) (int, error) {
+	self.readLock.Lock()
+	defer self.readLock.Unlock()
+	if len(self.readBuf) == 0 {
+		var header [4]byte
+		if _, err := io.ReadFull(self.Conn, header[:]); err != nil {
+			return 0, err
+		}
+		length := binary.BigEndian.Uint32(header[:])
+		if length < uint32(self.readAead.Overhead()) || length > uint32(SECURE_MAX_FRAME_LEN+self.readAead.Overhead()) {
+			return 0, fmt.Errorf("invalid encrypted frame length %d", length)
+		}
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(self.Conn, frame); err != nil {
+			return 0, err
+		}
+		plain, err := self.readAead.Open(frame[:0], frameNonce(self.readNonce), frame, nil)
+		if err != nil {
+			return 0, fmt.Errorf("decrypt frame failed: %s", err)
+		}
+		self.readNonce++
+		self.readBuf = plain
+	}
+	n := copy(b, self.readBuf)
+	self.readBuf = self.readBuf[n:]
+	return n, nil
+}
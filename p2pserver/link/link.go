@@ -20,6 +20,7 @@ package link
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
@@ -43,6 +44,11 @@ type Link struct {
 	time      int64                  // The latest time the node activity
 	recvChan  chan *types.MsgPayload //msgpayload channel
 	reqRecord map[string]int64       //Map RequestId to Timestamp, using for rejecting duplicate request in specific time
+
+	stats      *LinkStats              //traffic counters by message type
+	limiters   map[string]*tokenBucket //Map message type to rate limiter, only accessed by Rx
+	reputation Reputation              //behavior score of the peer
+	latency    int64                   //round trip time of heartbeat in nano second
 }
 
 func NewLink(id common.PeerId, c net.Conn, msgChan chan *types.MsgPayload) *Link {
@@ -53,6 +59,8 @@ func NewLink(id common.PeerId, c net.Conn, msgChan chan *types.MsgPayload) *Link
 		time:      time.Now().UnixNano(),
 		recvChan:  msgChan,
 		reqRecord: make(map[string]int64),
+		stats:     NewLinkStats(),
+		limiters:  newRateLimiters(time.Now()),
 	}
 
 	return link
@@ -77,6 +85,41 @@ func (this *Link) SetConn(conn net.Conn) {
 	this.conn = conn
 }
 
+//GetStats return the traffic counters of link
+func (this *Link) GetStats() *LinkStats {
+	return this.stats
+}
+
+//GetReputation return the reputation score of peer
+func (this *Link) GetReputation() int64 {
+	return this.reputation.Score()
+}
+
+//ReputationExhausted return whether the peer is disconnected for its bad reputation
+func (this *Link) ReputationExhausted() bool {
+	return this.reputation.Exhausted()
+}
+
+//UpdateReputation add delta to the reputation score, the connection is closed if the reputation is exhausted
+func (this *Link) UpdateReputation(delta int64) int64 {
+	score := this.reputation.Update(delta)
+	if score <= common.REPUTATION_BAN_SCORE {
+		log.Infof("[p2p]reputation of %s exhausted, disconnect", this.GetAddr())
+		this.CloseConn()
+	}
+	return score
+}
+
+//SetLatency record the round trip time of heartbeat
+func (this *Link) SetLatency(latency time.Duration) {
+	atomic.StoreInt64(&this.latency, int64(latency))
+}
+
+//GetLatency return the latest round trip time of heartbeat, zero if not measured
+func (this *Link) GetLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&this.latency))
+}
+
 //record latest message time
 func (this *Link) UpdateRXTime(t time.Time) {
 	atomic.StoreInt64(&this.time, t.UnixNano())
@@ -103,13 +146,24 @@ func (this *Link) Rx() {
 		}
 
 		if unknown, ok := msg.(*types.UnknownMessage); ok {
+			this.stats.addRecv(UNKNOWN_MSG_TYPE, payloadSize+common.MSG_HDR_LEN)
 			log.Infof("skip handle unknown msg type:%s from:%d", unknown.CmdType(), this.id)
 			continue
 		}
+		this.stats.addRecv(msg.CmdType(), payloadSize+common.MSG_HDR_LEN)
 
 		t := time.Now()
 		this.UpdateRXTime(t)
 
+		if limiter, ok := this.limiters[msg.CmdType()]; ok && !limiter.allow(t) {
+			log.Debugf("rate limit msgType:%s from:%d", msg.CmdType(), this.id)
+			this.stats.addLimited(msg.CmdType())
+			if this.UpdateReputation(common.REPUTATION_RATE_LIMITED) <= common.REPUTATION_BAN_SCORE {
+				break
+			}
+			continue
+		}
+
 		if !this.needSendMsg(msg) {
 			log.Debugf("skip handle msgType:%s from:%d", msg.CmdType(), this.id)
 			continue
@@ -153,6 +207,7 @@ func (this *Link) SendRaw(rawPacket []byte) error {
 	}
 	nByteCnt := len(rawPacket)
 	log.Tracef("[p2p]TX buf length: %d\n", nByteCnt)
+	this.stats.addSend(cmdTypeOfPacket(rawPacket), nByteCnt)
 
 	nCount := nByteCnt / common.PER_SEND_LEN
 	if nCount == 0 {
@@ -169,6 +224,15 @@ func (this *Link) SendRaw(rawPacket []byte) error {
 	return nil
 }
 
+//cmdTypeOfPacket return the message type in the header of raw packet
+func cmdTypeOfPacket(rawPacket []byte) string {
+	start := comm.UINT32_SIZE
+	if len(rawPacket) < start+common.MSG_CMD_LEN {
+		return ""
+	}
+	return string(bytes.TrimRight(rawPacket[start:start+common.MSG_CMD_LEN], string(rune(0))))
+}
+
 //needSendMsg check whether the msg is needed to push to channel
 func (this *Link) needSendMsg(msg types.Message) bool {
 	if msg.CmdType() != common.GET_DATA_TYPE {
@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package link
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ontio/ontology/p2pserver/common"
+)
+
+//the counter key of the messages of unknown type, they are counted together so that a peer can not
+//grow the counters by arbitrary message types
+const UNKNOWN_MSG_TYPE = "unknown"
+
+//MsgCounter record the traffic of a message type
+type MsgCounter struct {
+	RecvMsgs    uint64
+	RecvBytes   uint64
+	SendMsgs    uint64
+	SendBytes   uint64
+	LimitedMsgs uint64 //received messages dropped by rate limit
+}
+
+//LinkStats record the traffic of a link by message type
+type LinkStats struct {
+	lock     sync.RWMutex
+	counters map[string]*MsgCounter
+}
+
+func NewLinkStats() *LinkStats {
+	return &LinkStats{counters: make(map[string]*MsgCounter)}
+}
+
+func (this *LinkStats) counter(cmd string) *MsgCounter {
+	this.lock.RLock()
+	c, ok := this.counters[cmd]
+	this.lock.RUnlock()
+	if ok {
+		return c
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if c, ok = this.counters[cmd]; !ok {
+		c = &MsgCounter{}
+		this.counters[cmd] = c
+	}
+	return c
+}
+
+func (this *LinkStats) addRecv(cmd string, size uint32) {
+	c := this.counter(cmd)
+	atomic.AddUint64(&c.RecvMsgs, 1)
+	atomic.AddUint64(&c.RecvBytes, uint64(size))
+}
+
+func (this *LinkStats) addSend(cmd string, size int) {
+	c := this.counter(cmd)
+	atomic.AddUint64(&c.SendMsgs, 1)
+	atomic.AddUint64(&c.SendBytes, uint64(size))
+}
+
+func (this *LinkStats) addLimited(cmd string) {
+	atomic.AddUint64(&this.counter(cmd).LimitedMsgs, 1)
+}
+
+//Snapshot return a copy of the counters, map message type => counter
+func (this *LinkStats) Snapshot() map[string]MsgCounter {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	snapshot := make(map[string]MsgCounter, len(this.counters))
+	for cmd, c := range this.counters {
+		snapshot[cmd] = MsgCounter{
+			RecvMsgs:    atomic.LoadUint64(&c.RecvMsgs),
+			RecvBytes:   atomic.LoadUint64(&c.RecvBytes),
+			SendMsgs:    atomic.LoadUint64(&c.SendMsgs),
+			SendBytes:   atomic.LoadUint64(&c.SendBytes),
+			LimitedMsgs: atomic.LoadUint64(&c.LimitedMsgs),
+		}
+	}
+	return snapshot
+}
+
+//tokenBucket limit the rate of events, it is refilled with rate tokens per second up to burst tokens
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+//allow take a token if there is any
+func (this *tokenBucket) allow(now time.Time) bool {
+	if elapsed := now.Sub(this.last).Seconds(); elapsed > 0 {
+		this.tokens += elapsed * this.rate
+		if this.tokens > this.burst {
+			this.tokens = this.burst
+		}
+		this.last = now
+	}
+	if this.tokens < 1 {
+		return false
+	}
+	this.tokens--
+	return true
+}
+
+//newRateLimiters return the token buckets of the rate limited message types
+func newRateLimiters(now time.Time) map[string]*tokenBucket {
+	return map[string]*tokenBucket{
+		common.GET_DATA_TYPE:      newTokenBucket(common.GET_DATA_RATE_LIMIT, common.GET_DATA_RATE_BURST, now),
+		common.GET_HEADERS_TYPE:   newTokenBucket(common.GET_HEADERS_RATE_LIMIT, common.GET_HEADERS_RATE_BURST, now),
+		common.GET_BLK_RANGE_TYPE: newTokenBucket(common.GET_BLK_RANGE_RATE_LIMIT, common.GET_BLK_RANGE_RATE_BURST, now),
+		common.INV_TYPE:           newTokenBucket(common.INV_RATE_LIMIT, common.INV_RATE_BURST, now),
+		common.TX_TYPE:            newTokenBucket(common.TX_RATE_LIMIT, common.TX_RATE_BURST, now),
+		common.GET_BLK_TXN_TYPE:   newTokenBucket(common.GET_BLK_TXN_RATE_LIMIT, common.GET_BLK_TXN_RATE_BURST, now),
+	}
+}
+
+//Reputation is the score of a peer behavior, it is decreased by misbehavior and increased by useful responses
+type Reputation struct {
+	score int64
+}
+
+//Update add delta to the score and return the new score, the score is capped by REPUTATION_MAX_SCORE
+func (this *Reputation) Update(delta int64) int64 {
+	for {
+		old := atomic.LoadInt64(&this.score)
+		score := old + delta
+		if score > common.REPUTATION_MAX_SCORE {
+			score = common.REPUTATION_MAX_SCORE
+		}
+		if atomic.CompareAndSwapInt64(&this.score, old, score) {
+			return score
+		}
+	}
+}
+
+//Score return the current score
+func (this *Reputation) Score() int64 {
+	return atomic.LoadInt64(&this.score)
+}
+
+//Exhausted return whether the peer should be disconnected and banned
+func (this *Reputation) Exhausted() bool {
+	return this.Score() <= common.REPUTATION_BAN_SCORE
+}
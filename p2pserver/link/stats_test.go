@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package link
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	comm "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/p2pserver/common"
+	mt "github.com/ontio/ontology/p2pserver/message/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	bucket := newTokenBucket(10, 20, now)
+	for i := 0; i < 20; i++ {
+		assert.True(t, bucket.allow(now))
+	}
+	assert.False(t, bucket.allow(now))
+
+	now = now.Add(100 * time.Millisecond)
+	assert.True(t, bucket.allow(now))
+	assert.False(t, bucket.allow(now))
+
+	// refill never exceeds the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 20; i++ {
+		assert.True(t, bucket.allow(now))
+	}
+	assert.False(t, bucket.allow(now))
+}
+
+func TestReputation(t *testing.T) {
+	var rep Reputation
+	assert.Equal(t, rep.Update(common.REPUTATION_MAX_SCORE*2), int64(common.REPUTATION_MAX_SCORE))
+	assert.False(t, rep.Exhausted())
+
+	rep.Update(common.REPUTATION_BAN_SCORE - common.REPUTATION_MAX_SCORE + 1)
+	assert.False(t, rep.Exhausted())
+	rep.Update(-1)
+	assert.True(t, rep.Exhausted())
+	assert.Equal(t, rep.Score(), int64(common.REPUTATION_BAN_SCORE))
+}
+
+func TestLinkStatsSnapshot(t *testing.T) {
+	stats := NewLinkStats()
+	stats.addRecv(common.INV_TYPE, 100)
+	stats.addRecv(common.INV_TYPE, 50)
+	stats.addSend(common.BLOCK_TYPE, 1000)
+	stats.addLimited(common.INV_TYPE)
+
+	snapshot := stats.Snapshot()
+	assert.Equal(t, snapshot[common.INV_TYPE], MsgCounter{RecvMsgs: 2, RecvBytes: 150, LimitedMsgs: 1})
+	assert.Equal(t, snapshot[common.BLOCK_TYPE], MsgCounter{SendMsgs: 1, SendBytes: 1000})
+
+	// snapshot is not affected by later updates
+	stats.addRecv(common.INV_TYPE, 10)
+	assert.Equal(t, snapshot[common.INV_TYPE].RecvMsgs, uint64(2))
+}
+
+func TestCmdTypeOfPacket(t *testing.T) {
+	sink := comm.NewZeroCopySink(nil)
+	mt.WriteMessage(sink, &mt.Ping{Height: 1})
+	assert.Equal(t, cmdTypeOfPacket(sink.Bytes()), common.PING_TYPE)
+	assert.Equal(t, cmdTypeOfPacket(sink.Bytes()[:8]), "")
+}
+
+func TestRxRateLimit(t *testing.T) {
+	local, remote := net.Pipe()
+	msgChan := make(chan *mt.MsgPayload, common.GET_DATA_RATE_BURST)
+	link := NewLink(common.PseudoPeerIdFromUint64(1), local, msgChan)
+
+	done := make(chan struct{})
+	go func() {
+		link.Rx()
+		close(done)
+	}()
+
+	sink := comm.NewZeroCopySink(nil)
+	mt.WriteMessage(sink, &mt.DataReq{DataType: comm.BLOCK})
+	raw := sink.Bytes()
+	for i := 0; i < common.GET_DATA_RATE_BURST*2; i++ {
+		if _, err := remote.Write(raw); err != nil {
+			break
+		}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("flooding peer is not disconnected")
+	}
+	assert.True(t, link.ReputationExhausted())
+	counter := link.GetStats().Snapshot()[common.GET_DATA_TYPE]
+	assert.True(t, counter.LimitedMsgs > 0)
+	assert.Equal(t, counter.RecvBytes, counter.RecvMsgs*uint64(len(raw)))
+	// duplicated requests are not pushed to channel
+	assert.Equal(t, len(msgChan), 1)
+}
+
+func TestRxUnknownMessage(t *testing.T) {
+	local, remote := net.Pipe()
+	msgChan := make(chan *mt.MsgPayload, 1)
+	link := NewLink(common.PseudoPeerIdFromUint64(1), local, msgChan)
+
+	done := make(chan struct{})
+	go func() {
+		link.Rx()
+		close(done)
+	}()
+
+	for _, cmd := range []string{"foo", "bar", "baz"} {
+		sink := comm.NewZeroCopySink(nil)
+		mt.WriteMessage(sink, &mt.UnknownMessage{Cmd: cmd, Payload: []byte{1, 2, 3}})
+		_, err := remote.Write(sink.Bytes())
+		assert.Nil(t, err)
+	}
+	remote.Close()
+	<-done
+
+	snapshot := link.GetStats().Snapshot()
+	assert.Equal(t, 1, len(snapshot))
+	assert.Equal(t, uint64(3), snapshot[UNKNOWN_MSG_TYPE].RecvMsgs)
+	assert.Equal(t, 0, len(msgChan))
+}
@@ -19,6 +19,8 @@
 package msgpack
 
 import (
+	"math/rand"
+
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/log"
 	ct "github.com/ontio/ontology/core/types"
@@ -53,6 +55,32 @@ func NewBlock(bk *ct.Block, ccMsg *ct.CrossChainMsg, merkleRoot common.Uint256)
 	return &blk
 }
 
+//compact block package
+func NewCompactBlock(bk *ct.Block, ccMsg *ct.CrossChainMsg, merkleRoot common.Uint256) mt.Message {
+	log.Trace()
+	return mt.NewCompactBlock(bk, ccMsg, merkleRoot, rand.Uint64())
+}
+
+//compact block missing txs request package
+func NewGetBlockTxn(hash common.Uint256, indexes []uint32) mt.Message {
+	log.Trace()
+	var req mt.GetBlockTxn
+	req.BlockHash = hash
+	req.Indexes = indexes
+
+	return &req
+}
+
+//compact block missing txs package
+func NewBlockTxn(hash common.Uint256, txs []*ct.Transaction) mt.Message {
+	log.Trace()
+	var blkTxn mt.BlockTxn
+	blkTxn.BlockHash = hash
+	blkTxn.Txs = txs
+
+	return &blkTxn
+}
+
 //blk hdr package
 func NewHeaders(headers []*ct.RawHeader) mt.Message {
 	log.Trace()
@@ -156,6 +184,26 @@ func NewBlkDataReq(hash common.Uint256) mt.Message {
 	return &dataReq
 }
 
+//compact block request package
+func NewCmpctBlkDataReq(hash common.Uint256) mt.Message {
+	log.Trace()
+	var dataReq mt.DataReq
+	dataReq.DataType = common.CMPCT_BLOCK
+	dataReq.Hash = hash
+
+	return &dataReq
+}
+
+//block range request package
+func NewBlocksRangeReq(startHeight, count uint32) mt.Message {
+	log.Trace()
+	var req mt.BlocksRangeReq
+	req.StartHeight = startHeight
+	req.Count = count
+
+	return &req
+}
+
 //consensus request package
 func NewConsensusDataReq(hash common.Uint256) mt.Message {
 	log.Trace()
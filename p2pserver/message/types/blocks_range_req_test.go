@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	comm "github.com/ontio/ontology/common"
+)
+
+func TestBlocksRangeReqSerializationDeserialization(t *testing.T) {
+	msg := &BlocksRangeReq{
+		StartHeight: 1024,
+		Count:       64,
+	}
+
+	MessageTest(t, msg)
+}
+
+func TestBlocksRangeReqTruncated(t *testing.T) {
+	sink := comm.NewZeroCopySink(nil)
+	sink.WriteUint32(1024)
+
+	var msg BlocksRangeReq
+	err := msg.Deserialization(comm.NewZeroCopySource(sink.Bytes()))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ontio/ontology/common"
+	ct "github.com/ontio/ontology/core/types"
+	comm "github.com/ontio/ontology/p2pserver/common"
+)
+
+//PrefilledTxn is a transaction sent in full within compact block
+type PrefilledTxn struct {
+	Index uint32 //index of the transaction in block
+	Tx    *ct.Transaction
+}
+
+//CompactBlock carry the block header and the short ids of the transactions, the receiver rebuild the block
+//from the transactions in its tx pool
+type CompactBlock struct {
+	Header     *ct.Header
+	Nonce      uint64   //salt of short ids
+	ShortIds   []uint64 //short ids of the transactions not prefilled, in block order
+	Prefilled  []PrefilledTxn
+	MerkleRoot common.Uint256
+	CCMsg      *ct.CrossChainMsg
+}
+
+//ShortTxId return the short id of transaction salted by nonce
+func ShortTxId(nonce uint64, txHash common.Uint256) uint64 {
+	var buf [8 + common.UINT256_SIZE]byte
+	binary.LittleEndian.PutUint64(buf[:8], nonce)
+	copy(buf[8:], txHash[:])
+	sum := sha256.Sum256(buf[:])
+	return binary.LittleEndian.Uint64(sum[:8])
+}
+
+//NewCompactBlock build compact block, the transactions whose short id collides with an earlier one are prefilled
+func NewCompactBlock(blk *ct.Block, ccMsg *ct.CrossChainMsg, merkleRoot common.Uint256, nonce uint64) *CompactBlock {
+	cmpct := &CompactBlock{
+		Header:     blk.Header,
+		Nonce:      nonce,
+		MerkleRoot: merkleRoot,
+		CCMsg:      ccMsg,
+	}
+	ids := make(map[uint64]bool, len(blk.Transactions))
+	for i, tx := range blk.Transactions {
+		id := ShortTxId(nonce, tx.Hash())
+		if ids[id] {
+			cmpct.Prefilled = append(cmpct.Prefilled, PrefilledTxn{Index: uint32(i), Tx: tx})
+			continue
+		}
+		ids[id] = true
+		cmpct.ShortIds = append(cmpct.ShortIds, id)
+	}
+	return cmpct
+}
+
+//TxCount return the transaction count of block
+func (this *CompactBlock) TxCount() int {
+	return len(this.ShortIds) + len(this.Prefilled)
+}
+
+//Serialize message payload
+func (this *CompactBlock) Serialization(sink *common.ZeroCopySink) {
+	this.Header.Serialization(sink)
+	sink.WriteUint64(this.Nonce)
+	sink.WriteUint32(uint32(len(this.ShortIds)))
+	for _, id := range this.ShortIds {
+		sink.WriteUint64(id)
+	}
+	sink.WriteUint32(uint32(len(this.Prefilled)))
+	for _, txn := range this.Prefilled {
+		sink.WriteUint32(txn.Index)
+		txn.Tx.Serialization(sink)
+	}
+	sink.WriteHash(this.MerkleRoot)
+	sink.WriteBool(this.CCMsg != nil)
+	if this.CCMsg != nil {
+		this.CCMsg.Serialization(sink)
+	}
+}
+
+func (this *CompactBlock) CmdType() string {
+	return comm.CMPCT_BLOCK_TYPE
+}
+
+//Deserialize message payload
+func (this *CompactBlock) Deserialization(source *common.ZeroCopySource) error {
+	this.Header = new(ct.Header)
+	err := this.Header.Deserialization(source)
+	if err != nil {
+		return fmt.Errorf("read header error. err:%v", err)
+	}
+	var eof bool
+	this.Nonce, eof = source.NextUint64()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	count, eof := source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if uint64(count)*8 > source.Len() {
+		return io.ErrUnexpectedEOF
+	}
+	this.ShortIds = make([]uint64, 0, count)
+	for i := uint32(0); i < count; i++ {
+		id, _ := source.NextUint64()
+		this.ShortIds = append(this.ShortIds, id)
+	}
+	count, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	last := int64(-1)
+	for i := uint32(0); i < count; i++ {
+		index, eof := source.NextUint32()
+		if eof {
+			return io.ErrUnexpectedEOF
+		}
+		if int64(index) <= last {
+			return errors.New("prefilled transactions out of order")
+		}
+		last = int64(index)
+		tx := new(ct.Transaction)
+		if err := tx.Deserialization(source); err != nil {
+			return fmt.Errorf("read prefilled tx error. err:%v", err)
+		}
+		this.Prefilled = append(this.Prefilled, PrefilledTxn{Index: index, Tx: tx})
+	}
+	if last >= int64(this.TxCount()) {
+		return errors.New("prefilled transaction index out of range")
+	}
+	this.MerkleRoot, eof = source.NextHash()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	hasCCM, irr, eof := source.NextBool()
+	if irr || eof {
+		return io.ErrUnexpectedEOF
+	}
+	if hasCCM {
+		this.CCMsg = new(ct.CrossChainMsg)
+		if err := this.CCMsg.Deserialization(source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//GetBlockTxn request the transactions missing from tx pool to rebuild compact block
+type GetBlockTxn struct {
+	BlockHash common.Uint256
+	Indexes   []uint32 //indexes of the transactions in block
+}
+
+//Serialize message payload
+func (this *GetBlockTxn) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteHash(this.BlockHash)
+	sink.WriteUint32(uint32(len(this.Indexes)))
+	for _, index := range this.Indexes {
+		sink.WriteUint32(index)
+	}
+}
+
+func (this *GetBlockTxn) CmdType() string {
+	return comm.GET_BLK_TXN_TYPE
+}
+
+//Deserialize message payload
+func (this *GetBlockTxn) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.BlockHash, eof = source.NextHash()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	count, eof := source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if uint64(count)*4 > source.Len() {
+		return io.ErrUnexpectedEOF
+	}
+	this.Indexes = make([]uint32, 0, count)
+	for i := uint32(0); i < count; i++ {
+		index, _ := source.NextUint32()
+		this.Indexes = append(this.Indexes, index)
+	}
+	return nil
+}
+
+//BlockTxn response the requested transactions of compact block, in the order of request
+type BlockTxn struct {
+	BlockHash common.Uint256
+	Txs       []*ct.Transaction
+}
+
+//Serialize message payload
+func (this *BlockTxn) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteHash(this.BlockHash)
+	sink.WriteUint32(uint32(len(this.Txs)))
+	for _, tx := range this.Txs {
+		tx.Serialization(sink)
+	}
+}
+
+func (this *BlockTxn) CmdType() string {
+	return comm.BLK_TXN_TYPE
+}
+
+//Deserialize message payload
+func (this *BlockTxn) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.BlockHash, eof = source.NextHash()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	count, eof := source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	for i := uint32(0); i < count; i++ {
+		tx := new(ct.Transaction)
+		if err := tx.Deserialization(source); err != nil {
+			return fmt.Errorf("read tx error. err:%v", err)
+		}
+		this.Txs = append(this.Txs, tx)
+	}
+	return nil
+}
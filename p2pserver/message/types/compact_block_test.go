@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/payload"
+	ct "github.com/ontio/ontology/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTxs(t *testing.T, n int) []*ct.Transaction {
+	var txs []*ct.Transaction
+	for i := 0; i < n; i++ {
+		mutable := &ct.MutableTransaction{
+			TxType:  ct.InvokeNeo,
+			Nonce:   uint32(i),
+			Payload: &payload.InvokeCode{Code: []byte{byte(i)}},
+		}
+		tx, err := mutable.IntoImmutable()
+		assert.Nil(t, err)
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+func newTestBlock(t *testing.T, n int) *ct.Block {
+	block := &ct.Block{
+		Header:       &ct.Header{Height: 100},
+		Transactions: newTestTxs(t, n),
+	}
+	block.RebuildMerkleRoot()
+	return block
+}
+
+func readTestMessage(t *testing.T, msg Message) Message {
+	sink := common.NewZeroCopySink(nil)
+	WriteMessage(sink, msg)
+	demsg, _, err := ReadMessage(bytes.NewBuffer(sink.Bytes()))
+	assert.Nil(t, err)
+	return demsg
+}
+
+func TestCompactBlockSerializationDeserialization(t *testing.T) {
+	block := newTestBlock(t, 5)
+	cmpct := NewCompactBlock(block, nil, common.Uint256{1}, 42)
+	cmpct.Prefilled = []PrefilledTxn{{Index: 5, Tx: newTestTxs(t, 6)[5]}}
+
+	demsg := readTestMessage(t, cmpct).(*CompactBlock)
+	assert.Equal(t, demsg.Header.Hash(), block.Hash())
+	assert.Equal(t, demsg.Nonce, uint64(42))
+	assert.Equal(t, demsg.ShortIds, cmpct.ShortIds)
+	assert.Equal(t, demsg.MerkleRoot, common.Uint256{1})
+	assert.Nil(t, demsg.CCMsg)
+	assert.Equal(t, len(demsg.Prefilled), 1)
+	assert.Equal(t, demsg.Prefilled[0].Index, uint32(5))
+	assert.Equal(t, demsg.Prefilled[0].Tx.Hash(), cmpct.Prefilled[0].Tx.Hash())
+	assert.Equal(t, demsg.TxCount(), 6)
+
+	for i, tx := range block.Transactions {
+		assert.Equal(t, cmpct.ShortIds[i], ShortTxId(42, tx.Hash()))
+	}
+}
+
+func TestCompactBlockInvalidPrefilled(t *testing.T) {
+	txs := newTestTxs(t, 2)
+	cmpct := NewCompactBlock(newTestBlock(t, 1), nil, common.UINT256_EMPTY, 1)
+
+	cmpct.Prefilled = []PrefilledTxn{{Index: 1, Tx: txs[0]}, {Index: 1, Tx: txs[1]}}
+	sink := common.NewZeroCopySink(nil)
+	cmpct.Serialization(sink)
+	err := new(CompactBlock).Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NotNil(t, err)
+
+	cmpct.Prefilled = []PrefilledTxn{{Index: 2, Tx: txs[0]}}
+	sink = common.NewZeroCopySink(nil)
+	cmpct.Serialization(sink)
+	err = new(CompactBlock).Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NotNil(t, err)
+}
+
+func TestGetBlockTxnSerializationDeserialization(t *testing.T) {
+	msg := &GetBlockTxn{
+		BlockHash: common.Uint256{1, 2, 3},
+		Indexes:   []uint32{0, 3, 7},
+	}
+
+	MessageTest(t, msg)
+}
+
+func TestBlockTxnSerializationDeserialization(t *testing.T) {
+	txs := newTestTxs(t, 3)
+	msg := &BlockTxn{
+		BlockHash: common.Uint256{1, 2, 3},
+		Txs:       txs,
+	}
+
+	demsg := readTestMessage(t, msg).(*BlockTxn)
+	assert.Equal(t, demsg.BlockHash, msg.BlockHash)
+	assert.Equal(t, len(demsg.Txs), len(txs))
+	for i, tx := range txs {
+		assert.Equal(t, demsg.Txs[i].Hash(), tx.Hash())
+	}
+}
@@ -191,12 +191,24 @@ func makeEmptyMessage(cmdType string) Message {
 		return &NotFound{}
 	case common.GET_BLOCKS_TYPE:
 		return &BlocksReq{}
+	case common.GET_BLK_RANGE_TYPE:
+		return &BlocksRangeReq{}
 	case common.FINDNODE_TYPE:
 		return &FindNodeReq{}
 	case common.FINDNODE_RESP_TYPE:
 		return &FindNodeResp{}
 	case common.UPDATE_KADID_TYPE:
 		return &UpdatePeerKeyId{}
+	case common.SECURE_HELLO_TYPE:
+		return &SecureHello{}
+	case common.SECURE_AUTH_TYPE:
+		return &SecureAuth{}
+	case common.CMPCT_BLOCK_TYPE:
+		return &CompactBlock{}
+	case common.GET_BLK_TXN_TYPE:
+		return &GetBlockTxn{}
+	case common.BLK_TXN_TYPE:
+		return &BlockTxn{}
 	case common.GET_SUBNET_MEMBERS_TYPE:
 		return &SubnetMembersRequest{}
 	case common.SUBNET_MEMBERS_TYPE:
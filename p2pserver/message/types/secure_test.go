@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/p2pserver/common"
+)
+
+func TestSecureHelloSerializationDeserialization(t *testing.T) {
+	var msg SecureHello
+	for i := range msg.EphemeralKey {
+		msg.EphemeralKey[i] = byte(i)
+	}
+
+	MessageTest(t, &msg)
+}
+
+func TestSecureAuthSerializationDeserialization(t *testing.T) {
+	difficulty := common.Difficulty
+	common.Difficulty = 1
+	defer func() {
+		common.Difficulty = difficulty
+	}()
+	key := common.RandPeerKeyId()
+	msg := &SecureAuth{
+		KadKeyId:  &common.PeerKeyId{PublicKey: key.PublicKey, Id: key.Id},
+		Signature: []byte{1, 2, 3, 4},
+	}
+
+	MessageTest(t, msg)
+}
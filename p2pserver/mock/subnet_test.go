@@ -176,7 +176,7 @@ func NewSubnetNode(acct *account.Account, listenAddr string, seeds, govs []strin
 	context := fmt.Sprintf("peer %s-%s: ", logPrefix, seedId.Id.ToHexString()[:6])
 	logger := common.LoggerWithContext(common.NewGlobalLoggerWrapper(), context)
 	protocal := NewTestSubnetProtocalHandler(acct, seeds, govs, logger)
-	resvFilter := protocal.GetReservedAddrFilter(len(reservedPeers) != 0)
+	resvFilter := protocal.GetReservedAddrFilter(func() bool { return len(reservedPeers) != 0 })
 	return NewNode(seedId, listenAddr, info, protocal, net, reservedPeers, resvFilter, logger)
 }
 
@@ -198,7 +198,7 @@ func NewTestSubnetProtocalHandler(acct *account.Account, seedList, govs []string
 	return &TestSubnetProtocalHandler{seeds: seeds, subnet: subNet, acct: acct}
 }
 
-func (self *TestSubnetProtocalHandler) GetReservedAddrFilter(staticFilterEnabled bool) p2p.AddressFilter {
+func (self *TestSubnetProtocalHandler) GetReservedAddrFilter(staticFilterEnabled func() bool) p2p.AddressFilter {
 	return self.subnet.GetReservedAddrFilter(staticFilterEnabled)
 }
 
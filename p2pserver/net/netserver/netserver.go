@@ -21,6 +21,7 @@ package netserver
 import (
 	"errors"
 	"net"
+	"time"
 
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
@@ -42,6 +43,7 @@ func NewNetServer(protocol p2p.Protocol, conf *config.P2PNodeConfig, reserveAddr
 	keyId := common.RandPeerKeyId()
 	info := peer.NewPeerInfo(keyId.Id, common.PROTOCOL_VERSION, common.SERVICE_NODE, true,
 		conf.HttpInfoPort, nodePort, 0, config.Version, "")
+	info.CompactBlock = true
 
 	option, err := connect_controller.ConnCtrlOptionFromConfig(conf, reserveAddrFilter)
 	if err != nil {
@@ -210,7 +212,7 @@ func (this *NetServer) connect(addr string) error {
 	remotePeer := peer.NewPeer(peerInfo, conn, this.NetChan)
 
 	this.ReplacePeer(remotePeer)
-	go remotePeer.Link.Rx()
+	go this.rxPeer(remotePeer)
 
 	this.protocol.HandleSystemMessage(this, p2p.PeerConnected{Info: remotePeer.Info})
 	return nil
@@ -246,11 +248,20 @@ func (this *NetServer) handleClientConnection(conn net.Conn) error {
 	remotePeer := peer.NewPeer(peerInfo, conn, this.NetChan)
 	this.ReplacePeer(remotePeer)
 
-	go remotePeer.Link.Rx()
+	go this.rxPeer(remotePeer)
 	this.protocol.HandleSystemMessage(this, p2p.PeerConnected{Info: remotePeer.Info})
 	return nil
 }
 
+//rxPeer receive messages from the peer until the link is closed, the peer is banned if it is disconnected
+//for the exhausted reputation
+func (this *NetServer) rxPeer(remotePeer *peer.Peer) {
+	remotePeer.Link.Rx()
+	if remotePeer.Link.ReputationExhausted() {
+		this.connCtrl.BanPeer(remotePeer.GetID(), remotePeer.GetAddr(), common.PEER_BAN_DURATION*time.Second)
+	}
+}
+
 //startNetAccept accepts the sync connection from the inbound peer
 func (this *NetServer) startNetAccept(listener net.Listener) {
 	for {
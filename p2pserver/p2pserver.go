@@ -31,14 +31,18 @@ import (
 	"github.com/ontio/ontology/p2pserver/net/netserver"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
 	"github.com/ontio/ontology/p2pserver/protocols"
+	"github.com/ontio/ontology/p2pserver/protocols/block_sync"
+	"github.com/ontio/ontology/p2pserver/protocols/peer_admin"
 	"github.com/ontio/ontology/p2pserver/protocols/utils"
 	common2 "github.com/ontio/ontology/txnpool/common"
 )
 
 //P2PServer control all network activities
 type P2PServer struct {
-	network *netserver.NetServer
-	db      *ledger.Ledger
+	network  *netserver.NetServer
+	protocol *protocols.MsgHandler
+	admin    *peer_admin.PeerAdmin
+	db       *ledger.Ledger
 }
 
 //NewServer return a new p2pserver according to the pubkey
@@ -55,17 +59,32 @@ func NewServer(acct *account.Account, txpool common2.TxPoolService) (*P2PServer,
 	}
 
 	staticFilter := connect_controller.NewStaticReserveFilter(rsv)
-	protocol := protocols.NewMsgHandler(acct, connect_controller.NewStaticReserveFilter(recRsv), db, txpool, common.NewGlobalLoggerWrapper())
-	reserved := protocol.GetReservedAddrFilter(len(rsv) != 0)
+	recFilter := connect_controller.NewStaticReserveFilter(recRsv)
+	protocol := protocols.NewMsgHandler(acct, recFilter, db, txpool, common.NewGlobalLoggerWrapper())
+	// the static reserved peers can be replaced by the peer admin, so whether they restrict the normal node
+	// is decided at runtime
+	reserved := protocol.GetReservedAddrFilter(func() bool {
+		return conf.ReservedPeersOnly && len(staticFilter.GetReservedPeers()) != 0
+	})
 	reservedPeers := p2p.CombineAddrFilter(staticFilter, reserved)
 	n, err := netserver.NewNetServer(protocol, conf, reservedPeers)
 	if err != nil {
 		return nil, err
 	}
 
+	cancelRetry := func(listenAddr string) {
+		if reconnect := protocol.ReconnectService(); reconnect != nil {
+			reconnect.CancelRetry(listenAddr)
+		}
+	}
+	admin := peer_admin.NewPeerAdmin(n, protocol.GetMaskAddrFilter(), cancelRetry, common.ADMIN_FILE_NAME,
+		staticFilter, recFilter)
+
 	p := &P2PServer{
-		db:      db,
-		network: n,
+		db:       db,
+		network:  n,
+		protocol: protocol,
+		admin:    admin,
 	}
 
 	return p, nil
@@ -73,11 +92,17 @@ func NewServer(acct *account.Account, txpool common2.TxPoolService) (*P2PServer,
 
 //Start create all services
 func (self *P2PServer) Start() error {
-	return self.network.Start()
+	if err := self.network.Start(); err != nil {
+		return err
+	}
+	self.admin.Start()
+	peer_admin.RegisterPeerAdmin(self.admin)
+	return nil
 }
 
 //Stop halt all service by send signal to channels
 func (self *P2PServer) Stop() {
+	self.admin.Stop()
 	self.network.Stop()
 }
 
@@ -86,6 +111,11 @@ func (self *P2PServer) GetNetwork() p2p.P2P {
 	return self.network
 }
 
+//GetSyncProgress return the block sync progress
+func (self *P2PServer) GetSyncProgress() block_sync.SyncProgress {
+	return self.protocol.GetSyncProgress()
+}
+
 //WaitForPeersStart check whether enough peer linked in loop
 func (self *P2PServer) WaitForPeersStart() {
 	periodTime := config.DEFAULT_GEN_BLOCK_TIME / common.UPDATE_RATE_PER_BLOCK
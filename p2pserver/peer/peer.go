@@ -45,6 +45,7 @@ type PeerInfo struct {
 	Port         uint16
 	SoftVersion  string
 	Addr         string
+	CompactBlock bool //peer supports compact block relay
 
 	height uint64
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/ontio/ontology/core/types"
 	p2pComm "github.com/ontio/ontology/p2pserver/common"
 	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
+	msgTypes "github.com/ontio/ontology/p2pserver/message/types"
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
 	"github.com/ontio/ontology/p2pserver/peer"
 )
@@ -56,6 +57,7 @@ type NodeWeight struct {
 	id           p2pComm.PeerId //NodeID
 	timeoutCnt   int64          //Node response timeout count
 	errorRespCnt int64          //Node response error data count
+	rangeMissCnt int64          //Range request timeout count without any block replied
 
 	lock    sync.Mutex
 	speed   []float32 //Record node request-response speed, using for calc the avg speed, unit kB/s
@@ -97,6 +99,21 @@ func (this *NodeWeight) GetErrorRespCnt() int64 {
 	return atomic.LoadInt64(&this.errorRespCnt)
 }
 
+//AddRangeMissCnt incre range request timeout count without any block replied
+func (this *NodeWeight) AddRangeMissCnt() {
+	atomic.AddInt64(&this.rangeMissCnt, 1)
+}
+
+//ResetRangeMissCnt reset range miss count after the node replied a range block
+func (this *NodeWeight) ResetRangeMissCnt() {
+	atomic.StoreInt64(&this.rangeMissCnt, 0)
+}
+
+//SupportRangeReq return whether the node is considered to serve range request
+func (this *NodeWeight) SupportRangeReq() bool {
+	return atomic.LoadInt64(&this.rangeMissCnt) < SYNC_MAX_RANGE_TIMEOUT_TIMES
+}
+
 //AppendNewReqTime append new request time
 func (this *NodeWeight) AppendNewReqtime() {
 	this.lock.Lock()
@@ -240,6 +257,9 @@ type BlockInfo struct {
 type BlockSyncMgr struct {
 	flightBlocks   map[common.Uint256][]*SyncFlightInfo //Map BlockHash => []SyncFlightInfo, using for manager all of those block flights
 	flightHeaders  map[uint32]*SyncFlightInfo           //Map HeaderHeight => SyncFlightInfo, using for manager all of those header flights
+	flightRanges   map[uint32]*SyncFlightRange          //Map StartHeight => SyncFlightRange, using for manager all of those range flights in fast sync mode
+	fastSync       bool                                 //Download block ranges in parallel after header validated
+	meter          *syncMeter                           //Block sync speed meter
 	blocksCache    *BlockCache                          //Map BlockHash => BlockInfo, using for cache the blocks receive from net, and waiting for commit to ledger
 	server         p2p.P2P                              //Pointer to the local node
 	syncBlockLock  bool                                 //Help to avoid send block sync request duplicate
@@ -256,6 +276,8 @@ func NewBlockSyncMgr(server p2p.P2P, ld *ledger.Ledger) *BlockSyncMgr {
 	return &BlockSyncMgr{
 		flightBlocks:  make(map[common.Uint256][]*SyncFlightInfo),
 		flightHeaders: make(map[uint32]*SyncFlightInfo),
+		flightRanges:  make(map[uint32]*SyncFlightRange),
+		meter:         newSyncMeter(ld.GetCurrentBlockHeight(), time.Now()),
 		blocksCache:   NewBlockCache(),
 		server:        server,
 		ledger:        ld,
@@ -349,6 +371,7 @@ func (this *BlockSyncMgr) Start() {
 		case <-this.exitCh:
 			return
 		case <-ticker.C:
+			this.meter.record(this.ledger.GetCurrentBlockHeight(), time.Now())
 			go this.checkTimeout()
 			go this.sync()
 			go this.saveBlock()
@@ -382,6 +405,7 @@ func (this *BlockSyncMgr) checkTimeout() {
 
 	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
 	curBlockHeight := this.ledger.GetCurrentBlockHeight()
+	this.checkRangeTimeout(now, curBlockHeight)
 
 	for height, flightInfo := range headerTimeoutFlights {
 		this.addTimeoutCnt(flightInfo.GetNodeId())
@@ -424,7 +448,7 @@ func (this *BlockSyncMgr) checkTimeout() {
 			}
 			flightInfo.SetNodeId(reqNode.GetID())
 
-			msg := msgpack.NewBlkDataReq(blockHash)
+			msg := newBlkDataReq(reqNode, blockHash)
 			err := this.server.Send(reqNode, msg)
 			if err != nil {
 				log.Warnf("[block-sync] checkTimeout reqNode ID:%d Send error:%s", reqNode.GetID(), err)
@@ -438,6 +462,9 @@ func (this *BlockSyncMgr) checkTimeout() {
 
 func (this *BlockSyncMgr) sync() {
 	this.syncHeader()
+	if this.isFastSync() && this.syncBlockRanges() {
+		return
+	}
 	this.syncBlock()
 }
 
@@ -524,7 +551,7 @@ func (this *BlockSyncMgr) syncBlock() {
 				continue
 			}
 		}
-		if this.isInBlockCache(nextBlockHeight) {
+		if this.isInBlockCache(nextBlockHeight) || this.isRangeOnFlight(nextBlockHeight) {
 			continue
 		}
 		if nextBlockHeight <= curBlockHeight+SYNC_NEXT_BLOCKS_HEIGHT {
@@ -536,7 +563,7 @@ func (this *BlockSyncMgr) syncBlock() {
 				return
 			}
 			this.addFlightBlock(reqNode.GetID(), nextBlockHeight, nextBlockHash)
-			msg := msgpack.NewBlkDataReq(nextBlockHash)
+			msg := newBlkDataReq(reqNode, nextBlockHash)
 			err := this.server.Send(reqNode, msg)
 			if err != nil {
 				log.Warnf("[block-sync] syncBlock Height:%d ReqBlkData error:%s", nextBlockHeight, err)
@@ -623,6 +650,8 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID p2pComm.PeerId, blockSize uint32
 		t := (time.Now().UnixNano() - flightInfo.GetStartTime()) / int64(time.Millisecond)
 		s := float32(blockSize) / float32(t) * 1000.0 / 1024.0
 		this.addNewSpeed(fromID, s)
+	} else if !this.onRangeBlockReceive(fromID, blockSize, height, blockHash) {
+		return
 	}
 
 	this.delFlightBlock(blockHash)
@@ -638,6 +667,9 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID p2pComm.PeerId, blockSize uint32
 
 	this.addBlockCache(fromID, block, ccMsg, merkleRoot)
 	go this.saveBlock()
+	if this.isFastSync() && this.syncBlockRanges() {
+		return
+	}
 	this.syncBlock()
 }
 
@@ -761,7 +793,7 @@ func (this *BlockSyncMgr) saveBlock() {
 				return
 			}
 			this.addFlightBlock(reqNode.GetID(), nextBlockHeight, nextBlock.Hash())
-			msg := msgpack.NewBlkDataReq(nextBlock.Hash())
+			msg := newBlkDataReq(reqNode, nextBlock.Hash())
 			err := this.server.Send(reqNode, msg)
 			if err != nil {
 				log.Warn("[block-sync] require new block error:", err)
@@ -771,6 +803,7 @@ func (this *BlockSyncMgr) saveBlock() {
 			}
 			return
 		}
+		this.reputePeer(fromID, p2pComm.REPUTATION_VALID_BLOCK)
 		nextBlockHeight++
 		this.pingOutsyncNodes(nextBlockHeight - 1)
 	}
@@ -898,7 +931,7 @@ func (this *BlockSyncMgr) getNextNode(nextBlockHeight uint32) *peer.Peer {
 		}
 		triedNode[nextNodeId] = true
 		n := this.server.GetPeer(nextNodeId)
-		if n == nil {
+		if n == nil || !isReputable(n) {
 			continue
 		}
 		nodeBlockHeight := n.GetHeight()
@@ -970,6 +1003,27 @@ func (this *BlockSyncMgr) addErrorRespCnt(nodeId p2pComm.PeerId) {
 	if n != nil {
 		n.AddErrorRespCnt()
 	}
+	this.reputePeer(nodeId, p2pComm.REPUTATION_INVALID_DATA)
+}
+
+//newBlkDataReq return the block request, compact block is requested from the node supporting it
+func newBlkDataReq(node *peer.Peer, blockHash common.Uint256) msgTypes.Message {
+	if node.Info.CompactBlock {
+		return msgpack.NewCmpctBlkDataReq(blockHash)
+	}
+	return msgpack.NewBlkDataReq(blockHash)
+}
+
+//reputePeer update the reputation of a connected node
+func (this *BlockSyncMgr) reputePeer(nodeId p2pComm.PeerId, delta int64) {
+	if n := this.server.GetPeer(nodeId); n != nil {
+		n.Link.UpdateReputation(delta)
+	}
+}
+
+//isReputable return whether the node is trusted enough to sync blocks from
+func isReputable(n *peer.Peer) bool {
+	return n.Link.GetReputation() >= p2pComm.REPUTATION_SYNC_MIN_SCORE
 }
 
 //appendReqTime append a node's request time
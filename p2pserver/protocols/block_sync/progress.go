@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package block_sync
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const SYNC_SPEED_SAMPLE_CNT = 30 //Number of height samples for calc the block sync speed, one sample per second
+
+//SyncProgress is the snapshot of block sync progress
+type SyncProgress struct {
+	FastSync            bool    //Whether the fast sync mode is enabled
+	StartHeight         uint32  //Block height when sync started
+	CurrentBlockHeight  uint32  //Current block height
+	CurrentHeaderHeight uint32  //Current validated header height
+	TargetHeight        uint32  //Max height known from the header chain and peers
+	BlocksPerSecond     float64 //Average blocks saved per second of recent samples
+	ETA                 uint64  //Estimated seconds to reach the target height, 0 if synced or unknown
+}
+
+type heightSample struct {
+	height uint32
+	time   time.Time
+}
+
+//syncMeter calc the block sync speed with a sliding window of height samples
+type syncMeter struct {
+	lock        sync.Mutex
+	startHeight uint32
+	samples     []heightSample
+}
+
+func newSyncMeter(startHeight uint32, now time.Time) *syncMeter {
+	return &syncMeter{
+		startHeight: startHeight,
+		samples:     []heightSample{{height: startHeight, time: now}},
+	}
+}
+
+func (this *syncMeter) record(height uint32, now time.Time) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.samples = append(this.samples, heightSample{height: height, time: now})
+	if len(this.samples) > SYNC_SPEED_SAMPLE_CNT {
+		this.samples = this.samples[len(this.samples)-SYNC_SPEED_SAMPLE_CNT:]
+	}
+}
+
+//rate return the blocks per second over the samples
+func (this *syncMeter) rate() float64 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	first, last := this.samples[0], this.samples[len(this.samples)-1]
+	elapsed := last.time.Sub(first.time).Seconds()
+	if elapsed <= 0 || last.height <= first.height {
+		return 0
+	}
+	return float64(last.height-first.height) / elapsed
+}
+
+//estimateETA return the seconds to reach target height with the rate, 0 if synced or unknown
+func estimateETA(curHeight, targetHeight uint32, rate float64) uint64 {
+	if targetHeight <= curHeight || rate <= 0 {
+		return 0
+	}
+	return uint64(math.Ceil(float64(targetHeight-curHeight) / rate))
+}
+
+//GetSyncProgress return the current sync progress
+func (this *BlockSyncMgr) GetSyncProgress() SyncProgress {
+	curBlockHeight := this.ledger.GetCurrentBlockHeight()
+	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
+	target := curHeaderHeight
+	if maxPeerHeight := this.server.GetMaxPeerBlockHeight(); maxPeerHeight > uint64(target) {
+		target = uint32(maxPeerHeight)
+	}
+	rate := this.meter.rate()
+	return SyncProgress{
+		FastSync:            this.isFastSync(),
+		StartHeight:         this.meter.startHeight,
+		CurrentBlockHeight:  curBlockHeight,
+		CurrentHeaderHeight: curHeaderHeight,
+		TargetHeight:        target,
+		BlocksPerSecond:     rate,
+		ETA:                 estimateETA(curBlockHeight, target, rate),
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package block_sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncMeterRate(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	meter := newSyncMeter(100, now)
+	assert.Equal(t, float64(0), meter.rate())
+
+	for i := 1; i <= 10; i++ {
+		meter.record(uint32(100+i*20), now.Add(time.Duration(i)*time.Second))
+	}
+	assert.Equal(t, float64(20), meter.rate())
+
+	//only the recent samples are counted
+	for i := 11; i <= 10+SYNC_SPEED_SAMPLE_CNT; i++ {
+		meter.record(uint32(300+(i-10)*5), now.Add(time.Duration(i)*time.Second))
+	}
+	assert.Equal(t, float64(5), meter.rate())
+	assert.Equal(t, uint32(100), meter.startHeight)
+}
+
+func TestEstimateETA(t *testing.T) {
+	assert.Equal(t, uint64(0), estimateETA(100, 100, 10))
+	assert.Equal(t, uint64(0), estimateETA(100, 200, 0))
+	assert.Equal(t, uint64(10), estimateETA(100, 200, 10))
+	assert.Equal(t, uint64(34), estimateETA(100, 200, 3))
+}
@@ -0,0 +1,337 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package block_sync
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	p2pComm "github.com/ontio/ontology/p2pserver/common"
+	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
+	"github.com/ontio/ontology/p2pserver/peer"
+)
+
+const (
+	SYNC_BLOCK_RANGE_SIZE        = 64               //Number of blocks of a range request in fast sync mode
+	SYNC_MAX_FLIGHT_RANGE_SIZE   = 8                //Number of block ranges on flight in fast sync mode
+	SYNC_MAX_NODE_FLIGHT_RANGE   = 2                //Number of block ranges on flight of a single node
+	SYNC_RANGE_REQUEST_TIMEOUT   = 10 * time.Second //Request range timeout time. If range haven't received after SYNC_RANGE_REQUEST_TIMEOUT second, retry
+	SYNC_MAX_RANGE_TIMEOUT_TIMES = 3                //Max range timeout times without any block replied, if reaches, the node is treated as not serving range request
+)
+
+//SyncFlightRange record the info of a block range on flight, the Height of flight info is the start height of range
+type SyncFlightRange struct {
+	*SyncFlightInfo
+	Count    uint32 //Number of blocks in range
+	received uint32 //Number of blocks received from current node
+	recvSize uint32 //Bytes received from current node
+}
+
+//NewSyncFlightRange return a new SyncFlightRange instance
+func NewSyncFlightRange(startHeight, count uint32, nodeId p2pComm.PeerId) *SyncFlightRange {
+	return &SyncFlightRange{
+		SyncFlightInfo: NewSyncFlightInfo(startHeight, nodeId),
+		Count:          count,
+	}
+}
+
+//EndHeight return the last block height of range
+func (this *SyncFlightRange) EndHeight() uint32 {
+	return this.Height + this.Count - 1
+}
+
+func (this *SyncFlightRange) contains(height uint32) bool {
+	return height >= this.Height && height <= this.EndHeight()
+}
+
+//SetFastSync enable or disable the fast sync mode. In fast sync mode the bodies of validated headers are
+//downloaded by contiguous ranges from the fastest nodes in parallel, and executed while downloading.
+func (this *BlockSyncMgr) SetFastSync(enable bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.fastSync = enable
+}
+
+func (this *BlockSyncMgr) isFastSync() bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.fastSync
+}
+
+//syncBlockRanges request the missing blocks behind the validated header chain by ranges. Return false if no range
+//request is sent, such as the range nodes are behind or not reputable, and the caller should fall back to syncBlock
+func (this *BlockSyncMgr) syncBlockRanges() bool {
+	if this.tryGetSyncBlockLock() {
+		return true
+	}
+	defer this.releaseSyncBlockLock()
+
+	if !this.hasRangeNode() {
+		return false
+	}
+	curBlockHeight := this.ledger.GetCurrentBlockHeight()
+	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
+	maxHeight := curBlockHeight + SYNC_MAX_BLOCK_CACHE_SIZE
+	if maxHeight > curHeaderHeight {
+		maxHeight = curHeaderHeight
+	}
+	height := curBlockHeight + 1
+	sent := false
+	for height <= maxHeight && this.getFlightRangeCount() < SYNC_MAX_FLIGHT_RANGE_SIZE {
+		if this.isInBlockCache(height) {
+			height++
+			continue
+		}
+		if flightRange := this.getFlightRange(height); flightRange != nil {
+			height = flightRange.EndHeight() + 1
+			continue
+		}
+		count := maxHeight - height + 1
+		if count > SYNC_BLOCK_RANGE_SIZE {
+			count = SYNC_BLOCK_RANGE_SIZE
+		}
+		count = this.clipToFlightRanges(height, count)
+		reqNode := this.getRangeNode(height, nil)
+		if reqNode == nil {
+			break
+		}
+		if nodeHeight := uint32(reqNode.GetHeight()); nodeHeight < height+count-1 {
+			count = nodeHeight - height + 1
+		}
+		this.addFlightRange(reqNode.GetID(), height, count)
+		if !this.sendRangeReq(reqNode, height, count) {
+			this.delFlightRange(height)
+			break
+		}
+		sent = true
+		height += count
+	}
+	return sent
+}
+
+func (this *BlockSyncMgr) sendRangeReq(reqNode *peer.Peer, startHeight, count uint32) bool {
+	msg := msgpack.NewBlocksRangeReq(startHeight, count)
+	err := this.server.Send(reqNode, msg)
+	if err != nil {
+		log.Warnf("[block-sync] syncBlockRanges Height:%d-%d ReqBlkRange error:%s", startHeight, startHeight+count-1, err)
+		return false
+	}
+	this.appendReqTime(reqNode.GetID())
+	log.Debugf("[block-sync] block range request height:%d-%d node:%d", startHeight, startHeight+count-1,
+		reqNode.GetID())
+	return true
+}
+
+//onRangeBlockReceive account the block replied to a range request. Return false if the block mismatch the
+//validated header chain and should be dropped
+func (this *BlockSyncMgr) onRangeBlockReceive(fromID p2pComm.PeerId, blockSize uint32, height uint32,
+	blockHash common.Uint256) bool {
+	this.lock.Lock()
+	var flightRange *SyncFlightRange
+	for _, r := range this.flightRanges {
+		if r.contains(height) && r.GetNodeId() == fromID {
+			flightRange = r
+			break
+		}
+	}
+	if flightRange == nil {
+		this.lock.Unlock()
+		return true
+	}
+	flightRange.received++
+	flightRange.recvSize += blockSize
+	done := flightRange.received >= flightRange.Count
+	if done {
+		delete(this.flightRanges, flightRange.Height)
+	}
+	recvSize := flightRange.recvSize
+	this.lock.Unlock()
+
+	n := this.getNodeWeight(fromID)
+	if n != nil {
+		n.ResetRangeMissCnt()
+	}
+	if done {
+		t := (time.Now().UnixNano() - flightRange.GetStartTime()) / int64(time.Millisecond)
+		if t <= 0 {
+			t = 1
+		}
+		s := float32(recvSize) / float32(t) * 1000.0 / 1024.0
+		this.addNewSpeed(fromID, s)
+	}
+	if this.ledger.GetBlockHash(height) != blockHash {
+		log.Warnf("[block-sync] range block Height:%d from node:%s mismatch header", height, fromID.ToHexString())
+		this.addErrorRespCnt(fromID)
+		if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
+			this.delNode(fromID)
+		}
+		return false
+	}
+	return true
+}
+
+func (this *BlockSyncMgr) checkRangeTimeout(now int64, curBlockHeight uint32) {
+	timeoutRanges := make([]*SyncFlightRange, 0)
+	this.lock.RLock()
+	for _, flightRange := range this.flightRanges {
+		if timeDiff(now, flightRange.GetStartTime()) >= SYNC_RANGE_REQUEST_TIMEOUT {
+			timeoutRanges = append(timeoutRanges, flightRange)
+		}
+	}
+	this.lock.RUnlock()
+
+	for _, flightRange := range timeoutRanges {
+		nodeId := flightRange.GetNodeId()
+		this.addTimeoutCnt(nodeId)
+		if this.getRangeReceived(flightRange) == 0 {
+			if n := this.getNodeWeight(nodeId); n != nil {
+				n.AddRangeMissCnt()
+			}
+		}
+		if flightRange.EndHeight() <= curBlockHeight {
+			this.delFlightRange(flightRange.Height)
+			continue
+		}
+		flightRange.MarkFailedNode()
+		log.Tracef("[block-sync] checkTimeout sync range:%d-%d timeout after:%d s times:%d", flightRange.Height,
+			flightRange.EndHeight(), SYNC_RANGE_REQUEST_TIMEOUT/time.Second, flightRange.GetTotalFailedTimes())
+		reqNode := this.getRangeNode(flightRange.Height, flightRange.SyncFlightInfo)
+		if reqNode == nil {
+			//leave the blocks to syncBlock
+			this.delFlightRange(flightRange.Height)
+			continue
+		}
+		this.resetFlightRange(flightRange, reqNode.GetID())
+		this.sendRangeReq(reqNode, flightRange.Height, flightRange.Count)
+	}
+}
+
+//getRangeNode return the node to request range from, prefer the node with less failed times, less ranges on
+//flight and higher weight
+func (this *BlockSyncMgr) getRangeNode(startHeight uint32, flightInfo *SyncFlightInfo) *peer.Peer {
+	weights := this.getAllNodeWeights()
+	sort.Sort(sort.Reverse(weights))
+	loads := this.getNodeFlightRangeCount()
+	var reqNode *peer.Peer
+	minFailed, minLoad := 0, 0
+	for _, w := range weights {
+		if !w.SupportRangeReq() {
+			continue
+		}
+		n := this.server.GetPeer(w.id)
+		if n == nil || uint32(n.GetHeight()) < startHeight || !isReputable(n) {
+			continue
+		}
+		load := loads[w.id]
+		if load >= SYNC_MAX_NODE_FLIGHT_RANGE {
+			continue
+		}
+		failed := 0
+		if flightInfo != nil {
+			failed = flightInfo.GetFailedTimes(w.id)
+		}
+		if reqNode == nil || failed < minFailed || (failed == minFailed && load < minLoad) {
+			reqNode = n
+			minFailed, minLoad = failed, load
+		}
+	}
+	return reqNode
+}
+
+func (this *BlockSyncMgr) hasRangeNode() bool {
+	for _, w := range this.getAllNodeWeights() {
+		if w.SupportRangeReq() && this.server.GetPeer(w.id) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *BlockSyncMgr) addFlightRange(nodeId p2pComm.PeerId, startHeight, count uint32) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.flightRanges[startHeight] = NewSyncFlightRange(startHeight, count, nodeId)
+}
+
+func (this *BlockSyncMgr) resetFlightRange(flightRange *SyncFlightRange, nodeId p2pComm.PeerId) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	flightRange.SetNodeId(nodeId)
+	flightRange.ResetStartTime()
+	flightRange.received = 0
+	flightRange.recvSize = 0
+}
+
+func (this *BlockSyncMgr) getRangeReceived(flightRange *SyncFlightRange) uint32 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return flightRange.received
+}
+
+//getFlightRange return the range on flight which contains the height
+func (this *BlockSyncMgr) getFlightRange(height uint32) *SyncFlightRange {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	for _, flightRange := range this.flightRanges {
+		if flightRange.contains(height) {
+			return flightRange
+		}
+	}
+	return nil
+}
+
+//clipToFlightRanges clip the count of a new range to avoid overlapping with the ranges on flight
+func (this *BlockSyncMgr) clipToFlightRanges(startHeight, count uint32) uint32 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	for start := range this.flightRanges {
+		if start > startHeight && start-startHeight < count {
+			count = start - startHeight
+		}
+	}
+	return count
+}
+
+func (this *BlockSyncMgr) delFlightRange(startHeight uint32) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.flightRanges, startHeight)
+}
+
+func (this *BlockSyncMgr) getFlightRangeCount() int {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return len(this.flightRanges)
+}
+
+func (this *BlockSyncMgr) getNodeFlightRangeCount() map[p2pComm.PeerId]int {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	loads := make(map[p2pComm.PeerId]int)
+	for _, flightRange := range this.flightRanges {
+		loads[flightRange.GetNodeId()]++
+	}
+	return loads
+}
+
+func (this *BlockSyncMgr) isRangeOnFlight(height uint32) bool {
+	return this.getFlightRange(height) != nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package block_sync
+
+import (
+	"testing"
+
+	p2pComm "github.com/ontio/ontology/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRangeSyncMgr() *BlockSyncMgr {
+	return &BlockSyncMgr{
+		flightRanges: make(map[uint32]*SyncFlightRange),
+		nodeWeights:  make(map[p2pComm.PeerId]*NodeWeight),
+	}
+}
+
+func TestFlightRanges(t *testing.T) {
+	mgr := newTestRangeSyncMgr()
+	node1 := p2pComm.PseudoPeerIdFromUint64(1)
+	node2 := p2pComm.PseudoPeerIdFromUint64(2)
+	mgr.addFlightRange(node1, 101, SYNC_BLOCK_RANGE_SIZE)
+	mgr.addFlightRange(node2, 200, 10)
+
+	assert.Equal(t, 2, mgr.getFlightRangeCount())
+	assert.True(t, mgr.isRangeOnFlight(101))
+	assert.True(t, mgr.isRangeOnFlight(164))
+	assert.False(t, mgr.isRangeOnFlight(165))
+	assert.Equal(t, uint32(209), mgr.getFlightRange(205).EndHeight())
+
+	//new range must stop before the next range on flight
+	assert.Equal(t, uint32(35), mgr.clipToFlightRanges(165, SYNC_BLOCK_RANGE_SIZE))
+	assert.Equal(t, uint32(SYNC_BLOCK_RANGE_SIZE), mgr.clipToFlightRanges(210, SYNC_BLOCK_RANGE_SIZE))
+
+	mgr.addFlightRange(node1, 210, SYNC_BLOCK_RANGE_SIZE)
+	loads := mgr.getNodeFlightRangeCount()
+	assert.Equal(t, 2, loads[node1])
+	assert.Equal(t, 1, loads[node2])
+
+	mgr.delFlightRange(101)
+	assert.False(t, mgr.isRangeOnFlight(101))
+	assert.Equal(t, 2, mgr.getFlightRangeCount())
+}
+
+func TestNodeWeightRangeSupport(t *testing.T) {
+	w := NewNodeWeight(p2pComm.PseudoPeerIdFromUint64(1))
+	for i := 0; i < SYNC_MAX_RANGE_TIMEOUT_TIMES-1; i++ {
+		w.AddRangeMissCnt()
+	}
+	assert.True(t, w.SupportRangeReq())
+	w.AddRangeMissCnt()
+	assert.False(t, w.SupportRangeReq())
+	w.ResetRangeMissCnt()
+	assert.True(t, w.SupportRangeReq())
+}
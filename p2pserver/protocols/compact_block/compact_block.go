@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package compact_block
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/core/types"
+	p2pComm "github.com/ontio/ontology/p2pserver/common"
+	msgpack "github.com/ontio/ontology/p2pserver/message/msg_pack"
+	msgTypes "github.com/ontio/ontology/p2pserver/message/types"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+)
+
+//TxPool provide the transactions to rebuild compact block
+type TxPool interface {
+	GetTransaction(hash common.Uint256) *types.Transaction
+	GetTxList() []common.Uint256
+}
+
+//BlockStore provide the blocks to serve missing transactions request
+type BlockStore interface {
+	GetBlockByHash(hash common.Uint256) (*types.Block, error)
+}
+
+//pendingBlock is a compact block waiting for the missing transactions
+type pendingBlock struct {
+	sender  p2pComm.PeerId
+	cmpct   *msgTypes.CompactBlock
+	txs     []*types.Transaction
+	missing []uint32
+	time    time.Time
+}
+
+//CompactBlockRelay rebuild compact blocks from tx pool and request the missing transactions from sender
+type CompactBlockRelay struct {
+	lock    sync.Mutex
+	txPool  TxPool
+	store   BlockStore
+	pending map[common.Uint256]*pendingBlock
+}
+
+func NewCompactBlockRelay(txPool TxPool, store BlockStore) *CompactBlockRelay {
+	return &CompactBlockRelay{
+		txPool:  txPool,
+		store:   store,
+		pending: make(map[common.Uint256]*pendingBlock),
+	}
+}
+
+//OnCompactBlock rebuild the block from tx pool, return nil if the block is waiting for missing transactions
+func (self *CompactBlockRelay) OnCompactBlock(ctx *p2p.Context, cmpct *msgTypes.CompactBlock) *msgTypes.Block {
+	sender := ctx.Sender()
+	blockHash := cmpct.Header.Hash()
+	txs, missing := self.rebuild(cmpct)
+	if len(missing) == 0 {
+		return self.complete(ctx, blockHash, cmpct, txs)
+	}
+
+	self.lock.Lock()
+	if _, ok := self.pending[blockHash]; ok {
+		self.lock.Unlock()
+		return nil
+	}
+	self.addPendingLocked(blockHash, &pendingBlock{
+		sender:  sender.GetID(),
+		cmpct:   cmpct,
+		txs:     txs,
+		missing: missing,
+		time:    time.Now(),
+	})
+	self.lock.Unlock()
+
+	log.Debugf("[p2p]compact block %s missing %d of %d txs", blockHash.ToHexString(), len(missing), len(txs))
+	if err := sender.Send(msgpack.NewGetBlockTxn(blockHash, missing)); err != nil {
+		log.Warn(err)
+	}
+	return nil
+}
+
+//OnBlockTxn fill the missing transactions of pending compact block, return nil if the block is not complete
+func (self *CompactBlockRelay) OnBlockTxn(ctx *p2p.Context, blkTxn *msgTypes.BlockTxn) *msgTypes.Block {
+	self.lock.Lock()
+	pending, ok := self.pending[blkTxn.BlockHash]
+	if !ok || pending.sender != ctx.Sender().GetID() {
+		self.lock.Unlock()
+		log.Debugf("[p2p]receive unexpected block txn %s", blkTxn.BlockHash.ToHexString())
+		return nil
+	}
+	delete(self.pending, blkTxn.BlockHash)
+	self.lock.Unlock()
+
+	if len(blkTxn.Txs) != len(pending.missing) {
+		log.Warnf("[p2p]block txn %s count mismatch, expect %d got %d", blkTxn.BlockHash.ToHexString(),
+			len(pending.missing), len(blkTxn.Txs))
+		requestFullBlock(ctx, blkTxn.BlockHash)
+		return nil
+	}
+	for i, index := range pending.missing {
+		pending.txs[index] = blkTxn.Txs[i]
+	}
+	return self.complete(ctx, blkTxn.BlockHash, pending.cmpct, pending.txs)
+}
+
+//OnGetBlockTxn response the requested transactions of block
+func (self *CompactBlockRelay) OnGetBlockTxn(ctx *p2p.Context, req *msgTypes.GetBlockTxn) {
+	remotePeer := ctx.Sender()
+	block, err := self.store.GetBlockByHash(req.BlockHash)
+	if err != nil || block == nil {
+		log.Debug("[p2p]can't get block by hash: ", req.BlockHash, " ,send not found message")
+		if err := remotePeer.Send(msgpack.NewNotFound(req.BlockHash)); err != nil {
+			log.Warn(err)
+		}
+		return
+	}
+	txs := make([]*types.Transaction, 0, len(req.Indexes))
+	for _, index := range req.Indexes {
+		if int(index) >= len(block.Transactions) {
+			log.Debugf("[p2p]block txn request index %d out of range", index)
+			return
+		}
+		txs = append(txs, block.Transactions[index])
+	}
+	if err := remotePeer.Send(msgpack.NewBlockTxn(req.BlockHash, txs)); err != nil {
+		log.Warn(err)
+	}
+}
+
+//PendingCount return the count of compact blocks waiting for missing transactions
+func (self *CompactBlockRelay) PendingCount() int {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return len(self.pending)
+}
+
+//rebuild fill the transactions of block from prefilled and tx pool, return the indexes of missing transactions
+func (self *CompactBlockRelay) rebuild(cmpct *msgTypes.CompactBlock) ([]*types.Transaction, []uint32) {
+	txs := make([]*types.Transaction, cmpct.TxCount())
+	for _, prefilled := range cmpct.Prefilled {
+		txs[prefilled.Index] = prefilled.Tx
+	}
+	var poolTxs map[uint64]*types.Transaction
+	if len(cmpct.ShortIds) != 0 {
+		poolTxs = self.indexPool(cmpct.Nonce)
+	}
+	var missing []uint32
+	next := 0
+	for i := range txs {
+		if txs[i] != nil {
+			continue
+		}
+		if tx := poolTxs[cmpct.ShortIds[next]]; tx != nil {
+			txs[i] = tx
+		} else {
+			missing = append(missing, uint32(i))
+		}
+		next++
+	}
+	return txs, missing
+}
+
+//indexPool map the short ids of tx pool transactions, ambiguous short ids are mapped to nil
+func (self *CompactBlockRelay) indexPool(nonce uint64) map[uint64]*types.Transaction {
+	hashes := self.txPool.GetTxList()
+	index := make(map[uint64]*types.Transaction, len(hashes))
+	for _, hash := range hashes {
+		id := msgTypes.ShortTxId(nonce, hash)
+		if _, ok := index[id]; ok {
+			index[id] = nil
+			continue
+		}
+		index[id] = self.txPool.GetTransaction(hash)
+	}
+	return index
+}
+
+//complete check the transactions root of rebuilt block, the full block is requested if mismatched
+func (self *CompactBlockRelay) complete(ctx *p2p.Context, blockHash common.Uint256, cmpct *msgTypes.CompactBlock,
+	txs []*types.Transaction) *msgTypes.Block {
+	hashes := make([]common.Uint256, 0, len(txs))
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash())
+	}
+	if common.ComputeMerkleRoot(hashes) != cmpct.Header.TransactionsRoot {
+		log.Warnf("[p2p]rebuilt compact block %s transactions root mismatch", blockHash.ToHexString())
+		requestFullBlock(ctx, blockHash)
+		return nil
+	}
+	return &msgTypes.Block{
+		Blk:        &types.Block{Header: cmpct.Header, Transactions: txs},
+		MerkleRoot: cmpct.MerkleRoot,
+		CCMsg:      cmpct.CCMsg,
+	}
+}
+
+func (self *CompactBlockRelay) addPendingLocked(blockHash common.Uint256, pending *pendingBlock) {
+	var oldestHash common.Uint256
+	var oldest *pendingBlock
+	for hash, p := range self.pending {
+		if pending.time.Sub(p.time) > p2pComm.CMPCT_BLK_PENDING_TIMEOUT*time.Second {
+			delete(self.pending, hash)
+			continue
+		}
+		if oldest == nil || p.time.Before(oldest.time) {
+			oldestHash, oldest = hash, p
+		}
+	}
+	if oldest != nil && len(self.pending) >= p2pComm.MAX_PENDING_CMPCT_BLK_CNT {
+		delete(self.pending, oldestHash)
+	}
+	self.pending[blockHash] = pending
+}
+
+func requestFullBlock(ctx *p2p.Context, blockHash common.Uint256) {
+	if err := ctx.Sender().Send(msgpack.NewBlkDataReq(blockHash)); err != nil {
+		log.Warn(err)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package compact_block
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/core/payload"
+	"github.com/ontio/ontology/core/types"
+	p2pComm "github.com/ontio/ontology/p2pserver/common"
+	msgTypes "github.com/ontio/ontology/p2pserver/message/types"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/ontio/ontology/p2pserver/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTxPool map[common.Uint256]*types.Transaction
+
+func (self mockTxPool) GetTransaction(hash common.Uint256) *types.Transaction {
+	return self[hash]
+}
+
+func (self mockTxPool) GetTxList() []common.Uint256 {
+	var hashes []common.Uint256
+	for hash := range self {
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+type mockStore map[common.Uint256]*types.Block
+
+func (self mockStore) GetBlockByHash(hash common.Uint256) (*types.Block, error) {
+	if block, ok := self[hash]; ok {
+		return block, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func newTestBlock(t *testing.T, n int) *types.Block {
+	block := &types.Block{Header: &types.Header{Height: 100}}
+	for i := 0; i < n; i++ {
+		mutable := &types.MutableTransaction{
+			TxType:  types.InvokeNeo,
+			Nonce:   uint32(i),
+			Payload: &payload.InvokeCode{Code: []byte{byte(i)}},
+		}
+		tx, err := mutable.IntoImmutable()
+		assert.Nil(t, err)
+		block.Transactions = append(block.Transactions, tx)
+	}
+	block.RebuildMerkleRoot()
+	return block
+}
+
+//newTestContext return the context of a peer, the messages sent to the peer are delivered to the channel
+func newTestContext(t *testing.T) (*p2p.Context, chan msgTypes.Message) {
+	local, remote := net.Pipe()
+	info := &peer.PeerInfo{Id: p2pComm.PseudoPeerIdFromUint64(1), CompactBlock: true}
+	sender := peer.NewPeer(info, local, nil)
+	msgs := make(chan msgTypes.Message, 10)
+	go func() {
+		reader := bufio.NewReader(remote)
+		for {
+			msg, _, err := msgTypes.ReadMessage(reader)
+			if err != nil {
+				return
+			}
+			msgs <- msg
+		}
+	}()
+	return p2p.NewContext(sender, nil, 0), msgs
+}
+
+func nextMsg(t *testing.T, msgs chan msgTypes.Message) msgTypes.Message {
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message sent to peer")
+		return nil
+	}
+}
+
+func assertSameBlock(t *testing.T, expect *types.Block, got *msgTypes.Block) {
+	assert.NotNil(t, got)
+	assert.Equal(t, got.Blk.Hash(), expect.Hash())
+	assert.Equal(t, len(got.Blk.Transactions), len(expect.Transactions))
+	for i, tx := range expect.Transactions {
+		assert.Equal(t, got.Blk.Transactions[i].Hash(), tx.Hash())
+	}
+}
+
+func TestRebuildFromPool(t *testing.T) {
+	block := newTestBlock(t, 10)
+	pool := mockTxPool{}
+	for _, tx := range block.Transactions {
+		pool[tx.Hash()] = tx
+	}
+	relay := NewCompactBlockRelay(pool, mockStore{})
+	ctx, _ := newTestContext(t)
+	defer ctx.Sender().Close()
+
+	cmpct := msgTypes.NewCompactBlock(block, nil, common.Uint256{1}, 7)
+	got := relay.OnCompactBlock(ctx, cmpct)
+	assertSameBlock(t, block, got)
+	assert.Equal(t, got.MerkleRoot, common.Uint256{1})
+	assert.Equal(t, relay.PendingCount(), 0)
+}
+
+func TestRebuildWithMissingTxs(t *testing.T) {
+	block := newTestBlock(t, 10)
+	pool := mockTxPool{}
+	for i, tx := range block.Transactions {
+		if i != 2 && i != 7 {
+			pool[tx.Hash()] = tx
+		}
+	}
+	relay := NewCompactBlockRelay(pool, mockStore{})
+	ctx, msgs := newTestContext(t)
+	defer ctx.Sender().Close()
+
+	cmpct := msgTypes.NewCompactBlock(block, nil, common.UINT256_EMPTY, 7)
+	assert.Nil(t, relay.OnCompactBlock(ctx, cmpct))
+	assert.Equal(t, relay.PendingCount(), 1)
+
+	req := nextMsg(t, msgs).(*msgTypes.GetBlockTxn)
+	assert.Equal(t, req.BlockHash, block.Hash())
+	assert.Equal(t, req.Indexes, []uint32{2, 7})
+
+	// a duplicated compact block does not trigger another request
+	assert.Nil(t, relay.OnCompactBlock(ctx, cmpct))
+
+	blkTxn := &msgTypes.BlockTxn{
+		BlockHash: block.Hash(),
+		Txs:       []*types.Transaction{block.Transactions[2], block.Transactions[7]},
+	}
+	assertSameBlock(t, block, relay.OnBlockTxn(ctx, blkTxn))
+	assert.Equal(t, relay.PendingCount(), 0)
+
+	// unexpected block txn is ignored
+	assert.Nil(t, relay.OnBlockTxn(ctx, blkTxn))
+}
+
+func TestRebuildMismatchRequestFullBlock(t *testing.T) {
+	block := newTestBlock(t, 3)
+	relay := NewCompactBlockRelay(mockTxPool{}, mockStore{})
+	ctx, msgs := newTestContext(t)
+	defer ctx.Sender().Close()
+
+	assert.Nil(t, relay.OnCompactBlock(ctx, msgTypes.NewCompactBlock(block, nil, common.UINT256_EMPTY, 7)))
+	_ = nextMsg(t, msgs).(*msgTypes.GetBlockTxn)
+
+	other := newTestBlock(t, 4)
+	blkTxn := &msgTypes.BlockTxn{
+		BlockHash: block.Hash(),
+		Txs:       other.Transactions[1:],
+	}
+	assert.Nil(t, relay.OnBlockTxn(ctx, blkTxn))
+	req := nextMsg(t, msgs).(*msgTypes.DataReq)
+	assert.Equal(t, req.DataType, common.BLOCK)
+	assert.Equal(t, req.Hash, block.Hash())
+}
+
+func TestServeBlockTxn(t *testing.T) {
+	block := newTestBlock(t, 5)
+	relay := NewCompactBlockRelay(mockTxPool{}, mockStore{block.Hash(): block})
+	ctx, msgs := newTestContext(t)
+	defer ctx.Sender().Close()
+
+	relay.OnGetBlockTxn(ctx, &msgTypes.GetBlockTxn{BlockHash: block.Hash(), Indexes: []uint32{1, 4}})
+	resp := nextMsg(t, msgs).(*msgTypes.BlockTxn)
+	assert.Equal(t, resp.BlockHash, block.Hash())
+	assert.Equal(t, len(resp.Txs), 2)
+	assert.Equal(t, resp.Txs[0].Hash(), block.Transactions[1].Hash())
+	assert.Equal(t, resp.Txs[1].Hash(), block.Transactions[4].Hash())
+
+	relay.OnGetBlockTxn(ctx, &msgTypes.GetBlockTxn{BlockHash: common.Uint256{1}, Indexes: []uint32{0}})
+	notFound := nextMsg(t, msgs).(*msgTypes.NotFound)
+	assert.Equal(t, notFound.Hash, common.Uint256{1})
+}
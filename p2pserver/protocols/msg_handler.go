@@ -36,6 +36,7 @@ import (
 	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
 	"github.com/ontio/ontology/p2pserver/protocols/block_sync"
 	"github.com/ontio/ontology/p2pserver/protocols/bootstrap"
+	"github.com/ontio/ontology/p2pserver/protocols/compact_block"
 	"github.com/ontio/ontology/p2pserver/protocols/discovery"
 	"github.com/ontio/ontology/p2pserver/protocols/heatbeat"
 	"github.com/ontio/ontology/p2pserver/protocols/recent_peers"
@@ -55,6 +56,7 @@ var txCache, _ = lru.NewARC(msgCommon.MAX_TX_CACHE_SIZE)
 type MsgHandler struct {
 	seeds                    *utils.HostsResolver
 	blockSync                *block_sync.BlockSyncMgr
+	compactBlock             *compact_block.CompactBlockRelay
 	reconnect                *reconnect.ReconnectService
 	discovery                *discovery.Discovery
 	heatBeat                 *heatbeat.HeartBeat
@@ -76,10 +78,11 @@ func NewMsgHandler(acct *account.Account, staticReserveFilter p2p.AddressFilter,
 		panic(fmt.Errorf("invalid seed list； %v", invalid))
 	}
 	subNet := subnet.NewSubNet(acct, seeds, gov, logger)
-	return &MsgHandler{ledger: ld, seeds: seeds, subnet: subNet, acct: acct, txPoolService: txPool, staticReserveFilter: staticReserveFilter}
+	return &MsgHandler{ledger: ld, seeds: seeds, subnet: subNet, acct: acct, txPoolService: txPool, staticReserveFilter: staticReserveFilter,
+		compactBlock: compact_block.NewCompactBlockRelay(txPool, ld)}
 }
 
-func (self *MsgHandler) GetReservedAddrFilter(staticFilterEnabled bool) p2p.AddressFilter {
+func (self *MsgHandler) GetReservedAddrFilter(staticFilterEnabled func() bool) p2p.AddressFilter {
 	return self.subnet.GetReservedAddrFilter(staticFilterEnabled)
 }
 
@@ -91,8 +94,17 @@ func (self *MsgHandler) GetSubnetMembersInfo() []msgCommon.SubnetMemberInfo {
 	return self.subnet.GetMembersInfo()
 }
 
+//GetSyncProgress return the block sync progress, the zero value is returned before the network started
+func (self *MsgHandler) GetSyncProgress() block_sync.SyncProgress {
+	if self.blockSync == nil {
+		return block_sync.SyncProgress{}
+	}
+	return self.blockSync.GetSyncProgress()
+}
+
 func (self *MsgHandler) start(net p2p.P2P) {
 	self.blockSync = block_sync.NewBlockSyncMgr(net, self.ledger)
+	self.blockSync.SetFastSync(config.DefConfig.P2PNode.FastSync)
 	self.reconnect = reconnect.NewReconectService(net, self.staticReserveFilter)
 	maskFilter := self.subnet.GetMaskAddrFilter()
 	self.discovery = discovery.NewDiscovery(net, config.DefConfig.P2PNode.ReservedCfg.MaskPeers, maskFilter, 0)
@@ -164,6 +176,16 @@ func (self *MsgHandler) HandlePeerMessage(ctx *p2p.Context, msg msgTypes.Message
 		self.blockSync.OnHeaderReceive(ctx.Sender().GetID(), m.BlkHdr)
 	case *msgTypes.Block:
 		self.blockHandle(ctx, m)
+	case *msgTypes.CompactBlock:
+		if block := self.compactBlock.OnCompactBlock(ctx, m); block != nil {
+			self.blockHandle(ctx, block)
+		}
+	case *msgTypes.BlockTxn:
+		if block := self.compactBlock.OnBlockTxn(ctx, m); block != nil {
+			self.blockHandle(ctx, block)
+		}
+	case *msgTypes.GetBlockTxn:
+		self.compactBlock.OnGetBlockTxn(ctx, m)
 	case *msgTypes.Consensus:
 		ConsensusHandle(ctx, m)
 	case *msgTypes.Trn:
@@ -172,6 +194,8 @@ func (self *MsgHandler) HandlePeerMessage(ctx *p2p.Context, msg msgTypes.Message
 		self.discovery.AddrHandle(ctx, m)
 	case *msgTypes.DataReq:
 		DataReqHandle(ctx, m)
+	case *msgTypes.BlocksRangeReq:
+		BlocksRangeReqHandle(ctx, m)
 	case *msgTypes.Inv:
 		InvHandle(ctx, m)
 	case *msgTypes.SubnetMembersRequest:
@@ -252,7 +276,7 @@ func DataReqHandle(ctx *p2p.Context, dataReq *msgTypes.DataReq) {
 	reqType := common.InventoryType(dataReq.DataType)
 	hash := dataReq.Hash
 	switch reqType {
-	case common.BLOCK:
+	case common.BLOCK, common.CMPCT_BLOCK:
 		reqID := fmt.Sprintf("%x%s", reqType, hash.ToHexString())
 		data := getRespCacheValue(reqID)
 		var msg msgTypes.Message
@@ -260,6 +284,8 @@ func DataReqHandle(ctx *p2p.Context, dataReq *msgTypes.DataReq) {
 			switch data.(type) {
 			case *msgTypes.Block:
 				msg = data.(*msgTypes.Block)
+			case *msgTypes.CompactBlock:
+				msg = data.(*msgTypes.CompactBlock)
 			}
 		}
 		if msg == nil {
@@ -299,7 +325,11 @@ func DataReqHandle(ctx *p2p.Context, dataReq *msgTypes.DataReq) {
 				}
 				return
 			}
-			msg = msgpack.NewBlock(block, ccMsg, merkleRoot)
+			if reqType == common.CMPCT_BLOCK {
+				msg = msgpack.NewCompactBlock(block, ccMsg, merkleRoot)
+			} else {
+				msg = msgpack.NewBlock(block, ccMsg, merkleRoot)
+			}
 			saveRespCache(reqID, msg)
 		}
 		err := remotePeer.Send(msg)
@@ -329,6 +359,42 @@ func DataReqHandle(ctx *p2p.Context, dataReq *msgTypes.DataReq) {
 	}
 }
 
+// BlocksRangeReqHandle handles the block range req from peer, every block of the range is replied with a block message
+func BlocksRangeReqHandle(ctx *p2p.Context, req *msgTypes.BlocksRangeReq) {
+	remotePeer := ctx.Sender()
+	startHeight := req.StartHeight
+	if startHeight == 0 {
+		startHeight = 1
+	}
+	count := req.Count
+	if count > msgCommon.MAX_BLK_RANGE_CNT {
+		count = msgCommon.MAX_BLK_RANGE_CNT
+	}
+	curHeight := ledger.DefLedger.GetCurrentBlockHeight()
+	for height := startHeight; height < startHeight+count && height <= curHeight; height++ {
+		block, err := ledger.DefLedger.GetBlockByHeight(height)
+		if err != nil || block == nil || block.Header == nil {
+			log.Debugf("[p2p]can't get block by height %d, err %v", height, err)
+			return
+		}
+		ccMsg, err := ledger.DefLedger.GetCrossChainMsg(height - 1)
+		if err != nil {
+			log.Debugf("[p2p]failed to get cross chain message at height %v, err %v", height-1, err)
+			return
+		}
+		merkleRoot, err := ledger.DefLedger.GetStateMerkleRoot(height)
+		if err != nil {
+			log.Debugf("[p2p]failed to get state merkel root at height %v, err %v", height, err)
+			return
+		}
+		err = remotePeer.Send(msgpack.NewBlock(block, ccMsg, merkleRoot))
+		if err != nil {
+			log.Warn(err)
+			return
+		}
+	}
+}
+
 // InvHandle handles the inventory message(block,
 // transaction and consensus) from peer.
 func InvHandle(ctx *p2p.Context, inv *msgTypes.Inv) {
@@ -372,6 +438,9 @@ func InvHandle(ctx *p2p.Context, inv *msgTypes.Inv) {
 				// send the block request
 				log.Infof("[p2p]inv request block hash: %x", id)
 				msg := msgpack.NewBlkDataReq(id)
+				if remotePeer.Info.CompactBlock {
+					msg = msgpack.NewCmpctBlkDataReq(id)
+				}
 				err = remotePeer.Send(msg)
 				if err != nil {
 					log.Warn(err)
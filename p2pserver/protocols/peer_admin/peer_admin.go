@@ -0,0 +1,379 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer_admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	common2 "github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/log"
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/connect_controller"
+	"github.com/ontio/ontology/p2pserver/link"
+	p2p "github.com/ontio/ontology/p2pserver/net/protocol"
+	"github.com/ontio/ontology/p2pserver/peer"
+)
+
+//Network is the net layer managed by peer admin
+type Network interface {
+	Connect(addr string)
+	GetNeighbors() []*peer.Peer
+	ConnectController() *connect_controller.ConnectController
+}
+
+//PeerAdminInfo is the detail of a connected peer
+type PeerAdminInfo struct {
+	Id         string                     `json:"id"`
+	Addr       string                     `json:"addr"`
+	ListenAddr string                     `json:"listenAddr"`
+	Height     uint64                     `json:"height"`
+	LatencyMs  int64                      `json:"latencyMs"` // zero if not measured
+	Version    string                     `json:"version"`
+	Subnet     bool                       `json:"subnet"` // peer is a member of the gov node subnet
+	Reputation int64                      `json:"reputation"`
+	RecvBytes  uint64                     `json:"recvBytes"`
+	SendBytes  uint64                     `json:"sendBytes"`
+	Traffic    map[string]link.MsgCounter `json:"traffic"` // Map message type => traffic counter
+}
+
+//adminState is the state persisted to survive restart
+type adminState struct {
+	Peers    []string   `json:"peers"`              // peers kept connected
+	Bans     []banState `json:"bans"`               // bans issued by admin
+	Reserved *[]string  `json:"reserved,omitempty"` // reserved peers overriding the config if not nil
+}
+
+type banState struct {
+	Ip     string `json:"ip,omitempty"`
+	Id     string `json:"id,omitempty"`
+	Expire int64  `json:"expire"` // unix time in seconds
+}
+
+//PeerAdmin manage the peers at runtime, the changes are persisted to state file
+type PeerAdmin struct {
+	lock        sync.Mutex
+	net         Network
+	subnet      p2p.AddressFilter
+	reserved    []*connect_controller.StaticReserveFilter
+	cancelRetry func(listenAddr string)
+	stateFile   string
+	state       adminState
+	quit        chan bool
+}
+
+//NewPeerAdmin return the peer admin, subnet filter tells whether a listen address is a subnet member,
+//cancelRetry stops reconnecting removed peers, and the reserved filters are updated by SetReservedPeers
+func NewPeerAdmin(net Network, subnet p2p.AddressFilter, cancelRetry func(listenAddr string), stateFile string,
+	reserved ...*connect_controller.StaticReserveFilter) *PeerAdmin {
+	return &PeerAdmin{
+		net:         net,
+		subnet:      subnet,
+		reserved:    reserved,
+		cancelRetry: cancelRetry,
+		stateFile:   stateFile,
+		quit:        make(chan bool),
+	}
+}
+
+//Start restore the persisted state and keep the added peers connected
+func (self *PeerAdmin) Start() {
+	self.loadState()
+	self.lock.Lock()
+	state := self.state
+	self.lock.Unlock()
+
+	if state.Reserved != nil {
+		self.applyReservedPeers(*state.Reserved)
+	}
+	now := time.Now().Unix()
+	for _, ban := range state.Bans {
+		if ban.Expire <= now {
+			continue
+		}
+		kid, _ := common.PeerIdFromHexString(ban.Id)
+		self.net.ConnectController().BanPeer(kid, net.JoinHostPort(ban.Ip, "0"), time.Duration(ban.Expire-now)*time.Second)
+	}
+	go self.keepOnlineService()
+}
+
+func (self *PeerAdmin) Stop() {
+	close(self.quit)
+}
+
+func (self *PeerAdmin) keepOnlineService() {
+	self.connectAddedPeers()
+	tick := time.NewTicker(time.Second * common.CONN_MONITOR)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			self.connectAddedPeers()
+		case <-self.quit:
+			return
+		}
+	}
+}
+
+func (self *PeerAdmin) connectAddedPeers() {
+	self.lock.Lock()
+	addrs := append([]string{}, self.state.Peers...)
+	self.lock.Unlock()
+	for _, addr := range addrs {
+		if len(self.matchPeers(addr)) == 0 {
+			go self.net.Connect(addr)
+		}
+	}
+}
+
+//AddPeer connect to the address and keep it connected
+func (self *PeerAdmin) AddPeer(addr string) error {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("invalid peer address %s: %v", addr, err)
+	}
+	self.lock.Lock()
+	if !containsString(self.state.Peers, addr) {
+		self.state.Peers = append(self.state.Peers, addr)
+	}
+	self.lock.Unlock()
+	self.saveState()
+
+	go self.net.Connect(addr)
+	return nil
+}
+
+//RemovePeer disconnect the peer by listen address or peer id, and stop reconnecting it
+func (self *PeerAdmin) RemovePeer(target string) error {
+	peers := self.matchPeers(target)
+	self.lock.Lock()
+	self.state.Peers = removeString(self.state.Peers, target)
+	for _, p := range peers {
+		self.state.Peers = removeString(self.state.Peers, p.Info.RemoteListenAddress())
+	}
+	self.lock.Unlock()
+	self.saveState()
+
+	for _, p := range peers {
+		if self.cancelRetry != nil {
+			self.cancelRetry(p.Info.RemoteListenAddress())
+		}
+		p.Close()
+	}
+	return nil
+}
+
+//BanPeer disconnect and reject the peer for duration, target is a peer id, an ip or an ip with port
+func (self *PeerAdmin) BanPeer(target string, duration time.Duration) error {
+	if duration <= 0 {
+		return errors.New("ban duration should be positive")
+	}
+	connCtrl := self.net.ConnectController()
+	expire := time.Now().Add(duration).Unix()
+	var bans []banState
+	var peers []*peer.Peer
+	if ip := parseIp(target); ip != "" {
+		connCtrl.BanPeer(common.PeerId{}, net.JoinHostPort(ip, "0"), duration)
+		bans = append(bans, banState{Ip: ip, Expire: expire})
+		for _, p := range self.net.GetNeighbors() {
+			if parseIp(p.GetAddr()) == ip {
+				peers = append(peers, p)
+			}
+		}
+	} else {
+		kid, err := common.PeerIdFromHexString(target)
+		if err != nil {
+			return fmt.Errorf("invalid ban target %s, should be peer id or ip address", target)
+		}
+		peers = self.matchPeers(target)
+		ban := banState{Id: target, Expire: expire}
+		if len(peers) != 0 {
+			ban.Ip = parseIp(peers[0].GetAddr())
+		}
+		connCtrl.BanPeer(kid, net.JoinHostPort(ban.Ip, "0"), duration)
+		bans = append(bans, ban)
+	}
+
+	self.lock.Lock()
+	self.state.Bans = append(self.state.Bans, bans...)
+	self.lock.Unlock()
+	self.saveState()
+
+	for _, p := range peers {
+		p.Close()
+	}
+	return nil
+}
+
+//ListBans return the unexpired bans, including the bans for exhausted reputation
+func (self *PeerAdmin) ListBans() []connect_controller.BanInfo {
+	return self.net.ConnectController().ListBans()
+}
+
+//SetReservedPeers replace the reserved peers, the connected peers out of the reserved list are disconnected
+//when reserved peers only mode is enabled
+func (self *PeerAdmin) SetReservedPeers(peers []string) error {
+	for _, p := range peers {
+		if p == "" {
+			return errors.New("empty reserved peer")
+		}
+	}
+	peers = append([]string{}, peers...)
+	self.lock.Lock()
+	self.state.Reserved = &peers
+	self.lock.Unlock()
+	self.saveState()
+
+	self.applyReservedPeers(peers)
+	if filter := self.net.ConnectController().ReservedPeers; filter != nil {
+		for _, p := range self.net.GetNeighbors() {
+			if !filter.Contains(p.GetAddr()) {
+				log.Infof("[p2p]disconnect %s out of reserved peers", p.GetAddr())
+				p.Close()
+			}
+		}
+	}
+	return nil
+}
+
+func (self *PeerAdmin) applyReservedPeers(peers []string) {
+	for _, filter := range self.reserved {
+		filter.SetReservedPeers(peers)
+	}
+}
+
+//PeerInfo return the details of connected peers, all peers are returned if target is empty, otherwise the peers
+//matching the peer id or address
+func (self *PeerAdmin) PeerInfo(target string) []*PeerAdminInfo {
+	var peers []*peer.Peer
+	if target == "" {
+		peers = self.net.GetNeighbors()
+	} else {
+		peers = self.matchPeers(target)
+	}
+	infos := make([]*PeerAdminInfo, 0, len(peers))
+	for _, p := range peers {
+		id := p.GetID()
+		info := &PeerAdminInfo{
+			Id:         id.ToHexString(),
+			Addr:       p.GetAddr(),
+			ListenAddr: p.Info.RemoteListenAddress(),
+			Height:     p.GetHeight(),
+			LatencyMs:  int64(p.Link.GetLatency() / time.Millisecond),
+			Version:    p.GetSoftVersion(),
+			Reputation: p.Link.GetReputation(),
+			Traffic:    p.Link.GetStats().Snapshot(),
+		}
+		if self.subnet != nil {
+			info.Subnet = self.subnet.Contains(info.ListenAddr)
+		}
+		for _, counter := range info.Traffic {
+			info.RecvBytes += counter.RecvBytes
+			info.SendBytes += counter.SendBytes
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+//matchPeers return the connected peers matching the peer id, listen address or connection address
+func (self *PeerAdmin) matchPeers(target string) []*peer.Peer {
+	var peers []*peer.Peer
+	for _, p := range self.net.GetNeighbors() {
+		id := p.GetID()
+		if id.ToHexString() == target || p.Info.RemoteListenAddress() == target || p.GetAddr() == target {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+func (self *PeerAdmin) loadState() {
+	if !common2.FileExisted(self.stateFile) {
+		return
+	}
+	buf, err := ioutil.ReadFile(self.stateFile)
+	if err != nil {
+		log.Warnf("[p2p]read %s fail: %s", self.stateFile, err)
+		return
+	}
+	var state adminState
+	if err := json.Unmarshal(buf, &state); err != nil {
+		log.Warnf("[p2p]parse %s fail: %s", self.stateFile, err)
+		return
+	}
+	self.lock.Lock()
+	self.state = state
+	self.lock.Unlock()
+}
+
+func (self *PeerAdmin) saveState() {
+	self.lock.Lock()
+	now := time.Now().Unix()
+	bans := make([]banState, 0, len(self.state.Bans))
+	for _, ban := range self.state.Bans {
+		if ban.Expire > now {
+			bans = append(bans, ban)
+		}
+	}
+	self.state.Bans = bans
+	buf, err := json.Marshal(self.state)
+	self.lock.Unlock()
+	if err != nil {
+		log.Warn("[p2p]package peer admin state fail: ", err)
+		return
+	}
+	if err := ioutil.WriteFile(self.stateFile, buf, 0600); err != nil {
+		log.Warn("[p2p]write peer admin state fail: ", err)
+	}
+}
+
+//parseIp return the ip of address with or without port, empty if it is not an ip address
+func parseIp(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer_admin
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology/p2pserver/common"
+	"github.com/ontio/ontology/p2pserver/connect_controller"
+	"github.com/ontio/ontology/p2pserver/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockNetwork struct {
+	connCtrl  *connect_controller.ConnectController
+	neighbors []*peer.Peer
+	connected chan string
+}
+
+func newMockNetwork() *mockNetwork {
+	info := &peer.PeerInfo{}
+	logger := common.NewGlobalLoggerWrapper()
+	return &mockNetwork{
+		connCtrl:  connect_controller.NewConnectController(info, nil, connect_controller.NewConnCtrlOption(), logger),
+		connected: make(chan string, 10),
+	}
+}
+
+func (self *mockNetwork) Connect(addr string) {
+	self.connected <- addr
+}
+
+func (self *mockNetwork) GetNeighbors() []*peer.Peer {
+	return self.neighbors
+}
+
+func (self *mockNetwork) ConnectController() *connect_controller.ConnectController {
+	return self.connCtrl
+}
+
+func (self *mockNetwork) addPeer(id uint64, addr string, port uint16) *peer.Peer {
+	conn, _ := net.Pipe()
+	info := &peer.PeerInfo{
+		Id:          common.PseudoPeerIdFromUint64(id),
+		Addr:        addr,
+		Port:        port,
+		SoftVersion: "v2.0.0",
+	}
+	p := peer.NewPeer(info, conn, nil)
+	self.neighbors = append(self.neighbors, p)
+	return p
+}
+
+type listenAddrFilter map[string]bool
+
+func (self listenAddrFilter) Contains(addr string) bool {
+	return self[addr]
+}
+
+func newStateFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "peer_admin")
+	assert.Nil(t, err)
+	return filepath.Join(dir, common.ADMIN_FILE_NAME), func() { _ = os.RemoveAll(dir) }
+}
+
+func nextConnect(t *testing.T, network *mockNetwork) string {
+	select {
+	case addr := <-network.connected:
+		return addr
+	case <-time.After(5 * time.Second):
+		t.Fatal("peer is not connected")
+		return ""
+	}
+}
+
+func TestAddPeerPersisted(t *testing.T) {
+	stateFile, clean := newStateFile(t)
+	defer clean()
+	network := newMockNetwork()
+	admin := NewPeerAdmin(network, nil, nil, stateFile)
+
+	assert.NotNil(t, admin.AddPeer("1.2.3.4"))
+	assert.Nil(t, admin.AddPeer("1.2.3.4:20338"))
+	assert.Equal(t, nextConnect(t, network), "1.2.3.4:20338")
+
+	// the added peer is connected again after restart
+	network = newMockNetwork()
+	admin = NewPeerAdmin(network, nil, nil, stateFile)
+	admin.Start()
+	defer admin.Stop()
+	assert.Equal(t, nextConnect(t, network), "1.2.3.4:20338")
+}
+
+func TestRemovePeer(t *testing.T) {
+	stateFile, clean := newStateFile(t)
+	defer clean()
+	network := newMockNetwork()
+	p := network.addPeer(1, "1.2.3.4:50000", 20338)
+	var canceled []string
+	admin := NewPeerAdmin(network, nil, func(addr string) { canceled = append(canceled, addr) }, stateFile)
+	assert.Nil(t, admin.AddPeer("1.2.3.4:20338"))
+
+	id := p.GetID()
+	assert.Nil(t, admin.RemovePeer(id.ToHexString()))
+	assert.Nil(t, p.Link.GetConn())
+	assert.Equal(t, canceled, []string{"1.2.3.4:20338"})
+	assert.Equal(t, len(admin.state.Peers), 0)
+}
+
+func TestBanPeerPersisted(t *testing.T) {
+	stateFile, clean := newStateFile(t)
+	defer clean()
+	network := newMockNetwork()
+	p1 := network.addPeer(1, "1.2.3.4:50000", 20338)
+	p2 := network.addPeer(2, "5.6.7.8:50000", 20338)
+	admin := NewPeerAdmin(network, nil, nil, stateFile)
+
+	assert.NotNil(t, admin.BanPeer("1.2.3.4", 0))
+	assert.NotNil(t, admin.BanPeer("not a peer", time.Hour))
+	assert.Nil(t, admin.BanPeer("1.2.3.4:20338", time.Hour))
+	assert.Nil(t, p1.Link.GetConn())
+	assert.NotNil(t, p2.Link.GetConn())
+
+	id := p2.GetID()
+	assert.Nil(t, admin.BanPeer(id.ToHexString(), time.Hour))
+	assert.Nil(t, p2.Link.GetConn())
+
+	bans := admin.ListBans()
+	assert.Equal(t, len(bans), 3)
+
+	// bans are restored after restart
+	network = newMockNetwork()
+	admin = NewPeerAdmin(network, nil, nil, stateFile)
+	admin.Start()
+	defer admin.Stop()
+	restored := admin.ListBans()
+	assert.Equal(t, len(restored), 3)
+	assert.ElementsMatch(t, restored, bans)
+}
+
+func TestSetReservedPeers(t *testing.T) {
+	stateFile, clean := newStateFile(t)
+	defer clean()
+	network := newMockNetwork()
+	filter := connect_controller.NewStaticReserveFilter([]string{"9.9.9.9"})
+	network.connCtrl.ReservedPeers = filter
+	p1 := network.addPeer(1, "1.2.3.4:50000", 20338)
+	p2 := network.addPeer(2, "5.6.7.8:50000", 20338)
+	admin := NewPeerAdmin(network, nil, nil, stateFile, filter)
+
+	assert.Nil(t, admin.SetReservedPeers([]string{"5.6.7.8"}))
+	assert.Equal(t, filter.GetReservedPeers(), []string{"5.6.7.8"})
+	assert.Nil(t, p1.Link.GetConn())
+	assert.NotNil(t, p2.Link.GetConn())
+
+	// reserved peers are restored after restart
+	filter = connect_controller.NewStaticReserveFilter(nil)
+	admin = NewPeerAdmin(newMockNetwork(), nil, nil, stateFile, filter)
+	admin.Start()
+	defer admin.Stop()
+	assert.Equal(t, filter.GetReservedPeers(), []string{"5.6.7.8"})
+}
+
+func TestPeerInfo(t *testing.T) {
+	stateFile, clean := newStateFile(t)
+	defer clean()
+	network := newMockNetwork()
+	p1 := network.addPeer(1, "1.2.3.4:50000", 20338)
+	p1.SetHeight(100)
+	p1.Link.SetLatency(25 * time.Millisecond)
+	network.addPeer(2, "5.6.7.8:50000", 20338)
+	admin := NewPeerAdmin(network, listenAddrFilter{"1.2.3.4:20338": true}, nil, stateFile)
+
+	assert.Equal(t, len(admin.PeerInfo("")), 2)
+
+	infos := admin.PeerInfo("1.2.3.4:20338")
+	assert.Equal(t, len(infos), 1)
+	info := infos[0]
+	id := p1.GetID()
+	assert.Equal(t, info.Id, id.ToHexString())
+	assert.Equal(t, info.Addr, "1.2.3.4:50000")
+	assert.Equal(t, info.Height, uint64(100))
+	assert.Equal(t, info.LatencyMs, int64(25))
+	assert.Equal(t, info.Version, "v2.0.0")
+	assert.True(t, info.Subnet)
+	assert.Equal(t, info.Reputation, int64(0))
+
+	assert.False(t, admin.PeerInfo("5.6.7.8:20338")[0].Subnet)
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer_admin
+
+import (
+	"time"
+
+	"github.com/ontio/ontology/http/base/common"
+	"github.com/ontio/ontology/http/base/error"
+	"github.com/ontio/ontology/http/base/rpc"
+)
+
+//RegisterPeerAdmin register the admin api, which is only answered to the clients on local host with
+//the admin token as the last param
+func RegisterPeerAdmin(admin *PeerAdmin) {
+	// curl http://localhost:20337/local -v -d '{"method":"addPeer", "params":["1.2.3.4:20338", "<admin token>"]}'
+	handleAdminFunc("addPeer", func(params []interface{}) map[string]interface{} {
+		addr, ok := stringParam(params, 0)
+		if !ok {
+			return rpc.ResponsePack(error.INVALID_PARAMS, "")
+		}
+		if err := admin.AddPeer(addr); err != nil {
+			return rpc.ResponsePack(error.INVALID_PARAMS, err.Error())
+		}
+		return rpc.ResponseSuccess(nil)
+	})
+
+	// curl http://localhost:20337/local -v -d '{"method":"removePeer", "params":["1.2.3.4:20338", "<admin token>"]}'
+	handleAdminFunc("removePeer", func(params []interface{}) map[string]interface{} {
+		target, ok := stringParam(params, 0)
+		if !ok {
+			return rpc.ResponsePack(error.INVALID_PARAMS, "")
+		}
+		if err := admin.RemovePeer(target); err != nil {
+			return rpc.ResponsePack(error.INTERNAL_ERROR, err.Error())
+		}
+		return rpc.ResponseSuccess(nil)
+	})
+
+	// curl http://localhost:20337/local -v -d '{"method":"banPeer", "params":["1.2.3.4", 3600, "<admin token>"]}'
+	handleAdminFunc("banPeer", func(params []interface{}) map[string]interface{} {
+		target, ok := stringParam(params, 0)
+		if !ok || len(params) < 2 {
+			return rpc.ResponsePack(error.INVALID_PARAMS, "")
+		}
+		seconds, ok := params[1].(float64)
+		if !ok {
+			return rpc.ResponsePack(error.INVALID_PARAMS, "")
+		}
+		if err := admin.BanPeer(target, time.Duration(seconds)*time.Second); err != nil {
+			return rpc.ResponsePack(error.INVALID_PARAMS, err.Error())
+		}
+		return rpc.ResponseSuccess(nil)
+	})
+
+	// curl http://localhost:20337/local -v -d '{"method":"listBans", "params":["<admin token>"]}'
+	handleAdminFunc("listBans", func(params []interface{}) map[string]interface{} {
+		return rpc.ResponseSuccess(admin.ListBans())
+	})
+
+	// curl http://localhost:20337/local -v -d '{"method":"setReservedPeers", "params":["1.2.3.4", "seed.example.com", "<admin token>"]}'
+	handleAdminFunc("setReservedPeers", func(params []interface{}) map[string]interface{} {
+		peers := make([]string, 0, len(params))
+		for i := range params {
+			p, ok := stringParam(params, i)
+			if !ok {
+				return rpc.ResponsePack(error.INVALID_PARAMS, "")
+			}
+			peers = append(peers, p)
+		}
+		if err := admin.SetReservedPeers(peers); err != nil {
+			return rpc.ResponsePack(error.INVALID_PARAMS, err.Error())
+		}
+		return rpc.ResponseSuccess(nil)
+	})
+
+	// curl http://localhost:20337/local -v -d '{"method":"peerInfo", "params":["<admin token>"]}'
+	handleAdminFunc("peerInfo", func(params []interface{}) map[string]interface{} {
+		target := ""
+		if len(params) != 0 {
+			var ok bool
+			if target, ok = stringParam(params, 0); !ok {
+				return rpc.ResponsePack(error.INVALID_PARAMS, "")
+			}
+		}
+		return rpc.ResponseSuccess(admin.PeerInfo(target))
+	})
+}
+
+//handleAdminFunc registers the local api checking the admin token, which is removed from the params
+//passed to the handler
+func handleAdminFunc(name string, handler func(params []interface{}) map[string]interface{}) {
+	rpc.HandleLocalFunc(name, func(params []interface{}) map[string]interface{} {
+		if len(params) == 0 {
+			return rpc.ResponsePack(error.INVALID_PARAMS, "")
+		}
+		token, ok := params[len(params)-1].(string)
+		if !ok || !common.CheckAdminToken(token) {
+			return rpc.ResponsePack(error.SESSION_EXPIRED, "invalid admin token")
+		}
+		return handler(params[:len(params)-1])
+	})
+}
+
+func stringParam(params []interface{}, index int) (string, bool) {
+	if index >= len(params) {
+		return "", false
+	}
+	s, ok := params[index].(string)
+	return s, ok && s != ""
+}
@@ -42,6 +42,7 @@ type ReconnectService struct {
 	sync.RWMutex
 	MaxRetryCount       int
 	RetryAddrs          map[string]*ReconnectPeerInfo
+	canceled            map[string]bool // addr not to reconnect after the next disconnection
 	net                 p2p.P2P
 	staticReserveFilter p2p.AddressFilter
 	quit                chan bool
@@ -54,6 +55,7 @@ func NewReconectService(net p2p.P2P, staticReserveFilter p2p.AddressFilter) *Rec
 		MaxRetryCount:       common.MAX_RETRY_COUNT,
 		quit:                make(chan bool),
 		RetryAddrs:          make(map[string]*ReconnectPeerInfo),
+		canceled:            make(map[string]bool),
 	}
 }
 
@@ -92,7 +94,19 @@ func (self *ReconnectService) OnDelPeer(p *peer.PeerInfo) {
 		maxCount = MaxRetryCountForReserveNode
 	}
 	self.Lock()
-	self.RetryAddrs[nodeAddr] = &ReconnectPeerInfo{count: maxCount, id: p.Id}
+	if self.canceled[nodeAddr] {
+		delete(self.canceled, nodeAddr)
+	} else {
+		self.RetryAddrs[nodeAddr] = &ReconnectPeerInfo{count: maxCount, id: p.Id}
+	}
+	self.Unlock()
+}
+
+//CancelRetry stop reconnecting the listen address, including the retry after the next disconnection
+func (self *ReconnectService) CancelRetry(listenAddr string) {
+	self.Lock()
+	delete(self.RetryAddrs, listenAddr)
+	self.canceled[listenAddr] = true
 	self.Unlock()
 }
 
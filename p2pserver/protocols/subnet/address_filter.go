@@ -21,7 +21,7 @@ package subnet
 import "net"
 
 type SubNetReservedAddrFilter struct {
-	staticFilterEnabled bool
+	staticFilterEnabled func() bool // read at runtime since the static reserved peers can be updated
 	subnet              *SubNet
 }
 
@@ -42,7 +42,7 @@ func (self *SubNetReservedAddrFilter) Contains(addr string) bool {
 	}
 
 	// normal node, if static filter is disabled, then allow all node connection
-	return !self.staticFilterEnabled
+	return !self.staticFilterEnabled()
 }
 
 type SubNetMaskAddrFilter struct {
@@ -0,0 +1,35 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package subnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservedAddrFilterOfNormalNode(t *testing.T) {
+	enabled := false
+	filter := NewSubNet(nil, nil, nil, nil).GetReservedAddrFilter(func() bool { return enabled })
+	assert.True(t, filter.Contains("1.2.3.4:20338"))
+
+	// reserved peers set at runtime take effect
+	enabled = true
+	assert.False(t, filter.Contains("1.2.3.4:20338"))
+}
@@ -391,7 +391,7 @@ func (self *SubNet) maintainLoop(net p2p.P2P) {
 	}
 }
 
-func (self *SubNet) GetReservedAddrFilter(staticFilterEnabled bool) p2p.AddressFilter {
+func (self *SubNet) GetReservedAddrFilter(staticFilterEnabled func() bool) p2p.AddressFilter {
 	return &SubNetReservedAddrFilter{
 		subnet:              self,
 		staticFilterEnabled: staticFilterEnabled,
@@ -25,6 +25,7 @@ import (
 
 	"github.com/ontio/ontology/account"
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/smartcontract/service/native"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
@@ -479,6 +480,12 @@ func Delegate(native *native.NativeService) ([]byte, error) {
 	}
 	if ret {
 		pushEvent(native, sucState)
+		if native.Height >= config.GetAuthScopeHeight() {
+			//a plain delegation covers the whole role, drop the scope of the former one
+			delDelegateScope(native, param.ContractAddr, param.To, param.Role)
+			pushGrantEvent(native, param.ContractAddr, param.From, param.To, param.Role,
+				native.Time+uint32(param.Period), uint8(param.Level), nil)
+		}
 		return utils.BYTE_TRUE, nil
 	} else {
 		pushEvent(native, failState)
@@ -486,6 +493,61 @@ func Delegate(native *native.NativeService) ([]byte, error) {
 	}
 }
 
+func DelegateWithScope(native *native.NativeService) ([]byte, error) {
+	//deserialize param
+	param := &DelegateWithScopeParam{}
+	source := common.NewZeroCopySource(native.Input)
+	err := param.Deserialization(source)
+	if err != nil {
+		return nil, fmt.Errorf("[delegateWithScope] deserialize param failed: %v", err)
+	}
+
+	//prepare event msg
+	contract := param.ContractAddr.ToHexString()
+	failState := []interface{}{"delegateWithScope", contract, param.From, param.To, false}
+	sucState := []interface{}{"delegateWithScope", contract, param.From, param.To, true}
+
+	//the scope must be a subset of the role's functions
+	funcNames := StringsDedupAndSort(param.FuncNames)
+	if len(funcNames) == 0 {
+		return nil, fmt.Errorf("[delegateWithScope] invalid param: funcNames is empty")
+	}
+	funcs, err := getRoleFunc(native, param.ContractAddr, param.Role)
+	if err != nil {
+		return nil, fmt.Errorf("[delegateWithScope] getRoleFunc failed: %v", err)
+	}
+	if funcs == nil {
+		log.Debugf("[delegateWithScope] role %s has no function", string(param.Role))
+		pushEvent(native, failState)
+		return utils.BYTE_FALSE, nil
+	}
+	for _, fn := range funcNames {
+		if !funcs.ContainsFunc(fn) {
+			return nil, fmt.Errorf("[delegateWithScope] invalid param: function %s is not assigned to role %s",
+				fn, string(param.Role))
+		}
+	}
+
+	ret, err := delegate(native, param.ContractAddr, param.From, param.To, param.Role,
+		uint32(param.Period), uint8(param.Level), param.KeyNo)
+	if err != nil {
+		return nil, fmt.Errorf("[delegateWithScope] failed: %v", err)
+	}
+	if !ret {
+		pushEvent(native, failState)
+		return utils.BYTE_FALSE, nil
+	}
+	scope := &delegateScope{
+		funcNames: funcNames,
+		maxUses:   uint32(param.MaxUses),
+	}
+	putDelegateScope(native, param.ContractAddr, param.To, param.Role, scope)
+	pushEvent(native, sucState)
+	pushGrantEvent(native, param.ContractAddr, param.From, param.To, param.Role,
+		native.Time+uint32(param.Period), uint8(param.Level), scope)
+	return utils.BYTE_TRUE, nil
+}
+
 func withdraw(native *native.NativeService, contractAddr common.Address, initiator []byte, delegate []byte,
 	role []byte, keyNo uint64) (bool, error) {
 	//check from's permission
@@ -552,6 +614,11 @@ func Withdraw(native *native.NativeService) ([]byte, error) {
 	}
 	if ret {
 		pushEvent(native, sucState)
+		if native.Height >= config.GetAuthScopeHeight() {
+			delDelegateScope(native, param.ContractAddr, param.Delegate, param.Role)
+			pushEvent(native, []interface{}{"revokeDelegation", contract, string(param.Initiator),
+				string(param.Delegate), string(param.Role)})
+		}
 		return utils.BYTE_TRUE, nil
 	} else {
 		pushEvent(native, failState)
@@ -595,7 +662,7 @@ func verifyToken(native *native.NativeService, contractAddr common.Address, call
 		return false, fmt.Errorf("getDelegateStatus failed: %v", err)
 	}
 	if status != nil {
-		for _, s := range status.status {
+		for i, s := range status.status {
 			funcs, err := getRoleFunc(native, contractAddr, s.role)
 			if err != nil {
 				return false, fmt.Errorf("getRoleFunc failed: %v", err)
@@ -603,14 +670,56 @@ func verifyToken(native *native.NativeService, contractAddr common.Address, call
 			if funcs == nil || s.expireTime < native.Time {
 				continue
 			}
-			if funcs.ContainsFunc(fn) {
+			if !funcs.ContainsFunc(fn) {
+				continue
+			}
+			if native.Height < config.GetAuthScopeHeight() {
 				return true, nil
 			}
+			scope, err := getDelegateScope(native, contractAddr, caller, s.role)
+			if err != nil {
+				return false, fmt.Errorf("getDelegateScope failed: %v", err)
+			}
+			if scope != nil && !scope.ContainsFunc(fn) {
+				continue
+			}
+			err = useDelegation(native, contractAddr, caller, fn, status, i, scope)
+			if err != nil {
+				return false, err
+			}
+			return true, nil
 		}
 	}
 	return false, nil
 }
 
+//count a use of the i-th delegation of caller, the delegation is removed once its uses run out
+func useDelegation(native *native.NativeService, contractAddr common.Address, caller []byte, fn string,
+	status *Status, i int, scope *delegateScope) error {
+	s := status.status[i]
+	contract := contractAddr.ToHexString()
+	if scope == nil || scope.maxUses == 0 {
+		pushEvent(native, []interface{}{"useDelegation", contract, string(caller), string(s.role), fn})
+		return nil
+	}
+	scope.used += 1
+	pushEvent(native, []interface{}{"useDelegation", contract, string(caller), string(s.role), fn,
+		scope.used, scope.maxUses})
+	if scope.used < scope.maxUses {
+		putDelegateScope(native, contractAddr, caller, s.role, scope)
+		return nil
+	}
+	newStatus := new(Status)
+	newStatus.status = append(status.status[:i], status.status[i+1:]...)
+	err := putDelegateStatus(native, contractAddr, caller, newStatus)
+	if err != nil {
+		return fmt.Errorf("putDelegateStatus failed: %v", err)
+	}
+	delDelegateScope(native, contractAddr, caller, s.role)
+	pushEvent(native, []interface{}{"revokeDelegation", contract, string(s.root), string(caller), string(s.role)})
+	return nil
+}
+
 func VerifyToken(native *native.NativeService) ([]byte, error) {
 	//deserialize param
 	param := &VerifyTokenParam{}
@@ -636,6 +745,96 @@ func VerifyToken(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_FALSE, nil
 }
 
+func ListRoles(native *native.NativeService) ([]byte, error) {
+	param := new(ListParam)
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return nil, fmt.Errorf("[listRoles] deserialize param failed: %v", err)
+	}
+	roles := make([]*RoleInfo, 0)
+	tokens, err := getOntIDToken(native, param.ContractAddr, param.OntID)
+	if err != nil {
+		return nil, fmt.Errorf("[listRoles] getOntIDToken failed: %v", err)
+	}
+	if tokens != nil {
+		for _, token := range tokens.tokens {
+			roles = append(roles, &RoleInfo{Role: token.role, Level: token.level, ExpireTime: token.expireTime})
+		}
+	}
+	status, err := getDelegateStatus(native, param.ContractAddr, param.OntID)
+	if err != nil {
+		return nil, fmt.Errorf("[listRoles] getDelegateStatus failed: %v", err)
+	}
+	if status != nil {
+		for _, s := range status.status {
+			if native.Time >= s.expireTime {
+				continue
+			}
+			roles = append(roles, &RoleInfo{Role: s.role, Level: s.level, ExpireTime: s.expireTime})
+		}
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, uint64(len(roles)))
+	for _, role := range roles {
+		funcs, err := getRoleFunc(native, param.ContractAddr, role.Role)
+		if err != nil {
+			return nil, fmt.Errorf("[listRoles] getRoleFunc failed: %v", err)
+		}
+		if funcs != nil {
+			role.FuncNames = funcs.funcNames
+		}
+		role.Serialization(sink)
+	}
+	return sink.Bytes(), nil
+}
+
+func ListDelegations(native *native.NativeService) ([]byte, error) {
+	param := new(ListParam)
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return nil, fmt.Errorf("[listDelegations] deserialize param failed: %v", err)
+	}
+	status, err := getDelegateStatus(native, param.ContractAddr, param.OntID)
+	if err != nil {
+		return nil, fmt.Errorf("[listDelegations] getDelegateStatus failed: %v", err)
+	}
+	delegations := make([]*DelegationInfo, 0)
+	if status != nil {
+		for _, s := range status.status {
+			if native.Time >= s.expireTime {
+				continue
+			}
+			info := &DelegationInfo{Role: s.role, Root: s.root, Level: s.level, ExpireTime: s.expireTime}
+			scope, err := getDelegateScope(native, param.ContractAddr, param.OntID, s.role)
+			if err != nil {
+				return nil, fmt.Errorf("[listDelegations] getDelegateScope failed: %v", err)
+			}
+			if scope != nil {
+				info.FuncNames = scope.funcNames
+				info.MaxUses = scope.maxUses
+				info.Used = scope.used
+			}
+			delegations = append(delegations, info)
+		}
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, uint64(len(delegations)))
+	for _, d := range delegations {
+		d.Serialization(sink)
+	}
+	return sink.Bytes(), nil
+}
+
+func pushGrantEvent(native *native.NativeService, contractAddr common.Address, from, to, role []byte,
+	expireTime uint32, level uint8, scope *delegateScope) {
+	msg := []interface{}{"grantDelegation", contractAddr.ToHexString(), string(from), string(to), string(role),
+		expireTime, level}
+	if scope != nil {
+		msg = append(msg, scope.funcNames, scope.maxUses)
+	}
+	pushEvent(native, msg)
+}
+
 func verifySig(native *native.NativeService, ontID []byte, keyNo uint64) (bool, error) {
 	sink := common.NewZeroCopySink(nil)
 	sink.WriteVarBytes(ontID)
@@ -660,4 +859,9 @@ func RegisterAuthContract(native *native.NativeService) {
 	native.Register("assignOntIDsToRole", AssignOntIDsToRole)
 	native.Register("verifyToken", VerifyToken)
 	native.Register("transfer", Transfer)
+	if native.Height >= config.GetAuthScopeHeight() {
+		native.Register("delegateWithScope", DelegateWithScope)
+		native.Register("listRoles", ListRoles)
+		native.Register("listDelegations", ListDelegations)
+	}
 }
@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	ontid.Init()
+	Init()
+}
+
+func regID(t *testing.T, n *native.NativeService, acc *account.Account) []byte {
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	sink.WriteVarBytes(keypair.SerializePublicKey(acc.PubKey()))
+	n.Tx.SignedAddr = []common.Address{acc.Address}
+	_, err = n.NativeCall(utils.OntIDContractAddress, "regIDWithPublicKey", sink.Bytes())
+	assert.Nil(t, err)
+	return []byte(id)
+}
+
+func listDelegations(t *testing.T, n *native.NativeService, contract common.Address, ontID []byte) []*DelegationInfo {
+	n.Input = common.SerializeToBytes(&ListParam{ContractAddr: contract, OntID: ontID})
+	res, err := ListDelegations(n)
+	assert.Nil(t, err)
+	source := common.NewZeroCopySource(res)
+	count, err := utils.DecodeVarUint(source)
+	assert.Nil(t, err)
+	ret := make([]*DelegationInfo, 0)
+	for i := uint64(0); i < count; i++ {
+		info := new(DelegationInfo)
+		assert.Nil(t, info.Deserialization(source))
+		ret = append(ret, info)
+	}
+	return ret
+}
+
+func TestScopedDelegation(t *testing.T) {
+	testsuite.InvokeNativeContract(t, utils.AuthContractAddress, func(n *native.NativeService) ([]byte, error) {
+		n.Height = config.GetAuthScopeHeight()
+		n.Time = 1000
+		adminAcc, opAcc := account.NewAccount(""), account.NewAccount("")
+		admin, op := regID(t, n, adminAcc), regID(t, n, opAcc)
+		contract := testsuite.RandomAddress()
+		role := []byte("operator")
+
+		assert.Nil(t, putContractAdmin(n, contract, admin))
+		assert.Nil(t, putRoleFunc(n, contract, role, &roleFuncs{funcNames: []string{"pause", "upgrade"}}))
+		n.Tx.SignedAddr = []common.Address{adminAcc.Address}
+		ok, err := assignToRole(n, &OntIDsToRoleParam{ContractAddr: contract, AdminOntID: admin, Role: role,
+			Persons: [][]byte{admin}, KeyNo: 1})
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		delegateScoped := func(funcs []string) ([]byte, error) {
+			n.Tx.SignedAddr = []common.Address{adminAcc.Address}
+			n.Input = common.SerializeToBytes(&DelegateWithScopeParam{ContractAddr: contract, From: admin, To: op,
+				Role: role, Period: 100, Level: 1, FuncNames: funcs, MaxUses: 2, KeyNo: 1})
+			return DelegateWithScope(n)
+		}
+		_, err = delegateScoped([]string{"mint"})
+		assert.NotNil(t, err)
+		res, err := delegateScoped([]string{"pause"})
+		assert.Nil(t, err)
+		assert.Equal(t, utils.BYTE_TRUE, res)
+
+		delegations := listDelegations(t, n, contract, op)
+		assert.Equal(t, 1, len(delegations))
+		assert.Equal(t, admin, delegations[0].Root)
+		assert.Equal(t, []string{"pause"}, delegations[0].FuncNames)
+		assert.Equal(t, uint32(2), delegations[0].MaxUses)
+
+		n.Tx.SignedAddr = []common.Address{opAcc.Address}
+		ok, err = verifyToken(n, contract, op, "upgrade", 1)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		for i := 0; i < 2; i++ {
+			ok, err = verifyToken(n, contract, op, "pause", 1)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+		}
+		ok, err = verifyToken(n, contract, op, "pause", 1)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 0, len(listDelegations(t, n, contract, op)))
+
+		//the admin's permanent role is not limited by the scope
+		n.Tx.SignedAddr = []common.Address{adminAcc.Address}
+		ok, err = verifyToken(n, contract, admin, "upgrade", 1)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		n.Input = common.SerializeToBytes(&ListParam{ContractAddr: contract, OntID: admin})
+		res, err = ListRoles(n)
+		assert.Nil(t, err)
+		source := common.NewZeroCopySource(res)
+		count, err := utils.DecodeVarUint(source)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), count)
+		info := new(RoleInfo)
+		assert.Nil(t, info.Deserialization(source))
+		assert.Equal(t, role, info.Role)
+		assert.Equal(t, []string{"pause", "upgrade"}, info.FuncNames)
+		return nil, nil
+	})
+}
+
+func TestWithdrawScopedDelegation(t *testing.T) {
+	testsuite.InvokeNativeContract(t, utils.AuthContractAddress, func(n *native.NativeService) ([]byte, error) {
+		n.Height = config.GetAuthScopeHeight()
+		n.Time = 1000
+		adminAcc, opAcc := account.NewAccount(""), account.NewAccount("")
+		admin, op := regID(t, n, adminAcc), regID(t, n, opAcc)
+		contract := testsuite.RandomAddress()
+		role := []byte("operator")
+
+		assert.Nil(t, putContractAdmin(n, contract, admin))
+		assert.Nil(t, putRoleFunc(n, contract, role, &roleFuncs{funcNames: []string{"pause"}}))
+		n.Tx.SignedAddr = []common.Address{adminAcc.Address}
+		_, err := assignToRole(n, &OntIDsToRoleParam{ContractAddr: contract, AdminOntID: admin, Role: role,
+			Persons: [][]byte{admin}, KeyNo: 1})
+		assert.Nil(t, err)
+
+		n.Input = common.SerializeToBytes(&DelegateWithScopeParam{ContractAddr: contract, From: admin, To: op,
+			Role: role, Period: 100, Level: 1, FuncNames: []string{"pause"}, KeyNo: 1})
+		res, err := DelegateWithScope(n)
+		assert.Nil(t, err)
+		assert.Equal(t, utils.BYTE_TRUE, res)
+
+		n.Input = common.SerializeToBytes(&WithdrawParam{ContractAddr: contract, Initiator: admin, Delegate: op,
+			Role: role, KeyNo: 1})
+		res, err = Withdraw(n)
+		assert.Nil(t, err)
+		assert.Equal(t, utils.BYTE_TRUE, res)
+		assert.Equal(t, 0, len(listDelegations(t, n, contract, op)))
+		scope, err := getDelegateScope(n, contract, op, role)
+		assert.Nil(t, err)
+		assert.Nil(t, scope)
+
+		n.Tx.SignedAddr = []common.Address{opAcc.Address}
+		ok, err := verifyToken(n, contract, op, "pause", 1)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		return nil, nil
+	})
+}
@@ -292,3 +292,86 @@ func (this *VerifyTokenParam) Deserialization(source *common.ZeroCopySource) err
 	}
 	return nil
 }
+
+type DelegateWithScopeParam struct {
+	ContractAddr common.Address
+	From         []byte
+	To           []byte
+	Role         []byte
+	Period       uint64
+	Level        uint64
+	FuncNames    []string
+	MaxUses      uint64
+	KeyNo        uint64
+}
+
+func (this *DelegateWithScopeParam) Serialization(sink *common.ZeroCopySink) {
+	serializeAddress(sink, this.ContractAddr)
+	sink.WriteVarBytes(this.From)
+	sink.WriteVarBytes(this.To)
+	sink.WriteVarBytes(this.Role)
+	utils.EncodeVarUint(sink, this.Period)
+	utils.EncodeVarUint(sink, this.Level)
+	utils.EncodeVarUint(sink, uint64(len(this.FuncNames)))
+	for _, fn := range this.FuncNames {
+		sink.WriteString(fn)
+	}
+	utils.EncodeVarUint(sink, this.MaxUses)
+	utils.EncodeVarUint(sink, this.KeyNo)
+}
+
+func (this *DelegateWithScopeParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.ContractAddr, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.From, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("From Deserialization error: %s", err)
+	}
+	if this.To, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("To Deserialization error: %s", err)
+	}
+	if this.Role, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("Role Deserialization error: %s", err)
+	}
+	if this.Period, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Level, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.FuncNames, err = decodeStrings(source); err != nil {
+		return fmt.Errorf("FuncNames Deserialization error: %s", err)
+	}
+	if this.MaxUses, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.KeyNo, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Level > math.MaxInt8 || this.Period > math.MaxUint32 || this.MaxUses > math.MaxUint32 {
+		return fmt.Errorf("period, level or maxUses too large: (%d, %d, %d)", this.Period, this.Level, this.MaxUses)
+	}
+	return nil
+}
+
+type ListParam struct {
+	ContractAddr common.Address
+	OntID        []byte
+}
+
+func (this *ListParam) Serialization(sink *common.ZeroCopySink) {
+	serializeAddress(sink, this.ContractAddr)
+	sink.WriteVarBytes(this.OntID)
+}
+
+func (this *ListParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.ContractAddr, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.OntID, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("OntID Deserialization error: %s", err)
+	}
+	return nil
+}
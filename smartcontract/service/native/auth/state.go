@@ -182,3 +182,157 @@ func (this *roleTokens) Deserialization(source *common.ZeroCopySource) error {
 	}
 	return nil
 }
+
+/*
+ * the scope of a delegation, restricts the delegated role to a subset of its
+ * functions and a max number of uses, maxUses 0 means unlimited
+ */
+type delegateScope struct {
+	funcNames []string
+	maxUses   uint32
+	used      uint32
+}
+
+func (this *delegateScope) ContainsFunc(fn string) bool {
+	for _, f := range this.funcNames {
+		if strings.Compare(fn, f) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *delegateScope) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(uint32(len(this.funcNames)))
+	for _, fn := range this.funcNames {
+		sink.WriteString(fn)
+	}
+	sink.WriteUint32(this.maxUses)
+	sink.WriteUint32(this.used)
+}
+
+func (this *delegateScope) Deserialization(source *common.ZeroCopySource) error {
+	fnLen, eof := source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	funcNames := make([]string, 0)
+	for i := uint32(0); i < fnLen; i++ {
+		fn, err := utils.DecodeString(source)
+		if err != nil {
+			return err
+		}
+		funcNames = append(funcNames, fn)
+	}
+	this.funcNames = funcNames
+	this.maxUses, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.used, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//result of listRoles
+type RoleInfo struct {
+	Role       []byte
+	Level      uint8
+	ExpireTime uint32
+	FuncNames  []string
+}
+
+func (this *RoleInfo) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Role)
+	sink.WriteUint8(this.Level)
+	sink.WriteUint32(this.ExpireTime)
+	utils.EncodeVarUint(sink, uint64(len(this.FuncNames)))
+	for _, fn := range this.FuncNames {
+		sink.WriteString(fn)
+	}
+}
+
+func (this *RoleInfo) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	var eof bool
+	if this.Role, err = utils.DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Level, eof = source.NextUint8(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.ExpireTime, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.FuncNames, err = decodeStrings(source)
+	return err
+}
+
+//result of listDelegations, empty FuncNames means the delegation covers the whole role
+type DelegationInfo struct {
+	Role       []byte
+	Root       []byte
+	Level      uint8
+	ExpireTime uint32
+	FuncNames  []string
+	MaxUses    uint32
+	Used       uint32
+}
+
+func (this *DelegationInfo) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Role)
+	sink.WriteVarBytes(this.Root)
+	sink.WriteUint8(this.Level)
+	sink.WriteUint32(this.ExpireTime)
+	utils.EncodeVarUint(sink, uint64(len(this.FuncNames)))
+	for _, fn := range this.FuncNames {
+		sink.WriteString(fn)
+	}
+	sink.WriteUint32(this.MaxUses)
+	sink.WriteUint32(this.Used)
+}
+
+func (this *DelegationInfo) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	var eof bool
+	if this.Role, err = utils.DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Root, err = utils.DecodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Level, eof = source.NextUint8(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.ExpireTime, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.FuncNames, err = decodeStrings(source); err != nil {
+		return err
+	}
+	if this.MaxUses, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Used, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func decodeStrings(source *common.ZeroCopySource) ([]string, error) {
+	n, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0)
+	for i := uint64(0); i < n; i++ {
+		s, err := utils.DecodeString(source)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
@@ -33,6 +33,7 @@ var (
 	PreRoleFunc       = []byte{0x02}
 	PreRoleToken      = []byte{0x03}
 	PreDelegateStatus = []byte{0x04}
+	PreDelegateScope  = []byte{0x05}
 )
 
 //type(this.contractAddr.Admin) = []byte
@@ -164,6 +165,45 @@ func putDelegateStatus(native *native.NativeService, contractAddr common.Address
 	return nil
 }
 
+//type(this.contractAddr.DelegateScope.ontID.role) = delegateScope
+func concatDelegateScopeKey(native *native.NativeService, contractAddr common.Address, ontID, role []byte) []byte {
+	this := native.ContextRef.CurrentContext().ContractAddress
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBytes(this[:])
+	sink.WriteBytes(contractAddr[:])
+	sink.WriteBytes(PreDelegateScope)
+	sink.WriteVarBytes(ontID)
+	sink.WriteBytes(role)
+
+	return sink.Bytes()
+}
+
+func getDelegateScope(native *native.NativeService, contractAddr common.Address, ontID, role []byte) (*delegateScope, error) {
+	key := concatDelegateScopeKey(native, contractAddr, ontID, role)
+	item, err := utils.GetStorageItem(native.CacheDB, key)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, nil
+	}
+	scope := new(delegateScope)
+	err = scope.Deserialization(common.NewZeroCopySource(item.Value))
+	if err != nil {
+		return nil, fmt.Errorf("deserialize delegateScope object failed. data: %x", item.Value)
+	}
+	return scope, nil
+}
+
+func putDelegateScope(native *native.NativeService, contractAddr common.Address, ontID, role []byte, scope *delegateScope) {
+	key := concatDelegateScopeKey(native, contractAddr, ontID, role)
+	utils.PutBytes(native, key, common.SerializeToBytes(scope))
+}
+
+func delDelegateScope(native *native.NativeService, contractAddr common.Address, ontID, role []byte) {
+	native.CacheDB.Delete(concatDelegateScopeKey(native, contractAddr, ontID, role))
+}
+
 //remove duplicates in the slice of string and sorts the slice in increasing order.
 func StringsDedupAndSort(s []string) []string {
 	smap := make(map[string]int)
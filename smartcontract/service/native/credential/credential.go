@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package credential
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/event"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+const (
+	COMMIT     = "commit"
+	REVOKE     = "revoke"
+	GET_STATUS = "getStatus"
+
+	MAX_CREDENTIAL_ID_SIZE = 255
+)
+
+var PreCredential = []byte{0x01}
+
+func InitCredential() {
+	native.Contracts[utils.CredentialContractAddress] = RegisterCredentialContract
+}
+
+func RegisterCredentialContract(native *native.NativeService) {
+	if native.Height < config.GetCredentialHeight() {
+		return
+	}
+	native.Register(COMMIT, Commit)
+	native.Register(REVOKE, Revoke)
+	native.Register(GET_STATUS, GetStatus)
+}
+
+//Commit records a credential issued by the issuer ONT ID to the holder ONT ID
+func Commit(native *native.NativeService) ([]byte, error) {
+	param := new(CommitParam)
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[commit] deserialize param failed: %v", err)
+	}
+	if err := checkCredentialId(param.CredentialId); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[commit] %v", err)
+	}
+	if len(param.Issuer) == 0 || len(param.Holder) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("[commit] issuer and holder are required")
+	}
+	status, err := getCredentialStatus(native, param.CredentialId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[commit] get credential status failed: %v", err)
+	}
+	if status != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[commit] credential %s already exists", string(param.CredentialId))
+	}
+	if err := verifyOntId(native, param.Issuer, &param.Signer); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[commit] authorization of issuer failed: %v", err)
+	}
+
+	status = &CredentialStatus{
+		Issuer:       param.Issuer,
+		Holder:       param.Holder,
+		Status:       STATUS_COMMITTED,
+		CommitHeight: native.Height,
+	}
+	putCredentialStatus(native, param.CredentialId, status)
+	pushEvent(native, []interface{}{"Commit", string(param.CredentialId), string(param.Issuer), string(param.Holder)})
+	return utils.BYTE_TRUE, nil
+}
+
+//Revoke marks a committed credential as revoked, by its issuer or holder
+func Revoke(native *native.NativeService) ([]byte, error) {
+	param := new(RevokeParam)
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[revoke] deserialize param failed: %v", err)
+	}
+	status, err := getCredentialStatus(native, param.CredentialId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[revoke] get credential status failed: %v", err)
+	}
+	if status == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[revoke] credential %s not exist", string(param.CredentialId))
+	}
+	if status.Status == STATUS_REVOKED {
+		return utils.BYTE_FALSE, fmt.Errorf("[revoke] credential %s already revoked", string(param.CredentialId))
+	}
+	if !bytes.Equal(param.OntId, status.Issuer) && !bytes.Equal(param.OntId, status.Holder) {
+		return utils.BYTE_FALSE, fmt.Errorf("[revoke] %s is neither issuer nor holder", string(param.OntId))
+	}
+	if err := verifyOntId(native, param.OntId, &param.Signer); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[revoke] authorization failed: %v", err)
+	}
+
+	status.Status = STATUS_REVOKED
+	status.RevokeHeight = native.Height
+	status.RevokedBy = param.OntId
+	putCredentialStatus(native, param.CredentialId, status)
+	pushEvent(native, []interface{}{"Revoke", string(param.CredentialId), string(param.OntId)})
+	return utils.BYTE_TRUE, nil
+}
+
+//GetStatus returns the serialized CredentialStatus, or empty bytes if the credential is not committed
+func GetStatus(native *native.NativeService) ([]byte, error) {
+	source := common.NewZeroCopySource(native.Input)
+	id, err := utils.DecodeVarBytes(source)
+	if err != nil {
+		return nil, fmt.Errorf("[getStatus] deserialize credentialId failed: %v", err)
+	}
+	status, err := getCredentialStatus(native, id)
+	if err != nil {
+		return nil, fmt.Errorf("[getStatus] get credential status failed: %v", err)
+	}
+	if status == nil {
+		return []byte{}, nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	status.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//verifyOntId checks the signer authorization with the ONT ID contract
+func verifyOntId(native *native.NativeService, ontId []byte, signer *Signer) error {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(ontId)
+	method := "verifySignature"
+	if signer.ByController {
+		method = "verifyController"
+		sink.WriteBytes(signer.ControllerArgs)
+	} else {
+		utils.EncodeVarUint(sink, uint64(signer.Index))
+	}
+	ret, err := native.NativeCall(utils.OntIDContractAddress, method, sink.Bytes())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(ret, utils.BYTE_TRUE) {
+		return fmt.Errorf("%s of %s failed", method, string(ontId))
+	}
+	return nil
+}
+
+func checkCredentialId(id []byte) error {
+	if len(id) == 0 || len(id) > MAX_CREDENTIAL_ID_SIZE {
+		return fmt.Errorf("invalid credential id length %d", len(id))
+	}
+	return nil
+}
+
+//ConcatCredentialKey returns the storage key of credential status without the contract address
+func ConcatCredentialKey(id []byte) []byte {
+	return append(PreCredential, id...)
+}
+
+func getCredentialStatus(native *native.NativeService, id []byte) (*CredentialStatus, error) {
+	contract := utils.CredentialContractAddress
+	item, err := utils.GetStorageItem(native.CacheDB, append(contract[:], ConcatCredentialKey(id)...))
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, nil
+	}
+	status := new(CredentialStatus)
+	if err := status.Deserialization(common.NewZeroCopySource(item.Value)); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func putCredentialStatus(native *native.NativeService, id []byte, status *CredentialStatus) {
+	contract := utils.CredentialContractAddress
+	sink := common.NewZeroCopySink(nil)
+	status.Serialization(sink)
+	utils.PutBytes(native, append(contract[:], ConcatCredentialKey(id)...), sink.Bytes())
+}
+
+func pushEvent(native *native.NativeService, s interface{}) {
+	event := new(event.NotifyEventInfo)
+	event.ContractAddress = native.ContextRef.CurrentContext().ContractAddress
+	event.States = s
+	native.Notifications = append(native.Notifications, event)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package credential
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/ontid"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	ontid.Init()
+	InitCredential()
+}
+
+func regID(t *testing.T, n *native.NativeService, acc *account.Account) []byte {
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	sink.WriteVarBytes(keypair.SerializePublicKey(acc.PubKey()))
+	n.Tx.SignedAddr = []common.Address{acc.Address}
+	_, err = n.NativeCall(utils.OntIDContractAddress, "regIDWithPublicKey", sink.Bytes())
+	assert.Nil(t, err)
+	return []byte(id)
+}
+
+func getStatus(t *testing.T, n *native.NativeService, id []byte) *CredentialStatus {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(id)
+	n.Input = sink.Bytes()
+	res, err := GetStatus(n)
+	assert.Nil(t, err)
+	if len(res) == 0 {
+		return nil
+	}
+	status := new(CredentialStatus)
+	assert.Nil(t, status.Deserialization(common.NewZeroCopySource(res)))
+	return status
+}
+
+func TestCredential(t *testing.T) {
+	testsuite.InvokeNativeContract(t, utils.CredentialContractAddress, func(n *native.NativeService) ([]byte, error) {
+		n.Height = config.GetCredentialHeight()
+		issuerAcc, holderAcc, otherAcc := account.NewAccount(""), account.NewAccount(""), account.NewAccount("")
+		issuer, holder, other := regID(t, n, issuerAcc), regID(t, n, holderAcc), regID(t, n, otherAcc)
+		credId := []byte("urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5")
+
+		commit := func(ontId []byte, acc *account.Account) error {
+			param := &CommitParam{CredentialId: credId, Issuer: ontId, Holder: holder, Signer: Signer{Index: 1}}
+			sink := common.NewZeroCopySink(nil)
+			param.Serialization(sink)
+			n.Input = sink.Bytes()
+			n.Tx.SignedAddr = []common.Address{acc.Address}
+			_, err := Commit(n)
+			return err
+		}
+		revoke := func(ontId []byte, acc *account.Account) error {
+			param := &RevokeParam{CredentialId: credId, OntId: ontId, Signer: Signer{Index: 1}}
+			sink := common.NewZeroCopySink(nil)
+			param.Serialization(sink)
+			n.Input = sink.Bytes()
+			n.Tx.SignedAddr = []common.Address{acc.Address}
+			_, err := Revoke(n)
+			return err
+		}
+
+		assert.Nil(t, getStatus(t, n, credId))
+		// issuer must sign the commit
+		assert.NotNil(t, commit(issuer, otherAcc))
+		assert.Nil(t, commit(issuer, issuerAcc))
+		assert.NotNil(t, commit(issuer, issuerAcc))
+		status := getStatus(t, n, credId)
+		assert.Equal(t, STATUS_COMMITTED, status.Status)
+		assert.Equal(t, issuer, status.Issuer)
+		assert.Equal(t, holder, status.Holder)
+		assert.Equal(t, n.Height, status.CommitHeight)
+
+		// only issuer or holder can revoke
+		assert.NotNil(t, revoke(other, otherAcc))
+		assert.NotNil(t, revoke(holder, issuerAcc))
+		assert.Nil(t, revoke(holder, holderAcc))
+		assert.NotNil(t, revoke(issuer, issuerAcc))
+		status = getStatus(t, n, credId)
+		assert.Equal(t, STATUS_REVOKED, status.Status)
+		assert.Equal(t, holder, status.RevokedBy)
+		return nil, nil
+	})
+}
+
+func TestSignerSerialization(t *testing.T) {
+	for _, signer := range []Signer{{Index: 3}, {ByController: true, ControllerArgs: []byte{1, 2, 3}}} {
+		sink := common.NewZeroCopySink(nil)
+		signer.Serialization(sink)
+		var decoded Signer
+		assert.Nil(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+		assert.Equal(t, signer, decoded)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package credential
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+const (
+	STATUS_COMMITTED byte = 1
+	STATUS_REVOKED   byte = 2
+)
+
+//Signer is how the acting ONT ID authorizes the call, either by one of its keys or by its controller
+type Signer struct {
+	ByController   bool
+	Index          uint32 //key index of the ONT ID, used when not signed by controller
+	ControllerArgs []byte //arguments of ONT ID verifyController following the ID
+}
+
+func (this *Signer) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteBool(this.ByController)
+	if this.ByController {
+		sink.WriteVarBytes(this.ControllerArgs)
+	} else {
+		utils.EncodeVarUint(sink, uint64(this.Index))
+	}
+}
+
+func (this *Signer) Deserialization(source *common.ZeroCopySource) error {
+	byController, irregular, eof := source.NextBool()
+	if irregular || eof {
+		return fmt.Errorf("deserialize byController error")
+	}
+	this.ByController = byController
+	if byController {
+		args, err := utils.DecodeVarBytes(source)
+		if err != nil {
+			return fmt.Errorf("deserialize controller args error: %v", err)
+		}
+		this.ControllerArgs = args
+		return nil
+	}
+	index, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return fmt.Errorf("deserialize index error: %v", err)
+	}
+	this.Index = uint32(index)
+	return nil
+}
+
+type CommitParam struct {
+	CredentialId []byte
+	Issuer       []byte
+	Holder       []byte
+	Signer       Signer
+}
+
+func (this *CommitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.CredentialId)
+	sink.WriteVarBytes(this.Issuer)
+	sink.WriteVarBytes(this.Holder)
+	this.Signer.Serialization(sink)
+}
+
+func (this *CommitParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.CredentialId, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize credentialId error: %v", err)
+	}
+	if this.Issuer, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize issuer error: %v", err)
+	}
+	if this.Holder, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize holder error: %v", err)
+	}
+	return this.Signer.Deserialization(source)
+}
+
+//RevokeParam revokes the credential by its issuer or holder
+type RevokeParam struct {
+	CredentialId []byte
+	OntId        []byte
+	Signer       Signer
+}
+
+func (this *RevokeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.CredentialId)
+	sink.WriteVarBytes(this.OntId)
+	this.Signer.Serialization(sink)
+}
+
+func (this *RevokeParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.CredentialId, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize credentialId error: %v", err)
+	}
+	if this.OntId, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize ontId error: %v", err)
+	}
+	return this.Signer.Deserialization(source)
+}
+
+type CredentialStatus struct {
+	Issuer       []byte
+	Holder       []byte
+	Status       byte
+	CommitHeight uint32
+	RevokeHeight uint32
+	RevokedBy    []byte
+}
+
+func (this *CredentialStatus) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Issuer)
+	sink.WriteVarBytes(this.Holder)
+	sink.WriteByte(this.Status)
+	sink.WriteUint32(this.CommitHeight)
+	sink.WriteUint32(this.RevokeHeight)
+	sink.WriteVarBytes(this.RevokedBy)
+}
+
+func (this *CredentialStatus) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	var eof bool
+	if this.Issuer, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize issuer error: %v", err)
+	}
+	if this.Holder, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize holder error: %v", err)
+	}
+	if this.Status, eof = source.NextByte(); eof {
+		return fmt.Errorf("deserialize status error: %v", io.ErrUnexpectedEOF)
+	}
+	if this.CommitHeight, eof = source.NextUint32(); eof {
+		return fmt.Errorf("deserialize commit height error: %v", io.ErrUnexpectedEOF)
+	}
+	if this.RevokeHeight, eof = source.NextUint32(); eof {
+		return fmt.Errorf("deserialize revoke height error: %v", io.ErrUnexpectedEOF)
+	}
+	if this.RevokedBy, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("deserialize revokedBy error: %v", err)
+	}
+	return nil
+}
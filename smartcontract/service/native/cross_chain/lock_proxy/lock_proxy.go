@@ -50,6 +50,16 @@ func RegisterLockProxyContract(native *native.NativeService) {
 	native.Register(GET_ASSET_HASH_NAME, GetAssetHash)
 	native.Register(GET_CROSSED_AMOUNT_NAME, GetCrossedAmount)
 	native.Register(GET_CROSSED_LIMIT_NAME, GetCrossedLimit)
+	if native.Height >= config.GetLockProxyGuardHeight() {
+		native.Register(PAUSE_NAME, Pause)
+		native.Register(UNPAUSE_NAME, Unpause)
+		native.Register(IS_PAUSED_NAME, IsPaused)
+		native.Register(SET_DAILY_LIMIT_NAME, SetDailyLimit)
+		native.Register(GET_DAILY_LIMIT_NAME, GetDailyLimit)
+		native.Register(GET_DAILY_VOLUME_NAME, GetDailyVolume)
+		native.Register(GET_BOUND_PROXIES_NAME, GetBoundProxies)
+		native.Register(GET_BOUND_ASSETS_NAME, GetBoundAssets)
+	}
 }
 
 func BindProxyHash(native *native.NativeService) ([]byte, error) {
@@ -148,6 +158,9 @@ func Lock(native *native.NativeService) ([]byte, error) {
 	if lockParam.Value == 0 {
 		return utils.BYTE_FALSE, nil
 	}
+	if err := checkNotPaused(native, contract); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Lock] %s", err)
+	}
 	// currently, only support ont and ong lock operation
 	if lockParam.SourceAssetHash != ontContract && lockParam.SourceAssetHash != ongContract {
 		return utils.BYTE_FALSE, fmt.Errorf("[Lock] only support ont/ong lock, expect:%s or %s, but got:%s", hex.EncodeToString(ontContract[:]), hex.EncodeToString(ongContract[:]), hex.EncodeToString(lockParam.SourceAssetHash[:]))
@@ -179,6 +192,9 @@ func Lock(native *native.NativeService) ([]byte, error) {
 	}
 	// increase the new crossed amount by Value
 	native.CacheDB.Put(GenCrossedAmountKey(contract, lockParam.SourceAssetHash, lockParam.ToChainID), utils.GenVarBytesStorageItem(newCrossedAmount.Bytes()).ToArray())
+	if err := addDailyVolume(native, contract, lockParam.SourceAssetHash, lockParam.ToChainID, lockParam.Value, true); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Lock] %s", err)
+	}
 
 	// get target chain proxy hash from storage
 	targetProxyHashBs, err := utils.GetStorageVarBytes(native, GenBindProxyKey(contract, lockParam.ToChainID))
@@ -218,6 +234,10 @@ func Unlock(native *native.NativeService) ([]byte, error) {
 	ontContract := utils.OntContractAddress
 	ongContract := utils.OngContractAddress
 
+	if err := checkNotPaused(native, contract); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Unlock] %s", err)
+	}
+
 	var unlockParam UnlockParam
 	if err := unlockParam.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("[Unlock] contract params deserialization error:%s", err)
@@ -269,6 +289,9 @@ func Unlock(native *native.NativeService) ([]byte, error) {
 	}
 	// decrease the new crossed amount by Value
 	native.CacheDB.Put(GenCrossedAmountKey(contract, assetAddress, unlockParam.FromChainId), utils.GenVarBytesStorageItem(newCrossedAmount.Bytes()).ToArray())
+	if err := addDailyVolume(native, contract, assetAddress, unlockParam.FromChainId, args.Value, false); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Unlock] %s", err)
+	}
 
 	AddUnLockNotifications(native, contract, unlockParam.FromChainId, unlockParam.FromContractHashBs, assetAddress, toAddress, args.Value)
 
@@ -380,3 +403,149 @@ func GetCrossedLimit(native *native.NativeService) ([]byte, error) {
 	}
 	return common.BigIntToNeoBytes(big.NewInt(0).SetBytes(crossedLimitBs)), nil
 }
+
+func Pause(native *native.NativeService) ([]byte, error) {
+	return setPaused(native, true)
+}
+
+func Unpause(native *native.NativeService) ([]byte, error) {
+	return setPaused(native, false)
+}
+
+func setPaused(native *native.NativeService, paused bool) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	method := UNPAUSE_NAME
+	if paused {
+		method = PAUSE_NAME
+	}
+	// get operator from database
+	operatorAddress, err := global_params.GetStorageRole(native,
+		global_params.GenerateOperatorKey(utils.ParamContractAddress))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[%s] get operator error:%s", method, err)
+	}
+	//check witness
+	if err = utils.ValidateOwner(native, operatorAddress); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[%s] checkWitness error:%s", method, err)
+	}
+	if paused {
+		native.CacheDB.Put(GenPausedKey(contract), utils.GenVarBytesStorageItem(utils.BYTE_TRUE).ToArray())
+	} else {
+		native.CacheDB.Delete(GenPausedKey(contract))
+	}
+	if config.DefConfig.Common.EnableEventLog {
+		native.Notifications = append(native.Notifications,
+			&event.NotifyEventInfo{
+				ContractAddress: contract,
+				States:          []interface{}{method},
+			})
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func IsPaused(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	paused, err := isPaused(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[IsPaused] get paused status error:%s", err)
+	}
+	if paused {
+		return utils.BYTE_TRUE, nil
+	}
+	return utils.BYTE_FALSE, nil
+}
+
+func SetDailyLimit(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	var param DailyLimitParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[SetDailyLimit] Deserialization DailyLimitParam error:%s", err)
+	}
+	if param.Limit.Sign() < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("[SetDailyLimit] daily limit:%s should not be negative", param.Limit.String())
+	}
+	// get operator from database
+	operatorAddress, err := global_params.GetStorageRole(native,
+		global_params.GenerateOperatorKey(utils.ParamContractAddress))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[SetDailyLimit] get operator error:%s", err)
+	}
+	//check witness
+	if err = utils.ValidateOwner(native, operatorAddress); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[SetDailyLimit] checkWitness error:%s", err)
+	}
+	// zero daily limit means no limit
+	native.CacheDB.Put(GenDailyLimitKey(contract, param.SourceAssetHash, param.TargetChainId), utils.GenVarBytesStorageItem(param.Limit.Bytes()).ToArray())
+	if config.DefConfig.Common.EnableEventLog {
+		native.Notifications = append(native.Notifications,
+			&event.NotifyEventInfo{
+				ContractAddress: contract,
+				States:          []interface{}{SET_DAILY_LIMIT_NAME, hex.EncodeToString(param.SourceAssetHash[:]), param.TargetChainId, param.Limit.String()},
+			})
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func GetDailyLimit(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	source := common.NewZeroCopySource(native.Input)
+	sourceAssetAddress, err := utils.DecodeAddress(source)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetDailyLimit] input DecodeAddress sourceAssetAddress error:%s", err)
+	}
+	toChainId, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetDailyLimit] input DecodeVarUint toChainId error:%s", err)
+	}
+	limit, err := getAmount(native, GenDailyLimitKey(contract, sourceAssetAddress, toChainId))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetDailyLimit] get daily limit with toChainId:%d for sourceAssetAddress:%s error:%s", toChainId, sourceAssetAddress.ToHexString(), err)
+	}
+	return common.BigIntToNeoBytes(limit), nil
+}
+
+func GetDailyVolume(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	source := common.NewZeroCopySource(native.Input)
+	sourceAssetAddress, err := utils.DecodeAddress(source)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetDailyVolume] input DecodeAddress sourceAssetAddress error:%s", err)
+	}
+	toChainId, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetDailyVolume] input DecodeVarUint toChainId error:%s", err)
+	}
+	volume, err := getDailyVolume(native, contract, sourceAssetAddress, toChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetDailyVolume] get daily volume with toChainId:%d for sourceAssetAddress:%s error:%s", toChainId, sourceAssetAddress.ToHexString(), err)
+	}
+	return common.SerializeToBytes(volume), nil
+}
+
+func GetBoundProxies(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	bindings, err := ListProxyBindings(native.CacheDB, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetBoundProxies] %s", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, uint64(len(bindings)))
+	for _, binding := range bindings {
+		binding.Serialization(sink)
+	}
+	return sink.Bytes(), nil
+}
+
+func GetBoundAssets(native *native.NativeService) ([]byte, error) {
+	contract := native.ContextRef.CurrentContext().ContractAddress
+	bindings, err := ListAssetBindings(native.CacheDB, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetBoundAssets] %s", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, uint64(len(bindings)))
+	for _, binding := range bindings {
+		binding.Serialization(sink)
+	}
+	return sink.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package lock_proxy
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/testsuite"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyVolume(t *testing.T) {
+	testsuite.InvokeNativeContract(t, utils.LockProxyContractAddress, func(n *native.NativeService) ([]byte, error) {
+		n.Height = config.GetLockProxyGuardHeight()
+		n.Time = 10 * SECONDS_PER_DAY
+		contract, asset := utils.LockProxyContractAddress, utils.OntContractAddress
+
+		//no daily limit
+		assert.Nil(t, addDailyVolume(n, contract, asset, 2, 1000, true))
+
+		n.CacheDB.Put(GenDailyLimitKey(contract, asset, 2), utils.GenVarBytesStorageItem(big.NewInt(1500).Bytes()).ToArray())
+		assert.Nil(t, addDailyVolume(n, contract, asset, 2, 500, true))
+		assert.NotNil(t, addDailyVolume(n, contract, asset, 2, 1, true))
+		//unlocked volume is counted separately
+		assert.Nil(t, addDailyVolume(n, contract, asset, 2, 1500, false))
+		assert.NotNil(t, addDailyVolume(n, contract, asset, 2, 1, false))
+		//the limit is per target chain
+		assert.Nil(t, addDailyVolume(n, contract, asset, 3, 2000, false))
+
+		volume, err := getDailyVolume(n, contract, asset, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1500), volume.Locked.Uint64())
+		assert.Equal(t, uint64(1500), volume.Unlocked.Uint64())
+
+		//the volume is reset in the next day
+		n.Time += SECONDS_PER_DAY
+		volume, err = getDailyVolume(n, contract, asset, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, volume.Locked.Sign())
+		assert.Nil(t, addDailyVolume(n, contract, asset, 2, 1500, true))
+		return nil, nil
+	})
+}
+
+func TestPausedAndBindings(t *testing.T) {
+	testsuite.InvokeNativeContract(t, utils.LockProxyContractAddress, func(n *native.NativeService) ([]byte, error) {
+		n.Height = config.GetLockProxyGuardHeight()
+		contract := utils.LockProxyContractAddress
+
+		assert.Nil(t, checkNotPaused(n, contract))
+		n.CacheDB.Put(GenPausedKey(contract), utils.GenVarBytesStorageItem(utils.BYTE_TRUE).ToArray())
+		assert.NotNil(t, checkNotPaused(n, contract))
+		n.CacheDB.Delete(GenPausedKey(contract))
+		assert.Nil(t, checkNotPaused(n, contract))
+
+		proxyHash := []byte{1, 2, 3}
+		n.CacheDB.Put(GenBindProxyKey(contract, 2), utils.GenVarBytesStorageItem(proxyHash).ToArray())
+		n.CacheDB.Put(GenBindProxyKey(contract, 7), utils.GenVarBytesStorageItem(proxyHash).ToArray())
+		n.CacheDB.Put(GenBindAssetHashKey(contract, utils.OngContractAddress, 2), utils.GenVarBytesStorageItem(proxyHash).ToArray())
+		n.CacheDB.Put(GenCrossedLimitKey(contract, utils.OngContractAddress, 2), utils.GenVarBytesStorageItem(big.NewInt(100).Bytes()).ToArray())
+
+		proxies, err := ListProxyBindings(n.CacheDB, contract)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(proxies))
+		assert.Equal(t, uint64(2), proxies[0].TargetChainId)
+		assert.Equal(t, uint64(7), proxies[1].TargetChainId)
+		assert.Equal(t, proxyHash, proxies[1].TargetHash)
+
+		assets, err := ListAssetBindings(n.CacheDB, contract)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(assets))
+		assert.Equal(t, utils.OngContractAddress, assets[0].SourceAssetHash)
+		assert.Equal(t, uint64(2), assets[0].TargetChainId)
+		assert.Equal(t, uint64(100), assets[0].Limit.Uint64())
+		assert.Equal(t, 0, assets[0].DailyLimit.Sign())
+		return nil, nil
+	})
+}
@@ -190,3 +190,126 @@ func (this *BindAssetParam) Deserialization(source *common.ZeroCopySource) error
 	}
 	return nil
 }
+
+type DailyLimitParam struct {
+	SourceAssetHash common.Address
+	TargetChainId   uint64
+	Limit           *big.Int
+}
+
+func (this *DailyLimitParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.SourceAssetHash)
+	utils.EncodeVarUint(sink, this.TargetChainId)
+	utils.EncodeVarBytes(sink, common.BigIntToNeoBytes(this.Limit))
+}
+
+func (this *DailyLimitParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.SourceAssetHash, err = utils.DecodeAddress(source); err != nil {
+		return fmt.Errorf("DailyLimitParam.Deserialization DecodeAddress SourceAssetHash error:%s", err)
+	}
+	if this.TargetChainId, err = utils.DecodeVarUint(source); err != nil {
+		return fmt.Errorf("DailyLimitParam.Deserialization DecodeVarUint TargetChainId error:%s", err)
+	}
+	limitNeoBytes, err := utils.DecodeVarBytes(source)
+	if err != nil {
+		return fmt.Errorf("DailyLimitParam.Deserialization DecodeVarBytes Limit error:%s", err)
+	}
+	this.Limit = common.BigIntFromNeoBytes(limitNeoBytes)
+	return nil
+}
+
+// DailyVolume is the locked and unlocked amount of an asset with a chain in a day
+type DailyVolume struct {
+	Day      uint32
+	Locked   *big.Int
+	Unlocked *big.Int
+}
+
+func (this *DailyVolume) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Day)
+	utils.EncodeVarBytes(sink, common.BigIntToNeoBytes(this.Locked))
+	utils.EncodeVarBytes(sink, common.BigIntToNeoBytes(this.Unlocked))
+}
+
+func (this *DailyVolume) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if this.Day, eof = source.NextUint32(); eof {
+		return fmt.Errorf("DailyVolume.Deserialization NextUint32 Day error:%s", io.ErrUnexpectedEOF)
+	}
+	locked, err := utils.DecodeVarBytes(source)
+	if err != nil {
+		return fmt.Errorf("DailyVolume.Deserialization DecodeVarBytes Locked error:%s", err)
+	}
+	unlocked, err := utils.DecodeVarBytes(source)
+	if err != nil {
+		return fmt.Errorf("DailyVolume.Deserialization DecodeVarBytes Unlocked error:%s", err)
+	}
+	this.Locked = common.BigIntFromNeoBytes(locked)
+	this.Unlocked = common.BigIntFromNeoBytes(unlocked)
+	return nil
+}
+
+// ProxyBinding is a bound proxy contract of a target chain
+type ProxyBinding struct {
+	TargetChainId uint64
+	TargetHash    []byte
+}
+
+func (this *ProxyBinding) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.TargetChainId)
+	utils.EncodeVarBytes(sink, this.TargetHash)
+}
+
+func (this *ProxyBinding) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.TargetChainId, err = utils.DecodeVarUint(source); err != nil {
+		return fmt.Errorf("ProxyBinding.Deserialization DecodeVarUint TargetChainId error:%s", err)
+	}
+	if this.TargetHash, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("ProxyBinding.Deserialization DecodeVarBytes TargetHash error:%s", err)
+	}
+	return nil
+}
+
+// AssetBinding is a bound asset of a target chain with its limits
+type AssetBinding struct {
+	SourceAssetHash common.Address
+	TargetChainId   uint64
+	TargetAssetHash []byte
+	Limit           *big.Int
+	CrossedAmount   *big.Int
+	DailyLimit      *big.Int
+}
+
+func (this *AssetBinding) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.SourceAssetHash)
+	utils.EncodeVarUint(sink, this.TargetChainId)
+	utils.EncodeVarBytes(sink, this.TargetAssetHash)
+	utils.EncodeVarBytes(sink, common.BigIntToNeoBytes(this.Limit))
+	utils.EncodeVarBytes(sink, common.BigIntToNeoBytes(this.CrossedAmount))
+	utils.EncodeVarBytes(sink, common.BigIntToNeoBytes(this.DailyLimit))
+}
+
+func (this *AssetBinding) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.SourceAssetHash, err = utils.DecodeAddress(source); err != nil {
+		return fmt.Errorf("AssetBinding.Deserialization DecodeAddress SourceAssetHash error:%s", err)
+	}
+	if this.TargetChainId, err = utils.DecodeVarUint(source); err != nil {
+		return fmt.Errorf("AssetBinding.Deserialization DecodeVarUint TargetChainId error:%s", err)
+	}
+	if this.TargetAssetHash, err = utils.DecodeVarBytes(source); err != nil {
+		return fmt.Errorf("AssetBinding.Deserialization DecodeVarBytes TargetAssetHash error:%s", err)
+	}
+	amounts := make([]*big.Int, 3)
+	for i := range amounts {
+		bs, err := utils.DecodeVarBytes(source)
+		if err != nil {
+			return fmt.Errorf("AssetBinding.Deserialization DecodeVarBytes amount error:%s", err)
+		}
+		amounts[i] = common.BigIntFromNeoBytes(bs)
+	}
+	this.Limit, this.CrossedAmount, this.DailyLimit = amounts[0], amounts[1], amounts[2]
+	return nil
+}
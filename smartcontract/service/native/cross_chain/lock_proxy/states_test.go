@@ -103,3 +103,40 @@ func TestBindAssetParam_Serialize(t *testing.T) {
 	}
 	assert.Equal(t, bindAssetParam, bindAssetParam2)
 }
+
+func TestDailyLimitParam_Serialize(t *testing.T) {
+	param := DailyLimitParam{
+		SourceAssetHash: utils.OngContractAddress,
+		TargetChainId:   2,
+		Limit:           big.NewInt(10000),
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+
+	param2 := DailyLimitParam{}
+	source := common.NewZeroCopySource(sink.Bytes())
+	if err := param2.Deserialization(source); err != nil {
+		t.Fatal("DailyLimitParam deserialize fail!")
+	}
+	assert.Equal(t, param, param2)
+}
+
+func TestAssetBinding_Serialize(t *testing.T) {
+	binding := AssetBinding{
+		SourceAssetHash: utils.OntContractAddress,
+		TargetChainId:   2,
+		TargetAssetHash: utils.OntContractAddress[:],
+		Limit:           big.NewInt(int64(constants.ONT_TOTAL_SUPPLY)),
+		CrossedAmount:   big.NewInt(100),
+		DailyLimit:      big.NewInt(0),
+	}
+	sink := common.NewZeroCopySink(nil)
+	binding.Serialization(sink)
+
+	binding2 := AssetBinding{}
+	source := common.NewZeroCopySource(sink.Bytes())
+	if err := binding2.Deserialization(source); err != nil {
+		t.Fatal("AssetBinding deserialize fail!")
+	}
+	assert.Equal(t, binding, binding2)
+}
@@ -21,15 +21,18 @@ package lock_proxy
 import (
 	"encoding/hex"
 	"fmt"
+	"io"
 	"math/big"
 
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
+	cstates "github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/smartcontract/event"
 	"github.com/ontio/ontology/smartcontract/service/native"
 	"github.com/ontio/ontology/smartcontract/service/native/cross_chain/cross_chain_manager"
 	"github.com/ontio/ontology/smartcontract/service/native/ont"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
+	"github.com/ontio/ontology/smartcontract/storage"
 )
 
 const (
@@ -42,10 +45,23 @@ const (
 	GET_ASSET_HASH_NAME     = "getAssetHash"
 	GET_CROSSED_LIMIT_NAME  = "getCrossedLimit"
 	GET_CROSSED_AMOUNT_NAME = "getCrossedAmount"
+	PAUSE_NAME              = "pause"
+	UNPAUSE_NAME            = "unpause"
+	IS_PAUSED_NAME          = "isPaused"
+	SET_DAILY_LIMIT_NAME    = "setDailyLimit"
+	GET_DAILY_LIMIT_NAME    = "getDailyLimit"
+	GET_DAILY_VOLUME_NAME   = "getDailyVolume"
+	GET_BOUND_PROXIES_NAME  = "getBoundProxies"
+	GET_BOUND_ASSETS_NAME   = "getBoundAssets"
 
 	TARGET_ASSET_HASH_PEFIX = "TargetAssetHash"
 	CROSS_LIMIT_PREFIX      = "AssetCrossLimit"
 	CROSS_AMOUNT_PREFIX     = "AssetCrossedAmount"
+	DAILY_LIMIT_PREFIX      = "AssetDailyLimit"
+	DAILY_VOLUME_PREFIX     = "AssetDailyVolume"
+	PAUSED_KEY              = "Paused"
+
+	SECONDS_PER_DAY = 24 * 60 * 60
 )
 
 func AddLockNotifications(native *native.NativeService, contract, sourceAssetAddress common.Address, toChainId uint64, toContract []byte, targetAssetHash []byte, fromAddress common.Address, toAddress []byte, amount uint64) {
@@ -126,6 +142,193 @@ func GenCrossedAmountKey(contract, sourceContract common.Address, chainId uint64
 	return append(temp, chainIdBytes...)
 }
 
+func GenDailyLimitKey(contract, assetContract common.Address, chainId uint64) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(chainId)
+	chainIdBytes := sink.Bytes()
+	temp := append(contract[:], []byte(DAILY_LIMIT_PREFIX)...)
+	temp = append(temp, assetContract[:]...)
+	return append(temp, chainIdBytes...)
+}
+
+func GenDailyVolumeKey(contract, assetContract common.Address, chainId uint64) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(chainId)
+	chainIdBytes := sink.Bytes()
+	temp := append(contract[:], []byte(DAILY_VOLUME_PREFIX)...)
+	temp = append(temp, assetContract[:]...)
+	return append(temp, chainIdBytes...)
+}
+
+func GenPausedKey(contract common.Address) []byte {
+	return append(contract[:], []byte(PAUSED_KEY)...)
+}
+
+func isPaused(native *native.NativeService, contract common.Address) (bool, error) {
+	item, err := utils.GetStorageItem(native.CacheDB, GenPausedKey(contract))
+	if err != nil {
+		return false, err
+	}
+	return item != nil, nil
+}
+
+func checkNotPaused(native *native.NativeService, contract common.Address) error {
+	if native.Height < config.GetLockProxyGuardHeight() {
+		return nil
+	}
+	paused, err := isPaused(native, contract)
+	if err != nil {
+		return fmt.Errorf("get paused status error:%s", err)
+	}
+	if paused {
+		return fmt.Errorf("lock proxy is paused")
+	}
+	return nil
+}
+
+func getDailyVolume(native *native.NativeService, contract, assetContract common.Address, chainId uint64) (*DailyVolume, error) {
+	return DailyVolumeOf(native.CacheDB, contract, assetContract, chainId, native.Time)
+}
+
+// DailyVolumeOf returns the volume of an asset with a chain in the day of timestamp
+func DailyVolumeOf(cacheDB *storage.CacheDB, contract, assetContract common.Address, chainId uint64, timestamp uint32) (*DailyVolume, error) {
+	day := timestamp / SECONDS_PER_DAY
+	volume := &DailyVolume{Day: day, Locked: big.NewInt(0), Unlocked: big.NewInt(0)}
+	item, err := cacheDB.Get(GenDailyVolumeKey(contract, assetContract, chainId))
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return volume, nil
+	}
+	valueBs, err := getVarBytesFromRawItem(item)
+	if err != nil {
+		return nil, err
+	}
+	stored := new(DailyVolume)
+	if err := stored.Deserialization(common.NewZeroCopySource(valueBs)); err != nil {
+		return nil, err
+	}
+	if stored.Day != day {
+		return volume, nil
+	}
+	return stored, nil
+}
+
+// add the amount to the locked or unlocked volume of today, and make sure the volume is no greater than the daily limit
+func addDailyVolume(native *native.NativeService, contract, assetContract common.Address, chainId uint64, amount uint64, isLock bool) error {
+	if native.Height < config.GetLockProxyGuardHeight() {
+		return nil
+	}
+	volume, err := getDailyVolume(native, contract, assetContract, chainId)
+	if err != nil {
+		return fmt.Errorf("getDailyVolume error:%s", err)
+	}
+	total := volume.Unlocked
+	if isLock {
+		total = volume.Locked
+	}
+	total.Add(total, big.NewInt(0).SetUint64(amount))
+	limit, err := getAmount(native, GenDailyLimitKey(contract, assetContract, chainId))
+	if err != nil {
+		return fmt.Errorf("getDailyLimit error:%s", err)
+	}
+	if limit.Sign() > 0 && total.Cmp(limit) == 1 {
+		return fmt.Errorf("daily volume:%s of asset:%s with chainId:%d exceeds the daily limit:%s", total.String(),
+			hex.EncodeToString(assetContract[:]), chainId, limit.String())
+	}
+	native.CacheDB.Put(GenDailyVolumeKey(contract, assetContract, chainId), utils.GenVarBytesStorageItem(common.SerializeToBytes(volume)).ToArray())
+	return nil
+}
+
+// ListProxyBindings returns all the bound proxy contracts of the lock proxy
+func ListProxyBindings(cacheDB *storage.CacheDB, contract common.Address) ([]*ProxyBinding, error) {
+	prefix := append(contract[:], []byte(BIND_PROXY_NAME)...)
+	iter := cacheDB.NewIterator(prefix)
+	defer iter.Release()
+	bindings := make([]*ProxyBinding, 0)
+	for has := iter.First(); has; has = iter.Next() {
+		chainId, eof := common.NewZeroCopySource(iter.Key()[len(prefix):]).NextUint64()
+		if eof {
+			return nil, fmt.Errorf("ListProxyBindings, invalid key:%x", iter.Key())
+		}
+		targetHash, err := getVarBytesFromRawItem(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("ListProxyBindings, invalid value of chainId:%d error:%s", chainId, err)
+		}
+		bindings = append(bindings, &ProxyBinding{TargetChainId: chainId, TargetHash: targetHash})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+// ListAssetBindings returns all the bound assets of the lock proxy
+func ListAssetBindings(cacheDB *storage.CacheDB, contract common.Address) ([]*AssetBinding, error) {
+	prefix := append(contract[:], []byte(BIND_ASSET_NAME)...)
+	prefix = append(prefix, []byte(TARGET_ASSET_HASH_PEFIX)...)
+	iter := cacheDB.NewIterator(prefix)
+	defer iter.Release()
+	bindings := make([]*AssetBinding, 0)
+	for has := iter.First(); has; has = iter.Next() {
+		source := common.NewZeroCopySource(iter.Key()[len(prefix):])
+		asset, eof := source.NextAddress()
+		chainId, eof2 := source.NextUint64()
+		if eof || eof2 {
+			return nil, fmt.Errorf("ListAssetBindings, invalid key:%x", iter.Key())
+		}
+		targetAssetHash, err := getVarBytesFromRawItem(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("ListAssetBindings, invalid value of asset:%s error:%s", asset.ToHexString(), err)
+		}
+		bindings = append(bindings, &AssetBinding{SourceAssetHash: asset, TargetChainId: chainId, TargetAssetHash: targetAssetHash})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	for _, binding := range bindings {
+		amounts := make([]*big.Int, 3)
+		keys := [][]byte{
+			GenCrossedLimitKey(contract, binding.SourceAssetHash, binding.TargetChainId),
+			GenCrossedAmountKey(contract, binding.SourceAssetHash, binding.TargetChainId),
+			GenDailyLimitKey(contract, binding.SourceAssetHash, binding.TargetChainId),
+		}
+		for i, key := range keys {
+			item, err := cacheDB.Get(key)
+			if err != nil {
+				return nil, err
+			}
+			amounts[i] = big.NewInt(0)
+			if item == nil {
+				continue
+			}
+			valueBs, err := getVarBytesFromRawItem(item)
+			if err != nil {
+				return nil, fmt.Errorf("ListAssetBindings, invalid value of key:%x error:%s", key, err)
+			}
+			amounts[i].SetBytes(valueBs)
+		}
+		binding.Limit, binding.CrossedAmount, binding.DailyLimit = amounts[0], amounts[1], amounts[2]
+	}
+	return bindings, nil
+}
+
+func getVarBytesFromRawItem(raw []byte) ([]byte, error) {
+	value, err := cstates.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	valueBs, _, irregular, eof := common.NewZeroCopySource(value).NextVarBytes()
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return valueBs, nil
+}
+
 func getAmount(native *native.NativeService, storgedKey []byte) (*big.Int, error) {
 	valueBs, err := utils.GetStorageVarBytes(native, storgedKey)
 	if err != nil {
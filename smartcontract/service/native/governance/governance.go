@@ -31,6 +31,7 @@ import (
 	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/constants"
+	vbftconfig "github.com/ontio/ontology/consensus/vbft/config"
 	cstates "github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/core/types"
 	"github.com/ontio/ontology/smartcontract/service/native"
@@ -71,6 +72,7 @@ const (
 	UPDATE_GLOBAL_PARAM2             = "updateGlobalParam2"
 	UPDATE_SPLIT_CURVE               = "updateSplitCurve"
 	TRANSFER_PENALTY                 = "transferPenalty"
+	REPORT_EQUIVOCATION              = "reportEquivocation"
 	CHANGE_MAX_AUTHORIZATION         = "changeMaxAuthorization"
 	SET_PEER_COST                    = "setPeerCost"
 	SET_FEE_PERCENTAGE               = "setFeePercentage"
@@ -156,6 +158,9 @@ func RegisterGovernanceContract(native *native.NativeService) {
 	native.Register(UPDATE_GLOBAL_PARAM2, UpdateGlobalParam2)
 	native.Register(UPDATE_SPLIT_CURVE, UpdateSplitCurve)
 	native.Register(TRANSFER_PENALTY, TransferPenalty)
+	if native.Height >= config.GetEquivocationHeight() {
+		native.Register(REPORT_EQUIVOCATION, ReportEquivocation)
+	}
 	native.Register(SET_PROMISE_POS, SetPromisePos)
 	native.Register(SET_GAS_ADDRESS, SetGasAddress)
 
@@ -612,6 +617,22 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 	}
 	contract := native.ContextRef.CurrentContext().ContractAddress
 
+	err = blackNodes(native, contract, params.PeerPubkeyList)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("blackNodes, black nodes error: %v", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+//Report a consensus node signed conflicting messages for the same block, the evidence is
+//self-verifiable so anyone can report it, and the offender is put into black list.
+func ReportEquivocation(native *native.NativeService) ([]byte, error) {
+	evidence := new(vbftconfig.Evidence)
+	if err := evidence.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("deserialize, contract params deserialize error: %v", err)
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
 	//get current view
 	view, err := GetView(native, contract)
 	if err != nil {
@@ -622,42 +643,23 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("getPeerPoolMap, get peerPoolMap error: %v", err)
 	}
-	commit := false
-	for _, peerPubkey := range params.PeerPubkeyList {
-		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
-		}
-		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
-		if !ok {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey is not in peerPoolMap")
-		}
-
-		blackListItem := &BlackListItem{
-			PeerPubkey: peerPoolItem.PeerPubkey,
-			Address:    peerPoolItem.Address,
-			InitPos:    peerPoolItem.InitPos,
-		}
-		//put peer into black list
-		native.CacheDB.Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(common.SerializeToBytes(blackListItem)))
-		//change peerPool status
-		if peerPoolItem.Status == ConsensusStatus {
-			commit = true
-		}
-		peerPoolItem.Status = BlackStatus
-		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[evidence.Offender]
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, peerPubkey is not in peerPoolMap")
 	}
-	err = putPeerPoolMap(native, contract, view, peerPoolMap)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("putPeerPoolMap, put peerPoolMap error: %v", err)
+	if peerPoolItem.Status == BlackStatus {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, peer is already in black list")
+	}
+	if evidence.BlockNum() >= native.Height {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, evidence of future block %d", evidence.BlockNum())
+	}
+	if err := evidence.Verify(peerPoolItem.Index); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, verify evidence error: %v", err)
 	}
 
-	//commitDpos
-	if commit {
-		err = executeCommitDpos(native, contract)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("executeCommitDpos, executeCommitDpos error: %v", err)
-		}
+	err = blackNodes(native, contract, []string{evidence.Offender})
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("blackNodes, black nodes error: %v", err)
 	}
 	return utils.BYTE_TRUE, nil
 }
@@ -1455,3 +1455,55 @@ func splitNodeFee(native *native.NativeService, contract common.Address, peerPub
 	}
 	return nil
 }
+
+//put peers into black list, the stake of the peers is penalized when they quit in commitDpos
+func blackNodes(native *native.NativeService, contract common.Address, peerPubkeyList []string) error {
+	//get current view
+	view, err := GetView(native, contract)
+	if err != nil {
+		return fmt.Errorf("getView, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+	if err != nil {
+		return fmt.Errorf("getPeerPoolMap, get peerPoolMap error: %v", err)
+	}
+	commit := false
+	for _, peerPubkey := range peerPubkeyList {
+		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+		if err != nil {
+			return fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
+		}
+		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
+		if !ok {
+			return fmt.Errorf("blackNode, peerPubkey is not in peerPoolMap")
+		}
+
+		blackListItem := &BlackListItem{
+			PeerPubkey: peerPoolItem.PeerPubkey,
+			Address:    peerPoolItem.Address,
+			InitPos:    peerPoolItem.InitPos,
+		}
+		//put peer into black list
+		native.CacheDB.Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(common.SerializeToBytes(blackListItem)))
+		//change peerPool status
+		if peerPoolItem.Status == ConsensusStatus {
+			commit = true
+		}
+		peerPoolItem.Status = BlackStatus
+		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+	}
+	err = putPeerPoolMap(native, contract, view, peerPoolMap)
+	if err != nil {
+		return fmt.Errorf("putPeerPoolMap, put peerPoolMap error: %v", err)
+	}
+
+	//commitDpos
+	if commit {
+		err = executeCommitDpos(native, contract)
+		if err != nil {
+			return fmt.Errorf("executeCommitDpos, executeCommitDpos error: %v", err)
+		}
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"math"
 
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/errors"
 	"github.com/ontio/ontology/smartcontract/service/native"
@@ -109,6 +110,13 @@ func FsChallenge(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsChallenge node has no pdp record!")
 	}
 
+	challenge.ShardIndex = 0
+	if fileInfo.ShardLayout.IsErasure() {
+		if challenge.ShardIndex, err = fileInfo.ShardLayout.shardIndexOf(challenge.NodeAddr); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("[APP SDK] FsChallenge error: %s", err.Error())
+		}
+	}
+
 	if oldChallenge := getChallenge(native, challenge.NodeAddr, challenge.FileHash); oldChallenge != nil {
 		if oldChallenge.State == NoReplyAndExpire {
 			return utils.BYTE_FALSE, errors.NewErr("[APP SDK] FsChallenge Need to call Judge first!")
@@ -572,6 +580,18 @@ func FsStoreFiles(native *native.NativeService) ([]byte, error) {
 
 		log.Debugf("[APP SDK] FsStoreFiles BlockCount:%d, PayAmount :%d\n", fileInfo.FileBlockCount, fileInfo.PayAmount)
 
+		if fileInfo.ShardLayout.IsErasure() {
+			if native.Height < config.GetOntFsErasureHeight() {
+				fileInfo.ShardLayout = ShardLayout{}
+			} else {
+				if err = checkFileShardLayout(native, &fileInfo); err != nil {
+					errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] FsStoreFiles checkFileShardLayout error: "+err.Error())
+					continue
+				}
+				fileInfo.CopyNumber = fileInfo.ShardLayout.TotalShards
+			}
+		}
+
 		if fileInfo.StorageType == FileStorageTypeUseSpace {
 			spaceInfo := getAndUpdateSpaceInfo(native, fileInfo.FileOwner)
 			if spaceInfo == nil {
@@ -800,7 +820,7 @@ func deleteFile(native *native.NativeService, fileInfo *FileInfo, errInfos *Erro
 			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] DeleteFile file StorageType error")
 			continue
 		}
-		fileSize := fileInfo.FileBlockCount * DefaultPerBlockSize
+		fileSize := fileInfo.nodeBlockCount() * DefaultPerBlockSize
 		if err = checkUint64OverflowWithSum(nodeInfo.RestVol, fileSize); err != nil {
 			errInfos.AddObjectError(string(fileInfo.FileHash), "[APP SDK] DeleteFile checkUint64OverflowWithSum error: "+err.Error())
 			continue
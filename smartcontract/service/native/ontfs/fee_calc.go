@@ -28,7 +28,7 @@ func calcFileModeRestAmount(timeNow uint64, fileInfo *FileInfo) uint64 {
 		return 0
 	}
 	restHour := (fExpired - fTimeNow) / Hour
-	return restHour * fileInfo.CopyNumber * fileInfo.FileBlockCount * fileInfo.CurrFeeRate
+	return restHour * fileInfo.storedBlockCount() * fileInfo.CurrFeeRate
 }
 
 func calcFileModePerServerProfit(dataClosing uint64, fileInfo *FileInfo) uint64 {
@@ -43,7 +43,7 @@ func calcFileModePerServerProfit(dataClosing uint64, fileInfo *FileInfo) uint64
 		dataClosing = fExpired
 	}
 	intervalHour := (dataClosing - fStart) / Hour
-	return intervalHour * fileInfo.FileBlockCount * fileInfo.CurrFeeRate
+	return intervalHour * fileInfo.nodeBlockCount() * fileInfo.CurrFeeRate
 }
 
 func calcSpaceModePerServerProfit(dataClosing uint64, spaceExpired uint64, fileInfo *FileInfo) uint64 {
@@ -58,7 +58,7 @@ func calcSpaceModePerServerProfit(dataClosing uint64, spaceExpired uint64, fileI
 		dataClosing = sExpired
 	}
 	intervalHour := (dataClosing - fStart) / Hour
-	return intervalHour * fileInfo.FileBlockCount * fileInfo.CurrFeeRate
+	return intervalHour * fileInfo.nodeBlockCount() * fileInfo.CurrFeeRate
 }
 
 func calcTotalPayAmountWithFile(fileInfo *FileInfo) uint64 {
@@ -68,7 +68,7 @@ func calcTotalPayAmountWithFile(fileInfo *FileInfo) uint64 {
 		return 0
 	}
 	intervalHour := (fExpired - fStart) / Hour
-	return intervalHour * fileInfo.CopyNumber * fileInfo.FileBlockCount * fileInfo.CurrFeeRate
+	return intervalHour * fileInfo.storedBlockCount() * fileInfo.CurrFeeRate
 }
 
 func calcTotalPayAmountWithSpaceFile(fileInfo *FileInfo, spaceTimeExpired uint64) uint64 {
@@ -78,7 +78,7 @@ func calcTotalPayAmountWithSpaceFile(fileInfo *FileInfo, spaceTimeExpired uint64
 		return 0
 	}
 	intervalHour := (fExpired - fStart) / Hour
-	return intervalHour * fileInfo.CopyNumber * fileInfo.FileBlockCount * fileInfo.CurrFeeRate
+	return intervalHour * fileInfo.storedBlockCount() * fileInfo.CurrFeeRate
 }
 
 func calcTotalPayAmountWithSpace(spaceInfo *SpaceInfo) uint64 {
@@ -34,6 +34,7 @@ type Challenge struct {
 	Reward          uint64
 	ExpiredTime     uint64
 	State           uint64
+	ShardIndex      uint64
 }
 
 type ChallengeList struct {
@@ -48,6 +49,10 @@ func (this *Challenge) Serialization(sink *common.ZeroCopySink) {
 	utils.EncodeVarUint(sink, this.Reward)
 	utils.EncodeVarUint(sink, this.ExpiredTime)
 	utils.EncodeVarUint(sink, this.State)
+	//shard 0 and replicated files leave the index out
+	if this.ShardIndex != 0 {
+		utils.EncodeVarUint(sink, this.ShardIndex)
+	}
 }
 
 func (this *Challenge) Deserialization(source *common.ZeroCopySource) error {
@@ -80,6 +85,12 @@ func (this *Challenge) Deserialization(source *common.ZeroCopySource) error {
 	if err != nil {
 		return err
 	}
+	if source.Len() > 0 {
+		this.ShardIndex, err = utils.DecodeVarUint(source)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -51,6 +51,7 @@ type FileInfo struct {
 	ValidFlag      bool
 	CurrFeeRate    uint64
 	StorageType    uint64
+	ShardLayout    ShardLayout
 }
 
 type FileInfoList struct {
@@ -79,6 +80,9 @@ func (this *FileInfo) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteBool(this.ValidFlag)
 	utils.EncodeVarUint(sink, this.CurrFeeRate)
 	utils.EncodeVarUint(sink, this.StorageType)
+	if this.ShardLayout.IsErasure() {
+		this.ShardLayout.Serialization(sink)
+	}
 }
 
 func (this *FileInfo) Deserialization(source *common.ZeroCopySource) error {
@@ -151,6 +155,12 @@ func (this *FileInfo) Deserialization(source *common.ZeroCopySource) error {
 	if err != nil {
 		return err
 	}
+	//replicated files carry no shard layout
+	if source.Len() > 0 {
+		if err = this.ShardLayout.Deserialization(source); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
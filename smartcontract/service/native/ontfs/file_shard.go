@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/ontio/ontology/smartcontract/service/native/utils"
+)
+
+const MaxTotalShards = 64
+
+//k-of-n erasure coded layout of a file, shard i is stored by ShardNodes[i]
+//and proved against ShardPdpParams[i]. A zero TotalShards means full replicas.
+type ShardLayout struct {
+	DataShards     uint64
+	TotalShards    uint64
+	ShardPdpParams [][]byte
+	ShardNodes     []common.Address
+}
+
+func (this *ShardLayout) IsErasure() bool {
+	return this.TotalShards != 0
+}
+
+func (this *ShardLayout) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.DataShards)
+	utils.EncodeVarUint(sink, this.TotalShards)
+	utils.EncodeVarUint(sink, uint64(len(this.ShardPdpParams)))
+	for _, pdpParam := range this.ShardPdpParams {
+		sink.WriteVarBytes(pdpParam)
+	}
+	utils.EncodeVarUint(sink, uint64(len(this.ShardNodes)))
+	for _, nodeAddr := range this.ShardNodes {
+		utils.EncodeAddress(sink, nodeAddr)
+	}
+}
+
+func (this *ShardLayout) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	this.DataShards, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.TotalShards, err = utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	paramCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	if paramCount > MaxTotalShards {
+		return fmt.Errorf("shard pdp param count %d exceeds %d", paramCount, MaxTotalShards)
+	}
+	for i := uint64(0); i < paramCount; i++ {
+		pdpParam, err := DecodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		this.ShardPdpParams = append(this.ShardPdpParams, pdpParam)
+	}
+	nodeCount, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	if nodeCount > MaxTotalShards {
+		return fmt.Errorf("shard node count %d exceeds %d", nodeCount, MaxTotalShards)
+	}
+	for i := uint64(0); i < nodeCount; i++ {
+		nodeAddr, err := utils.DecodeAddress(source)
+		if err != nil {
+			return err
+		}
+		this.ShardNodes = append(this.ShardNodes, nodeAddr)
+	}
+	return nil
+}
+
+func (this *ShardLayout) check() error {
+	if this.DataShards == 0 || this.DataShards >= this.TotalShards {
+		return fmt.Errorf("invalid shard layout %d-of-%d", this.DataShards, this.TotalShards)
+	}
+	if this.TotalShards > MaxTotalShards {
+		return fmt.Errorf("total shards %d exceeds %d", this.TotalShards, MaxTotalShards)
+	}
+	if uint64(len(this.ShardPdpParams)) != this.TotalShards {
+		return fmt.Errorf("shard pdp param count %d not equals total shards %d",
+			len(this.ShardPdpParams), this.TotalShards)
+	}
+	if uint64(len(this.ShardNodes)) != this.TotalShards {
+		return fmt.Errorf("shard node count %d not equals total shards %d",
+			len(this.ShardNodes), this.TotalShards)
+	}
+	nodes := make(map[common.Address]bool)
+	for _, nodeAddr := range this.ShardNodes {
+		if nodes[nodeAddr] {
+			return fmt.Errorf("node %s assigned to more than one shard", nodeAddr.ToBase58())
+		}
+		nodes[nodeAddr] = true
+	}
+	return nil
+}
+
+//index of the shard assigned to nodeAddr
+func (this *ShardLayout) shardIndexOf(nodeAddr common.Address) (uint64, error) {
+	for i, addr := range this.ShardNodes {
+		if addr == nodeAddr {
+			return uint64(i), nil
+		}
+	}
+	return 0, fmt.Errorf("node %s has no shard assigned", nodeAddr.ToBase58())
+}
+
+//blocks of one shard, the file is split into DataShards pieces of equal size
+func (this *FileInfo) shardBlockCount() uint64 {
+	k := this.ShardLayout.DataShards
+	return (this.FileBlockCount + k - 1) / k
+}
+
+//blocks kept by a single node: a full replica or one shard
+func (this *FileInfo) nodeBlockCount() uint64 {
+	if this.ShardLayout.IsErasure() {
+		return this.shardBlockCount()
+	}
+	return this.FileBlockCount
+}
+
+//blocks kept by all the nodes, the encoded size of the file
+func (this *FileInfo) storedBlockCount() uint64 {
+	if this.ShardLayout.IsErasure() {
+		return this.ShardLayout.TotalShards * this.shardBlockCount()
+	}
+	return this.CopyNumber * this.FileBlockCount
+}
+
+//block count and pdp param the proof of nodeAddr is checked against
+func (this *FileInfo) pdpTarget(nodeAddr common.Address) (uint64, []byte, error) {
+	if !this.ShardLayout.IsErasure() {
+		return this.FileBlockCount, this.PdpParam, nil
+	}
+	index, err := this.ShardLayout.shardIndexOf(nodeAddr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return this.shardBlockCount(), this.ShardLayout.ShardPdpParams[index], nil
+}
+
+func checkFileShardLayout(native *native.NativeService, fileInfo *FileInfo) error {
+	if fileInfo.StorageType != FileStorageTypeUseFile {
+		return fmt.Errorf("erasure coding needs StorageType FileStorageTypeUseFile")
+	}
+	if err := fileInfo.ShardLayout.check(); err != nil {
+		return err
+	}
+	for _, nodeAddr := range fileInfo.ShardLayout.ShardNodes {
+		if getNodeInfo(native, nodeAddr) == nil {
+			return fmt.Errorf("shard node %s is not registered", nodeAddr.ToBase58())
+		}
+	}
+	return nil
+}
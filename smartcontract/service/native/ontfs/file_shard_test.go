@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontfs
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func testShardFileInfo() FileInfo {
+	return FileInfo{
+		FileHash:       []byte("QmeQ9b2reF2Gq3Mik6cFDfPF9K9ALanFJ8QEKBNxQzsVfY"),
+		FileOwner:      common.Address{0x01},
+		FileDesc:       []byte("desc"),
+		FileBlockCount: 10,
+		CopyNumber:     3,
+		TimeStart:      0,
+		TimeExpired:    10 * Hour,
+		PdpParam:       []byte("param"),
+		ValidFlag:      true,
+		CurrFeeRate:    2,
+		StorageType:    FileStorageTypeUseFile,
+	}
+}
+
+func TestFileInfo_SerializationWithShardLayout(t *testing.T) {
+	replica := testShardFileInfo()
+	sink := common.NewZeroCopySink(nil)
+	replica.Serialization(sink)
+	replicaBytes := sink.Bytes()
+
+	var replica2 FileInfo
+	assert.Nil(t, replica2.Deserialization(common.NewZeroCopySource(replicaBytes)))
+	assert.Equal(t, replica, replica2)
+	assert.False(t, replica2.ShardLayout.IsErasure())
+
+	sharded := testShardFileInfo()
+	sharded.ShardLayout = ShardLayout{
+		DataShards:     2,
+		TotalShards:    3,
+		ShardPdpParams: [][]byte{[]byte("p0"), []byte("p1"), []byte("p2")},
+		ShardNodes:     []common.Address{{0x10}, {0x11}, {0x12}},
+	}
+	sink = common.NewZeroCopySink(nil)
+	sharded.Serialization(sink)
+	//the layout is appended after the replica fields
+	assert.Equal(t, replicaBytes, sink.Bytes()[:len(replicaBytes)])
+
+	var sharded2 FileInfo
+	assert.Nil(t, sharded2.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, sharded, sharded2)
+}
+
+func TestShardLayout_Check(t *testing.T) {
+	layout := ShardLayout{
+		DataShards:     2,
+		TotalShards:    3,
+		ShardPdpParams: [][]byte{[]byte("p0"), []byte("p1"), []byte("p2")},
+		ShardNodes:     []common.Address{{0x10}, {0x11}, {0x12}},
+	}
+	assert.Nil(t, layout.check())
+
+	index, err := layout.shardIndexOf(common.Address{0x12})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), index)
+	_, err = layout.shardIndexOf(common.Address{0x13})
+	assert.NotNil(t, err)
+
+	noParity := layout
+	noParity.DataShards = 3
+	assert.NotNil(t, noParity.check())
+
+	missingParam := layout
+	missingParam.ShardPdpParams = layout.ShardPdpParams[:2]
+	assert.NotNil(t, missingParam.check())
+
+	sameNode := layout
+	sameNode.ShardNodes = []common.Address{{0x10}, {0x11}, {0x10}}
+	assert.NotNil(t, sameNode.check())
+}
+
+func TestFeeCalcWithShardLayout(t *testing.T) {
+	fileInfo := testShardFileInfo()
+	assert.Equal(t, uint64(10*3*10*2), calcTotalPayAmountWithFile(&fileInfo))
+	assert.Equal(t, uint64(10*10*2), calcFileModePerServerProfit(fileInfo.TimeExpired, &fileInfo))
+
+	//10 blocks in 4-of-6 shards: 3 blocks per shard, 18 encoded blocks
+	fileInfo.ShardLayout = ShardLayout{DataShards: 4, TotalShards: 6}
+	fileInfo.CopyNumber = 6
+	assert.Equal(t, uint64(3), fileInfo.nodeBlockCount())
+	assert.Equal(t, uint64(18), fileInfo.storedBlockCount())
+	assert.Equal(t, uint64(10*18*2), calcTotalPayAmountWithFile(&fileInfo))
+	assert.Equal(t, uint64(10*3*2), calcFileModePerServerProfit(fileInfo.TimeExpired, &fileInfo))
+	assert.Equal(t, uint64(4*18*2), calcFileModeRestAmount(6*Hour, &fileInfo))
+}
+
+func TestChallenge_SerializationWithShardIndex(t *testing.T) {
+	challenge := Challenge{
+		FileHash:  []byte("hash"),
+		FileOwner: common.Address{0x01},
+		NodeAddr:  common.Address{0x02},
+		State:     NoReplyAndValid,
+	}
+	sink := common.NewZeroCopySink(nil)
+	challenge.Serialization(sink)
+	replicaLen := len(sink.Bytes())
+
+	var challenge2 Challenge
+	assert.Nil(t, challenge2.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, challenge, challenge2)
+
+	challenge.ShardIndex = 5
+	sink = common.NewZeroCopySink(nil)
+	challenge.Serialization(sink)
+	assert.True(t, len(sink.Bytes()) > replicaLen)
+
+	var challenge3 Challenge
+	assert.Nil(t, challenge3.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, challenge, challenge3)
+}
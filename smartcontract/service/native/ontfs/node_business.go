@@ -67,6 +67,11 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 		if recordList != nil && uint64(len(recordList.PdpRecords)) >= fileInfo.CopyNumber {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve pdpRecordCount equals copy number error!")
 		}
+		if fileInfo.ShardLayout.IsErasure() {
+			if _, err = fileInfo.ShardLayout.shardIndexOf(pdpData.NodeAddr); err != nil {
+				return utils.BYTE_FALSE, fmt.Errorf("[Node Business] FsFileProve error: %s", err.Error())
+			}
+		}
 
 		if fileInfo.FirstPdp {
 			log.Info("[Node Business] FsFileProve FirstPdp is true, checkPdpData.")
@@ -84,10 +89,10 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 		pdpRecord = &PdpRecord{NodeAddr: pdpData.NodeAddr, FileHash: pdpData.FileHash,
 			FileOwner: fileInfo.FileOwner, LastPdpTime: uint64(native.Time), SettleFlag: false}
 
-		if nodeInfo.RestVol < fileInfo.FileBlockCount*DefaultPerBlockSize {
+		if nodeInfo.RestVol < fileInfo.nodeBlockCount()*DefaultPerBlockSize {
 			return utils.BYTE_FALSE, errors.NewErr("[Node Business] FsFileProve space RestVol not enough error!")
 		}
-		nodeInfo.RestVol -= fileInfo.FileBlockCount * DefaultPerBlockSize
+		nodeInfo.RestVol -= fileInfo.nodeBlockCount() * DefaultPerBlockSize
 
 		if err = checkUint64OverflowWithSum(nodeInfo.Profit, globalParam.ContractInvokeGasFee); err != nil {
 			return utils.BYTE_FALSE, fmt.Errorf("[Node Business] FsFileProve error: %s", err.Error())
@@ -146,7 +151,7 @@ func FsFileProve(native *native.NativeService) ([]byte, error) {
 	}
 	nodeInfo.Profit += globalParam.ContractInvokeGasFee
 
-	fileSize := fileInfo.FileBlockCount * DefaultPerBlockSize
+	fileSize := fileInfo.nodeBlockCount() * DefaultPerBlockSize
 	if err = checkUint64OverflowWithSum(nodeInfo.RestVol, fileSize); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("[Node Business] FsFileProve error: %s", err.Error())
 	}
@@ -307,12 +312,16 @@ func FsResponse(native *native.NativeService) ([]byte, error) {
 }
 
 func checkPdpData(native *native.NativeService, pdpData *PdpData, fileInfo *FileInfo) error {
+	blockCount, pdpParam, err := fileInfo.pdpTarget(pdpData.NodeAddr)
+	if err != nil {
+		return err
+	}
 	blockHash := native.Store.GetBlockHash(uint32(pdpData.ChallengeHeight))
 	hexBlockHash := blockHash.ToArray()
 
 	log.Debugf("ChallengeHeight: %d, blockCount: %d, blockHash: %v\n", pdpData.ChallengeHeight,
-		fileInfo.FileBlockCount, hexBlockHash)
-	return CheckPdpProve(pdpData.NodeAddr, hexBlockHash, fileInfo.FileBlockCount, fileInfo.PdpParam, pdpData.ProveData)
+		blockCount, hexBlockHash)
+	return CheckPdpProve(pdpData.NodeAddr, hexBlockHash, blockCount, pdpParam, pdpData.ProveData)
 }
 
 //export this function for ont-fs server
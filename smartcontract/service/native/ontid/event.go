@@ -81,3 +81,8 @@ func triggerAuthKeyEvent(srvc *native.NativeService, op string, id []byte, keyID
 	st := []interface{}{"AuthKey", op, string(id), keyID}
 	newEvent(srvc, st)
 }
+
+func triggerProofEvent(srvc *native.NativeService, op string, id []byte, keyID uint32) {
+	st := []interface{}{"Proof", op, string(id), keyID}
+	newEvent(srvc, st)
+}
@@ -77,6 +77,9 @@ func RegisterIDContract(srvc *native.NativeService) {
 	srvc.Register("addContext", addContext)
 	srvc.Register("removeContext", removeContext)
 	srvc.Register("addProof", addProof)
+	if srvc.Height >= config.GetOntIdProofHeight() {
+		srvc.Register("removeProof", removeProof)
+	}
 	srvc.Register("getPublicKeysJson", GetPublicKeysJson)
 	srvc.Register("getAttributesJson", GetAttributesJson)
 	srvc.Register("getAttributeByKey", GetAttributeByKey)
@@ -101,6 +101,61 @@ func (this *ProofParam) Deserialization(source *common.ZeroCopySource) error {
 	return nil
 }
 
+type DocumentProofParam struct {
+	OntId []byte
+	Proof []byte
+	Index uint32
+}
+
+func (this *DocumentProofParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarBytes(sink, this.OntId)
+	utils.EncodeVarBytes(sink, this.Proof)
+	utils.EncodeVarUint(sink, uint64(this.Index))
+}
+
+func (this *DocumentProofParam) Deserialization(source *common.ZeroCopySource) error {
+	OntId, err := utils.DecodeVarBytes(source)
+	if err != nil {
+		return fmt.Errorf("serialization.DecodeVarBytes, deserialize OntId error: %v", err)
+	}
+	Proof, err := utils.DecodeVarBytes(source)
+	if err != nil {
+		return fmt.Errorf("serialization.DecodeVarBytes, deserialize Proof error: %v", err)
+	}
+	Index, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return fmt.Errorf("serialization.DecodeVarUint, deserialize Index error: %v", err)
+	}
+	this.OntId = OntId
+	this.Proof = Proof
+	this.Index = uint32(Index)
+	return nil
+}
+
+type ProofRemoveParam struct {
+	OntId []byte
+	Index uint32
+}
+
+func (this *ProofRemoveParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarBytes(sink, this.OntId)
+	utils.EncodeVarUint(sink, uint64(this.Index))
+}
+
+func (this *ProofRemoveParam) Deserialization(source *common.ZeroCopySource) error {
+	OntId, err := utils.DecodeVarBytes(source)
+	if err != nil {
+		return fmt.Errorf("serialization.DecodeVarBytes, deserialize OntId error: %v", err)
+	}
+	Index, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return fmt.Errorf("serialization.DecodeVarUint, deserialize Index error: %v", err)
+	}
+	this.OntId = OntId
+	this.Index = uint32(Index)
+	return nil
+}
+
 type ServiceParam struct {
 	OntId          []byte
 	ServiceId      []byte
@@ -470,5 +525,5 @@ type Document struct {
 	Attribute      []*attributeJson `json:"attribute"`
 	Created        uint32           `json:"created"`
 	Updated        uint32           `json:"updated"`
-	Proof          string           `json:"proof"`
+	Proof          interface{}      `json:"proof"`
 }
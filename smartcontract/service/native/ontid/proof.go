@@ -18,15 +18,127 @@
 package ontid
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/core/states"
 	"github.com/ontio/ontology/smartcontract/service/native"
 	"github.com/ontio/ontology/smartcontract/service/native/utils"
 )
 
+const MAX_PROOF_SIZE = 4 * 1024
+
+//proofJson is the linked data proof attached to ONT ID document
+type proofJson struct {
+	Type               string `json:"type"`
+	Created            string `json:"created"`
+	Creator            string `json:"creator,omitempty"`
+	VerificationMethod string `json:"verificationMethod,omitempty"`
+	ProofPurpose       string `json:"proofPurpose,omitempty"`
+	SignatureValue     string `json:"signatureValue,omitempty"`
+	ProofValue         string `json:"proofValue,omitempty"`
+	Jws                string `json:"jws,omitempty"`
+}
+
+//checkProof validates the JSON-LD proof, its verification method must be the key at index.
+//
+//The signatureValue, proofValue or jws of the proof is stored without verification, since it is
+//signed over the canonicalized linked data of the document, which is not computed by the contract.
+//The contract attests only that the proof is submitted by the key of its verification method,
+//the signature of which is checked by checkWitnessByIndex, and that the document has not changed
+//since then; the resolvers verify the signature value off chain.
+func checkProof(ontId, data []byte, index uint32) error {
+	if len(data) > MAX_PROOF_SIZE {
+		return fmt.Errorf("proof size %d is too large, max limit is %d", len(data), MAX_PROOF_SIZE)
+	}
+	proof := new(proofJson)
+	if err := json.Unmarshal(data, proof); err != nil {
+		return fmt.Errorf("invalid proof json, %s", err)
+	}
+	if proof.Type == "" || proof.Created == "" {
+		return errors.New("proof type and created are required")
+	}
+	if proof.SignatureValue == "" && proof.ProofValue == "" && proof.Jws == "" {
+		return errors.New("proof has no signature value")
+	}
+	method := proof.VerificationMethod
+	if method == "" {
+		method = proof.Creator
+	}
+	if expect := fmt.Sprintf("%s#keys-%d", string(ontId), index); method != expect {
+		return fmt.Errorf("proof verification method %s mismatch key %s", method, expect)
+	}
+	return nil
+}
+
 func addProof(srvc *native.NativeService) ([]byte, error) {
-	return utils.BYTE_FALSE, errors.New("property \"proof\" in ONT ID document is not supported yet")
+	if srvc.Height < config.GetOntIdProofHeight() {
+		return utils.BYTE_FALSE, errors.New("property \"proof\" in ONT ID document is not supported yet")
+	}
+	params := new(DocumentProofParam)
+	if err := params.Deserialization(common.NewZeroCopySource(srvc.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.New("addProof error: deserialization params error, " + err.Error())
+	}
+	encId, err := encodeID(params.OntId)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.New("addProof error: " + err.Error())
+	}
+	if !isValid(srvc, encId) {
+		return utils.BYTE_FALSE, errors.New("addProof error: have not registered")
+	}
+	if err := checkProof(params.OntId, params.Proof, params.Index); err != nil {
+		return utils.BYTE_FALSE, errors.New("addProof error: " + err.Error())
+	}
+	if err := checkWitnessByIndex(srvc, encId, params.Index); err != nil {
+		return utils.BYTE_FALSE, errors.New("verify signature failed: " + err.Error())
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarBytes(sink, params.Proof)
+	item := states.StorageItem{}
+	item.Value = sink.Bytes()
+	item.StateVersion = _VERSION_0
+	srvc.CacheDB.Put(append(encId, FIELD_PROOF), item.ToArray())
+	triggerProofEvent(srvc, "add", params.OntId, params.Index)
+	return utils.BYTE_TRUE, nil
+}
+
+func removeProof(srvc *native.NativeService) ([]byte, error) {
+	params := new(ProofRemoveParam)
+	if err := params.Deserialization(common.NewZeroCopySource(srvc.Input)); err != nil {
+		return utils.BYTE_FALSE, errors.New("removeProof error: deserialization params error, " + err.Error())
+	}
+	encId, err := encodeID(params.OntId)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.New("removeProof error: " + err.Error())
+	}
+	if !isValid(srvc, encId) {
+		return utils.BYTE_FALSE, errors.New("removeProof error: have not registered")
+	}
+	if err := checkWitnessByIndex(srvc, encId, params.Index); err != nil {
+		return utils.BYTE_FALSE, errors.New("verify signature failed: " + err.Error())
+	}
+	proof, err := getProof(srvc, encId)
+	if err != nil {
+		return utils.BYTE_FALSE, errors.New("removeProof error: " + err.Error())
+	}
+	if proof == "" {
+		return utils.BYTE_FALSE, errors.New("removeProof error: proof not exist")
+	}
+	clearProof(srvc, encId)
+	triggerProofEvent(srvc, "remove", params.OntId, params.Index)
+	return utils.BYTE_TRUE, nil
+}
+
+//clearProof removes the document proof, which is invalidated by any change of the document
+func clearProof(srvc *native.NativeService, encId []byte) {
+	if srvc.Height < config.GetOntIdProofHeight() {
+		return
+	}
+	srvc.CacheDB.Delete(append(encId, FIELD_PROOF))
 }
 
 func getProof(srvc *native.NativeService, encId []byte) (string, error) {
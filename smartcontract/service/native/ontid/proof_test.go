@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package ontid
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ontio/ontology/account"
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
+	"github.com/ontio/ontology/smartcontract/service/native"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProof(t *testing.T) {
+	testcase(t, CaseProof)
+}
+
+//newProof returns the proof with a signature value which is not a valid signature, the contract stores
+//it unverified
+func newProof(id string, index uint32) []byte {
+	return []byte(fmt.Sprintf(`{"type":"EcdsaSecp256r1Signature2019","created":"2020-11-11T00:00:00Z",`+
+		`"proofPurpose":"assertionMethod","verificationMethod":"%s#keys-%d","signatureValue":"AQID"}`, id, index))
+}
+
+func invokeProof(n *native.NativeService, id string, proof []byte, index uint32, signer common.Address) error {
+	param := &DocumentProofParam{OntId: []byte(id), Proof: proof, Index: index}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	n.Input = sink.Bytes()
+	n.Tx.SignedAddr = []common.Address{signer}
+	_, err := addProof(n)
+	return err
+}
+
+func getDocumentProof(t *testing.T, n *native.NativeService, id string) interface{} {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes([]byte(id))
+	n.Input = sink.Bytes()
+	res, err := GetDocumentJson(n)
+	assert.Nil(t, err)
+	doc := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(res, &doc))
+	return doc["proof"]
+}
+
+func CaseProof(t *testing.T, n *native.NativeService) {
+	id, _ := account.GenerateID()
+	acc := account.NewAccount("")
+	other := account.NewAccount("")
+	n.Height = config.GetNewOntIdHeight()
+	if err := regID(n, id, acc); err != nil {
+		t.Fatal("register id error", err)
+	}
+
+	// not supported before the fork height
+	assert.NotNil(t, invokeProof(n, id, newProof(id, 1), 1, acc.Address))
+
+	n.Height = config.GetOntIdProofHeight()
+	// malformed proof and mismatched verification method
+	assert.NotNil(t, invokeProof(n, id, []byte("{"), 1, acc.Address))
+	assert.NotNil(t, invokeProof(n, id, []byte(`{"type":"a","created":"b"}`), 1, acc.Address))
+	assert.NotNil(t, invokeProof(n, id, newProof(id, 2), 1, acc.Address))
+	// not signed by the authentication key
+	assert.NotNil(t, invokeProof(n, id, newProof(id, 1), 1, other.Address))
+	assert.Equal(t, "", getDocumentProof(t, n, id))
+
+	assert.Nil(t, invokeProof(n, id, newProof(id, 1), 1, acc.Address))
+	proof, ok := getDocumentProof(t, n, id).(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, fmt.Sprintf("%s#keys-1", id), proof["verificationMethod"])
+	// the signature value is stored as submitted, only the submitting key is attested
+	assert.Equal(t, "AQID", proof["signatureValue"])
+
+	// any update of the document clears the proof
+	context := &Context{OntId: []byte(id), Contexts: [][]byte{[]byte("https://example.com/v1")}, Index: 1}
+	sink := common.NewZeroCopySink(nil)
+	context.Serialization(sink)
+	n.Input = sink.Bytes()
+	_, err := addContext(n)
+	assert.Nil(t, err)
+	assert.Equal(t, "", getDocumentProof(t, n, id))
+
+	// remove proof
+	assert.Nil(t, invokeProof(n, id, newProof(id, 1), 1, acc.Address))
+	remove := &ProofRemoveParam{OntId: []byte(id), Index: 1}
+	sink = common.NewZeroCopySink(nil)
+	remove.Serialization(sink)
+	n.Input = sink.Bytes()
+	n.Tx.SignedAddr = []common.Address{other.Address}
+	_, err = removeProof(n)
+	assert.NotNil(t, err)
+	n.Tx.SignedAddr = []common.Address{acc.Address}
+	_, err = removeProof(n)
+	assert.Nil(t, err)
+	assert.Equal(t, "", getDocumentProof(t, n, id))
+	_, err = removeProof(n)
+	assert.NotNil(t, err)
+}
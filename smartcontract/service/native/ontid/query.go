@@ -439,6 +439,19 @@ func GetDocumentJson(srvc *native.NativeService) ([]byte, error) {
 	document.Attribute = attribute
 	document.Created = created
 	document.Updated = updated
-	document.Proof = proof
+	if proof != "" {
+		document.Proof = json.RawMessage(proof)
+	} else {
+		document.Proof = proof
+	}
 	return json.Marshal(document)
 }
+
+//IsRevokedID return true if the ID was registered and has been revoked
+func IsRevokedID(srvc *native.NativeService, id []byte) (bool, error) {
+	encId, err := encodeID(id)
+	if err != nil {
+		return false, err
+	}
+	return checkIDState(srvc, encId) == flag_revoke, nil
+}
@@ -170,9 +170,11 @@ func deleteID(srvc *native.NativeService, encId []byte) error {
 func updateTimeAndClearProof(srvc *native.NativeService, encId []byte) {
 	key := append(encId, FIELD_UPDATED)
 	updateTime(srvc, key)
+	clearProof(srvc, encId)
 }
 
 func createTimeAndClearProof(srvc *native.NativeService, encId []byte) {
 	key := append(encId, FIELD_CREATED)
 	updateTime(srvc, key)
+	clearProof(srvc, encId)
 }
@@ -35,6 +35,7 @@ var (
 	CrossChainContractAddress, _ = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09})
 	LockProxyContractAddress, _  = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a})
 	OntFSContractAddress, _      = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0b})
+	CredentialContractAddress, _ = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0c})
 	SystemContractAddress, _     = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff})
 	//WARN: when add Contract Here, please update IsNativeContract function bellow.
 )
@@ -44,7 +45,7 @@ func IsNativeContract(addr common.Address) bool {
 	case OntContractAddress, OngContractAddress, OntIDContractAddress,
 		ParamContractAddress, AuthContractAddress, GovernanceContractAddress,
 		HeaderSyncContractAddress, CrossChainContractAddress, LockProxyContractAddress,
-		OntFSContractAddress, SystemContractAddress:
+		OntFSContractAddress, CredentialContractAddress, SystemContractAddress:
 		return true
 	default:
 		return false
@@ -20,6 +20,7 @@
 package common
 
 import (
+	"math"
 	"sort"
 	"sync"
 
@@ -77,9 +78,12 @@ type UserNonceInfo struct {
 // in the ledger.
 type TXPool struct {
 	sync.RWMutex
-	validTxMap            map[common.Uint256]*VerifiedTx    // Transactions which have been verified
-	eipTxPool             map[common.Address]*txSortedMap   // The tx pool that holds the valid transaction
-	userLatestEiptxHeight map[common.Address]*UserNonceInfo // record last block height user commit eiptx
+	validTxMap            map[common.Uint256]*VerifiedTx               // Transactions which have been verified
+	eipTxPool             map[common.Address]*txSortedMap              // The tx pool that holds the valid transaction
+	userLatestEiptxHeight map[common.Address]*UserNonceInfo            // record last block height user commit eiptx
+	nativeTxs             map[common.Address]map[uint32]common.Uint256 // native txs indexed by payer and nonce
+	payerTxCount          map[common.Address]int                       // the number of txs of each payer in the pool
+	priced                *txPricedList                                // all the txs sorted by gas price for eviction
 }
 
 func NewTxPool() *TXPool {
@@ -87,6 +91,64 @@ func NewTxPool() *TXPool {
 		validTxMap:            make(map[common.Uint256]*VerifiedTx),
 		eipTxPool:             make(map[common.Address]*txSortedMap),
 		userLatestEiptxHeight: make(map[common.Address]*UserNonceInfo),
+		nativeTxs:             make(map[common.Address]map[uint32]common.Uint256),
+		payerTxCount:          make(map[common.Address]int),
+		priced:                newTxPricedList(),
+	}
+}
+
+// putTxLocked adds the tx into validTxMap and the indexes, the eip tx pool is maintained by the caller
+func (tp *TXPool) putTxLocked(txEntry *VerifiedTx) {
+	tx := txEntry.Tx
+	txHash := tx.Hash()
+	tp.validTxMap[txHash] = txEntry
+	tp.payerTxCount[tx.Payer]++
+	if !tx.IsEipTx() {
+		nonces := tp.nativeTxs[tx.Payer]
+		if nonces == nil {
+			nonces = make(map[uint32]common.Uint256)
+			tp.nativeTxs[tx.Payer] = nonces
+		}
+		nonces[tx.Nonce] = txHash
+	}
+	tp.priced.Put(tx)
+}
+
+// removeTxLocked deletes the tx from validTxMap and the indexes, the eip tx pool is maintained by the caller
+func (tp *TXPool) removeTxLocked(hash common.Uint256) {
+	txEntry, ok := tp.validTxMap[hash]
+	if !ok {
+		return
+	}
+	tx := txEntry.Tx
+	delete(tp.validTxMap, hash)
+	if count := tp.payerTxCount[tx.Payer]; count > 1 {
+		tp.payerTxCount[tx.Payer] = count - 1
+	} else {
+		delete(tp.payerTxCount, tx.Payer)
+	}
+	if !tx.IsEipTx() {
+		if nonces := tp.nativeTxs[tx.Payer]; nonces != nil && nonces[tx.Nonce] == hash {
+			delete(nonces, tx.Nonce)
+			if len(nonces) == 0 {
+				delete(tp.nativeTxs, tx.Payer)
+			}
+		}
+	}
+	tp.priced.Removed(tp.validTxMap)
+}
+
+// dropTxLocked deletes the tx from the pool, the eip155 txs with higher nonce of the same payer are queued after that.
+func (tp *TXPool) dropTxLocked(tx *types.Transaction) {
+	tp.removeTxLocked(tx.Hash())
+	if tx.IsEipTx() {
+		if list := tp.eipTxPool[tx.Payer]; list != nil {
+			list.Remove(uint64(tx.Nonce))
+			if list.Len() == 0 {
+				delete(tp.eipTxPool, tx.Payer)
+				delete(tp.userLatestEiptxHeight, tx.Payer)
+			}
+		}
 	}
 }
 
@@ -98,7 +160,7 @@ func (s *TXPool) CleanStaledEIPTx(height uint32) {
 			if height >= v.Height+EIPTX_EXPIRATION_BLOCKS {
 				if list := s.eipTxPool[addr]; list != nil {
 					for _, txn := range list.items {
-						delete(s.validTxMap, txn.Hash())
+						s.removeTxLocked(txn.Hash())
 					}
 
 					delete(s.eipTxPool, addr)
@@ -141,60 +203,103 @@ func (s *TXPool) getTxListByAddr(addr common.Address) *txSortedMap {
 	return s.eipTxPool[addr]
 }
 
-func (s *TXPool) addEIPTxPool(trans *types.Transaction) (replaced *types.Transaction, err errors.ErrCode) {
-	list := s.getTxListByAddr(trans.Payer)
-
-	// does the same nonce exist?
-	old := list.Get(uint64(trans.Nonce))
-	if old == nil {
-		s.eipTxPool[trans.Payer].Put(trans)
-		return nil, errors.ErrNoError
+// sameNonceTxLocked returns the tx in the pool with the same payer and nonce, or nil if not exist.
+func (tp *TXPool) sameNonceTxLocked(tx *types.Transaction) *types.Transaction {
+	if tx.IsEipTx() {
+		if list := tp.eipTxPool[tx.Payer]; list != nil {
+			return list.Get(uint64(tx.Nonce))
+		}
+		return nil
 	}
+	if hash, ok := tp.nativeTxs[tx.Payer][tx.Nonce]; ok {
+		if txEntry := tp.validTxMap[hash]; txEntry != nil {
+			return txEntry.Tx
+		}
+	}
+	return nil
+}
 
-	if trans.GasPrice > old.GasPrice*101/100 {
-		log.Infof("replace transaction %s with lower gas fee", old.Hash().ToHexString())
-		s.eipTxPool[trans.Payer].Put(trans)
-		return old, errors.ErrNoError
+// isPriceBumped checks the new gas price is higher than the old one by more than bump percent
+func isPriceBumped(oldPrice, newPrice uint64, bump uint64) bool {
+	threshold := oldPrice/100*bump + oldPrice%100*bump/100
+	if threshold > math.MaxUint64-oldPrice {
+		return false
 	}
+	return newPrice > oldPrice+threshold
+}
 
-	return nil, errors.ErrSameNonceExist
+// IsFull checks whether the pool reaches its capacity and all the txs in it pay
+// no less than the gas price, so a new tx with the gas price can not get in.
+func (tp *TXPool) IsFull(gasPrice uint64) bool {
+	tp.Lock()
+	defer tp.Unlock()
+	capacity := config.DefConfig.Common.TxPoolCapacity
+	if capacity == 0 || uint(len(tp.validTxMap)) < capacity {
+		return false
+	}
+	cheapest := tp.priced.Cheapest(tp.validTxMap)
+	return cheapest == nil || cheapest.GasPrice >= gasPrice
 }
 
 // AddTxList adds a valid transaction to the transaction pool. If the
 // transaction is already in the pool, just return false. Parameter
 // txEntry includes transaction, fee, and verified information(height,
 // validator, error code).
+// A tx with the same payer and nonce of a pooled one replaces it only if the
+// gas price is bumped. When the pool is full, the tx with the lowest gas price
+// is evicted to make room for a tx paying more.
 func (tp *TXPool) AddTxList(txEntry *VerifiedTx) errors.ErrCode {
 	tp.Lock()
 	defer tp.Unlock()
-	txHash := txEntry.Tx.Hash()
-	if txEntry.Tx.IsEipTx() {
-		//check the new tx nonce should not be greater than latest nonce + 1000
-		if uint64(txEntry.Tx.Nonce) >= txEntry.Nonce+EIPTX_NONCE_MAX_GAP {
-			return errors.ErrETHTxNonceToobig
-		}
+	tx := txEntry.Tx
+	txHash := tx.Hash()
+	if _, ok := tp.validTxMap[txHash]; ok {
+		log.Infof("AddTxList: transaction %x is already in the pool", txHash)
+		return errors.ErrDuplicatedTx
+	}
+	//check the new tx nonce should not be greater than latest nonce + 1000
+	if tx.IsEipTx() && uint64(tx.Nonce) >= txEntry.Nonce+EIPTX_NONCE_MAX_GAP {
+		return errors.ErrETHTxNonceToobig
+	}
 
-		repalced, code := tp.addEIPTxPool(txEntry.Tx)
-		if repalced != nil {
-			delete(tp.validTxMap, repalced.Hash())
+	// does the same nonce exist?
+	replaced := tp.sameNonceTxLocked(tx)
+	if replaced != nil {
+		if !isPriceBumped(replaced.GasPrice, tx.GasPrice, uint64(config.DefConfig.Common.TxPoolPriceBump)) {
+			return errors.ErrSameNonceExist
+		}
+	} else {
+		slots := config.DefConfig.Common.TxPoolPayerSlots
+		if slots != 0 && uint(tp.payerTxCount[tx.Payer]) >= slots {
+			log.Infof("AddTxList: payer %s has too many transactions in the pool", tx.Payer.ToBase58())
+			return errors.ErrTxPoolPayerFull
 		}
-		if !code.Success() {
-			return code
+		capacity := config.DefConfig.Common.TxPoolCapacity
+		if capacity != 0 && uint(len(tp.validTxMap)) >= capacity {
+			cheapest := tp.priced.Cheapest(tp.validTxMap)
+			if cheapest == nil || cheapest.GasPrice >= tx.GasPrice {
+				return errors.ErrTxPoolFull
+			}
+			log.Infof("AddTxList: evict transaction %s with gas price %d", cheapest.Hash().ToHexString(), cheapest.GasPrice)
+			tp.dropTxLocked(cheapest)
 		}
-		if tp.userLatestEiptxHeight[txEntry.Tx.Payer] == nil {
-			tp.userLatestEiptxHeight[txEntry.Tx.Payer] = &UserNonceInfo{
+	}
+
+	if tx.IsEipTx() {
+		tp.getTxListByAddr(tx.Payer).Put(tx)
+		if tp.userLatestEiptxHeight[tx.Payer] == nil {
+			tp.userLatestEiptxHeight[tx.Payer] = &UserNonceInfo{
 				Height: txEntry.VerifiedHeight,
 				Nonce:  txEntry.Nonce,
 			}
 		}
 	}
-
-	if _, ok := tp.validTxMap[txHash]; ok {
-		log.Infof("AddTxList: transaction %x is already in the pool", txHash)
-		return errors.ErrDuplicatedTx
+	if replaced != nil {
+		log.Infof("replace transaction %s with lower gas fee", replaced.Hash().ToHexString())
+		tp.removeTxLocked(replaced.Hash())
 	}
 
-	tp.validTxMap[txHash] = txEntry
+	tp.putTxLocked(txEntry)
 	return errors.ErrNoError
 }
 
@@ -230,7 +335,7 @@ func (tp *TXPool) CleanCompletedTransactionList(txs []*types.Transaction, height
 	txs = append(txs, cleanedEips...)
 	for _, tx := range txs {
 		if _, ok := tp.validTxMap[tx.Hash()]; ok {
-			delete(tp.validTxMap, tx.Hash())
+			tp.removeTxLocked(tx.Hash())
 			cleaned++
 			log.Infof("transaction cleaned: %s", tx.Hash().ToHexString())
 		}
@@ -328,7 +433,7 @@ func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*VerifiedTx, []*type
 
 	tp.Lock()
 	for _, tx := range oldTxList {
-		delete(tp.validTxMap, tx.Hash())
+		tp.removeTxLocked(tx.Hash())
 		if tx.IsEipTx() {
 			removed := tp.eipTxPool[tx.Payer].Remove(uint64(tx.Nonce))
 			if !removed {
@@ -388,6 +493,77 @@ func (tp *TXPool) GetTransactionHashList() []common.Uint256 {
 	return ret
 }
 
+// eipQueuedNonceLocked returns the lowest nonce of the queued eip155 txs paid by the address,
+// the txs with lower nonce can be packed into a block.
+func (tp *TXPool) eipQueuedNonceLocked(addr common.Address) uint64 {
+	list := tp.eipTxPool[addr]
+	if list == nil {
+		return math.MaxUint64
+	}
+	heading := list.Heading()
+	if len(heading) == 0 {
+		return 0
+	}
+	headNonce := uint64(heading[0].Nonce)
+	if info := tp.userLatestEiptxHeight[addr]; headNonce > 0 && info != nil && headNonce != info.Nonce {
+		return 0
+	}
+	return headNonce + uint64(len(heading))
+}
+
+func (tp *TXPool) getTxInfoList(filter func(tx *types.Transaction) bool) []*PoolTxInfo {
+	tp.RLock()
+	defer tp.RUnlock()
+	queuedNonces := make(map[common.Address]uint64)
+	ret := make([]*PoolTxInfo, 0)
+	for _, txEntry := range tp.validTxMap {
+		tx := txEntry.Tx
+		if filter != nil && !filter(tx) {
+			continue
+		}
+		state := TxPending
+		if tx.IsEipTx() {
+			queuedNonce, ok := queuedNonces[tx.Payer]
+			if !ok {
+				queuedNonce = tp.eipQueuedNonceLocked(tx.Payer)
+				queuedNonces[tx.Payer] = queuedNonce
+			}
+			if uint64(tx.Nonce) >= queuedNonce {
+				state = TxQueued
+			}
+		}
+		ret = append(ret, &PoolTxInfo{Tx: tx, State: state, Attrs: txEntry.GetAttrs()})
+	}
+	return ret
+}
+
+// GetTxInfoList returns all the txs with their states in the pool.
+func (tp *TXPool) GetTxInfoList() []*PoolTxInfo {
+	return tp.getTxInfoList(nil)
+}
+
+// GetTxsByAddress returns the txs paid by the address in the pool.
+func (tp *TXPool) GetTxsByAddress(addr common.Address) []*PoolTxInfo {
+	return tp.getTxInfoList(func(tx *types.Transaction) bool {
+		return tx.Payer == addr
+	})
+}
+
+// RemoveTransaction drops a transaction from the pool and returns it, the
+// eip155 txs with higher nonce of the same payer are queued after that.
+func (tp *TXPool) RemoveTransaction(hash common.Uint256) *types.Transaction {
+	tp.Lock()
+	defer tp.Unlock()
+	txEntry, ok := tp.validTxMap[hash]
+	if !ok {
+		return nil
+	}
+	tx := txEntry.Tx
+	tp.dropTxLocked(tx)
+	log.Infof("transaction dropped: %s", hash.ToHexString())
+	return tx
+}
+
 // checks the tx list in the block from consensus,
 // and returns verified tx list, unverified tx list, and
 // the tx list to be re-verified
@@ -429,7 +605,7 @@ func (tp *TXPool) RemoveTxsBelowGasPrice(gasPrice uint64) {
 	for _, txEntry := range tp.validTxMap {
 		tx := txEntry.Tx
 		if tx.GasPrice < gasPrice {
-			delete(tp.validTxMap, tx.Hash())
+			tp.removeTxLocked(tx.Hash())
 			if tx.IsEipTx() {
 				tp.eipTxPool[tx.Payer].Remove(uint64(tx.Nonce))
 			}
@@ -450,6 +626,9 @@ func (tp *TXPool) Remain() []*types.Transaction {
 		delete(tp.validTxMap, txEntry.Tx.Hash())
 		log.Infof("pool remain: remove tx: %s from pool", txEntry.Tx.Hash().ToHexString())
 	}
+	tp.nativeTxs = make(map[common.Address]map[uint32]common.Uint256)
+	tp.payerTxCount = make(map[common.Address]int)
+	tp.priced = newTxPricedList()
 
 	return txList
 }
@@ -19,12 +19,16 @@
 package common
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"github.com/ontio/ontology/common"
+	"github.com/ontio/ontology/common/config"
 	"github.com/ontio/ontology/common/log"
 	"github.com/ontio/ontology/core/payload"
 	"github.com/ontio/ontology/core/types"
+	"github.com/ontio/ontology/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -94,3 +98,79 @@ func TestTxPool(t *testing.T) {
 
 	txPool.CleanCompletedTransactionList([]*types.Transaction{txn}, 0)
 }
+
+func TestGasPriceBucket(t *testing.T) {
+	cases := map[uint64]uint64{
+		0: 0, 1: 1, 2: 2, 4: 2, 5: 5, 9: 5, 10: 10, 19: 10, 20: 20, 2500: 2000, 5000: 5000, 99999: 50000,
+		math.MaxUint64: 10000000000000000000,
+	}
+	for gasPrice, bucket := range cases {
+		assert.Equal(t, bucket, gasPriceBucketOf(gasPrice), "gas price %d", gasPrice)
+	}
+}
+
+func newPoolTx(payer common.Address, nonce uint32, gasPrice uint64) *VerifiedTx {
+	mutable := &types.MutableTransaction{
+		TxType:   types.InvokeNeo,
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Payer:    payer,
+		Payload:  &payload.InvokeCode{Code: []byte{}},
+	}
+	tx, _ := mutable.IntoImmutable()
+	return &VerifiedTx{Tx: tx, VerifiedHeight: 10}
+}
+
+func TestTxPoolReplaceByFee(t *testing.T) {
+	txPool := NewTxPool()
+	payer := common.Address{1}
+	old := newPoolTx(payer, 1, 1000)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(old))
+
+	// the gas price bump is not enough
+	assert.Equal(t, errors.ErrSameNonceExist, txPool.AddTxList(newPoolTx(payer, 1, 1010)))
+	assert.Equal(t, 1, txPool.GetTransactionCount())
+
+	replacement := newPoolTx(payer, 1, 1011)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(replacement))
+	assert.Equal(t, 1, txPool.GetTransactionCount())
+	assert.Nil(t, txPool.GetTransaction(old.Tx.Hash()))
+	assert.NotNil(t, txPool.GetTransaction(replacement.Tx.Hash()))
+
+	// the same nonce of another payer is not a replacement
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(newPoolTx(common.Address{2}, 1, 500)))
+	assert.Equal(t, 2, txPool.GetTransactionCount())
+}
+
+func TestTxPoolLimits(t *testing.T) {
+	capacity, slots := config.DefConfig.Common.TxPoolCapacity, config.DefConfig.Common.TxPoolPayerSlots
+	defer func() {
+		config.DefConfig.Common.TxPoolCapacity, config.DefConfig.Common.TxPoolPayerSlots = capacity, slots
+	}()
+	config.DefConfig.Common.TxPoolCapacity = 3
+	config.DefConfig.Common.TxPoolPayerSlots = 2
+
+	txPool := NewTxPool()
+	payer := common.Address{1}
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(newPoolTx(payer, 1, 500)))
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(newPoolTx(payer, 2, 500)))
+	assert.Equal(t, errors.ErrTxPoolPayerFull, txPool.AddTxList(newPoolTx(payer, 3, 500)))
+
+	cheapest := newPoolTx(common.Address{2}, 1, 300)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(cheapest))
+	assert.True(t, txPool.IsFull(300))
+	assert.False(t, txPool.IsFull(301))
+
+	// the pool is full and the new tx does not pay more than the cheapest one
+	assert.Equal(t, errors.ErrTxPoolFull, txPool.AddTxList(newPoolTx(common.Address{3}, 1, 300)))
+
+	expensive := newPoolTx(common.Address{3}, 1, 1000)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(expensive))
+	assert.Equal(t, 3, txPool.GetTransactionCount())
+	assert.Nil(t, txPool.GetTransaction(cheapest.Tx.Hash()))
+	assert.NotNil(t, txPool.GetTransaction(expensive.Tx.Hash()))
+
+	// removed txs release the slots of the payer
+	txPool.CleanCompletedTransactionList([]*types.Transaction{expensive.Tx}, 0)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTxList(newPoolTx(common.Address{3}, 2, 100)))
+}
@@ -20,6 +20,7 @@ package common
 import (
 	"container/heap"
 
+	"github.com/ontio/ontology/common"
 	"github.com/ontio/ontology/core/types"
 )
 
@@ -133,3 +134,75 @@ func (m *txSortedMap) Heading() Transactions {
 func (m *txSortedMap) Len() int {
 	return len(m.items)
 }
+
+// priceHeap is a heap.Interface implementation over transactions for retrieving
+// the transaction with the lowest gas price.
+type priceHeap []*types.Transaction
+
+func (h priceHeap) Len() int           { return len(h) }
+func (h priceHeap) Less(i, j int) bool { return h[i].GasPrice < h[j].GasPrice }
+func (h priceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *priceHeap) Push(x interface{}) {
+	*h = append(*h, x.(*types.Transaction))
+}
+
+func (h *priceHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[0 : n-1]
+	return x
+}
+
+// txPricedList is a price-sorted heap to allow evicting the cheapest transactions
+// when the pool is full. The transactions removed from the pool are dropped from
+// the heap lazily.
+type txPricedList struct {
+	items  *priceHeap // Heap of prices of all the stored transactions
+	stales int        // Number of stale price points to (re-heap trigger)
+}
+
+// newTxPricedList creates a new price-sorted transaction heap.
+func newTxPricedList() *txPricedList {
+	return &txPricedList{
+		items: new(priceHeap),
+	}
+}
+
+// Put inserts a new transaction into the heap.
+func (l *txPricedList) Put(tx *types.Transaction) {
+	heap.Push(l.items, tx)
+}
+
+// Removed notifies the heap that a transaction was removed from the pool, the
+// heap is rebuilt once the stale entries are more than the live ones.
+func (l *txPricedList) Removed(all map[common.Uint256]*VerifiedTx) {
+	l.stales++
+	if l.stales <= len(all) {
+		return
+	}
+	l.stales = 0
+	reheap := make(priceHeap, 0, len(all))
+	for _, txEntry := range all {
+		reheap = append(reheap, txEntry.Tx)
+	}
+	heap.Init(&reheap)
+	l.items = &reheap
+}
+
+// Cheapest returns the transaction with the lowest gas price in the pool, or nil if the pool is empty.
+func (l *txPricedList) Cheapest(all map[common.Uint256]*VerifiedTx) *types.Transaction {
+	for l.items.Len() > 0 {
+		tx := (*l.items)[0]
+		if txEntry, ok := all[tx.Hash()]; ok && txEntry.Tx == tx {
+			return tx
+		}
+		heap.Pop(l.items)
+		if l.stales > 0 {
+			l.stales--
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ package common
 
 import (
 	"math/big"
+	"sort"
 	"sync/atomic"
 
 	"github.com/ontio/ontology/common"
@@ -32,7 +33,6 @@ import (
 )
 
 const (
-	MAX_CAPACITY            = 100140      // The tx pool's capacity that holds the verified txs
 	MAX_PENDING_TXN         = 4096 * 10   // The max length of pending txs
 	MAX_LIMITATION          = 10000       // The length of pending tx from net and http
 	UPDATE_FREQUENCY        = 100         // The frequency to update gas price from global params
@@ -65,6 +65,94 @@ type TxStatus struct {
 	Attrs []*TXAttr      // transaction's status
 }
 
+// TxState enumerates the state of a transaction in the pool
+type TxState uint8
+
+const (
+	TxVerifying TxState = iota // the tx is being verified
+	TxPending                  // the tx is verified and can be packed into a block
+	TxQueued                   // the eip155 tx is verified but waits for the txs with lower nonce
+)
+
+func (s TxState) String() string {
+	switch s {
+	case TxVerifying:
+		return "verifying"
+	case TxPending:
+		return "pending"
+	case TxQueued:
+		return "queued"
+	}
+	return "unknown"
+}
+
+// PoolTxInfo describes a transaction in the pool for inspection
+type PoolTxInfo struct {
+	Tx    *types.Transaction
+	State TxState
+	Attrs []*TXAttr // the verified results
+}
+
+// GasPriceBucket counts the txs whose gas price is in [MinGasPrice, next bucket's MinGasPrice)
+type GasPriceBucket struct {
+	MinGasPrice uint64
+	Count       uint32
+}
+
+// TxPoolStats contains the statistics of the txs in the pool
+type TxPoolStats struct {
+	Verifying uint32
+	Pending   uint32
+	Queued    uint32
+	GasPrices []*GasPriceBucket // gas price histogram in ascending order, empty buckets are omitted
+}
+
+// gasPriceBucketOf returns the lower bound of the bucket in the 1-2-5 series, such as 0, 1, 2, 5, 10, 20, 50...
+func gasPriceBucketOf(gasPrice uint64) uint64 {
+	if gasPrice == 0 {
+		return 0
+	}
+	decade := uint64(1)
+	for gasPrice/decade >= 10 {
+		decade *= 10
+	}
+	switch lead := gasPrice / decade; {
+	case lead >= 5:
+		return 5 * decade
+	case lead >= 2:
+		return 2 * decade
+	}
+	return decade
+}
+
+// NewTxPoolStats makes the statistics of the txs
+func NewTxPoolStats(txs []*PoolTxInfo) *TxPoolStats {
+	stats := &TxPoolStats{}
+	buckets := make(map[uint64]*GasPriceBucket)
+	for _, info := range txs {
+		switch info.State {
+		case TxVerifying:
+			stats.Verifying++
+		case TxPending:
+			stats.Pending++
+		case TxQueued:
+			stats.Queued++
+		}
+		bound := gasPriceBucketOf(info.Tx.GasPrice)
+		bucket := buckets[bound]
+		if bucket == nil {
+			bucket = &GasPriceBucket{MinGasPrice: bound}
+			buckets[bound] = bucket
+			stats.GasPrices = append(stats.GasPrices, bucket)
+		}
+		bucket.Count++
+	}
+	sort.Slice(stats.GasPrices, func(i, j int) bool {
+		return stats.GasPrices[i].MinGasPrice < stats.GasPrices[j].MinGasPrice
+	})
+	return stats
+}
+
 type TxResult struct {
 	Err  errors.ErrCode
 	Hash common.Uint256
@@ -141,6 +229,10 @@ type TxPoolService interface {
 	GetTxList() []common.Uint256
 	AppendTransaction(sender SenderType, txn *types.Transaction) *TxResult
 	AppendTransactionAsync(sender SenderType, txn *types.Transaction)
+	GetTxInfoList() []*PoolTxInfo
+	GetTxsByAddress(addr common.Address) []*PoolTxInfo
+	GetTxPoolStats() *TxPoolStats
+	DropTransaction(hash common.Uint256) bool
 }
 
 // VerifyBlockReq specifies that api that how to verify a block from consensus.
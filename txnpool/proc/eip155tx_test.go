@@ -24,8 +24,10 @@ import (
 	ethcomm "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ontio/ontology/common"
 	sysconfig "github.com/ontio/ontology/common/config"
 	txtypes "github.com/ontio/ontology/core/types"
+	tc "github.com/ontio/ontology/txnpool/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -75,3 +77,44 @@ func Test_From(t *testing.T) {
 	assert.Equal(t, otx3.Payer, otx4.Payer)
 	assert.Equal(t, otx1.Payer, otx4.Payer)
 }
+
+func Test_TxPoolInspect(t *testing.T) {
+	initCfg()
+	pool := tc.NewTxPool()
+	txs := []*txtypes.Transaction{
+		genTxWithNonceAndPrice(0, 2500),
+		genTxWithNonceAndPrice(1, 3000),
+		genTxWithNonceAndPrice(3, 600),
+	}
+	for _, tx := range txs {
+		assert.True(t, pool.AddTxList(&tc.VerifiedTx{Tx: tx, VerifiedHeight: 10, Nonce: 0}).Success())
+	}
+
+	infos := pool.GetTxsByAddress(txs[0].Payer)
+	assert.Equal(t, 3, len(infos))
+	states := make(map[uint32]tc.TxState)
+	for _, info := range infos {
+		states[info.Tx.Nonce] = info.State
+	}
+	assert.Equal(t, map[uint32]tc.TxState{0: tc.TxPending, 1: tc.TxPending, 3: tc.TxQueued}, states)
+	assert.Equal(t, 0, len(pool.GetTxsByAddress(common.ADDRESS_EMPTY)))
+
+	stats := tc.NewTxPoolStats(pool.GetTxInfoList())
+	assert.Equal(t, uint32(2), stats.Pending)
+	assert.Equal(t, uint32(1), stats.Queued)
+	assert.Equal(t, []*tc.GasPriceBucket{{MinGasPrice: 500, Count: 1}, {MinGasPrice: 2000, Count: 2}}, stats.GasPrices)
+
+	// dropping nonce 1 makes nonce 3 still queued and keeps nonce 0 pending
+	assert.Equal(t, txs[1], pool.RemoveTransaction(txs[1].Hash()))
+	assert.Nil(t, pool.RemoveTransaction(txs[1].Hash()))
+	stats = tc.NewTxPoolStats(pool.GetTxInfoList())
+	assert.Equal(t, uint32(1), stats.Pending)
+	assert.Equal(t, uint32(1), stats.Queued)
+	assert.Equal(t, uint64(1), pool.NextNonce(txs[0].Payer))
+
+	// dropping nonce 0 queues all the txs of the payer
+	pool.RemoveTransaction(txs[0].Hash())
+	stats = tc.NewTxPoolStats(pool.GetTxInfoList())
+	assert.Equal(t, uint32(0), stats.Pending)
+	assert.Equal(t, uint32(1), stats.Queued)
+}
@@ -120,7 +120,7 @@ func (ta *TxPoolService) handleTransaction(sender tc.SenderType, txn *tx.Transac
 		return
 	}
 
-	if ta.server.getTransactionCount() >= tc.MAX_CAPACITY {
+	if ta.server.txPool.IsFull(txn.GasPrice) {
 		log.Debugf("handleTransaction: transaction pool is full for tx %x", txn.Hash())
 
 		replyTxResult(txResultCh, txn.Hash(), errors.ErrTxPoolFull, "transaction pool is full")
@@ -215,6 +215,22 @@ func (ta *TxPoolService) GetTxList() []common.Uint256 {
 	return ta.server.getTxHashList()
 }
 
+func (ta *TxPoolService) GetTxInfoList() []*tc.PoolTxInfo {
+	return ta.server.TxInfoList()
+}
+
+func (ta *TxPoolService) GetTxsByAddress(addr common.Address) []*tc.PoolTxInfo {
+	return ta.server.getTxsByAddress(addr)
+}
+
+func (ta *TxPoolService) GetTxPoolStats() *tc.TxPoolStats {
+	return ta.server.getTxPoolStats()
+}
+
+func (ta *TxPoolService) DropTransaction(hash common.Uint256) bool {
+	return ta.server.dropTransaction(hash)
+}
+
 func (ta *TxPoolService) AppendTransaction(sender tc.SenderType, txn *tx.Transaction) *tc.TxResult {
 	ch := make(chan *tc.TxResult, 1)
 	ta.handleTransaction(sender, txn, ch)
@@ -21,6 +21,7 @@
 package proc
 
 import (
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -311,6 +312,65 @@ func (s *TXPoolServer) getTxHashList() []common.Uint256 {
 	return ret
 }
 
+// appendVerifyingTxsLocked appends the txs being verified to the tx list of the pool
+func (s *TXPoolServer) appendVerifyingTxsLocked(txs []*tc.PoolTxInfo, filter func(tx *txtypes.Transaction) bool) []*tc.PoolTxInfo {
+	existedTxHash := make(map[common.Uint256]bool, len(txs))
+	for _, info := range txs {
+		existedTxHash[info.Tx.Hash()] = true
+	}
+	for hash, pt := range s.allPendingTxs {
+		if existedTxHash[hash] || (filter != nil && !filter(pt.tx)) {
+			continue
+		}
+		txs = append(txs, &tc.PoolTxInfo{
+			Tx:    pt.tx,
+			State: tc.TxVerifying,
+			Attrs: pt.checkingStatus.GetTxAttr(),
+		})
+	}
+	return txs
+}
+
+// TxInfoList returns the txs in the pool and the txs being verified
+func (s *TXPoolServer) TxInfoList() []*tc.PoolTxInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.appendVerifyingTxsLocked(s.txPool.GetTxInfoList(), nil)
+}
+
+// getTxsByAddress returns the txs paid by the address in order of nonce
+func (s *TXPoolServer) getTxsByAddress(addr common.Address) []*tc.PoolTxInfo {
+	s.mu.RLock()
+	ret := s.appendVerifyingTxsLocked(s.txPool.GetTxsByAddress(addr), func(tx *txtypes.Transaction) bool {
+		return tx.Payer == addr
+	})
+	s.mu.RUnlock()
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Tx.Nonce < ret[j].Tx.Nonce
+	})
+	return ret
+}
+
+// getTxPoolStats returns the statistics of the txs in the pool and the txs being verified
+func (s *TXPoolServer) getTxPoolStats() *tc.TxPoolStats {
+	return tc.NewTxPoolStats(s.TxInfoList())
+}
+
+// dropTransaction removes a tx from the pool or stops verifying it, it returns false if the tx is not found
+func (s *TXPoolServer) dropTransaction(hash common.Uint256) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.txPool.RemoveTransaction(hash) != nil {
+		return true
+	}
+	if s.allPendingTxs[hash] == nil {
+		return false
+	}
+	s.removePendingTxLocked(hash, errors.ErrTxDropped)
+	log.Infof("transaction dropped from pending pool: %s", hash.ToHexString())
+	return true
+}
+
 // cleanTransactionList cleans the txs in the block from the ledger
 func (s *TXPoolServer) cleanTransactionList(txs []*txtypes.Transaction, height uint32) {
 	s.txPool.CleanCompletedTransactionList(txs, height)
@@ -357,11 +417,6 @@ func (s *TXPoolServer) getTxStatusReq(hash common.Uint256) *tc.TxStatus {
 	return s.txPool.GetTxStatus(hash)
 }
 
-// getTransactionCount returns the tx size of the transaction pool.
-func (s *TXPoolServer) getTransactionCount() int {
-	return s.txPool.GetTransactionCount()
-}
-
 // re-verify a transaction's stateful data.
 func (s *TXPoolServer) reVerifyStateful(tx *txtypes.Transaction, sender tc.SenderType) {
 	pt := s.setPendingTx(tx, sender, nil)